@@ -43,6 +43,8 @@ const (
 const (
 	ExperimentStatusActive ExperimentStatus = "active"
 
+	ExperimentStatusDraft ExperimentStatus = "draft"
+
 	ExperimentStatusInactive ExperimentStatus = "inactive"
 )
 
@@ -52,6 +54,8 @@ const (
 
 	ExperimentStatusFriendlyDeactivated ExperimentStatusFriendly = "deactivated"
 
+	ExperimentStatusFriendlyDraft ExperimentStatusFriendly = "draft"
+
 	ExperimentStatusFriendlyRunning ExperimentStatusFriendly = "running"
 
 	ExperimentStatusFriendlyScheduled ExperimentStatusFriendly = "scheduled"
@@ -132,8 +136,10 @@ type Experiment struct {
 	Id          *int64             `json:"id,omitempty"`
 	Interval    *int32             `json:"interval"`
 	Name        *string            `json:"name,omitempty"`
+	Priority    *int32             `json:"priority,omitempty"`
 	ProjectId   *int64             `json:"project_id,omitempty"`
 	Segment     *ExperimentSegment `json:"segment,omitempty"`
+	Shadow      *bool              `json:"shadow,omitempty"`
 	StartTime   *time.Time         `json:"start_time,omitempty"`
 	Status      *ExperimentStatus  `json:"status,omitempty"`
 
@@ -161,7 +167,9 @@ type ExperimentHistory struct {
 	Id           int64                 `json:"id"`
 	Interval     *int32                `json:"interval"`
 	Name         string                `json:"name"`
+	Priority     *int32                `json:"priority,omitempty"`
 	Segment      ExperimentSegment     `json:"segment"`
+	Shadow       *bool                 `json:"shadow,omitempty"`
 	StartTime    time.Time             `json:"start_time"`
 	Status       ExperimentStatus      `json:"status"`
 	Tier         ExperimentTier        `json:"tier"`
@@ -249,18 +257,62 @@ type ProjectSegmenters_Variables struct {
 
 // ProjectSettings defines model for ProjectSettings.
 type ProjectSettings struct {
-	CreatedAt            time.Time         `json:"created_at"`
-	EnableS2idClustering bool              `json:"enable_s2id_clustering"`
-	Passkey              string            `json:"passkey"`
-	ProjectId            int64             `json:"project_id"`
-	RandomizationKey     string            `json:"randomization_key"`
-	Segmenters           ProjectSegmenters `json:"segmenters"`
+	// Whether the experiments with two or more treatments of identical configurations are blocked
+	BlockDuplicateTreatmentConfigs *bool `json:"block_duplicate_treatment_configs,omitempty"`
+
+	// Whether the experiments whose segments list as many values of a segmenter as it allows are blocked
+	BlockExhaustiveSegmentValues *bool `json:"block_exhaustive_segment_values,omitempty"`
+	// Whether disabling a default tier experiment that would orphan an override tier experiment is blocked
+	BlockOrphanedOverrides *bool              `json:"block_orphaned_overrides,omitempty"`
+	CreatedAt              time.Time          `json:"created_at"`
+	DefaultSegment         *ExperimentSegment `json:"default_segment,omitempty"`
+	DefaultTier            *ExperimentTier    `json:"default_tier,omitempty"`
+	DefaultType            *ExperimentType    `json:"default_type,omitempty"`
+	EnableS2idClustering   bool               `json:"enable_s2id_clustering"`
+
+	// Names of the registered custom validators that are run on the experiments
+	EnabledValidators *[]string `json:"enabled_validators,omitempty"`
+
+	// Number of running experiments beyond which creating or enabling an active experiment is blocked
+	HardMaxActiveExperiments *int `json:"hard_max_active_experiments,omitempty"`
+
+	// Maximum size in bytes of the configuration of each treatment, marshaled as JSON
+	MaxTreatmentConfigBytes *int `json:"max_treatment_config_bytes,omitempty"`
+
+	// Segmenters that are ignored when checking the orthogonality of the experiments
+	OrthogonalityExemptSegmenters *[]string `json:"orthogonality_exempt_segmenters,omitempty"`
+
+	// Number of seconds by which the end of the time window in which the other experiments are checked for orthogonality is extended
+	OrthogonalityLookaheadSeconds *int   `json:"orthogonality_lookahead_seconds,omitempty"`
+	Passkey                       string `json:"passkey"`
+	PriorityOverlapResolution     *bool  `json:"priority_overlap_resolution,omitempty"`
+	ProjectId                     int64  `json:"project_id"`
+	RandomizationKey              string `json:"randomization_key"`
+
+	// Groups of segmenters that must be set together, i.e., an experiment that sets any segmenter of a group must set all of them
+	RequiredSegmenterCombinations *[][]string       `json:"required_segmenter_combinations,omitempty"`
+	Segmenters                    ProjectSegmenters `json:"segmenters"`
+
+	// Number of running experiments beyond which creating or enabling an active experiment is allowed with a warning
+	SoftMaxActiveExperiments *int `json:"soft_max_active_experiments,omitempty"`
+
+	// Object that is deep-merged into the configuration of each treatment before it is validated
+	TreatmentConfigDefaults *map[string]interface{} `json:"treatment_config_defaults,omitempty"`
+
+	// Regular expression that the names of the treatments of the experiments must match
+	TreatmentNamePattern *string `json:"treatment_name_pattern,omitempty"`
 
 	// Object containing information to define a valid treatment schema
 	TreatmentSchema *TreatmentSchema `json:"treatment_schema,omitempty"`
-	UpdatedAt       time.Time        `json:"updated_at"`
-	Username        string           `json:"username"`
-	ValidationUrl   *string          `json:"validation_url,omitempty"`
+
+	// Whether the segments of the experiments are also stored and matched as typed values
+	TypedSegmentStorage *bool     `json:"typed_segment_storage,omitempty"`
+	UpdatedAt           time.Time `json:"updated_at"`
+	Username            string    `json:"username"`
+	ValidationUrl       *string   `json:"validation_url,omitempty"`
+
+	// Token bucket rate limit on the requests to the validation URL of a project
+	ValidationUrlRateLimit *ValidationUrlRateLimit `json:"validation_url_rate_limit,omitempty"`
 }
 
 // PubSub defines model for PubSub.
@@ -420,6 +472,19 @@ type TreatmentServiceConfig struct {
 	SegmenterConfig *SegmenterConfig `json:"segmenter_config,omitempty"`
 }
 
+// Token bucket rate limit on the requests to the validation URL of a project
+type ValidationUrlRateLimit struct {
+
+	// Capacity of the bucket, i.e., the number of requests that may be sent at once
+	Burst int `json:"burst"`
+
+	// Maximum time for which a request beyond the rate is queued before it is rejected
+	MaxWaitMillis *int `json:"max_wait_millis,omitempty"`
+
+	// Rate at which the bucket is refilled
+	RequestsPerSecond float64 `json:"requests_per_second"`
+}
+
 // Getter for additional properties for ProjectSegmenters_Variables. Returns the specified
 // element and whether it was found
 func (a ProjectSegmenters_Variables) Get(fieldName string) (value []string, found bool) {
@@ -528,40 +593,53 @@ func (a SegmenterOptions) MarshalJSON() ([]byte, error) {
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
-
-	"H4sIAAAAAAAC/+xaW6/jthH+K4TavmnPFmnRB7+ladM+NNlFfJA+5CwMWhzZTChSGVJ23IX/e8GL7rQu",
-	"XiPZA+TpyNLMcDg3znw8H5NMFaWSII1ONh8TnR2hoO7xKyW1Qcqlsb9KVCWg4eC+USHUGdjuREXl33AD",
-	"hXv4I0KebJI/vG0Fvw1S327hUIA0gN97vmuamEsJySahiPRif6vScCWXS3oX6K9pUiLsEH6uuOZmhVLv",
-	"Eb6rucYaXdPEyURgyeaH4Rrp0BIfGn61/xEyYwX+E1Hh2IaZYmD/BnptkMuDpYeafvSlAK3pIcY1UNPJ",
-	"bulrmVHtfikBuTVmREUEaoDtqPuWKyzsU8KogTeGF1bwSEcGOkPuvGKZZCUE3QtINgYriNCDZDsna/EK",
-	"nPVouTR/+2tLx6WBA6AjtAFyomJI/pcvkvSWYh12SYu4g0pU1nq7xYpoH61zkdi6IoS34zUUzUoLaUNN",
-	"pVcs5+kbzl2OHCQTl7Uivq75bB5xwOX8z9ybytiQK+pytCiBO0Jq5lhl8b8Xi7LU1zSpSrY6BWqe/SUa",
-	"PidAHbJjNnaukxn7NQfhYhBkVdi85ywJcRv4xh4NjukFVicLezvuueNDZKetKv/m2ii8vJYaAo3iy7P4",
-	"N687r6eM/J77j8n97pneD9lWVD9deqns6JpobCpDHUeDGhDc3RSIjjuaatLJ5kGl6Gx8us3YtlE8RdXE",
-	"XlPbJM0MP1ktwsN0RRqcSJuP/bKSPB+BVBrwTV0aSSao1jznGbUkROWktTnx1gH9RCxjRg0cFHLQhCK8",
-	"SA0ifwO/lIJKauvgE/lWGSDmSA0xlr5CtFKsqUkp6EUTSlAJIFw6AgY5l9yu+yJVTrQqwCpgjqChXfvF",
-	"O9gbBCsp7a5T17WzSoB1t41sAcY9M3CWsn6ZMdZzSFgGOa2Ei/Lw1K7XvlEnQORszgNtSkaaX5nzQ4W0",
-	"rvF933zV/Uyo1irjdhfkzM3R2evATyBJE6JJJOTqOtoX/S1tLBtjb/dhkOY5z8YS/nsE77NOdHBNCmqs",
-	"G1L36U8ksBOjyB4I4wiZ3YBR/ZWfXqQfYagguUKyPXOTHfc0+4m0hgyOH50lMxWjb+RgkOnkfA6Fsvb5",
-	"l2//nqRJq1TU4+/pwT6NnFyGWWXggKrYA9YuqBOj9HPK7BZTJ1VH0lkZKohshHuyRRKNZZ2XiKArYYKj",
-	"uTw4/X+uAC8kQ24AOb3DSX5xv62k3l3MSb05dWRrXQ/Eu7n2AfDhY/tgSwNdIivH9+emqsd0kIt7NaSS",
-	"qYL/z+XI7ie4TJuub7RxzRj0HXd1EBrwhg8HdnbH+8SJXAuK7bK3pwl3bHs77zvGCo9k4n+4NjZf2gWI",
-	"pXS1m0sSBKf25CuRK+TmQhQywCfbrSy27Ykit420B6QY476Kvu+pGNesYbU6nI8882eKBuGLdKO5reu2",
-	"ytel21ZyQH4CRnJUxSp9+6p8Q8vS1pCunerDoa7bwLpHTLvfkbcGceH90rXQpION4fKgH5N3IO2CO/0F",
-	"Z7tMVNpWRX80BNK9UgKo9IVc61sJtxpiuSePp1HBYfh3B5SdJ5sT0jRAW0/++IpgnSw487uuUMwXjY5l",
-	"FxaP2k+zZeSm+6PhV+231T7SNLTHQD9jgkd8LQndgxdCdLXvTkDjVFQlz3bxfvDZflsvNAbMfFeJyAJf",
-	"EqxEGAWsvzUpKboyRDtdv//tnNk2hySEWRopvDfSBpgdX6Jq/EsRA0UpqHGtK4K286NXrKi0IQimQkko",
-	"CUlK3Gkd3fuw3CTdtT/csM1ERbYm0l4VZxOYMsaipsU5I1KGO+Pnr9hmfB5Y8sMBjVgWhPUmsMnYDBG4",
-	"Hgojfrp3Pglx84/LPft5wU0d9QOQ1AJOIxzpblioOV+jEEG4f1w+qXTuLCOp/wAoenwlVwnD/VjD4m3O",
-	"zeD6hKvO1lGxFXWm5uHORuzWUS+Ge1u+Fu1t2iKrF2izy23yTzfgF9v9ZqrYc9nAbdG+nOt+P55ROdWH",
-	"xxeM9dEpOR9BkkoDs+tlSv5YycwypsNF+lqsavvvgaIbG9+PRMfP6IDi1pE3CN8JT6a9dOzInkxqD+NF",
-	"kd5RVN8c4nrXJBEB2zra64PmINTeQyqhlZw4b5ow7vA3MHODOE8KGEJmgSR1GRnw94MLGgQqpmV93wAz",
-	"SsK7PNn8MI6wSMY3rzxYlVw/OKF+mp1AYe+5Bevw3KxsBRjKqKHzcT5Q8ZuasVtVVkv5h5Mwc30y3Ed3",
-	"wc4O4vEdW3A1xl1powqSPQLqXt3p/I6Jz2Hit2NzKo3uu2jsCFjTsaWJbiyzO3PJ1Dnk8fimy38mnBHN",
-	"ZQbO4Hs4cHeFNATjgxL16479W02fXuSzPRXdAUHOXAiipLhYx2owQ7+1fJpQyZzYjkqGov1gyJ/HXl15",
-	"N9p2qUO3xLy85opqxPxKJsZfZeprDLly7mv4bk9+n6kf2l7plU54vQ10x7vufxEN6+Xdk94QCx1VqXeO",
-	"1J6HhnJXlbj0W3IolVoADPUDB2vIaQ4m0iPTeNbpbQCeeAZtizuAMav9Tnt8cxJm9iho73Yua0QumhGC",
-	"BpGsvLr/RcpVspGVELbtB0lLnmwSh+qao/Zfrv8PAAD//7mpDQoGLAAA",
+	"H4sIAAAAAAACA+0b247buPVXCHf75kyKbdGHvG3T3S2K3JCZTR82gUBLtM2MJGpJaibexfx7z+FNpETL",
+	"sjNIN8UCQcayeA4Pz/1C/7YqRdOJlrVarZ79tlLlnjXUfHwuWqUl5a3Gp06KjknNmXlH61rcs6q4o3Vv",
+	"v+GaNebDN5JtV89Wf3o6IH7qsD69ZrsGvmDynYV7WK/0oWOwnEpJD/gsOs1h48WYXrv1ANpJVkj2S88V",
+	"ELOcqDeSvfVQU4rgC4NTsmr17OfxHusxJz4EeLH5yEqNCL+XUsgpD0tRMfzr1gOvebvD9cyvn7xpmFJ0",
+	"l4MakWlwD+s9zix1n4AmjszMkCgZ1XA4at5thWzw06qCL59ogFmtpzRWTJWSG6kgUNvXNd3UsEbLnmXW",
+	"s7YqDK7FO/AqWQsK+ve/Devgke2YNAtRQUAw4+V//RaWHyEsAm9pkxdQJ7mQXB+yeKd4gKfI7GIx3coq",
+	"9ynFHSTnrMHA7mkl7iOyN0LUjLbmnaZSn8lsgNG9OoMUuz5AFlvJQcT14VwUP3g4NEnO5HL4G27ZqFF7",
+	"G+/ZFvmCCIkHzjkp+7wYFa4GqL6rzrYmD7M5ZDXxjknlDO2kXj3MGv8PnNVGP1nbN+hCQFudCTi4qUSd",
+	"YBLFigw6OXEijg+Zkw6k/IsrLeTha3FHLBC+3MK/Nhf2/+GR/nAjj+NG4kwj1f4BVWp5iVcw64JiByfj",
+	"dWzkTpy4g6+JxBEcU+QYRk4nOvh88nM9aPjcqqB7wU22tNT8DqkIHypJt/qEkxsFOcCYeKrVzZ6RXjH5",
+	"xHtbUtZUKb7lJcUlRGzJwHtiucTUFUFAWMJ2YN9MESrZ+1axevsEVte0pehar8groRnRe6rhP1jfS4lY",
+	"kOUEVh0AjkiwUsJbs6BiW95y3Pd9CxsrAevgL7xSbNj7vRW0ZYzs2xZPvTY1RdXXDMWOGl4zbT5XzHCM",
+	"uqcFTLtxBgzk0L42Wu8+DfsO3wjQRckhET6BNJhoJkVvt3zXS+rDRyqj5/FrAtIRJcfTkHuu94ZvO1AI",
+	"4GDYIaOC3kWnqF/RwOEc+HAOqNC2oBNTDP/ZMyu7SEu4ImDcKI61efVn4sCJFmQDYgarLvEA8JjsDKK1",
+	"hRatCXgIcg0nLPcbWt6SgZFOAU6Gk0mtEjPZMWTeWG+c4/Qy/+7pPwBwICor8Td0h58mQu5cRTUSQN9s",
+	"mPQi8AbS2WpqSdIPK1XGrIUGFrYBuV22CKNG0NMYJVOg/k7QcGBD/y89k+BBIOIDB+kFQrKb22Ot/Oly",
+	"Qkqq6QmvlS/bi6OZybDksZsLoyONaMnsnD+fKeYeJzldnAZK2lai4b8aGylu2WGedSnTpj5jlIdclFFA",
+	"bDoiwxGfTbifidAeUe6UyZlmxHGdnDwVDCLPWOILqDLQXoYNCK40vhuinkO8xgjok2UiZMXkFWYvi3l7",
+	"R8HgIEe3bbOq4taLvklIzFMWQJGG+z0vbUyBYG6ddKAc/Tp6ee+60ZODnd/B01aK5ix6U1Je0q5DHxLz",
+	"yQcH77dhlyjEDOedSGukF1YuMYdmBaw1EJIR76YW5W1R9V2NmRErQswqLIEqGxrhAHIUHZUL2/cC5Ewa",
+	"IaP4p5ADkEq0GjapSRKyTI5FDBkmkZlWOZZE9mlPe4UpYuF4GXm4hQTuBeZbFlqRGhWFoqtvD8TiQjpp",
+	"JCt4y2ENNimX0ilkt6ctmKfPn2YIrLgC0aGGUOISL4J5eqwRJsu8F31dEYuawD+Pe7IaVHmOxMtaAIaw",
+	"4nOKWI/jshIyQF9U8LEWzaNQ3/KqKGtQISZdIjPlj11r2tG8wlxf5XNL5TMbyXYcMYIVl4BaNGQAtaJD",
+	"rYFknohxOqnOcix7KquioZ8KWyYVMZ6Z7MuVEYkZbNhBtJVziUYjjI8CPWq9NoKSmW1OqVYUY5G2sfOA",
+	"ulHn9P8l/cSbviGK/2pqJLMsJItJWYB1GkXX7VGvwV6l2lMQE5rnv69fv8rSI6Teix0GCwg9wC7WdEGH",
+	"WU6uQxAcBMd3rUDZ3mM1ABpW3vqUMEHvSb9UuCmttRC3dM9oBeQCM6pZAbslwMIoxkHFG6ofboIyZAb3",
+	"aRwUxgPFeoEHNmfEwAfqkB7RBEkNmI/Iv4MC7lhm5TMA4xNr2hWQZYu692Xh1A7P7vkvy/B8/Bz0ABS1",
+	"2fCWhqlZyucfpeg7NcpzjHY0YO2YKSgGLlrsjD+HbOeKXa3RfMYOHJYBgyHODLHFhJodbmCRISaINE5w",
+	"SdqxXJPGz6nCz8/xxqkggout/p97HTcjtPkFJfdUus5IpsgbOyAXOjLUvjY5kpUObFIx1j1pmNzBRoBQ",
+	"LHFFcKYt5jncYHCOPzaPIQ8bCMO8reioBhZneiJv2a6vqbFLsBFsBQ5tpjYOPGluNc50jEKZEjbf9PDE",
+	"WOGfUo3Q4rm2y52eBTMqcOCQbQPEmVjIuzL0ouuhtRIEMaFjB01xrRZ08mY3l6Jl85pHLsEwqzbCRGfS",
+	"y3rBkkJi9lzzhp/MkN4FwJ9k/RbAXhio6aw8+MCFBaB3wSdLwaNJUbaE6DfX/SbT+BlK+VTozpPYetAJ",
+	"2yIhqt/EXe2pZoqOl0W+p3eD785Hmpvbve3rzAbfgd+qXVsXVUiRjkpt/fTQwbXPRviRJ3CGtM4Uz0ci",
+	"IqtMwZUj40fwPpCu1PB64giMZUumewnekjgTsKaRPfu4ZFzFe384wpuZqhpZ5EKg4QmbY8aixpMRRiZ0",
+	"RSOFL9gqmpHYF7yG8OhDqpwVuP1mRte5PrCDetQp8+dL57MmrC6KLabi9zVCjMh3w8FhiDiZDV486gt5",
+	"YXbM4266Le82R7fjMqb/CDcVppe/IA3ktmVU5YuOo8r1GZfqBkFl5/elON3RCGivzerFI/wBbpjgh8QP",
+	"6WJKF1s0/vkm6gFz86hMOtpbhRw46amWEJhmeqn5DXO90LWtvyHTwdwcs/KPfVsi4Hq8SUrFWUX4JdcL",
+	"Ao8vv12Qj9FuMu81b6S+M5JcJ+YY4Z41ajuKzU7vJ1p9tBGf3KLJILj22u4Dza4WGzsWc6nkTLwJahzB",
+	"hxsD4RbBLILx2NMtWRuLdHcqdkZpgLn1PK53ofUMGvEa9OPnqYZlLD58ZQeOq4cPBqmdSMxM0i+5JBXB",
+	"HPVsDdMUdJOe1vMRiS89YOxVzsbyT4PhxJWY8TniDaMT5PU7t+HZ9xRsY7d8jOsKZ2c6f9xrOHWv4bhu",
+	"zpnRhbOEAcE5GRuE7MCZwjZjnR1Pby35Xm1FFG9LZhi+YTtuO2qjCxWOCP91xP+BUmD/DUZFEyAAPbYY",
+	"2/rgm5cjuSVdmbYyaCOS8GoZvNDkL1OpnnnfbchSx2LJSfmca0YT4K+kYvwiVV9g5Jl1X4A7Xvn9TuUw",
+	"5EpfaYWXHCAu7+JL5mN/eXGlN+72Huucw4Yaskz0Sry1RzJdKrGgMZQqjvQtp1NtIjVhjQWdPwaTd7xk",
+	"Q4o7amP2m0LZ/ubseMR2QZMbVmVAuahGcBRkrfJITzjTBL2F+L3py1tw3dhyJqbl7KfLrhhQPlIPPWry",
+	"09sXtnXpk+2xFDa9VJkdn9OOltGM0+7tR11mLhFdnvPbm1YldUEGJE+RxJIdnRzfU66LBmITnxkXm1km",
+	"xitbcVK/n58tGQ4gTyDawQuolNIJjWQfTaaQnyA62gtgiRu8ZsYziB3vY4QxqhOFwb4F+g32wWGIHn9g",
+	"sB6n/WMtzuy9dgKZ6vaD+WHDVqye4S8YsEhkLe04rDAzAL1X9s3DfwEFgFE1njgAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
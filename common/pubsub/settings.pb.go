@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.26.0
+// 	protoc-gen-go v1.28.1
 // 	protoc        v3.19.4
 // source: api/proto/settings.proto
 
@@ -225,14 +225,15 @@ type ProjectSettings struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ProjectId            int64                  `protobuf:"varint,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
-	CreatedAt            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	Username             string                 `protobuf:"bytes,4,opt,name=username,proto3" json:"username,omitempty"`
-	Passkey              string                 `protobuf:"bytes,5,opt,name=passkey,proto3" json:"passkey,omitempty"`
-	EnableS2IdClustering bool                   `protobuf:"varint,6,opt,name=enable_s2id_clustering,json=enableS2idClustering,proto3" json:"enable_s2id_clustering,omitempty"`
-	Segmenters           *Segmenters            `protobuf:"bytes,7,opt,name=segmenters,proto3" json:"segmenters,omitempty"`
-	RandomizationKey     string                 `protobuf:"bytes,8,opt,name=randomization_key,json=randomizationKey,proto3" json:"randomization_key,omitempty"`
+	ProjectId                 int64                  `protobuf:"varint,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	CreatedAt                 *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt                 *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Username                  string                 `protobuf:"bytes,4,opt,name=username,proto3" json:"username,omitempty"`
+	Passkey                   string                 `protobuf:"bytes,5,opt,name=passkey,proto3" json:"passkey,omitempty"`
+	EnableS2IdClustering      bool                   `protobuf:"varint,6,opt,name=enable_s2id_clustering,json=enableS2idClustering,proto3" json:"enable_s2id_clustering,omitempty"`
+	Segmenters                *Segmenters            `protobuf:"bytes,7,opt,name=segmenters,proto3" json:"segmenters,omitempty"`
+	RandomizationKey          string                 `protobuf:"bytes,8,opt,name=randomization_key,json=randomizationKey,proto3" json:"randomization_key,omitempty"`
+	PriorityOverlapResolution bool                   `protobuf:"varint,9,opt,name=priority_overlap_resolution,json=priorityOverlapResolution,proto3" json:"priority_overlap_resolution,omitempty"` // Whether the overlaps between experiments are resolved by priority
 }
 
 func (x *ProjectSettings) Reset() {
@@ -323,6 +324,13 @@ func (x *ProjectSettings) GetRandomizationKey() string {
 	return ""
 }
 
+func (x *ProjectSettings) GetPriorityOverlapResolution() bool {
+	if x != nil {
+		return x.PriorityOverlapResolution
+	}
+	return false
+}
+
 var File_api_proto_settings_proto protoreflect.FileDescriptor
 
 var file_api_proto_settings_proto_rawDesc = []byte{
@@ -356,7 +364,7 @@ var file_api_proto_settings_proto_rawDesc = []byte{
 	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x31, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
 	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x75, 0x62, 0x73, 0x75, 0x62, 0x2e, 0x45,
 	0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x56, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c,
-	0x65, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xf3, 0x02,
+	0x65, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xb3, 0x03,
 	0x0a, 0x0f, 0x50, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67,
 	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x49, 0x64,
@@ -380,7 +388,11 @@ var file_api_proto_settings_proto_rawDesc = []byte{
 	0x65, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x72, 0x61, 0x6e, 0x64, 0x6f, 0x6d,
 	0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x10, 0x72, 0x61, 0x6e, 0x64, 0x6f, 0x6d, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x4b, 0x65, 0x79, 0x42, 0x09, 0x5a, 0x07, 0x2f, 0x70, 0x75, 0x62, 0x73, 0x75, 0x62, 0x62, 0x06,
+	0x4b, 0x65, 0x79, 0x12, 0x3e, 0x0a, 0x1b, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x5f,
+	0x6f, 0x76, 0x65, 0x72, 0x6c, 0x61, 0x70, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x19, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69,
+	0x74, 0x79, 0x4f, 0x76, 0x65, 0x72, 0x6c, 0x61, 0x70, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74,
+	0x69, 0x6f, 0x6e, 0x42, 0x09, 0x5a, 0x07, 0x2f, 0x70, 0x75, 0x62, 0x73, 0x75, 0x62, 0x62, 0x06,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
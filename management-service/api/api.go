@@ -212,13 +212,59 @@ type CreateExperimentRequestBody struct {
 
 // CreateProjectSettingsRequestBody defines model for CreateProjectSettingsRequestBody.
 type CreateProjectSettingsRequestBody struct {
-	EnableS2idClustering *bool                          `json:"enable_s2id_clustering,omitempty"`
-	RandomizationKey     string                         `json:"randomization_key"`
-	Segmenters           externalRef0.ProjectSegmenters `json:"segmenters"`
+	// Whether the experiments with two or more treatments of identical configurations are blocked
+	BlockDuplicateTreatmentConfigs *bool `json:"block_duplicate_treatment_configs,omitempty"`
+
+	// Whether the experiments whose segments list as many values of a segmenter as it allows are blocked
+	BlockExhaustiveSegmentValues *bool `json:"block_exhaustive_segment_values,omitempty"`
+	// Whether disabling a default tier experiment that would orphan an override tier experiment is blocked
+	BlockOrphanedOverrides *bool                           `json:"block_orphaned_overrides,omitempty"`
+	DefaultSegment         *externalRef0.ExperimentSegment `json:"default_segment,omitempty"`
+	DefaultTier            *externalRef0.ExperimentTier    `json:"default_tier,omitempty"`
+	DefaultType            *externalRef0.ExperimentType    `json:"default_type,omitempty"`
+	EnableS2idClustering   *bool                           `json:"enable_s2id_clustering,omitempty"`
+
+	// Names of the registered custom validators that are run on the experiments
+	EnabledValidators *[]string `json:"enabled_validators,omitempty"`
+
+	// Number of running experiments beyond which creating or enabling an active experiment is blocked
+	HardMaxActiveExperiments *int `json:"hard_max_active_experiments,omitempty"`
+
+	// Maximum size in bytes of the configuration of each treatment, marshaled as JSON
+	MaxTreatmentConfigBytes *int `json:"max_treatment_config_bytes,omitempty"`
+
+	// Segmenters that are ignored when checking the orthogonality of the experiments
+	OrthogonalityExemptSegmenters *[]string `json:"orthogonality_exempt_segmenters,omitempty"`
+
+	// Number of seconds by which the end of the time window in which the other experiments are checked for orthogonality is extended
+	OrthogonalityLookaheadSeconds *int `json:"orthogonality_lookahead_seconds,omitempty"`
+
+	// Whether overlapping experiments of the same tier are allowed, in which case the one with the higher priority takes effect
+	PriorityOverlapResolution *bool  `json:"priority_overlap_resolution,omitempty"`
+	RandomizationKey          string `json:"randomization_key"`
+
+	// Groups of segmenters that must be set together, i.e., an experiment that sets any segmenter of a group must set all of them
+	RequiredSegmenterCombinations *[][]string                    `json:"required_segmenter_combinations,omitempty"`
+	Segmenters                    externalRef0.ProjectSegmenters `json:"segmenters"`
+
+	// Number of running experiments beyond which creating or enabling an active experiment is allowed with a warning
+	SoftMaxActiveExperiments *int `json:"soft_max_active_experiments,omitempty"`
+
+	// Object that is deep-merged into the configuration of each treatment before it is validated
+	TreatmentConfigDefaults *map[string]interface{} `json:"treatment_config_defaults,omitempty"`
+
+	// Regular expression that the names of the treatments of the experiments must match
+	TreatmentNamePattern *string `json:"treatment_name_pattern,omitempty"`
 
 	// Object containing information to define a valid treatment schema
 	TreatmentSchema *externalRef0.TreatmentSchema `json:"treatment_schema,omitempty"`
-	ValidationUrl   *string                       `json:"validation_url,omitempty"`
+
+	// Whether the segments of the experiments are also stored and matched as typed values
+	TypedSegmentStorage *bool   `json:"typed_segment_storage,omitempty"`
+	ValidationUrl       *string `json:"validation_url,omitempty"`
+
+	// Token bucket rate limit on the requests to the validation URL of a project
+	ValidationUrlRateLimit *externalRef0.ValidationUrlRateLimit `json:"validation_url_rate_limit,omitempty"`
 }
 
 // CreateSegmentRequestBody defines model for CreateSegmentRequestBody.
@@ -262,13 +308,59 @@ type UpdateExperimentRequestBody struct {
 
 // UpdateProjectSettingsRequestBody defines model for UpdateProjectSettingsRequestBody.
 type UpdateProjectSettingsRequestBody struct {
-	EnableS2idClustering *bool                          `json:"enable_s2id_clustering,omitempty"`
-	RandomizationKey     string                         `json:"randomization_key"`
-	Segmenters           externalRef0.ProjectSegmenters `json:"segmenters"`
+	// Whether the experiments with two or more treatments of identical configurations are blocked
+	BlockDuplicateTreatmentConfigs *bool `json:"block_duplicate_treatment_configs,omitempty"`
+
+	// Whether the experiments whose segments list as many values of a segmenter as it allows are blocked
+	BlockExhaustiveSegmentValues *bool `json:"block_exhaustive_segment_values,omitempty"`
+	// Whether disabling a default tier experiment that would orphan an override tier experiment is blocked
+	BlockOrphanedOverrides *bool                           `json:"block_orphaned_overrides,omitempty"`
+	DefaultSegment         *externalRef0.ExperimentSegment `json:"default_segment,omitempty"`
+	DefaultTier            *externalRef0.ExperimentTier    `json:"default_tier,omitempty"`
+	DefaultType            *externalRef0.ExperimentType    `json:"default_type,omitempty"`
+	EnableS2idClustering   *bool                           `json:"enable_s2id_clustering,omitempty"`
+
+	// Names of the registered custom validators that are run on the experiments
+	EnabledValidators *[]string `json:"enabled_validators,omitempty"`
+
+	// Number of running experiments beyond which creating or enabling an active experiment is blocked
+	HardMaxActiveExperiments *int `json:"hard_max_active_experiments,omitempty"`
+
+	// Maximum size in bytes of the configuration of each treatment, marshaled as JSON
+	MaxTreatmentConfigBytes *int `json:"max_treatment_config_bytes,omitempty"`
+
+	// Segmenters that are ignored when checking the orthogonality of the experiments
+	OrthogonalityExemptSegmenters *[]string `json:"orthogonality_exempt_segmenters,omitempty"`
+
+	// Number of seconds by which the end of the time window in which the other experiments are checked for orthogonality is extended
+	OrthogonalityLookaheadSeconds *int `json:"orthogonality_lookahead_seconds,omitempty"`
+
+	// Whether overlapping experiments of the same tier are allowed, in which case the one with the higher priority takes effect
+	PriorityOverlapResolution *bool  `json:"priority_overlap_resolution,omitempty"`
+	RandomizationKey          string `json:"randomization_key"`
+
+	// Groups of segmenters that must be set together, i.e., an experiment that sets any segmenter of a group must set all of them
+	RequiredSegmenterCombinations *[][]string                    `json:"required_segmenter_combinations,omitempty"`
+	Segmenters                    externalRef0.ProjectSegmenters `json:"segmenters"`
+
+	// Number of running experiments beyond which creating or enabling an active experiment is allowed with a warning
+	SoftMaxActiveExperiments *int `json:"soft_max_active_experiments,omitempty"`
+
+	// Object that is deep-merged into the configuration of each treatment before it is validated
+	TreatmentConfigDefaults *map[string]interface{} `json:"treatment_config_defaults,omitempty"`
+
+	// Regular expression that the names of the treatments of the experiments must match
+	TreatmentNamePattern *string `json:"treatment_name_pattern,omitempty"`
 
 	// Object containing information to define a valid treatment schema
 	TreatmentSchema *externalRef0.TreatmentSchema `json:"treatment_schema,omitempty"`
-	ValidationUrl   *string                       `json:"validation_url,omitempty"`
+
+	// Whether the segments of the experiments are also stored and matched as typed values
+	TypedSegmentStorage *bool   `json:"typed_segment_storage,omitempty"`
+	ValidationUrl       *string `json:"validation_url,omitempty"`
+
+	// Token bucket rate limit on the requests to the validation URL of a project
+	ValidationUrlRateLimit *externalRef0.ValidationUrlRateLimit `json:"validation_url_rate_limit,omitempty"`
 }
 
 // UpdateSegmentRequestBody defines model for UpdateSegmentRequestBody.
@@ -2259,66 +2351,80 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
-
-	"H4sIAAAAAAAC/+xdW5PbthX+Kxi2M21nuJKduH3Yt8RxHM+0qcd20od4ZxcijyQkFKAAoDbKjv57BxeS",
-	"AC8SRXFFar1vtpYEcC445zsXgA9BxFZrRoFKEVw/BBx+T0HIb1lMQP/wmgOW8OaPNXCyAio/5A9s1Z8j",
-	"RiVQqf6J1+uERFgSRqe/CkbVbyJawgqrf605WwOXdtQYRMTJWj2r/kvTJMGzBIJryVMIA7ldQ3AdCMkJ",
-	"XQS7MAAa30qyAvXwnPEVlsF1EGMJV/rXmjcIlcA3OPHeIFR+/VUQNs2n3lkAV69TbCarjCtgsbIE/5XD",
-	"XP1N0zjZ4lXyl2nBzan5XUwL3n2076phJObySJKExDIV3WY2r+7CQBLgnYb4RAxnpFKIVaYvRMKq25I+",
-	"ZePoQQ2tmHO8Lf7fZVT14i4M0rViZXw729ZIcRdqPScc4uD6l0K5rNgLIXtyygXg8cCu9Sangc1+hUgG",
-	"O38WpWe70O6m95ypZz6ClIQuRD9bCqjS6FvxFYlvoyQVEjSxBfUzxhLAVHGHYxqzFflTj3z7G2z3qTrw",
-	"YwSc05a/6+rMbbH6luPlavLRvLkLgw1OSGyWnvLksHyr1Hq0HSU6S1c/IntsI3PMJihpfhemAO+HLRGj",
-	"QnJMOpqY1/nrdZal5HkqrF+liSS3G5ykisy6zdMoNaZHPWapOeP+a1/1eFw3+ZGGMZ/A2MV6mesxS5Q7",
-	"Dx6lCvl27U0V5mSRclySV7aSPdLooPz+bC3p/knP88UBpGcc9ORwkKtzoYuKclV5RGRkttEzMho7MspF",
-	"1SsSGgDwHIl0PKK/EKTz+ICmJJMTIYiR0dkhyDFa1wli/Gy2NbyhkshtTwADS1xLzbAWSS+rFVv0L2LN",
-	"qDAEfYtjy5mjuNLW3HDOuFmHt6/UtMgmzYIcBTvGKY0iEKIHQR1tF4/hrU+TIUIgTBHkw6E540guAS3I",
-	"BihaG28WNCU1zk54af7TqS9I1+tFwo6cM8KyAN0Tuaxy5pbEQTlCPjtTct94siog6y8PqUHuAYaiVaHz",
-	"3qgFfojewuadm14ncDid3tzqN9P7HSTQoyYTFxDkgeWuxarNQuJMRpW19aF7DWmFDssz0aL5sUdlOZ19",
-	"0g0834IsPMcPREjGtwP6LruC7pr9FqTWYrGGiMwJxGiphyQRTtAGuFAWnc19F1dhxEV67w8gU05d/4Vi",
-	"kJgkwniqspdCmMbOw9ZvvQVpPWqxpp8xJyqU7tG55yFPJTzxw5lTmWEhGlpjjlegoltt6LBr4AqSLx/E",
-	"KP1vBDBs3h6/vIUswB7KKvjTn8EkuI6lIP/ysFum+xly62YFLhrQKZkXUK6t3mteaARgWJC77aG2QHkB",
-	"59gEZXhQYBfgGxLBa53JGI4V3jJO3yQFABZmYOSlalDMtZLMtpp9K0zxAtzHK1y6wHCgyosOJuOd2moU",
-	"J0o+wE3e5JwJmWx+ZBaA7INh8G8iHhPjdq8A5Zu6msNd44UtVbQFEOaFziqgmKT3f5LUWAZRC5l9xoox",
-	"sHQUvKwCcdHscybo3RzpJRp7Y3Pv6B44oFRAHOrXOIg0kQJhDkhEbA0xwlHEeEzoItnqHaldnF46InTO",
-	"EKHZmzpZimYs3iKiYIGcZOKzuHNA2b0vcHi/yD8zYVapLcc1+Shd6yigBJQzpjwW7j2WNWUAfCFmwoXR",
-	"DjuBi8FZaSv4veiZBzGPi60crgzPk1GZTMvQvfbyU8kcWuMJcWcr+Hg4/1iZVAH/pWx6L2zwmCpGwM5R",
-	"KXnRKzNGWPAjk9+zlMZnBe8fQLCUR4Aok2iup69psbvItKwhIi5h59q2p8tNOxpyRD+pR6/l5vLSb5nA",
-	"HRhUaiK6xIxaiSqDpEqNN5eY+8jokt5QAqKUE7nVDS1maTPAHPg3qVzmBOiWJv1z0a66lHJt5lHWttLS",
-	"G7z+8NN36Jv370QpAnFSS2owIhM12pvSfvpPkX9SYwRhYL1wcB1sXpreLaB4TYLr4OvJi8nLQPk5udQU",
-	"TLMYSP1nAVo4ivl66Hex9fRZSBiU+my+evHCkYwnjvy5aV1MuQuDf7Z5ty6BpGWRrlaYbzMgop3YnqCu",
-	"xDLFTLwQSieyks+NGjVnxvShsD67aSGQq01W9Wpk195ameZ8VnQKrn95CIiSkpJGduDoOnAMX7nRKXQ2",
-	"idun/a9XQbUve3fTRVqtan27MHj14tXhwXLc0J+8VYilxVwU7zIeaVEvgGpx0IWLqeobGbqqwf7N4qS/",
-	"zivv0A7/ewp8W4yf92MfD80qvfJqDt92mdFv55wAjRONGjGK2GqWo1Tj5c1zaE4giUMFOCNGf01ppJ/J",
-	"XX9sU+zhZyqXWCpJxWmku1JSAfwqnyZKsBBkbn1INoljOs18ICbof0tQ8JaIQmc+UwVuU+WEMtRsng9R",
-	"0cpuUtq28x3NSaKVLcIU4UQwNAMNj9EP7B42wM0oc0Jx8pkaCI7uWZrE6kFMke6bFxC5y3W8oPoJcLS0",
-	"fxL5hJPPVHfdN8k157wn4O7ZUiPp77NBa1IjZQ34SShXyRYgl8ALURaMDJFklhwv/6klrKIULFEC2FTk",
-	"JcFJskU8pdSEJ3owQtepRBzTBUwa2OGcUajZNHsOkTTtG30q5KRdY46HNI5vjlqdMr49yFU/fnaKLx+/",
-	"Jd1OC/OBt309+AiYR0t3D6oB9S5yHsxaLYyk0QrLXOmRMCNI+EM26bx+4rh1fTCbcY0XgGi6mgGfoHcS",
-	"xTDHOk6WDL1sUir1UtBkhfXpqDor7M//o55T0ah3JWLU7HQ1dnUlL/Yt5VaQP09eT8N+zfbPeXarf2Ko",
-	"l/3qHEcqK0cO9SvMUDEJZ4lA90vDD8Z1vuEe8G9Ot4BWUxDo7179ZgkcrOJmDxJh0zI4+QcSy8wBcJ3m",
-	"0QcZ6pZOaJSkMdyqWW/1XHVUOCclymR8gwQkEEkFcxjioHgVmep+YjN12RKQYYaw5WvCjU8WOruUUgEy",
-	"1ADLuDP1F3RPksSlYvKZvs8TrDl4qzyGyBzNmFwqngIx3J2jO6XId9os3OU6fefiOZ3A5WxDYj1VA8/M",
-	"2nryet+rwWqc3U3XgKemBqpBc4vXncML/cJmV3e9hjd0P+ETOUGaw0YSwgHHTpbqZhcGayZqkG/5sMMA",
-	"oY53FKaeYc6FJtN9t5nsusi96bzHoII3i0IYUbgvn+DANZGQK+ww+OMqYjEsgF5Z3l3NWLy9suJr4GDQ",
-	"LoiaPnj9Irt9IfVQehXWDu/3uQwTpDeo2XBBuVtb984KlfK6HvO0G/Ck1WR00hrFKNcCnqRuHGnV9l1B",
-	"0MmqNRVcBrVqZlG9K9ohg9fA3I4GbxoTYS5WeKjX7+/M378k4/eqmh23XChXy4aydXY5/Ru5bjpkLjRo",
-	"VKE39FmDLBPGokBmNWPRn6VtKmmX0c5aUJ6YFj3njHoApXt7qgfcb7pC6O22v4m6nqVH2VfTBzt8y/Dm",
-	"6W6wmhmyOvXQEdSXoKv+NUWNpt65kWgUtcuIdSuSFH01eoRHKI4WMzTWRm1FpDhCowsij10A6Wy8q03Z",
-	"I6jzF6l17wxWc9W/nOXcV/Z377c6kNks2qUuJLFZez3VCXnNSqfaeNKalttX9iBj5DSkNci6jZ2cPihh",
-	"7kw4kYCEmgDdv2piDF7bVn2bB+7FXDTcsTGoSpg1FUntI9QhbERmX6Bs6855D+wItA1P2Awn02bhotkW",
-	"ZbfYNtj35iTyE5Bzp0Rxf16ioZ95NGliIjQ8eARf0QpRjwNPn9jbk90ecRYc2y4pM0ewWktzrIXaLoM7",
-	"0xtwhyjjWb+BOc0SIjKeLE67pdv+iKb1P36/0HNvyQknKXtvLCmfER28qyQ/nlnTUtLUUZLft9su6Lqw",
-	"kKvfgGt84ZZ7+yNujqrb9o/4XAta+Nvpg/1X1jZSxGc119spr19cWakNCYcV24CypXPOVqbzHEs8wwLQ",
-	"GvgKKw4lW2WsGF2Y4gyRtZk4ZX73BIVjgJMFs4bItNZeFTmOQLHQCa/4VvCrufLWWsc98h1aDgScz3pT",
-	"vW1tRA1OvahOq4j06SnCKXFqv1HqqGLUs1ijOmYe7XFb9QyULqp5Slr83C3QU7dA/aVKg5dfsy13sPRa",
-	"WPKOO6hdd8DT3kqj6wsYnVbqUuhepTxaJ+3dHocPsefXgFzSyfXy3SkjqF5UPhjRXJK2DD+UGxleQJ1y",
-	"JHu+6nVCrmSf4IcCdh/N/RNOgfrAXRS+6Jsjg4uT/MHvuZ0A5ccoeQvpu+77ZsPtf2ewEXt/cj+8d/FF",
-	"pzO3Tz2XnZ7LTpdbdsq3fu+Fp+rdjYOXnkoX/LQsPhW3eh2CWMVVYhcCrmo/d3gCrKrc4jaeIpT/kaq6",
-	"MpQj53aFqDL3glaeePpQfCWxfTmqWP65ClIDKXN9hO+ybLii1LjUOy9LubrhpYJdrjUng4/Q+xIbWpSn",
-	"nrXIzzjUq9BIilT9KdL+gPRJK0WnWLc/R9xwneo4Slbns1T1bO3koVuVryqXrj8tzT4qyB1j9HqGiPT0",
-	"SGl0ha1cgw6Wtlzbf8Iea1fgevqbbXRFrqeoo/n/r+x32K7MkcFWqud/Qu5kNFj3Xbz+WMVBcgIb6OFT",
-	"dQU7vRctS+0n+839IrWZkp/tE2+oJHIbdEBM/ggtAJPvL+zr+ssCYwBHGcvMJ/M1TQgvMKFau0vwCJm9",
-	"jhhHm4KOlCeOXHIZhL7GO1fSaxvpXkb/y42yDEIv0lhQNeZ1MN28DHY3u/8HAAD//+NEqzvqkwAA",
+	"H4sIAAAAAAACA+0d247bNvZXCO8C3QU846TN7kPe2jRNs+glmCTtQxM4tETbbGTRpaSZcQfz73sOSUmU",
+	"RNmyrLHkiYEiHdsUeXjuN1J3I0+s1iJkYRyNnt+NJPsrYVH8nfA5U1+8kIzG7OXtmkm+glFX2YAN/uyJ",
+	"MIZv8U+6XgfcozEX4eTPSIT4XeQt2YriX2spYIrYzOqzyJN8jWPxY5gEAZ0FbPQ8lgkbj+LNGv4eRbHk",
+	"4WJ0Px6x0J/GsD4Ongu5orDiyAfALtS3jic4wCWvaVB4Ar785msYXbMePrNgEh8PqV6sMm/EFiuz4X9K",
+	"Nsff1B4vN3QV/GOSY3Oiv48mOe7emmdxmpjKeM8twTNxErVbWT8KkwAFZKsp3nGNmRgZYpXyC4/Zqh1I",
+	"79J51KR6r1RKusk/t5kVH4QJkjWi0p/ONg4qwu/I51wyf/T8j5y5DNlzIhfolBGggAMD68dsD2L2J/Ng",
+	"U8VVkM/gCy1Nb6TAMW9ZHAM8UTciNQuE93nqJ/oJNs1gnMKEc76oyt3o9yWLl0wS+IewDIURueHxksQ3",
+	"gghJVkIyku+XiDnhPvwFiwREz5xIBWFEKAxVYMCOM3zMhAgYDZEoGkR2u6RJFPNrNjV4noKcJmwfAJci",
+	"YsQ8HZGARzGhEVnRcEP0XAgnTUfADPArhzFBIG6awinkeklD4CFxzaSETW8B0OcR6BMgJizqszlNgpig",
+	"pFlQwyZoTG5EEvhET03gv3TuymgebQXRLDLtSB2l0x2sHbKJDhVgFqKCnkZfc3/qBcAvTMluLswWMvRY",
+	"H9mIg9gL6aDULyDZiiuQlyRbcJyR+cSDqcWK5I9qOiGLyAToE5aZD8iRKb2Kii7rsSWV/nRFb6fUUwxv",
+	"z1MFMVnNgAkARlg5RGayeX7GNiL0gfW5tyQeCiSOAAlVu1esBxylltnFR5adQ9jKmgKUZuxi9p/pLV8l",
+	"KxLxvxnhIVHDUpQWVAF+ySjAmU09BuGU0ZICmVAW//f211+c8AgZL8VChECOeAPoYqt1xuTMRde32W85",
+	"4fgiFEjbmyULCXCY9xnRg1AWpk9Bb0vcIqyBEJ/pklEfwAVk+FsJbIYACg1BFRxAXgMSGhxQw6EvbhDT",
+	"+Rih1I3NF7hhtUfYMPgRpS0C+dktmBO/hv5ryYVE8FERBXQ9lSwSQZI6Zm5tZ8auyyxqgI9A1LQ+Q9iU",
+	"zmX+ON+HR0F5q82EzNga+LDkC5w7BYjE9DNwF5vP0Zq6VKCkgJ4V/1tx3PQz2ziJlprgnImAy1czHmqb",
+	"Vd3kKymSdaSJVGStFagKEEP4HpS5WChcwLYu2eUYZa+s6mEYUAcsUm6FlFFa4AJ6MpwJ8GMQt7K5rzkb",
+	"lj8XpaWhAs5ckuxZnEnM4961l+EfzSiU3FCJkzuZuaLIjDVyQPur8tI0oWARn7H1xYrJBSwEE4omKg32",
+	"NEfniKsZjAGxxSz1BG3A0MGcrmkMKHYI2BVbJAFV8g1yGHFlfWisoAltA1Z0yMrukeItCCi8pSuMyIHJ",
+	"XcmGXJL57G/1k4b7MuGagi2VdMG2e3KZ3+YAXWuMSBCcCW0FMI3aibYbajXj4jmVgiEDaoREBk75KQ6Z",
+	"SnSWA77i+zhRv2VzvJfBFczwk5qgHFlUVVRBPPcKGoxodhMsPHR4u0/4VYq52iCFyW7QAo8BkJS3DG5f",
+	"ZI+79HIp51FB/Qo0FdehkO/2c2upJtaZMWsIaoa4X82jBRy7Ft/To88W0A69m+ZqztLOrYF7sUKmmzpj",
+	"hVz5WxjJlXotNVowf3G1hvt+r9b54lJz5wzco8vA2Tw3tvNxGas8YE5Oi9E5J3fOyZ1zcuec3Dknd87J",
+	"nXNy55zcOSd3zsmdc3LnnBwGDVmQ0GkOrodU2545tsKmv5Ac28On0ko0OTD5pWl09OTXPlzXKrllpJm9",
+	"hLA23nSU2qIxde6mW/W7U9+Vsx8IViO0qG8iACHSG/qO+gYze2GlqbqRUkgNR9FwwLLENAqOsvyrpZwS",
+	"zwNL2QGh9taLCI7xRlyZAfMLoTORaAu+1j7WV5Ft8caEXS7Agczt/FY/it16jPmRiWIBvpXy7cFGqths",
+	"j/ilOWMUN6YpEJU8Xow8EKYFOHBhutNRXRfa0alWWv/w3edb195hZGbOEGFQkMc3BcxMuT8qF5aOjpTM",
+	"sB/MCqlLt4sNMvPV114xTuhstyClO/abK+xj79fKtx++3zzkqd3v9yxgHXIyt72ZLMK7bwC1BsRPaVSB",
+	"rQveq6nGtQBPx636yw6Z5XD0xXa95qXKr3ZpdE/bbu7EokaYb0Ew1oYg2zc88orFOUp/5Bjwbnp0ZwwE",
+	"7fXFK6YJEa2Zx+cctr9UU6pK0TXEuyaLUnAcKojo1aFru/UrFicytPmT+CymPDC1tLLtV2mNfLDxBgAP",
+	"xk/JYfqNSo681KHL9NAOYooM47WTNZWgL1UaFc0Htc1GvuXTdw2R/2vdQpPqauQVwkTGWPWlFYrLH0El",
+	"2OY63/7pecQp76f+cDstcNJuMtK8UPFoxPcKF8qv0ijInKG+RKAMwDGEoOB02Uh4y+Q199gLldzqDxUF",
+	"MA4XkjysiPTEpYqLLxWTzDYKfSsa0gWzh1ewdIJBVhUXLVTGaxS1kAZIHyZ1Ku2YObp0faIBIGbgePQT",
+	"MPoD+rjt29Eyoa6m9dd0YRpNmjoQ+oHWLIBIUvIfBA7NEDld5iJioyGgdBC4rDriUb3NuSSv50SBaCq8",
+	"uqZCbphkJImwZ0GHixHWkFVpMvIEFiCp5wnpA6TBRkmkMnEKdMLDucBWB/Okyp+TmfBVKwa4g5cp+Yzf",
+	"2SPt3uR+eLeef6rCDFMbjKvtk2StooCSo5wi5aH83n1RU3aAT0RN2G60hU4Y0jsqTYdGJ3xWcDH3i60s",
+	"rPSPk0GpzKwrY4u+fFdSh0Z5mj6NNlrw4fz8fWlSdfhPRegLYUMBqdEA0DkoJrf6pgboFvwi4h9EEvpH",
+	"dd6vWCQSCcFXKLC+g8s7zvucZFpWb8Iv+c7OMxinm3bU24m6ST0WurBOL/2WEtxyg0p9ZaeYUSvtSntS",
+	"pV6sU8x9pPuKC1NFzEuwAVz1OJmjTQyUrPw2iZfZBlSXm/o6bwNdxvFar4PatlpafHH1/nvy7ZvXUSkC",
+	"sVJLOBmP8UTg6GVJnn7O8084B4w0VhjGXj/V7XwspGsOn7+5fHL5dIR2Ll6qHUzSGAg/LJgiDiJfTf3a",
+	"N5Y+DQlHpdarr588sShTIEc2buKKKQGo/zR51pVAUrRIVisKrpBxRJQR2xLUlVCGyKRY4/1jlJZ8PuKs",
+	"GTImd7n2uZ/kBLm4TqtetejaWitTmE+LTrA8eC9IJaRGeu/W85Gl+Mq9b2NLSOxDo/995mg7v//YhlqN",
+	"an2Aq2dPnu2eLPMbuqP3K3MywSrepThSpF6wUJEDz9XkPpW7PaQtG2wXlpeFUztHpPfYTP9XwuQmnz87",
+	"HLq/a1Y5uItrFHWXnn06l5yFfqC8RkqsgyzZ2Rs1jsw5C/SRG7AGfyahp8Zkpt83Kfbxh1D1TwBu/MRT",
+	"vT7g4MqLbBkvoFHE58aGVBv2zXosuiS/42GrGIKUnGc+hOjcJmiEUq9Zjx+T/FytTmmbY7gAeKCYzcPj",
+	"IHgOQJ+3uSQ/iht2jedtcJY57Dr4EGoX3JypnOFMRB3iBRNig2tZwewUh/4pyha8/BCqI8B1dM0wXyBw",
+	"+2yppvQP6aSO1EiZA95HaCrNsaOclDkiATXCbKeQ/8yOxcH/AkZ1RT7mIN2brFXGRCY8XCcxkTRcsMsa",
+	"dFgHph1Cs+VEe53cqCOnB0mNPntaO7++9+GQ+c2tEu750ytFsvkb7tvqat/xdPnAI5XAwJYM4oRKiqyB",
+	"aauFprQ+QpPpCD1DzG7jOp5XI/aD60oLI8S7aS8WBNJxeho6QuZ8WsdU+NCoTgurqxpcWrju/JmSSjy5",
+	"qyQd565C8mQbKFM84XooPDXymsrPcaS1eH1BJ/Jq3Y1QZo7M1a8gA2MSKbCqmR4hlSrfcMPoZ6tbQJ/0",
+	"isi/iof9MfFiHRyDgWBXdFqGBv8m0TI1AFKledTZFhfoPPSCxGdTXHWano+r7MI6PFPexrcAQgBbRDdH",
+	"YB4HcOXp6n5gMnUpCEQjIzLlay61TY5UdikBLyweKwdLmzP8BXgEPlu7AJv0JkuwZs5bZRjhczITwF4Y",
+	"1HCN3Tn5hIz8SamFTxlPf7L9OZXAleKa+2qpGpxp2Dqyej/gZA5j97FtwOOogSqnucHj1nmWbt1mm3cL",
+	"DW/k5lJegiujMKwpEVnOsZWl+og5UhE5PN/y+ZceQp3C6Sg3wqx7vSfbLvW+b0P3uiNAvRJeAwW0DtlN",
+	"+VwMdURCNrHHo9sLT/iA4/DC4O4CU8MXhnw1GBw1C6Imd4V+kfttIXVffDV2Tl/sc+knSK9hs/6Ccru2",
+	"XjiBVcrrFpCnzECBWnVKJ3EwRrkW8Ch5Y0+ttu0+tFZara7g0qtW00B1zmi7FF4NclsqvIm+nEmd2HHy",
+	"9/f69y9J+T2rZscNFsrVsr50nQGneyXXjof0FVO1LFQ+IXU2n0DkumNjQAEnB1ZPTfXJgBqaofDf0jSl",
+	"NMuIpy0sj4wLzzmnDqRya092j/KmKowFafsqcvU8PYhcTe7M9A3Do8crYI4V0jp33xHYl8CrxcvbalW9",
+	"dU/bIGqf2P/WpsiS9+WoGR6guJqvUFtbNRWV/AiOKqg8dAGltfKuNnUPoE8gT82X7gSu6xooZ0m3tQ3Y",
+	"V6btyIzm7VYnkhh13nh2QF600uk2nLSowfaFOQjpWQ1tNbRuoicnd0jMex1O4OUVjgC/eAHIEKy2qRrX",
+	"T9yJuqi5+aRXltAw5UnxPdhhXOuZfYG0dZ0T79kQKB0eiBkNJvXExYJo+kqOGv1en4R+BHRulWjuzkrU",
+	"9EMPJs0MDg66Bw9gKxp51MPwpw/sDUpvnziKH9ssKTMneJO7PhYTmi6FT7q34BMJAUTTr6BPw4zxUuWh",
+	"ZHGagW76K+rgf/h+o3NvygEnMTtvTCmfMe29KyU73uloSanrSMmucG4WdJ1YyNVtwDW8cMu+k5PWR9VN",
+	"+0+KWBs1sLeTu/RaeNN2ksdnjksH9ctzUqCVIpFsJa7VBftzKVa6c53GdIbvjwA2XFHEULBBZSXgaVWc",
+	"4bEzE4fqd0tQOAR3MkdWH5lW5wWewwgUc54oFN9yfNVX3hrzeGH71l52BJxnvqne1jagBqlOWKdRRPr4",
+	"GOGQOLXbKHVQMepRtJELmXtb3EY9A6WLbh4TF5+7BTrqFnBfytR7+TUVuZ2l11yTt5SgZt0Bj1uUBtcX",
+	"MDiuVKXQrUy5N0+au0F2H4LPrhE5pZPv5btXBlC9qLzGo74kbRC+KzfSP4Fa5Ui2vKL4gFzJNsL35di9",
+	"1fdXWAXqHXdZFElfHxmcHOV3vpz6AFd+iJQ3Ln1bua9X3MWXptf63u/st4iffNHpyO1T57LTuex0umWn",
+	"TPQ7LzxV737svfRUuiCoYfEpvxVsl4uVX0V2Is6V8w2aB7hVlVvghlOEKr46zFWGsujcrBBVxt6okSWe",
+	"3OUv3mxejsrBP1ZBqidmdkf4Nsr6K0oNi72zspTNG4VUsI21+mTwHnxfQkOD8tSZi4oZBzcLDaRI1R0j",
+	"bQ9IHzVTtIp1uzPENdexDqNkdTxN5UZrKwvdqHxVubT9cXH2XkHuEKPXI0Skh0dKgytsZRy0s7Rl6/4D",
+	"ZKxZgevxC9vgilyPkUezzxfmPW4X+shgI9YrvoLuYG/Q9V697lAlWSw5u2YdvOouR2fhQYPSaxpwNLzq",
+	"fhJnpuQ3M+JlGPN4M2rhMRVnaOAwFe2FeVy9mWAIzlGKskgdO1F7InRBeai4u+QeES3rmE68zveRyMCi",
+	"S0aDcZHjrSvtlY60L7P/4yNqhkgBqTUozvkcCPp0dP/x/v/8EgbGMasAAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
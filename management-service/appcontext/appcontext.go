@@ -50,6 +50,7 @@ func NewAppContext(db *gorm.DB, authorizer *mw.Authorizer, cfg *config.Config) (
 
 	experimentHistorySvc := services.NewExperimentHistoryService(db)
 	experimentSvc := services.NewExperimentService(&allServices, db)
+	experimentSvc.SetValidationConcurrency(cfg.ValidationConfig.MaxConcurrentValidations)
 	projectSettingsSvc := services.NewProjectSettingsService(&allServices, db)
 
 	segmentHistorySvc := services.NewSegmentHistoryService(db)
@@ -58,11 +58,44 @@ type MLPConfig struct {
 type PubSubConfig struct {
 	Project   string `default:"dev"`
 	TopicName string `default:"xp-update"`
+	// TierTopicMap optionally maps the experiment tiers to the names of additional topics that the messages about the
+	// experiments of the tier are published to. All the messages are still published to TopicName, which the
+	// treatment service subscribes to.
+	TierTopicMap map[string]string
 }
 
 // ValidationConfig captures the config related to the validation of schemas
 type ValidationConfig struct {
 	ValidationUrlTimeoutSeconds int `default:"5"`
+	// ValidationUrlMaxRetries is the number of times a request to the validation URL is retried on a transient
+	// failure, i.e., a network error or a 5xx response
+	ValidationUrlMaxRetries int `default:"2"`
+	// ValidationUrlRetryBackoffMillis is the wait before the first retry, which is doubled on every subsequent retry
+	ValidationUrlRetryBackoffMillis int `default:"100"`
+	// ValidationUrlCircuitBreakerThreshold is the number of consecutive failed requests to a validation URL after which
+	// the circuit breaker opens and further requests fail fast. Set to 0 to disable the circuit breaker.
+	ValidationUrlCircuitBreakerThreshold int `default:"5"`
+	// ValidationUrlCircuitBreakerCooldownSeconds is the duration for which the circuit breaker stays open, before
+	// allowing a trial request through to the validation URL
+	ValidationUrlCircuitBreakerCooldownSeconds int `default:"30"`
+	// ValidationUrlAllowedSchemes are the URL schemes that the validation URLs may use
+	ValidationUrlAllowedSchemes []string `default:"https"`
+	// ValidationUrlAllowedHosts restricts the hosts of the validation URLs to the given host names, e.g.,
+	// validation.example.com, or *.example.com for any of its subdomains, IP addresses or CIDR ranges. If it is empty,
+	// any host that is not blocked is allowed.
+	ValidationUrlAllowedHosts []string
+	// ValidationUrlBlockedHosts are the host names, IP addresses or CIDR ranges that the validation URLs may not
+	// resolve to, even if they are allowed. By default, these are the loopback and link-local ranges, the latter of
+	// which hold the cloud metadata endpoints. The private ranges are allowed, so that the validation endpoints may be
+	// hosted within the cluster; to block them too, add 10.0.0.0/8, 172.16.0.0/12, 192.168.0.0/16 and fc00::/7.
+	ValidationUrlBlockedHosts []string `default:"0.0.0.0/8,127.0.0.0/8,169.254.0.0/16,::1,fe80::/10"`
+	// MaxNameLength is the maximum length of the names of experiments and treatments
+	MaxNameLength int `default:"64"`
+	// MaxDescriptionLength is the maximum length of the descriptions of experiments
+	MaxDescriptionLength int `default:"4096"`
+	// MaxConcurrentValidations is the maximum number of validations of an experiment, i.e., the treatment schema
+	// validation of each treatment, the validation URL request and the custom validators, that are run concurrently
+	MaxConcurrentValidations int `default:"8"`
 }
 
 // DeploymentConfig captures the config related to the deployment of Management Service
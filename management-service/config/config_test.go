@@ -11,6 +11,9 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// defaultValidationUrlBlockedHosts are the loopback and link-local ranges blocked by default
+var defaultValidationUrlBlockedHosts = []string{"0.0.0.0/8", "127.0.0.0/8", "169.254.0.0/16", "::1", "fe80::/10"}
+
 func TestDefaultConfigs(t *testing.T) {
 	zeroSecond, _ := time.ParseDuration("0s")
 	emptyInterfaceMap := make(map[string]interface{})
@@ -39,11 +42,21 @@ func TestDefaultConfigs(t *testing.T) {
 			URL: "",
 		},
 		PubSubConfig: &PubSubConfig{
-			Project:   "dev",
-			TopicName: "xp-update",
+			Project:      "dev",
+			TopicName:    "xp-update",
+			TierTopicMap: map[string]string{},
 		},
 		ValidationConfig: ValidationConfig{
-			ValidationUrlTimeoutSeconds: 5,
+			ValidationUrlTimeoutSeconds:                5,
+			ValidationUrlMaxRetries:                    2,
+			ValidationUrlRetryBackoffMillis:            100,
+			ValidationUrlCircuitBreakerThreshold:       5,
+			ValidationUrlCircuitBreakerCooldownSeconds: 30,
+			ValidationUrlAllowedSchemes:                []string{"https"},
+			ValidationUrlBlockedHosts:                  defaultValidationUrlBlockedHosts,
+			MaxNameLength:                              64,
+			MaxDescriptionLength:                       4096,
+			MaxConcurrentValidations:                   8,
 		},
 		OpenAPISpecsPath: ".",
 		DeploymentConfig: DeploymentConfig{
@@ -112,9 +125,21 @@ func TestLoadConfigFiles(t *testing.T) {
 				PubSubConfig: &PubSubConfig{
 					Project:   "test-pubsub-project",
 					TopicName: "test-pubsub-topic",
+					TierTopicMap: map[string]string{
+						"override": "test-pubsub-override-topic",
+					},
 				},
 				ValidationConfig: ValidationConfig{
-					ValidationUrlTimeoutSeconds: 5,
+					ValidationUrlTimeoutSeconds:                5,
+					ValidationUrlMaxRetries:                    2,
+					ValidationUrlRetryBackoffMillis:            100,
+					ValidationUrlCircuitBreakerThreshold:       5,
+					ValidationUrlCircuitBreakerCooldownSeconds: 30,
+					ValidationUrlAllowedSchemes:                []string{"https"},
+					ValidationUrlBlockedHosts:                  defaultValidationUrlBlockedHosts,
+					MaxNameLength:                              64,
+					MaxDescriptionLength:                       4096,
+					MaxConcurrentValidations:                   8,
 				},
 				OpenAPISpecsPath: "test-path",
 				DeploymentConfig: DeploymentConfig{
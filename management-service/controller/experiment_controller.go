@@ -54,7 +54,7 @@ func (e ExperimentController) GetExperiment(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	Ok(w, exp.ToApiSchema(segmenterTypes))
+	Ok(w, exp.ToApiSchema(segmenterTypes, e.Services.ExperimentService.Now()))
 }
 
 func (e ExperimentController) ListExperiments(w http.ResponseWriter, r *http.Request, projectId int64, params api.ListExperimentsParams) {
@@ -91,8 +91,9 @@ func (e ExperimentController) ListExperiments(w http.ResponseWriter, r *http.Req
 	if listExperimentParams.Fields != nil {
 		fields = *listExperimentParams.Fields
 	}
+	now := e.Services.ExperimentService.Now()
 	for _, exp := range exps {
-		expsResp = append(expsResp, exp.ToApiSchema(segmenterTypes, fields...))
+		expsResp = append(expsResp, exp.ToApiSchema(segmenterTypes, now, fields...))
 	}
 
 	Ok(w, expsResp, ToPagingSchema(paging))
@@ -144,7 +145,12 @@ func (e ExperimentController) CreateExperiment(w http.ResponseWriter, r *http.Re
 		WriteErrorResponse(w, err)
 		return
 	}
-	Ok(w, exp.ToApiSchema(segmenterTypes))
+	warnings, err := e.getActiveExperimentLimitWarnings(*settings)
+	if err != nil {
+		WriteErrorResponse(w, err)
+		return
+	}
+	OkWithWarnings(w, exp.ToApiSchema(segmenterTypes, e.Services.ExperimentService.Now()), warnings)
 }
 
 func (e ExperimentController) UpdateExperiment(w http.ResponseWriter, r *http.Request, projectId int64, experimentId int64) {
@@ -195,7 +201,7 @@ func (e ExperimentController) UpdateExperiment(w http.ResponseWriter, r *http.Re
 		WriteErrorResponse(w, err)
 		return
 	}
-	Ok(w, exp.ToApiSchema(segmenterTypes))
+	Ok(w, exp.ToApiSchema(segmenterTypes, e.Services.ExperimentService.Now()))
 }
 
 func (e ExperimentController) EnableExperiment(w http.ResponseWriter, r *http.Request, projectId int64, experimentId int64) {
@@ -216,7 +222,22 @@ func (e ExperimentController) EnableExperiment(w http.ResponseWriter, r *http.Re
 		WriteErrorResponse(w, err)
 		return
 	}
-	Ok(w, nil)
+	warnings, err := e.getActiveExperimentLimitWarnings(*settings)
+	if err != nil {
+		WriteErrorResponse(w, err)
+		return
+	}
+	OkWithWarnings(w, nil, warnings)
+}
+
+// getActiveExperimentLimitWarnings returns the warning of the project's running experiments exceeding the project's
+// soft maximum, if they do, to be returned after an experiment is created or enabled
+func (e ExperimentController) getActiveExperimentLimitWarnings(settings models.Settings) ([]string, error) {
+	warning, err := e.Services.ExperimentService.GetActiveExperimentLimitWarning(settings)
+	if err != nil || warning == "" {
+		return nil, err
+	}
+	return []string{warning}, nil
 }
 
 func (e ExperimentController) DisableExperiment(w http.ResponseWriter, r *http.Request, projectId int64, experimentId int64) {
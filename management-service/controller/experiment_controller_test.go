@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -38,6 +39,9 @@ func (s *ExperimentControllerTestSuite) SetupSuite() {
 	settingsSvc.
 		On("GetDBRecord", models.ID(2)).
 		Return(&models.Settings{ProjectID: models.ID(2)}, nil)
+	settingsSvc.
+		On("GetDBRecord", models.ID(5)).
+		Return(&models.Settings{ProjectID: models.ID(5)}, nil)
 	settingsSvc.
 		On("GetProjectSettings", int64(1)).
 		Return(nil, errors.Newf(errors.NotFound, "test get project settings error"))
@@ -83,7 +87,9 @@ func (s *ExperimentControllerTestSuite) SetupSuite() {
 			"start_time": "0001-01-01T00:00:00Z",
 			"updated_at": "0001-01-01T00:00:00Z",
 			"updated_by": "",
-			"version": 0
+			"version": 0,
+			"shadow": false,
+			"priority": 0
 		}`,
 		`{
 			"project_id": 2,
@@ -102,7 +108,9 @@ func (s *ExperimentControllerTestSuite) SetupSuite() {
 			"start_time": "0001-01-01T00:00:00Z",
 			"updated_at": "0001-01-01T00:00:00Z",
 			"updated_by": "",
-			"version": 0
+			"version": 0,
+			"shadow": false,
+			"priority": 0
 		}`,
 		`{
 			"project_id": 5,
@@ -121,10 +129,13 @@ func (s *ExperimentControllerTestSuite) SetupSuite() {
 			"start_time": "0001-01-01T00:00:00Z",
 			"updated_at": "0001-01-01T00:00:00Z",
 			"updated_by": "",
-			"version": 0
+			"version": 0,
+			"shadow": false,
+			"priority": 0
 		}`,
 	}
 	s.expectedErrorResponseFormat = `{"code":"%[1]v", "error":%[2]v, "message":%[2]v}`
+	expSvc.On("Now").Return(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
 	expSvc.
 		On("GetExperiment", int64(2), int64(20)).
 		Return(nil, errors.Newf(errors.NotFound, "experiment not found"))
@@ -188,6 +199,16 @@ func (s *ExperimentControllerTestSuite) SetupSuite() {
 				Segment:   models.ExperimentSegmentRaw(nil),
 			}).
 		Return(testExperiment1, nil)
+	expSvc.
+		On("CreateExperiment",
+			models.Settings{ProjectID: models.ID(5)},
+			services.CreateExperimentRequestBody{
+				Name:      "test-exp-3",
+				UpdatedBy: &updatedBy,
+				Tier:      models.ExperimentTierDefault,
+				Segment:   models.ExperimentSegmentRaw(nil),
+			}).
+		Return(testExperiment2, nil)
 	testDescription := "test-description-2"
 	testDaysOfWeek := []interface{}{float64(1)}
 	expSvc.
@@ -232,6 +253,17 @@ func (s *ExperimentControllerTestSuite) SetupSuite() {
 			models.Settings{ProjectID: models.ID(2)},
 			int64(3)).
 		Return(errors.Newf(errors.BadInput, "experiment id 3 is already active"))
+	expSvc.
+		On("EnableExperiment",
+			models.Settings{ProjectID: models.ID(5)},
+			int64(1)).
+		Return(nil)
+	expSvc.
+		On("GetActiveExperimentLimitWarning", models.Settings{ProjectID: models.ID(2)}).
+		Return("", nil)
+	expSvc.
+		On("GetActiveExperimentLimitWarning", models.Settings{ProjectID: models.ID(5)}).
+		Return("project 5 has 3 running experiments, exceeding the recommended maximum of 2", nil)
 	expSvc.
 		On("DisableExperiment",
 			int64(2),
@@ -465,6 +497,13 @@ func (s *ExperimentControllerTestSuite) TestCreateExperiment() {
 			experimentData: `{"name": "test-exp-2", "updated_by": "test-user", "tier": "override"}`,
 			expected:       fmt.Sprintf(`{"data": %s}`, s.expectedExperimentResponses[1]),
 		},
+		{
+			name:           "success | active experiment limit warning",
+			projectID:      5,
+			experimentData: `{"name": "test-exp-3", "updated_by": "test-user"}`,
+			expected: fmt.Sprintf(`{"data": %s, "warnings": [%q]}`, s.expectedExperimentResponses[2],
+				"project 5 has 3 running experiments, exceeding the recommended maximum of 2"),
+		},
 	}
 
 	// Run tests
@@ -598,6 +637,12 @@ func (s *ExperimentControllerTestSuite) TestEnableExperiment() {
 			experimentID: 1,
 			expected:     fmt.Sprintf(`{"data": %s}`, "null"),
 		},
+		{
+			name:         "success | active experiment limit warning",
+			projectID:    5,
+			experimentID: 1,
+			expected:     `{"warnings": ["project 5 has 3 running experiments, exceeding the recommended maximum of 2"]}`,
+		},
 	}
 
 	// Run tests
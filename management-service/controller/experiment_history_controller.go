@@ -100,7 +100,7 @@ func (e ExperimentHistoryController) checkProjectAndExperiment(projectId int64,
 	// Check that the experiment exists
 	_, err = e.Services.ExperimentService.GetDBRecord(models.ID(projectId), models.ID(experimentId))
 	if err != nil {
-		return errors.Newf(errors.NotFound, "Experiment with id %d cannot be retrieved: %v", experimentId, err)
+		return errors.Newf(errors.GetType(err), "Experiment with id %d cannot be retrieved: %v", experimentId, err)
 	}
 	return nil
 }
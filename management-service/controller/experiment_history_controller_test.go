@@ -53,6 +53,9 @@ func (s *ExperimentHistoryControllerTestSuite) SetupSuite() {
 	expSvc.
 		On("GetDBRecord", models.ID(3), models.ID(1)).
 		Return(nil, errors.Newf(errors.NotFound, "experiment not found"))
+	expSvc.
+		On("GetDBRecord", models.ID(3), models.ID(2)).
+		Return(nil, errors.Newf(errors.Internal, "sql: database is closed"))
 	expSvc.
 		On("GetDBRecord", models.ID(3), models.ID(10)).
 		Return(nil, nil)
@@ -143,7 +146,9 @@ func (s *ExperimentHistoryControllerTestSuite) SetupSuite() {
 		}],
 		"type": "Switchback",
 		"updated_by": "test-updated-by",
-		"version": 8
+		"version": 8,
+		"shadow": false,
+		"priority": 0
 	}`
 
 	// Create test controller
@@ -196,6 +201,14 @@ func (s *ExperimentHistoryControllerTestSuite) TestGetExperimentHistory() {
 			version:      1,
 			expected:     fmt.Sprintf(s.expectedErrorResponseFormat, 404, "\"Experiment with id 1 cannot be retrieved: experiment not found\""),
 		},
+		{
+			name:         "experiment retrieval error",
+			projectID:    3,
+			experimentID: 2,
+			version:      1,
+			expected: fmt.Sprintf(s.expectedErrorResponseFormat,
+				500, "\"Experiment with id 2 cannot be retrieved: sql: database is closed\""),
+		},
 		{
 			name:         "experiment history not found",
 			projectID:    3,
@@ -258,6 +271,13 @@ func (s *ExperimentHistoryControllerTestSuite) TestListExperimentHistory() {
 			experimentID: 1,
 			expected:     fmt.Sprintf(s.expectedErrorResponseFormat, 404, "\"Experiment with id 1 cannot be retrieved: experiment not found\""),
 		},
+		{
+			name:         "experiment retrieval error",
+			projectID:    3,
+			experimentID: 2,
+			expected: fmt.Sprintf(s.expectedErrorResponseFormat,
+				500, "\"Experiment with id 2 cannot be retrieved: sql: database is closed\""),
+		},
 		{
 			name:         "success",
 			projectID:    3,
@@ -88,9 +88,35 @@ func (p ProjectSettingsController) CreateProjectSettings(w http.ResponseWriter,
 		return
 	}
 
+	treatmentConfigDefaults, err := parseTreatmentConfigDefaults(settingsData.TreatmentConfigDefaults)
+	if err != nil {
+		WriteErrorResponse(w, errors.Newf(errors.BadInput, err.Error()))
+		return
+	}
+
 	settings, err := p.Services.ProjectSettingsService.CreateProjectSettings(
 		projectId,
 		services.CreateProjectSettingsRequestBody{
+			ExperimentationConfigRequestBody: services.ExperimentationConfigRequestBody{
+				TreatmentConfigDefaults:        treatmentConfigDefaults,
+				OrthogonalityExemptSegmenters:  settingsData.OrthogonalityExemptSegmenters,
+				EnabledValidators:              settingsData.EnabledValidators,
+				DefaultSegment:                 (*models.ExperimentSegmentRaw)(settingsData.DefaultSegment),
+				BlockOrphanedOverrides:         settingsData.BlockOrphanedOverrides,
+				ValidationUrlRateLimit:         parseValidationUrlRateLimit(settingsData.ValidationUrlRateLimit),
+				OrthogonalityLookaheadSeconds:  settingsData.OrthogonalityLookaheadSeconds,
+				RequiredSegmenterCombinations:  settingsData.RequiredSegmenterCombinations,
+				TypedSegmentStorage:            settingsData.TypedSegmentStorage,
+				TreatmentNamePattern:           settingsData.TreatmentNamePattern,
+				SoftMaxActiveExperiments:       settingsData.SoftMaxActiveExperiments,
+				HardMaxActiveExperiments:       settingsData.HardMaxActiveExperiments,
+				PriorityOverlapResolution:      settingsData.PriorityOverlapResolution,
+				BlockDuplicateTreatmentConfigs: settingsData.BlockDuplicateTreatmentConfigs,
+				DefaultTier:                    (*models.ExperimentTier)(settingsData.DefaultTier),
+				DefaultType:                    (*models.ExperimentType)(settingsData.DefaultType),
+				BlockExhaustiveSegmentValues:   settingsData.BlockExhaustiveSegmentValues,
+				MaxTreatmentConfigBytes:        settingsData.MaxTreatmentConfigBytes,
+			},
 			Segmenters: models.ProjectSegmenters{
 				Names:     settingsData.Segmenters.Names,
 				Variables: settingsData.Segmenters.Variables.AdditionalProperties,
@@ -130,9 +156,35 @@ func (p ProjectSettingsController) UpdateProjectSettings(w http.ResponseWriter,
 		return
 	}
 
+	treatmentConfigDefaults, err := parseTreatmentConfigDefaults(settingsData.TreatmentConfigDefaults)
+	if err != nil {
+		WriteErrorResponse(w, errors.Newf(errors.BadInput, err.Error()))
+		return
+	}
+
 	settings, err := p.Services.ProjectSettingsService.UpdateProjectSettings(
 		projectId,
 		services.UpdateProjectSettingsRequestBody{
+			ExperimentationConfigRequestBody: services.ExperimentationConfigRequestBody{
+				TreatmentConfigDefaults:        treatmentConfigDefaults,
+				OrthogonalityExemptSegmenters:  settingsData.OrthogonalityExemptSegmenters,
+				EnabledValidators:              settingsData.EnabledValidators,
+				DefaultSegment:                 (*models.ExperimentSegmentRaw)(settingsData.DefaultSegment),
+				BlockOrphanedOverrides:         settingsData.BlockOrphanedOverrides,
+				ValidationUrlRateLimit:         parseValidationUrlRateLimit(settingsData.ValidationUrlRateLimit),
+				OrthogonalityLookaheadSeconds:  settingsData.OrthogonalityLookaheadSeconds,
+				RequiredSegmenterCombinations:  settingsData.RequiredSegmenterCombinations,
+				TypedSegmentStorage:            settingsData.TypedSegmentStorage,
+				TreatmentNamePattern:           settingsData.TreatmentNamePattern,
+				SoftMaxActiveExperiments:       settingsData.SoftMaxActiveExperiments,
+				HardMaxActiveExperiments:       settingsData.HardMaxActiveExperiments,
+				PriorityOverlapResolution:      settingsData.PriorityOverlapResolution,
+				BlockDuplicateTreatmentConfigs: settingsData.BlockDuplicateTreatmentConfigs,
+				DefaultTier:                    (*models.ExperimentTier)(settingsData.DefaultTier),
+				DefaultType:                    (*models.ExperimentType)(settingsData.DefaultType),
+				BlockExhaustiveSegmentValues:   settingsData.BlockExhaustiveSegmentValues,
+				MaxTreatmentConfigBytes:        settingsData.MaxTreatmentConfigBytes,
+			},
 			Segmenters: models.ProjectSegmenters{
 				Names:     settingsData.Segmenters.Names,
 				Variables: settingsData.Segmenters.Variables.AdditionalProperties,
@@ -166,3 +218,28 @@ func parseTreatmentSchema(treatmentSchema *schema.TreatmentSchema) (parsedTreatm
 
 	return
 }
+
+// parseValidationUrlRateLimit parses validationUrlRateLimit from an api struct into a model struct
+func parseValidationUrlRateLimit(rateLimit *schema.ValidationUrlRateLimit) *models.ValidationUrlRateLimit {
+	if rateLimit == nil {
+		return nil
+	}
+
+	parsedRateLimit := &models.ValidationUrlRateLimit{
+		RequestsPerSecond: rateLimit.RequestsPerSecond,
+		Burst:             rateLimit.Burst,
+	}
+	if rateLimit.MaxWaitMillis != nil {
+		parsedRateLimit.MaxWaitMillis = *rateLimit.MaxWaitMillis
+	}
+
+	return parsedRateLimit
+}
+
+// parseTreatmentConfigDefaults marshals the treatment config defaults, if given, as they are stored in the settings
+func parseTreatmentConfigDefaults(configDefaults *map[string]interface{}) (json.RawMessage, error) {
+	if configDefaults == nil {
+		return nil, nil
+	}
+	return json.Marshal(*configDefaults)
+}
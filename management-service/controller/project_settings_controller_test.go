@@ -83,7 +83,12 @@ func (s *ProjectSettingsControllerTestSuite) SetupSuite() {
 			]
 		},
 		"randomization_key": "rand",
-		"enable_s2id_clustering": false
+		"enable_s2id_clustering": false,
+		"priority_overlap_resolution": false,
+		"block_duplicate_treatment_configs": false,
+		"block_exhaustive_segment_values": false,
+		"typed_segment_storage": false,
+		"block_orphaned_overrides": false
 	}`
 	s.expectedErrorResponseFormat = `{"code":"%[1]v", "error":%[2]v, "message":%[2]v}`
 	s.expectedProjectSettingsParamsResponse = `{"data": ["rand", "exp_var_1", "exp_var_2"]}`
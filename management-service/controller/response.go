@@ -30,6 +30,27 @@ func Ok(w http.ResponseWriter, jsonBody interface{}, paging ...*schema.Paging) {
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+// OkWithWarnings writes the response as in Ok, together with the given warnings, if any. Unlike Ok, the response has a
+// body whenever there are warnings, even without the data.
+func OkWithWarnings(w http.ResponseWriter, jsonBody interface{}, warnings []string) {
+	if len(warnings) == 0 {
+		Ok(w, jsonBody)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	resp := struct {
+		Data     interface{} `json:"data,omitempty"`
+		Warnings []string    `json:"warnings"`
+	}{
+		Data:     jsonBody,
+		Warnings: warnings,
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
 func WriteErrorResponse(w http.ResponseWriter, err error) {
 	httpErr := errors.NewHTTPError(err)
 	w.Header().Set("Content-Type", "application/json")
@@ -106,3 +106,42 @@ func TestOk(t *testing.T) {
 	}
 
 }
+
+func TestOkWithWarnings(t *testing.T) {
+	tests := map[string]struct {
+		jsonBody interface{}
+		warnings []string
+		expected string
+	}{
+		"no warnings": {
+			jsonBody: interface{}(map[string]string{"key": "val"}),
+			expected: `{"data": {"key":"val"}}`,
+		},
+		"warnings": {
+			jsonBody: interface{}(map[string]string{"key": "val"}),
+			warnings: []string{"warning"},
+			expected: `{"data": {"key":"val"}, "warnings": ["warning"]}`,
+		},
+		"warnings without data": {
+			warnings: []string{"warning"},
+			expected: `{"warnings": ["warning"]}`,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			OkWithWarnings(w, data.jsonBody, data.warnings)
+			resp := w.Result()
+			if resp != nil && resp.Body != nil {
+				defer resp.Body.Close()
+			}
+			body, _ := io.ReadAll(resp.Body)
+
+			// Validate
+			assert.Equal(t, 200, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			assert.JSONEq(t, data.expected, string(body))
+		})
+	}
+}
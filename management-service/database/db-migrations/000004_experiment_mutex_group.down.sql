@@ -0,0 +1 @@
+ALTER TABLE experiments DROP COLUMN mutex_group;
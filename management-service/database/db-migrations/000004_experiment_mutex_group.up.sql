@@ -0,0 +1 @@
+ALTER TABLE experiments ADD mutex_group varchar(64);
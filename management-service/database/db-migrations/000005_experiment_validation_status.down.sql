@@ -0,0 +1 @@
+ALTER TABLE experiments DROP COLUMN last_validation_status;
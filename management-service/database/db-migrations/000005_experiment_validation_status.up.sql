@@ -0,0 +1 @@
+ALTER TABLE experiments ADD last_validation_status varchar(16);
@@ -0,0 +1,3 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+-- Trigram index on the experiment name, used for the fuzzy search by similarity
+CREATE INDEX experiment_name_trgm ON experiments USING gin (name gin_trgm_ops);
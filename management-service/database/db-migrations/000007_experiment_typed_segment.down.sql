@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS experiment_typed_segment;
+ALTER TABLE experiments DROP COLUMN typed_segment;
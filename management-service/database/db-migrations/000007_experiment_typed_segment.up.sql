@@ -0,0 +1,3 @@
+-- Segment of the experiment with the values in their segmenter types, for the projects that store typed segments
+ALTER TABLE experiments ADD typed_segment jsonb;
+CREATE INDEX experiment_typed_segment ON experiments USING gin (typed_segment);
@@ -0,0 +1,2 @@
+-- Recurrence of the experiment, set on the latest occurrence of the recurring experiments only
+ALTER TABLE experiments ADD recurrence jsonb;
@@ -0,0 +1,2 @@
+-- Priority of the experiment, used to resolve the overlaps between experiments in the projects that allow them
+ALTER TABLE experiments ADD priority integer NOT NULL DEFAULT 0;
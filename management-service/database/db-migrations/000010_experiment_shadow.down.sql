@@ -0,0 +1 @@
+ALTER TABLE experiments DROP COLUMN shadow;
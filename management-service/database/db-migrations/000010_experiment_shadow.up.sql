@@ -0,0 +1,2 @@
+-- Whether the experiment is a shadow experiment, which only observes the traffic and is exempt from orthogonality
+ALTER TABLE experiments ADD shadow boolean NOT NULL DEFAULT false;
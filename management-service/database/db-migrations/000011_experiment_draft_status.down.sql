@@ -0,0 +1,16 @@
+-- Enum values cannot be dropped, so the drafts are deactivated and the type is recreated without the draft status
+UPDATE experiments SET status = 'inactive' WHERE status = 'draft';
+UPDATE experiment_history SET status = 'inactive' WHERE status = 'draft';
+
+ALTER TYPE experiment_status RENAME TO experiment_status_old;
+CREATE TYPE experiment_status as ENUM ('active', 'inactive');
+
+ALTER TABLE experiments ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE experiments ALTER COLUMN status TYPE experiment_status USING status::text::experiment_status;
+ALTER TABLE experiments ALTER COLUMN status SET DEFAULT 'active';
+
+ALTER TABLE experiment_history ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE experiment_history ALTER COLUMN status TYPE experiment_status USING status::text::experiment_status;
+ALTER TABLE experiment_history ALTER COLUMN status SET DEFAULT 'active';
+
+DROP TYPE experiment_status_old;
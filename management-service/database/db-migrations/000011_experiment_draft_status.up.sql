@@ -0,0 +1,2 @@
+-- Draft experiments are still being authored and are not served
+ALTER TYPE experiment_status ADD VALUE IF NOT EXISTS 'draft';
@@ -0,0 +1,4 @@
+ALTER TABLE experiment_history DROP COLUMN shadow;
+ALTER TABLE experiment_history DROP COLUMN priority;
+ALTER TABLE experiment_history DROP COLUMN recurrence;
+ALTER TABLE experiment_history DROP COLUMN mutex_group;
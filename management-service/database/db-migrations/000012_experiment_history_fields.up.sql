@@ -0,0 +1,5 @@
+-- Fields of the experiments that were added after the history, so that each version is recorded in full
+ALTER TABLE experiment_history ADD mutex_group varchar(64);
+ALTER TABLE experiment_history ADD recurrence jsonb;
+ALTER TABLE experiment_history ADD priority integer NOT NULL DEFAULT 0;
+ALTER TABLE experiment_history ADD shadow boolean NOT NULL DEFAULT false;
@@ -19,6 +19,12 @@ const (
 	BadInput
 	// NotFound is used when a resource cannot be located
 	NotFound
+	// Conflict is used when the request cannot be carried out in the current state of the resource
+	Conflict
+	// Duplicate is used when the request would duplicate an existing resource
+	Duplicate
+	// Internal is used when the request fails for reasons unrelated to the input, such as a database failure
+	Internal
 )
 
 type errorData struct {
@@ -69,6 +75,10 @@ func GetHTTPErrorCode(err error) int {
 		code = http.StatusBadRequest
 	case NotFound:
 		code = http.StatusNotFound
+	case Conflict, Duplicate:
+		code = http.StatusConflict
+	case Internal:
+		code = http.StatusInternalServerError
 	default:
 		code = http.StatusInternalServerError
 	}
@@ -59,6 +59,21 @@ func TestGetHTTPErrorCode(t *testing.T) {
 			err:          Newf(NotFound, ""),
 			expectedCode: http.StatusNotFound,
 		},
+		{
+			name:         "Conflict",
+			err:          Newf(Conflict, ""),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "Duplicate",
+			err:          Newf(Duplicate, ""),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "Internal",
+			err:          Newf(Internal, ""),
+			expectedCode: http.StatusInternalServerError,
+		},
 	}
 
 	for _, data := range testErrorSuite {
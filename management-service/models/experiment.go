@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -15,11 +17,16 @@ type ExperimentStatus string
 type ExperimentType string
 type ExperimentTier string
 type ExperimentField string
+type ExperimentValidationStatus string
 
 const (
 	ExperimentStatusActive ExperimentStatus = "active"
 
 	ExperimentStatusInactive ExperimentStatus = "inactive"
+
+	// ExperimentStatusDraft is the status of an experiment that is still being authored. Drafts are not checked for
+	// orthogonality, and are promoted to inactive before they can be enabled.
+	ExperimentStatusDraft ExperimentStatus = "draft"
 )
 
 // Defines values for ExperimentType.
@@ -29,6 +36,9 @@ const (
 	ExperimentTypeSwitchback ExperimentType = "Switchback"
 )
 
+// SwitchbackIntervalUnit is the unit of the interval of Switchback experiments
+const SwitchbackIntervalUnit = time.Minute
+
 // Defines values for ExperimentTier.
 const (
 	ExperimentTierDefault ExperimentTier = "default"
@@ -36,8 +46,18 @@ const (
 	ExperimentTierOverride ExperimentTier = "override"
 )
 
+// Defines values for ExperimentValidationStatus.
+const (
+	ExperimentValidationStatusPassed ExperimentValidationStatus = "passed"
+
+	ExperimentValidationStatusFailed ExperimentValidationStatus = "failed"
+)
+
 // Defines values for ExperimentField.
 const (
+	// ExperimentFieldAll selects all the fields explicitly, and cannot be combined with other fields
+	ExperimentFieldAll ExperimentField = "*"
+
 	ExperimentFieldEndTime ExperimentField = "end_time"
 
 	ExperimentFieldId ExperimentField = "id"
@@ -79,7 +99,7 @@ type Experiment struct {
 	Description *string `json:"description"`
 	// Type captures the experiment's type
 	Type ExperimentType `json:"type"`
-	// Interval holds the switchback interval in minutes
+	// Interval holds the switchback interval, in SwitchbackIntervalUnit (minutes)
 	Interval *int32 `json:"interval"`
 	// Tier holds the priority of the experiment
 	Tier ExperimentTier `json:"tier"`
@@ -87,6 +107,9 @@ type Experiment struct {
 	Treatments ExperimentTreatments `json:"treatments"`
 	// Segment holds the combination of segmenters that the experiment applies to
 	Segment ExperimentSegment `json:"segment"`
+	// TypedSegment holds the same segment with the values in their segmenter types, for the projects that store
+	// typed segments. It is not set for the other projects.
+	TypedSegment ExperimentSegmentRaw `json:"-"`
 	// Status is the experiment's status
 	Status ExperimentStatus `json:"status"`
 	// StartTime describes the time at which an experiment starts
@@ -95,6 +118,20 @@ type Experiment struct {
 	EndTime time.Time `json:"end_time"`
 	// UpdatedBy holds the details of the last person/job that updated the experiment
 	UpdatedBy string `json:"updated_by"`
+	// MutexGroup is an optional name of a group of experiments, of which at most one may be active at any time
+	MutexGroup *string `json:"mutex_group"`
+	// LastValidationStatus is the outcome of the last custom validation run on the experiment, if any
+	LastValidationStatus *ExperimentValidationStatus `json:"last_validation_status"`
+	// Recurrence schedules the next occurrences of the experiment, if it recurs. It is handed over to the next
+	// occurrence once that is created, so that only the latest occurrence of a recurring experiment has it set.
+	Recurrence *RecurrenceSpec `json:"recurrence"`
+	// Priority ranks the experiment against the experiments of the same tier that it overlaps with, for the projects
+	// that resolve overlaps by priority. The experiment with the higher priority takes effect.
+	Priority int32 `json:"priority"`
+	// Shadow marks an experiment that only observes the traffic, without affecting it. Shadow experiments are enabled
+	// and published like the others, but are excluded from the orthogonality checks, both as the experiment checked
+	// and as the existing experiments checked against.
+	Shadow bool `json:"shadow"`
 }
 
 // AfterFind sets the retrieved start and end times to be in UTC as opposed to Local.
@@ -106,13 +143,75 @@ func (e *Experiment) AfterFind(tx *gorm.DB) error {
 	return nil
 }
 
+// Validate checks that the experiment is well-formed, i.e., that it has a name, that its type, tier and status are
+// known values, and that it ends after it starts. Unlike the validation of the request bodies, this applies to every
+// experiment that is saved, including those constructed internally.
+func (e *Experiment) Validate() error {
+	if strings.TrimSpace(e.Name) == "" {
+		return fmt.Errorf("experiment name must not be empty")
+	}
+	switch e.Type {
+	case ExperimentTypeAB, ExperimentTypeSwitchback:
+	default:
+		return fmt.Errorf("unknown experiment type: %s", e.Type)
+	}
+	switch e.Tier {
+	case ExperimentTierDefault, ExperimentTierOverride:
+	default:
+		return fmt.Errorf("unknown experiment tier: %s", e.Tier)
+	}
+	switch e.Status {
+	case ExperimentStatusActive, ExperimentStatusInactive, ExperimentStatusDraft:
+	default:
+		return fmt.Errorf("unknown experiment status: %s", e.Status)
+	}
+	if !e.EndTime.After(e.StartTime) {
+		return fmt.Errorf("experiment end time must be after the start time")
+	}
+	if e.Type == ExperimentTypeSwitchback && e.IntervalOrZero() <= 0 {
+		return fmt.Errorf("switchback experiment must have a positive interval")
+	}
+	if e.Recurrence != nil {
+		return e.Recurrence.Validate(e.EndTime.Sub(e.StartTime))
+	}
+	return nil
+}
+
+// IntervalOrZero returns the switchback interval of the experiment, or 0 if it is not set, e.g., for A/B experiments
+func (e *Experiment) IntervalOrZero() int32 {
+	if e.Interval == nil {
+		return 0
+	}
+	return *e.Interval
+}
+
+// NumberOfSwitchbackPeriods returns the number of full switchback intervals that fit in the experiment's duration.
+// It is 0 if the interval is not set or is not positive.
+func (e *Experiment) NumberOfSwitchbackPeriods() int64 {
+	interval := e.IntervalOrZero()
+	if interval <= 0 || !e.EndTime.After(e.StartTime) {
+		return 0
+	}
+	return int64(e.EndTime.Sub(e.StartTime) / (time.Duration(interval) * SwitchbackIntervalUnit))
+}
+
+// IsAllExperimentFields returns true if the given fields select all the fields of an experiment explicitly, which is
+// equivalent to not selecting any fields
+func IsAllExperimentFields(fields []ExperimentField) bool {
+	return len(fields) == 1 && fields[0] == ExperimentFieldAll
+}
+
 // ToApiSchema converts the experiment DB model to a format compatible with the
-// OpenAPI specifications.
-func (e *Experiment) ToApiSchema(segmentersType map[string]schema.SegmenterType, fields ...ExperimentField) schema.Experiment {
+// OpenAPI specifications. The friendly status of the experiment is evaluated at the given time.
+func (e *Experiment) ToApiSchema(
+	segmentersType map[string]schema.SegmenterType,
+	now time.Time,
+	fields ...ExperimentField,
+) schema.Experiment {
 	experiment := schema.Experiment{}
 
 	// Only return requested fields
-	if fields != nil {
+	if fields != nil && !IsAllExperimentFields(fields) {
 		for _, field := range fields {
 			switch field {
 			case ExperimentFieldName:
@@ -124,7 +223,7 @@ func (e *Experiment) ToApiSchema(segmentersType map[string]schema.SegmenterType,
 				experimentType := schema.ExperimentType(e.Type)
 				experiment.Type = &experimentType
 			case ExperimentFieldStatusFriendly:
-				statusFriendly := getExperimentStatusFriendly(e.StartTime, e.EndTime, e.Status)
+				statusFriendly := getExperimentStatusFriendly(e.StartTime, e.EndTime, e.Status, now)
 				experiment.StatusFriendly = &statusFriendly
 			case ExperimentFieldTier:
 				tier := schema.ExperimentTier(e.Tier)
@@ -147,7 +246,7 @@ func (e *Experiment) ToApiSchema(segmentersType map[string]schema.SegmenterType,
 	projectId := e.ProjectID.ToApiSchema()
 	segment := e.Segment.ToApiSchema(segmentersType)
 	status := schema.ExperimentStatus(e.Status)
-	statusFriendly := getExperimentStatusFriendly(e.StartTime, e.EndTime, e.Status)
+	statusFriendly := getExperimentStatusFriendly(e.StartTime, e.EndTime, e.Status, now)
 	treatments := e.Treatments.ToApiSchema()
 	experimentType := schema.ExperimentType(e.Type)
 	tier := schema.ExperimentTier(e.Tier)
@@ -170,22 +269,22 @@ func (e *Experiment) ToApiSchema(segmentersType map[string]schema.SegmenterType,
 		UpdatedAt:      &e.UpdatedAt,
 		UpdatedBy:      &e.UpdatedBy,
 		Version:        &e.Version,
+		Shadow:         &e.Shadow,
+		Priority:       &e.Priority,
 	}
 }
 
 // ToProtoSchema converts the experiment DB model to a format compatible with the
 // Protobuf specifications.
 func (e *Experiment) ToProtoSchema(segmentersType map[string]schema.SegmenterType) (*_pubsub.Experiment, error) {
-	var interval int32
-	if e.Interval != nil {
-		interval = *e.Interval
-	}
+	interval := e.IntervalOrZero()
 
 	var experimentStatus _pubsub.Experiment_Status
 	switch e.Status {
 	case ExperimentStatusActive:
 		experimentStatus = _pubsub.Experiment_Active
-	case ExperimentStatusInactive:
+	case ExperimentStatusInactive, ExperimentStatusDraft:
+		// Drafts are not served
 		experimentStatus = _pubsub.Experiment_Inactive
 	}
 
@@ -229,13 +328,21 @@ func (e *Experiment) ToProtoSchema(segmentersType map[string]schema.SegmenterTyp
 		StartTime:  startTime,
 		UpdatedAt:  updatedAt,
 		Version:    e.Version,
+		Shadow:     e.Shadow,
+		Priority:   e.Priority,
 	}, nil
 }
 
-func getExperimentStatusFriendly(startTime time.Time, endTime time.Time, status ExperimentStatus) schema.ExperimentStatusFriendly {
+func getExperimentStatusFriendly(
+	startTime time.Time,
+	endTime time.Time,
+	status ExperimentStatus,
+	currentTime time.Time,
+) schema.ExperimentStatusFriendly {
 	statusFriendly := schema.ExperimentStatusFriendlyDeactivated
-	if status == ExperimentStatusActive {
-		currentTime := time.Now()
+	if status == ExperimentStatusDraft {
+		statusFriendly = schema.ExperimentStatusFriendlyDraft
+	} else if status == ExperimentStatusActive {
 		if currentTime.Before(startTime) {
 			statusFriendly = schema.ExperimentStatusFriendlyScheduled
 		} else if currentTime.After(endTime) {
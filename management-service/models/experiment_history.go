@@ -37,6 +37,10 @@ type ExperimentHistory struct {
 	StartTime   time.Time            `json:"start_time"`
 	EndTime     time.Time            `json:"end_time"`
 	UpdatedBy   string               `json:"updated_by"`
+	MutexGroup  *string              `json:"mutex_group"`
+	Recurrence  *RecurrenceSpec      `json:"recurrence"`
+	Priority    int32                `json:"priority"`
+	Shadow      bool                 `json:"shadow"`
 }
 
 // TableName overrides Gorm's default pluralised name: "experiment_histories"
@@ -67,7 +71,9 @@ func (e *ExperimentHistory) ToApiSchema(segmentersType map[string]schema.Segment
 		Interval:     e.Interval,
 		Name:         e.Name,
 		ExperimentId: e.ExperimentID.ToApiSchema(),
+		Priority:     &e.Priority,
 		Segment:      e.Segment.ToApiSchema(segmentersType),
+		Shadow:       &e.Shadow,
 		Status:       status,
 		Tier:         tierType,
 		Treatments:   e.Treatments.ToApiSchema(),
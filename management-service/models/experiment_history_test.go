@@ -21,6 +21,8 @@ func TestExperimentHistoryToApiSchema(t *testing.T) {
 	var testExperimentTraffic int32 = 80
 	var testDescription = "exp history desc"
 	var testTreatmentTraffic20 int32 = 20
+	var testPriority int32 = 3
+	var testShadow = true
 	config := map[string]interface{}{
 		"config-1": "value",
 		"config-2": 2,
@@ -60,6 +62,8 @@ func TestExperimentHistoryToApiSchema(t *testing.T) {
 		EndTime:   time.Date(2022, 1, 1, 1, 1, 1, 0, time.UTC),
 		StartTime: time.Date(2022, 2, 2, 1, 1, 1, 0, time.UTC),
 		UpdatedBy: "test-updated-by",
+		Priority:  testPriority,
+		Shadow:    true,
 	}
 	assert.Equal(t, schema.ExperimentHistory{
 		Id:           int64(100),
@@ -97,6 +101,8 @@ func TestExperimentHistoryToApiSchema(t *testing.T) {
 		},
 		Status:    schema.ExperimentStatusInactive,
 		Tier:      schema.ExperimentTierOverride,
+		Priority:  &testPriority,
+		Shadow:    &testShadow,
 		EndTime:   time.Date(2022, 1, 1, 1, 1, 1, 0, time.UTC),
 		StartTime: time.Date(2022, 2, 2, 1, 1, 1, 0, time.UTC),
 		UpdatedBy: "test-updated-by",
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RecurrenceSpec schedules the occurrences of a recurring experiment. Each occurrence starts a period after the start of
+// the previous one and has the same duration, until the given number of occurrences have been scheduled.
+type RecurrenceSpec struct {
+	// Period is the time between the starts of consecutive occurrences, e.g., a week. It is serialised in whole seconds,
+	// as period_seconds.
+	Period time.Duration `json:"-"`
+	// Occurrences is the total number of occurrences, including the first
+	Occurrences int `json:"occurrences"`
+	// Occurrence is the 1-based index of the experiment among the occurrences
+	Occurrence int `json:"occurrence"`
+}
+
+// recurrenceSpecJSON is the serialised form of the RecurrenceSpec
+type recurrenceSpecJSON struct {
+	PeriodSeconds int64 `json:"period_seconds"`
+	Occurrences   int   `json:"occurrences"`
+	Occurrence    int   `json:"occurrence"`
+}
+
+func (r RecurrenceSpec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(recurrenceSpecJSON{
+		PeriodSeconds: int64(r.Period / time.Second),
+		Occurrences:   r.Occurrences,
+		Occurrence:    r.Occurrence,
+	})
+}
+
+func (r *RecurrenceSpec) UnmarshalJSON(b []byte) error {
+	var spec recurrenceSpecJSON
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return err
+	}
+	r.Period = time.Duration(spec.PeriodSeconds) * time.Second
+	r.Occurrences = spec.Occurrences
+	r.Occurrence = spec.Occurrence
+	return nil
+}
+
+func (r *RecurrenceSpec) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &r)
+}
+
+func (r RecurrenceSpec) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Validate checks that the recurrence is well-formed for an experiment of the given duration. The period may not be
+// shorter than the duration, so that the occurrences do not overlap.
+func (r *RecurrenceSpec) Validate(duration time.Duration) error {
+	if r.Occurrences < 1 {
+		return fmt.Errorf("recurrence occurrences must be positive")
+	}
+	if r.Occurrence < 1 || r.Occurrence > r.Occurrences {
+		return fmt.Errorf("recurrence occurrence must be between 1 and %d", r.Occurrences)
+	}
+	if r.Period < duration {
+		return fmt.Errorf("recurrence period %s must not be shorter than the experiment duration %s",
+			r.Period, duration)
+	}
+	return nil
+}
+
+// HasNextOccurrence returns true if there are occurrences remaining after the current one
+func (r *RecurrenceSpec) HasNextOccurrence() bool {
+	return r.Occurrence < r.Occurrences
+}
+
+// NextOccurrence returns the next occurrence of the recurring experiment, as a new experiment that is shifted by the
+// recurrence period and carries the recurrence forward. It returns nil if the experiment does not recur, or if it is
+// the last occurrence.
+func (e *Experiment) NextOccurrence() *Experiment {
+	if e.Recurrence == nil || !e.Recurrence.HasNextOccurrence() {
+		return nil
+	}
+	recurrence := *e.Recurrence
+	recurrence.Occurrence++
+
+	return &Experiment{
+		ProjectID:    e.ProjectID,
+		Version:      1,
+		Name:         e.Name,
+		Description:  e.Description,
+		Type:         e.Type,
+		Interval:     e.Interval,
+		Tier:         e.Tier,
+		Treatments:   e.Treatments,
+		Segment:      e.Segment,
+		TypedSegment: e.TypedSegment,
+		Status:       e.Status,
+		StartTime:    e.StartTime.Add(recurrence.Period),
+		EndTime:      e.EndTime.Add(recurrence.Period),
+		UpdatedBy:    e.UpdatedBy,
+		MutexGroup:   e.MutexGroup,
+		Recurrence:   &recurrence,
+		Priority:     e.Priority,
+		Shadow:       e.Shadow,
+	}
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurrenceSpecValidate(t *testing.T) {
+	tests := map[string]struct {
+		recurrence RecurrenceSpec
+		err        string
+	}{
+		"success": {
+			recurrence: RecurrenceSpec{Period: 7 * 24 * time.Hour, Occurrences: 3, Occurrence: 1},
+		},
+		"success | period equal to the duration": {
+			recurrence: RecurrenceSpec{Period: time.Hour, Occurrences: 3, Occurrence: 3},
+		},
+		"failure | no occurrences": {
+			recurrence: RecurrenceSpec{Period: time.Hour, Occurrence: 1},
+			err:        "recurrence occurrences must be positive",
+		},
+		"failure | occurrence out of range": {
+			recurrence: RecurrenceSpec{Period: time.Hour, Occurrences: 3, Occurrence: 4},
+			err:        "recurrence occurrence must be between 1 and 3",
+		},
+		"failure | period shorter than the duration": {
+			recurrence: RecurrenceSpec{Period: time.Minute, Occurrences: 3, Occurrence: 1},
+			err:        "recurrence period 1m0s must not be shorter than the experiment duration 1h0m0s",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := data.recurrence.Validate(time.Hour)
+			if data.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, data.err)
+			}
+		})
+	}
+}
+
+func TestRecurrenceSpecJSON(t *testing.T) {
+	recurrence := RecurrenceSpec{Period: 7 * 24 * time.Hour, Occurrences: 3, Occurrence: 2}
+	expected := `{"period_seconds":604800,"occurrences":3,"occurrence":2}`
+
+	b, err := json.Marshal(recurrence)
+	assert.NoError(t, err)
+	assert.JSONEq(t, expected, string(b))
+
+	var unmarshalled RecurrenceSpec
+	assert.NoError(t, json.Unmarshal([]byte(expected), &unmarshalled))
+	assert.Equal(t, recurrence, unmarshalled)
+
+	// The stored value is serialised in the same way
+	value, err := recurrence.Value()
+	assert.NoError(t, err)
+	assert.JSONEq(t, expected, string(value.([]byte)))
+	var scanned RecurrenceSpec
+	assert.NoError(t, scanned.Scan(value))
+	assert.Equal(t, recurrence, scanned)
+}
+
+func TestExperimentNextOccurrence(t *testing.T) {
+	startTime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	experiment := Experiment{
+		ID:        ID(5),
+		ProjectID: ID(1),
+		Version:   3,
+		Name:      "test-exp",
+		Type:      ExperimentTypeSwitchback,
+		Tier:      ExperimentTierDefault,
+		Status:    ExperimentStatusActive,
+		Segment:   ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		StartTime: startTime,
+		EndTime:   startTime.Add(time.Hour),
+		UpdatedBy: "test-user",
+		Recurrence: &RecurrenceSpec{
+			Period:      7 * 24 * time.Hour,
+			Occurrences: 2,
+			Occurrence:  1,
+		},
+	}
+
+	next := experiment.NextOccurrence()
+	assert.Equal(t, &Experiment{
+		ProjectID: ID(1),
+		Version:   1,
+		Name:      "test-exp",
+		Type:      ExperimentTypeSwitchback,
+		Tier:      ExperimentTierDefault,
+		Status:    ExperimentStatusActive,
+		Segment:   ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		StartTime: startTime.Add(7 * 24 * time.Hour),
+		EndTime:   startTime.Add(7*24*time.Hour + time.Hour),
+		UpdatedBy: "test-user",
+		Recurrence: &RecurrenceSpec{
+			Period:      7 * 24 * time.Hour,
+			Occurrences: 2,
+			Occurrence:  2,
+		},
+	}, next)
+	// The recurrence of the current occurrence is not changed
+	assert.Equal(t, 1, experiment.Recurrence.Occurrence)
+
+	// There is no occurrence after the last one, nor for experiments that do not recur
+	assert.Nil(t, next.NextOccurrence())
+	experiment.Recurrence = nil
+	assert.Nil(t, experiment.NextOccurrence())
+}
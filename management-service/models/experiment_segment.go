@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/caraml-dev/xp/common/api/schema"
@@ -28,6 +29,63 @@ func (s ExperimentSegment) Value() (driver.Value, error) {
 	return json.Marshal(s)
 }
 
+// Scan reads a typed segment from the DB, where a NULL value is an unset segment
+func (s *ExperimentSegmentRaw) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &s)
+}
+
+// Value stores the segment in the DB as typed JSON values, where an unset segment is stored as NULL
+func (s ExperimentSegmentRaw) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+// WithDefaults returns a copy of the segment, in which the segmenters that are not set (absent or empty) take on
+// the values of the given default segment
+func (s ExperimentSegment) WithDefaults(defaults ExperimentSegment) ExperimentSegment {
+	segment := ExperimentSegment{}
+	for key, vals := range defaults {
+		segment[key] = vals
+	}
+	for key, vals := range s {
+		if len(vals) > 0 || len(segment[key]) == 0 {
+			segment[key] = vals
+		}
+	}
+	return segment
+}
+
+// WithDefaults returns a copy of the segment, in which the segmenters that are not set (absent or empty) take on
+// the values of the given default segment
+func (s ExperimentSegmentRaw) WithDefaults(defaults ExperimentSegmentRaw) ExperimentSegmentRaw {
+	segment := ExperimentSegmentRaw{}
+	for key, vals := range defaults {
+		segment[key] = vals
+	}
+	for key, vals := range s {
+		if isRawSegmenterValueSet(vals) || !isRawSegmenterValueSet(segment[key]) {
+			segment[key] = vals
+		}
+	}
+	return segment
+}
+
+func isRawSegmenterValueSet(vals interface{}) bool {
+	list, ok := vals.([]interface{})
+	return ok && len(list) > 0
+}
+
 // ToApiSchema converts all DB string values to appropriate ExperimentSegment values based on
 // registered SegmenterType to be used when returning API response
 func (s ExperimentSegment) ToApiSchema(segmentersType map[string]schema.SegmenterType) schema.ExperimentSegment {
@@ -100,7 +158,9 @@ func (s ExperimentSegment) ToProtoSchema(segmenterTypes map[string]schema.Segmen
 	return protoSegments
 }
 
-// ToStorageSchema converts raw request ExperimentSegment values to string values for storing in DB
+// ToStorageSchema converts raw request ExperimentSegment values to string values for storing in DB. The values of each
+// segmenter are sorted in their natural order, so that segments that only differ in the order of their values are stored
+// identically.
 func (s ExperimentSegmentRaw) ToStorageSchema(segmenterTypes map[string]schema.SegmenterType) (ExperimentSegment, error) {
 	segmenterVals := ExperimentSegment{}
 	for k, v := range s {
@@ -116,35 +176,52 @@ func (s ExperimentSegmentRaw) ToStorageSchema(segmenterTypes map[string]schema.S
 				}
 				strVals = append(strVals, stringVal)
 			}
+			sort.Strings(strVals)
 			segmenterVals[k] = strVals
 		case schema.SegmenterTypeInteger:
-			strVals := []string{}
+			intVals := []int{}
 			for _, val := range vals {
 				floatVal, ok := val.(float64)
 				if !ok {
 					return nil, fmt.Errorf("%s %s", errTmpl, schema.SegmenterTypeInteger)
 				}
-				strVals = append(strVals, strconv.Itoa(int(floatVal)))
+				intVals = append(intVals, int(floatVal))
+			}
+			sort.Ints(intVals)
+			strVals := []string{}
+			for _, intVal := range intVals {
+				strVals = append(strVals, strconv.Itoa(intVal))
 			}
 			segmenterVals[k] = strVals
 		case schema.SegmenterTypeReal:
-			strVals := []string{}
+			floatVals := []float64{}
 			for _, val := range vals {
 				floatVal, ok := val.(float64)
 				if !ok {
 					return nil, fmt.Errorf("%s %s", errTmpl, schema.SegmenterTypeReal)
 				}
+				floatVals = append(floatVals, floatVal)
+			}
+			sort.Float64s(floatVals)
+			strVals := []string{}
+			for _, floatVal := range floatVals {
 				strVals = append(strVals, strconv.FormatFloat(floatVal, 'f', -1, 64))
 			}
 			segmenterVals[k] = strVals
 		case schema.SegmenterTypeBool:
-			strVals := []string{}
+			boolVals := []bool{}
 			for _, val := range vals {
 				boolValue, ok := val.(bool)
 				if !ok {
 					return nil, fmt.Errorf("%s %s", errTmpl, schema.SegmenterTypeBool)
 				}
-				strVals = append(strVals, strconv.FormatBool(boolValue))
+				boolVals = append(boolVals, boolValue)
+			}
+			// Sort false before true
+			sort.Slice(boolVals, func(i, j int) bool { return !boolVals[i] && boolVals[j] })
+			strVals := []string{}
+			for _, boolVal := range boolVals {
+				strVals = append(strVals, strconv.FormatBool(boolVal))
 			}
 			segmenterVals[k] = strVals
 		}
@@ -78,6 +78,62 @@ func TestSegmentScan(t *testing.T) {
 	}
 }
 
+func TestSegmentRawValue(t *testing.T) {
+	value, err := ExperimentSegmentRaw{
+		"integer_segmenter": []interface{}{float64(1), float64(2)},
+		"bool_segmenter":    []interface{}{false},
+	}.Value()
+	require.NoError(t, err)
+	byteValue, ok := value.([]byte)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"integer_segmenter": [1, 2], "bool_segmenter": [false]}`, string(byteValue))
+
+	// An unset segment is stored as NULL
+	value, err = ExperimentSegmentRaw(nil).Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
+
+func TestSegmentRawScan(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		errString string
+		expected  ExperimentSegmentRaw
+	}{
+		{
+			name:  "success",
+			value: []byte(`{"integer_segmenter": [1, 2], "bool_segmenter": [false]}`),
+			expected: ExperimentSegmentRaw{
+				"integer_segmenter": []interface{}{float64(1), float64(2)},
+				"bool_segmenter":    []interface{}{false},
+			},
+		},
+		{
+			name:  "success | null",
+			value: nil,
+		},
+		{
+			name:      "failure | invalid value",
+			value:     100,
+			errString: "type assertion to []byte failed",
+		},
+	}
+
+	for _, data := range tests {
+		t.Run(data.name, func(t *testing.T) {
+			var segment ExperimentSegmentRaw
+			err := segment.Scan(data.value)
+			if data.errString == "" {
+				require.NoError(t, err)
+				assert.Equal(t, data.expected, segment)
+			} else {
+				assert.EqualError(t, err, data.errString)
+			}
+		})
+	}
+}
+
 func TestSegmentToApiSchema(t *testing.T) {
 	segmenterTypes := map[string]schema.SegmenterType{
 		"integer_segmenter": schema.SegmenterTypeInteger,
@@ -188,6 +244,22 @@ func TestSegmentToStorageSchema(t *testing.T) {
 			segmentersType: segmentersType,
 			expected:       experimentBoolSegment,
 		},
+		{
+			name: "success | values sorted",
+			segment: ExperimentSegmentRaw{
+				"integer_segmenter": []interface{}{float64(10), float64(2), float64(-1)},
+				"float_segmenter":   []interface{}{float64(2.5), float64(10), float64(0.1)},
+				"string_segmenter":  []interface{}{"B", "a", "A"},
+				"bool_segmenter":    []interface{}{true, false},
+			},
+			segmentersType: segmentersType,
+			expected: ExperimentSegment{
+				"integer_segmenter": []string{"-1", "2", "10"},
+				"float_segmenter":   []string{"0.1", "2.5", "10"},
+				"string_segmenter":  []string{"A", "B", "a"},
+				"bool_segmenter":    []string{"false", "true"},
+			},
+		},
 	}
 
 	// Run tests
@@ -205,6 +277,32 @@ func TestSegmentToStorageSchema(t *testing.T) {
 	}
 }
 
+func TestSegmentToStorageSchemaStoredValue(t *testing.T) {
+	segmentersType := map[string]schema.SegmenterType{
+		"integer_segmenter": schema.SegmenterTypeInteger,
+		"string_segmenter":  schema.SegmenterTypeString,
+	}
+	segment1 := ExperimentSegmentRaw{
+		"integer_segmenter": []interface{}{float64(1), float64(2)},
+		"string_segmenter":  []interface{}{"A", "B"},
+	}
+	segment2 := ExperimentSegmentRaw{
+		"string_segmenter":  []interface{}{"B", "A"},
+		"integer_segmenter": []interface{}{float64(2), float64(1)},
+	}
+
+	storageSegment1, err := segment1.ToStorageSchema(segmentersType)
+	require.NoError(t, err)
+	storageSegment2, err := segment2.ToStorageSchema(segmentersType)
+	require.NoError(t, err)
+	value1, err := storageSegment1.Value()
+	require.NoError(t, err)
+	value2, err := storageSegment2.Value()
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"integer_segmenter":["1","2"],"string_segmenter":["A","B"]}`), value1)
+	assert.Equal(t, value1, value2)
+}
+
 func TestSegmentToRawSchema(t *testing.T) {
 	segmentersType := map[string]schema.SegmenterType{
 		"integer_segmenter": schema.SegmenterTypeInteger,
@@ -290,3 +388,59 @@ func TestSegmentToRawSchema(t *testing.T) {
 		})
 	}
 }
+
+func TestSegmentWithDefaults(t *testing.T) {
+	defaults := ExperimentSegment{
+		"string_segmenter":  []string{"seg-1"},
+		"integer_segmenter": []string{"1", "2"},
+	}
+	tests := map[string]struct {
+		segment  ExperimentSegment
+		expected ExperimentSegment
+	}{
+		"unset segmenters": {
+			segment: ExperimentSegment{"string_segmenter": []string{}},
+			expected: ExperimentSegment{
+				"string_segmenter":  []string{"seg-1"},
+				"integer_segmenter": []string{"1", "2"},
+			},
+		},
+		"set segmenters": {
+			segment: ExperimentSegment{
+				"string_segmenter": []string{"seg-2"},
+				"bool_segmenter":   []string{"true"},
+			},
+			expected: ExperimentSegment{
+				"string_segmenter":  []string{"seg-2"},
+				"integer_segmenter": []string{"1", "2"},
+				"bool_segmenter":    []string{"true"},
+			},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, data.expected, data.segment.WithDefaults(defaults))
+		})
+	}
+}
+
+func TestSegmentRawWithDefaults(t *testing.T) {
+	defaults := ExperimentSegmentRaw{
+		"string_segmenter":  []interface{}{"seg-1"},
+		"integer_segmenter": []interface{}{float64(1)},
+	}
+	segment := ExperimentSegmentRaw{
+		"string_segmenter":  []interface{}{},
+		"integer_segmenter": []interface{}{float64(2)},
+		"bool_segmenter":    []interface{}{true},
+	}
+
+	assert.Equal(t, ExperimentSegmentRaw{
+		"string_segmenter":  []interface{}{"seg-1"},
+		"integer_segmenter": []interface{}{float64(2)},
+		"bool_segmenter":    []interface{}{true},
+	}, segment.WithDefaults(defaults))
+	// The original segment is left unchanged
+	assert.Equal(t, []interface{}{}, segment["string_segmenter"])
+}
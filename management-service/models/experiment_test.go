@@ -14,6 +14,7 @@ import (
 	_utils "github.com/caraml-dev/xp/common/utils"
 )
 
+var testNow = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
 var testExperimentInterval int32 = 100
 var testExperimentTraffic int32 = 20
 var testExperimentDescription = "desc"
@@ -67,6 +68,8 @@ func TestExperimentToApiSchema(t *testing.T) {
 	experimentType := schema.ExperimentTypeSwitchback
 	tier := schema.ExperimentTierDefault
 	version := int64(2)
+	shadow := false
+	priority := int32(0)
 
 	assert.Equal(t, schema.Experiment{
 		Id:             &id,
@@ -96,8 +99,10 @@ func TestExperimentToApiSchema(t *testing.T) {
 		Segment: &schema.ExperimentSegment{
 			"string_segmenter": []string{"seg-1"},
 		},
-		Version: &version,
-	}, testExperiment.ToApiSchema(segmenterTypes))
+		Version:  &version,
+		Shadow:   &shadow,
+		Priority: &priority,
+	}, testExperiment.ToApiSchema(segmenterTypes, testNow))
 }
 
 func TestExperimentToApiSchemaWithFields(t *testing.T) {
@@ -144,7 +149,18 @@ func TestExperimentToApiSchemaWithFields(t *testing.T) {
 				Traffic: &testExperimentTraffic,
 			},
 		},
-	}, testExperiment.ToApiSchema(segmenterTypes, fields...))
+	}, testExperiment.ToApiSchema(segmenterTypes, testNow, fields...))
+}
+
+func TestExperimentToApiSchemaWithAllFields(t *testing.T) {
+	segmenterTypes := map[string]schema.SegmenterType{
+		"string_segmenter": schema.SegmenterTypeString,
+	}
+
+	assert.Equal(t,
+		testExperiment.ToApiSchema(segmenterTypes, testNow),
+		testExperiment.ToApiSchema(segmenterTypes, testNow, ExperimentFieldAll),
+	)
 }
 
 func TestExperimentToApiSchemaStatusFriendly(t *testing.T) {
@@ -178,11 +194,29 @@ func TestExperimentToApiSchemaStatusFriendly(t *testing.T) {
 			status:    ExperimentStatusActive,
 			expected:  schema.ExperimentStatusFriendlyCompleted,
 		},
+		"running at the start time": {
+			startTime: testNow,
+			endTime:   time.Date(3000, 1, 1, 2, 3, 4, 0, time.UTC),
+			status:    ExperimentStatusActive,
+			expected:  schema.ExperimentStatusFriendlyRunning,
+		},
+		"running at the end time": {
+			startTime: time.Date(2000, 1, 1, 2, 3, 4, 0, time.UTC),
+			endTime:   testNow,
+			status:    ExperimentStatusActive,
+			expected:  schema.ExperimentStatusFriendlyRunning,
+		},
+		"draft": {
+			startTime: time.Date(2000, 1, 1, 2, 3, 4, 0, time.UTC),
+			endTime:   time.Date(3000, 1, 1, 2, 3, 4, 0, time.UTC),
+			status:    ExperimentStatusDraft,
+			expected:  schema.ExperimentStatusFriendlyDraft,
+		},
 	}
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			assert.Equal(t, tt.expected, getExperimentStatusFriendly(tt.startTime, tt.endTime, tt.status))
+			assert.Equal(t, tt.expected, getExperimentStatusFriendly(tt.startTime, tt.endTime, tt.status, testNow))
 		})
 	}
 }
@@ -216,3 +250,132 @@ func TestExperimentToProtoSchema(t *testing.T) {
 		Version: 2,
 	}, protoRecord)
 }
+
+func TestExperimentNumberOfSwitchbackPeriods(t *testing.T) {
+	startTime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	newInterval := func(interval int32) *int32 {
+		return &interval
+	}
+	tests := map[string]struct {
+		interval *int32
+		endTime  time.Time
+		expected int64
+	}{
+		"exact multiple": {
+			interval: newInterval(30),
+			endTime:  startTime.Add(2 * time.Hour),
+			expected: 4,
+		},
+		"partial period": {
+			interval: newInterval(45),
+			endTime:  startTime.Add(2 * time.Hour),
+			expected: 2,
+		},
+		"window shorter than interval": {
+			interval: newInterval(60),
+			endTime:  startTime.Add(59 * time.Minute),
+			expected: 0,
+		},
+		"daily interval": {
+			interval: newInterval(24 * 60),
+			endTime:  startTime.Add(7 * 24 * time.Hour),
+			expected: 7,
+		},
+		"interval not set": {
+			endTime:  startTime.Add(time.Hour),
+			expected: 0,
+		},
+		"interval not positive": {
+			interval: newInterval(0),
+			endTime:  startTime.Add(time.Hour),
+			expected: 0,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			experiment := Experiment{Interval: data.interval, StartTime: startTime, EndTime: data.endTime}
+			assert.Equal(t, data.expected, experiment.NumberOfSwitchbackPeriods())
+		})
+	}
+}
+
+func TestExperimentIntervalOrZero(t *testing.T) {
+	// A/B experiments do not have an interval
+	experiment := Experiment{Type: ExperimentTypeAB}
+	assert.Equal(t, int32(0), experiment.IntervalOrZero())
+	assert.Equal(t, int64(0), experiment.NumberOfSwitchbackPeriods())
+	protoExperiment, err := experiment.ToProtoSchema(map[string]schema.SegmenterType{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), protoExperiment.Interval)
+
+	experiment = Experiment{Type: ExperimentTypeSwitchback, Interval: &testExperimentInterval}
+	assert.Equal(t, testExperimentInterval, experiment.IntervalOrZero())
+}
+
+func TestExperimentValidate(t *testing.T) {
+	startTime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	validExperiment := Experiment{
+		Name:      "test-exp",
+		Type:      ExperimentTypeAB,
+		Tier:      ExperimentTierDefault,
+		Status:    ExperimentStatusActive,
+		StartTime: startTime,
+		EndTime:   startTime.Add(time.Hour),
+	}
+	tests := map[string]struct {
+		update func(experiment *Experiment)
+		err    string
+	}{
+		"success": {
+			update: func(experiment *Experiment) {},
+		},
+		"failure | empty name": {
+			update: func(experiment *Experiment) { experiment.Name = " " },
+			err:    "experiment name must not be empty",
+		},
+		"failure | unknown type": {
+			update: func(experiment *Experiment) { experiment.Type = "Multi-Armed Bandit" },
+			err:    "unknown experiment type: Multi-Armed Bandit",
+		},
+		"failure | unknown tier": {
+			update: func(experiment *Experiment) { experiment.Tier = "" },
+			err:    "unknown experiment tier: ",
+		},
+		"failure | unknown status": {
+			update: func(experiment *Experiment) { experiment.Status = "paused" },
+			err:    "unknown experiment status: paused",
+		},
+		"failure | end time before start time": {
+			update: func(experiment *Experiment) { experiment.EndTime = startTime.Add(-time.Hour) },
+			err:    "experiment end time must be after the start time",
+		},
+		"failure | end time equal to start time": {
+			update: func(experiment *Experiment) { experiment.EndTime = startTime },
+			err:    "experiment end time must be after the start time",
+		},
+		"success | switchback with interval": {
+			update: func(experiment *Experiment) {
+				experiment.Type = ExperimentTypeSwitchback
+				experiment.Interval = &testExperimentInterval
+			},
+		},
+		"failure | switchback without interval": {
+			update: func(experiment *Experiment) { experiment.Type = ExperimentTypeSwitchback },
+			err:    "switchback experiment must have a positive interval",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			experiment := validExperiment
+			data.update(&experiment)
+			err := experiment.Validate()
+			if data.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, data.err)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"reflect"
 
 	"google.golang.org/protobuf/types/known/structpb"
 
@@ -19,6 +20,21 @@ type ExperimentTreatment struct {
 	Traffic       *int32                 `json:"traffic,omitempty"`
 }
 
+// ExperimentTreatmentsDiff captures the names of the treatments that differ between two sets of treatments
+type ExperimentTreatmentsDiff struct {
+	// Added holds the treatments that are only in the new set
+	Added []string `json:"added"`
+	// Removed holds the treatments that are only in the old set
+	Removed []string `json:"removed"`
+	// Modified holds the treatments in both sets whose configuration or traffic changed
+	Modified []string `json:"modified"`
+}
+
+// IsEmpty returns true if the two sets of treatments are the same
+func (d ExperimentTreatmentsDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
 func (t *ExperimentTreatments) Scan(value interface{}) error {
 	b, ok := value.([]byte)
 	if !ok {
@@ -67,3 +83,37 @@ func (t ExperimentTreatments) ToProtoSchema() ([]*_pubsub.ExperimentTreatment, e
 	}
 	return protoTreatments, nil
 }
+
+// Diff compares the treatments with the new treatments, by their names. The order of the treatments is disregarded.
+// The added and modified treatments are listed in the order of the new treatments, and the removed treatments in the
+// order of the old treatments.
+func (t ExperimentTreatments) Diff(newTreatments ExperimentTreatments) ExperimentTreatmentsDiff {
+	diff := ExperimentTreatmentsDiff{Added: []string{}, Removed: []string{}, Modified: []string{}}
+	oldTreatments := map[string]ExperimentTreatment{}
+	for _, treatment := range t {
+		oldTreatments[treatment.Name] = treatment
+	}
+	newTreatmentNames := map[string]bool{}
+	for _, treatment := range newTreatments {
+		newTreatmentNames[treatment.Name] = true
+		oldTreatment, ok := oldTreatments[treatment.Name]
+		if !ok {
+			diff.Added = append(diff.Added, treatment.Name)
+		} else if !isSameTreatment(oldTreatment, treatment) {
+			diff.Modified = append(diff.Modified, treatment.Name)
+		}
+	}
+	for _, treatment := range t {
+		if !newTreatmentNames[treatment.Name] {
+			diff.Removed = append(diff.Removed, treatment.Name)
+		}
+	}
+	return diff
+}
+
+func isSameTreatment(a ExperimentTreatment, b ExperimentTreatment) bool {
+	if (a.Traffic == nil) != (b.Traffic == nil) || (a.Traffic != nil && *a.Traffic != *b.Traffic) {
+		return false
+	}
+	return reflect.DeepEqual(a.Configuration, b.Configuration)
+}
@@ -157,3 +157,55 @@ func TestTreatmentsToProtoSchema(t *testing.T) {
 		}
 	]`, string(jsonData))
 }
+
+func TestTreatmentsDiff(t *testing.T) {
+	var traffic50, traffic70 int32 = 50, 70
+	oldTreatments := ExperimentTreatments{
+		{Name: "control", Configuration: map[string]interface{}{"key": "value"}, Traffic: &traffic50},
+		{Name: "treatment-1", Configuration: map[string]interface{}{"key": "value-1"}, Traffic: &traffic50},
+		{Name: "treatment-2", Configuration: map[string]interface{}{"key": "value-2"}},
+	}
+
+	tests := map[string]struct {
+		newTreatments ExperimentTreatments
+		expected      ExperimentTreatmentsDiff
+	}{
+		"no change": {
+			// The order of the treatments is disregarded
+			newTreatments: ExperimentTreatments{oldTreatments[2], oldTreatments[0], oldTreatments[1]},
+			expected:      ExperimentTreatmentsDiff{Added: []string{}, Removed: []string{}, Modified: []string{}},
+		},
+		"added and removed": {
+			newTreatments: ExperimentTreatments{
+				oldTreatments[0],
+				{Name: "treatment-3", Configuration: map[string]interface{}{"key": "value-3"}},
+				oldTreatments[1],
+			},
+			expected: ExperimentTreatmentsDiff{
+				Added:    []string{"treatment-3"},
+				Removed:  []string{"treatment-2"},
+				Modified: []string{},
+			},
+		},
+		"modified": {
+			newTreatments: ExperimentTreatments{
+				{Name: "control", Configuration: map[string]interface{}{"key": "value"}, Traffic: &traffic70},
+				{Name: "treatment-1", Configuration: map[string]interface{}{"key": "new-value"}, Traffic: &traffic50},
+				{Name: "treatment-2", Configuration: map[string]interface{}{"key": "value-2"}, Traffic: &traffic50},
+			},
+			expected: ExperimentTreatmentsDiff{
+				Added:    []string{},
+				Removed:  []string{},
+				Modified: []string{"control", "treatment-1", "treatment-2"},
+			},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			diff := oldTreatments.Diff(data.newTreatments)
+			assert.Equal(t, data.expected, diff)
+			assert.Equal(t, name == "no change", diff.IsEmpty())
+		})
+	}
+}
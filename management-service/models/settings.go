@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -24,6 +25,84 @@ type ExperimentationConfig struct {
 	// S2IDClusteringEnabled determines whether S2ID cluster ID should be used
 	// as the randomization key, for randomized switchback experiments
 	S2IDClusteringEnabled bool `json:"enable_s2id_clustering"`
+	// TreatmentConfigDefaults is an optional JSON object that is deep-merged into the configuration of each
+	// treatment when the experiment is saved, with the values of the treatment configuration taking precedence
+	TreatmentConfigDefaults json.RawMessage `json:"treatment_config_defaults,omitempty"`
+	// OrthogonalityExemptSegmenters is a list of names of segmenters that are ignored when checking the
+	// orthogonality of experiment segments
+	OrthogonalityExemptSegmenters []string `json:"orthogonality_exempt_segmenters,omitempty"`
+	// EnabledValidators is a list of names of custom validators, registered with the experiment service, that are
+	// run on the project's experiments
+	EnabledValidators []string `json:"enabled_validators,omitempty"`
+	// DefaultSegment holds the segmenter values that apply to the project's experiments, for the segmenters that
+	// are not set by the experiments themselves
+	DefaultSegment ExperimentSegmentRaw `json:"default_segment,omitempty"`
+	// ExperimentsLocked blocks all mutations of the project's experiments, e.g., during a production freeze
+	ExperimentsLocked bool `json:"experiments_locked,omitempty"`
+	// BlockOrphanedOverrides blocks disabling a default tier experiment when it would leave an active override tier
+	// experiment without an underlying default tier experiment, as opposed to only logging a warning
+	BlockOrphanedOverrides bool `json:"block_orphaned_overrides,omitempty"`
+	// ValidationUrlRateLimit optionally limits the rate of the requests to the project's validation URL
+	ValidationUrlRateLimit *ValidationUrlRateLimit `json:"validation_url_rate_limit,omitempty"`
+	// OrthogonalityLookahead extends the end of the time window in which the other experiments are checked for
+	// orthogonality, so that the conflicts with the experiments scheduled to start soon after are also reported.
+	// This only widens the set of experiments that are checked: the conflicts with the experiments that do not
+	// overlap the actual time window are logged as warnings and do not fail the check.
+	OrthogonalityLookahead time.Duration `json:"orthogonality_lookahead,omitempty"`
+	// RequiredSegmenterCombinations is a list of groups of names of segmenters that must be set together, i.e., if an
+	// experiment sets any segmenter of a group, it must set all the segmenters of the group
+	RequiredSegmenterCombinations [][]string `json:"required_segmenter_combinations,omitempty"`
+	// TypedSegmentStorage stores the segments of the project's experiments as typed JSON values as well, and matches the
+	// segment filters against the typed values, so that, e.g., an integer value 5 is not matched against the string
+	// "5". The experiments that were last created or updated before this was enabled are matched on their string values.
+	TypedSegmentStorage bool `json:"typed_segment_storage,omitempty"`
+	// TreatmentNamePattern is a regular expression that the names of the treatments of the project's experiments must
+	// match, as the names are used as map keys and URL path segments downstream. If empty, the names are only checked
+	// against the rules that apply to all names.
+	TreatmentNamePattern string `json:"treatment_name_pattern,omitempty"`
+	// SoftMaxActiveExperiments is the number of the project's running experiments beyond which enabling or creating an
+	// active experiment is allowed with a warning. If 0, there is no soft maximum.
+	SoftMaxActiveExperiments int `json:"soft_max_active_experiments,omitempty"`
+	// HardMaxActiveExperiments is the number of the project's running experiments beyond which enabling or creating an
+	// active experiment is blocked. If 0, there is no hard maximum.
+	HardMaxActiveExperiments int `json:"hard_max_active_experiments,omitempty"`
+	// PriorityOverlapResolution allows the project's experiments of the same tier to overlap, in which case the one
+	// with the higher priority, or the one created first if the priorities are equal, takes effect. The orthogonality
+	// of the experiments is then only advisory, and the conflicts are logged as warnings instead of failing the check.
+	PriorityOverlapResolution bool `json:"priority_overlap_resolution,omitempty"`
+	// BlockDuplicateTreatmentConfigs blocks the experiments with two or more treatments of identical configurations,
+	// as opposed to only logging a warning
+	BlockDuplicateTreatmentConfigs bool `json:"block_duplicate_treatment_configs,omitempty"`
+	// BlockExhaustiveSegmentValues blocks the experiments whose segments list as many values of a segmenter as the
+	// segmenter allows, or more, as opposed to only logging a warning
+	BlockExhaustiveSegmentValues bool `json:"block_exhaustive_segment_values,omitempty"`
+	// DefaultTier is the tier of the experiments that are created without one. If empty, the tier must be given.
+	DefaultTier ExperimentTier `json:"default_tier,omitempty"`
+	// DefaultType is the type of the experiments that are created without one. If empty, the type must be given.
+	DefaultType ExperimentType `json:"default_type,omitempty"`
+	// MaxTreatmentConfigBytes is the maximum size of the configuration of each treatment, marshaled as JSON, as the
+	// configurations are stored with the experiments and published in their messages. If 0, there is no maximum.
+	MaxTreatmentConfigBytes int `json:"max_treatment_config_bytes,omitempty"`
+}
+
+// ValidationUrlRateLimit configures a token bucket rate limiter on the requests to a project's validation URL
+type ValidationUrlRateLimit struct {
+	// RequestsPerSecond is the rate at which the bucket is refilled
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	// Burst is the capacity of the bucket, i.e., the number of requests that may be sent at once
+	Burst int `json:"burst"`
+	// MaxWaitMillis is the maximum time for which a request beyond the rate is queued, after which it is rejected.
+	// If 0, requests beyond the rate are rejected immediately.
+	MaxWaitMillis int `json:"max_wait_millis"`
+}
+
+func (l *ValidationUrlRateLimit) ToApiSchema() *schema.ValidationUrlRateLimit {
+	maxWaitMillis := l.MaxWaitMillis
+	return &schema.ValidationUrlRateLimit{
+		RequestsPerSecond: l.RequestsPerSecond,
+		Burst:             l.Burst,
+		MaxWaitMillis:     &maxWaitMillis,
+	}
 }
 
 type Rule struct {
@@ -112,6 +191,58 @@ func (c *Settings) ToApiSchema() schema.ProjectSettings {
 		Username:        c.Username,
 		TreatmentSchema: c.TreatmentSchema.ToOpenApi(),
 		ValidationUrl:   c.ValidationUrl,
+
+		PriorityOverlapResolution:      &c.Config.PriorityOverlapResolution,
+		BlockDuplicateTreatmentConfigs: &c.Config.BlockDuplicateTreatmentConfigs,
+		BlockExhaustiveSegmentValues:   &c.Config.BlockExhaustiveSegmentValues,
+		TypedSegmentStorage:            &c.Config.TypedSegmentStorage,
+		BlockOrphanedOverrides:         &c.Config.BlockOrphanedOverrides,
+	}
+	if len(c.Config.TreatmentConfigDefaults) > 0 {
+		var configDefaults map[string]interface{}
+		if err := json.Unmarshal(c.Config.TreatmentConfigDefaults, &configDefaults); err == nil {
+			user.TreatmentConfigDefaults = &configDefaults
+		}
+	}
+	if len(c.Config.OrthogonalityExemptSegmenters) > 0 {
+		user.OrthogonalityExemptSegmenters = &c.Config.OrthogonalityExemptSegmenters
+	}
+	if len(c.Config.EnabledValidators) > 0 {
+		user.EnabledValidators = &c.Config.EnabledValidators
+	}
+	if len(c.Config.DefaultSegment) > 0 {
+		defaultSegment := schema.ExperimentSegment(c.Config.DefaultSegment)
+		user.DefaultSegment = &defaultSegment
+	}
+	if c.Config.ValidationUrlRateLimit != nil {
+		user.ValidationUrlRateLimit = c.Config.ValidationUrlRateLimit.ToApiSchema()
+	}
+	if c.Config.OrthogonalityLookahead > 0 {
+		lookaheadSeconds := int(c.Config.OrthogonalityLookahead / time.Second)
+		user.OrthogonalityLookaheadSeconds = &lookaheadSeconds
+	}
+	if len(c.Config.RequiredSegmenterCombinations) > 0 {
+		user.RequiredSegmenterCombinations = &c.Config.RequiredSegmenterCombinations
+	}
+	if c.Config.TreatmentNamePattern != "" {
+		user.TreatmentNamePattern = &c.Config.TreatmentNamePattern
+	}
+	if c.Config.SoftMaxActiveExperiments > 0 {
+		user.SoftMaxActiveExperiments = &c.Config.SoftMaxActiveExperiments
+	}
+	if c.Config.HardMaxActiveExperiments > 0 {
+		user.HardMaxActiveExperiments = &c.Config.HardMaxActiveExperiments
+	}
+	if c.Config.DefaultTier != "" {
+		defaultTier := schema.ExperimentTier(c.Config.DefaultTier)
+		user.DefaultTier = &defaultTier
+	}
+	if c.Config.DefaultType != "" {
+		defaultType := schema.ExperimentType(c.Config.DefaultType)
+		user.DefaultType = &defaultType
+	}
+	if c.Config.MaxTreatmentConfigBytes > 0 {
+		user.MaxTreatmentConfigBytes = &c.Config.MaxTreatmentConfigBytes
 	}
 
 	return user
@@ -141,5 +272,7 @@ func (c *Settings) ToProtoSchema() _pubsub.ProjectSettings {
 		EnableS2IdClustering: c.Config.S2IDClusteringEnabled,
 		Segmenters:           &projectSegmenters,
 		RandomizationKey:     c.Config.RandomizationKey,
+
+		PriorityOverlapResolution: c.Config.PriorityOverlapResolution,
 	}
 }
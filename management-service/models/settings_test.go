@@ -94,6 +94,13 @@ func TestExperimentationConfigScan(t *testing.T) {
 }
 
 func TestSettingsToApiSchema(t *testing.T) {
+	priorityOverlapResolution := false
+	blockDuplicateTreatmentConfigs := false
+	blockExhaustiveSegmentValues := false
+	typedSegmentStorage := false
+	blockOrphanedOverrides := false
+	maxWaitMillis := 100
+	orthogonalityLookaheadSeconds := 3600
 	tests := []struct {
 		Name     string
 		Settings Settings
@@ -134,8 +141,13 @@ func TestSettingsToApiSchema(t *testing.T) {
 							"seg4": {"exp_var_4"},
 						}},
 				},
-				RandomizationKey:     "rand",
-				EnableS2idClustering: false,
+				RandomizationKey:               "rand",
+				EnableS2idClustering:           false,
+				PriorityOverlapResolution:      &priorityOverlapResolution,
+				BlockDuplicateTreatmentConfigs: &blockDuplicateTreatmentConfigs,
+				BlockExhaustiveSegmentValues:   &blockExhaustiveSegmentValues,
+				TypedSegmentStorage:            &typedSegmentStorage,
+				BlockOrphanedOverrides:         &blockOrphanedOverrides,
 			},
 		},
 		{
@@ -174,8 +186,13 @@ func TestSettingsToApiSchema(t *testing.T) {
 							"seg6": {"exp_var_6"},
 						}},
 				},
-				RandomizationKey:     "rand-2",
-				EnableS2idClustering: true,
+				RandomizationKey:               "rand-2",
+				EnableS2idClustering:           true,
+				PriorityOverlapResolution:      &priorityOverlapResolution,
+				BlockDuplicateTreatmentConfigs: &blockDuplicateTreatmentConfigs,
+				BlockExhaustiveSegmentValues:   &blockExhaustiveSegmentValues,
+				TypedSegmentStorage:            &typedSegmentStorage,
+				BlockOrphanedOverrides:         &blockOrphanedOverrides,
 			},
 		},
 		{
@@ -198,6 +215,12 @@ func TestSettingsToApiSchema(t *testing.T) {
 					},
 					RandomizationKey:      "rand-3",
 					S2IDClusteringEnabled: false,
+					ValidationUrlRateLimit: &ValidationUrlRateLimit{
+						RequestsPerSecond: 2.5,
+						Burst:             5,
+						MaxWaitMillis:     100,
+					},
+					OrthogonalityLookahead: time.Hour,
 				},
 				TreatmentSchema: &TreatmentSchema{
 					Rules: []Rule{
@@ -239,9 +262,20 @@ func TestSettingsToApiSchema(t *testing.T) {
 						},
 					},
 				},
-				ValidationUrl:        nil,
-				RandomizationKey:     "rand-3",
-				EnableS2idClustering: false,
+				ValidationUrl:                  nil,
+				RandomizationKey:               "rand-3",
+				EnableS2idClustering:           false,
+				PriorityOverlapResolution:      &priorityOverlapResolution,
+				BlockDuplicateTreatmentConfigs: &blockDuplicateTreatmentConfigs,
+				BlockExhaustiveSegmentValues:   &blockExhaustiveSegmentValues,
+				TypedSegmentStorage:            &typedSegmentStorage,
+				BlockOrphanedOverrides:         &blockOrphanedOverrides,
+				ValidationUrlRateLimit: &schema.ValidationUrlRateLimit{
+					RequestsPerSecond: 2.5,
+					Burst:             5,
+					MaxWaitMillis:     &maxWaitMillis,
+				},
+				OrthogonalityLookaheadSeconds: &orthogonalityLookaheadSeconds,
 			},
 		},
 	}
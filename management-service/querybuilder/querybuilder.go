@@ -0,0 +1,202 @@
+// Package querybuilder builds the predicates on the experiments table, that select the experiments by their segments and
+// time windows, as expressions that can be composed into the queries of any service.
+package querybuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/caraml-dev/xp/common/api/schema"
+)
+
+// SegmentStorage describes how the segments that the segment predicates are matched against are stored. The segmenter
+// types are only set for the projects that store typed segments.
+type SegmentStorage struct {
+	SegmenterTypes map[string]schema.SegmenterType
+}
+
+// IsTyped returns true if the segments are matched against their typed JSON values
+func (s SegmentStorage) IsTyped() bool {
+	return s.SegmenterTypes != nil
+}
+
+// Column returns the name of the column of the segments
+func (s SegmentStorage) Column() string {
+	if s.IsTyped() {
+		return "typed_segment"
+	}
+	return "segment"
+}
+
+// FormatValue formats the segmenter value, given in the string storage schema, as a JSON literal of the stored type
+func (s SegmentStorage) FormatValue(name string, value string) string {
+	if s.IsTyped() {
+		switch s.SegmenterTypes[name] {
+		case schema.SegmenterTypeInteger, schema.SegmenterTypeReal, schema.SegmenterTypeBool:
+			// The values in the string storage schema are already formatted as JSON numbers and booleans
+			if json.Valid([]byte(value)) {
+				return value
+			}
+		}
+	}
+	return formatJsonString(value)
+}
+
+// predicate builds the predicate, and its bind variables, with the given function. For typed storage, the experiments
+// that have no typed segment, as they were saved before the typed segments were stored, are matched on their string
+// values instead.
+func (s SegmentStorage) predicate(
+	build func(storage SegmentStorage) (string, []interface{}),
+) (string, []interface{}) {
+	if !s.IsTyped() {
+		return build(s)
+	}
+	untypedPredicate, untypedVars := build(SegmentStorage{})
+	typedPredicate, typedVars := build(s)
+	return fmt.Sprintf("(CASE WHEN typed_segment IS NULL THEN %s ELSE %s END)", untypedPredicate, typedPredicate),
+		append(untypedVars, typedVars...)
+}
+
+// SegmenterAnyOf matches the experiments whose segment contains any of the given values of the segmenter, and, if
+// includeWeakMatch is set, the experiments that do not constrain the segmenter. It returns nil if there are no values.
+func SegmenterAnyOf(name string, values []string, includeWeakMatch bool, storage SegmentStorage) clause.Expression {
+	if len(values) == 0 {
+		return nil
+	}
+	sql, vars := storage.predicate(func(storage SegmentStorage) (string, []interface{}) {
+		predicate, vars := segmenterAnyOfPredicate(name, values, storage)
+		// Include weak matches if the flag is set
+		if includeWeakMatch {
+			unconstrainedPredicate, unconstrainedVars := segmenterUnconstrainedPredicate(name, storage)
+			predicate = fmt.Sprintf("(%s OR %s)", predicate, unconstrainedPredicate)
+			vars = append(vars, unconstrainedVars...)
+		}
+		return predicate, vars
+	})
+	return clause.Expr{SQL: sql, Vars: vars}
+}
+
+// SegmenterNoneOf excludes the experiments whose segment contains any of the given values of the segmenter. The
+// experiments that do not set the segmenter, or set it as [], do not contain the values and are kept. It returns nil if
+// there are no values.
+func SegmenterNoneOf(name string, values []string, storage SegmentStorage) clause.Expression {
+	if len(values) == 0 {
+		return nil
+	}
+	sql, vars := storage.predicate(func(storage SegmentStorage) (string, []interface{}) {
+		return segmenterAnyOfPredicate(name, values, storage)
+	})
+	return clause.Expr{SQL: fmt.Sprintf("NOT (%s)", sql), Vars: vars}
+}
+
+// SegmenterUnconstrained matches the experiments that do not constrain the segmenter, i.e., the weak matches of any
+// values of the segmenter
+func SegmenterUnconstrained(name string, storage SegmentStorage) clause.Expression {
+	sql, vars := segmenterUnconstrainedPredicate(name, storage)
+	return clause.Expr{SQL: sql, Vars: vars}
+}
+
+// SegmentSharesAnyValue matches the experiments whose segment contains any of the given values of any of the given
+// segmenters. The segmenters without values are ignored, and nil is returned if there are none with values.
+func SegmentSharesAnyValue(segment map[string][]string, storage SegmentStorage) clause.Expression {
+	// Sort the segmenters so that the generated query is deterministic
+	segmenterNames := []string{}
+	for name, values := range segment {
+		if len(values) > 0 {
+			segmenterNames = append(segmenterNames, name)
+		}
+	}
+	sort.Strings(segmenterNames)
+	if len(segmenterNames) == 0 {
+		return nil
+	}
+	sql, vars := storage.predicate(func(storage SegmentStorage) (string, []interface{}) {
+		predicates := []string{}
+		vars := []interface{}{}
+		for _, name := range segmenterNames {
+			predicate, predicateVars := segmenterAnyOfPredicate(name, segment[name], storage)
+			predicates = append(predicates, predicate)
+			vars = append(vars, predicateVars...)
+		}
+		return fmt.Sprintf("(%s)", strings.Join(predicates, " OR ")), vars
+	})
+	return clause.Expr{SQL: sql, Vars: vars}
+}
+
+// SegmentEquals matches the experiments whose segment is exactly the given segment, in the string storage schema. The
+// order of the values and the segmenters that are unset or set as [] are disregarded, by canonicalizing both segments
+// in the query before they are compared as JSON.
+func SegmentEquals(segment map[string][]string) (clause.Expression, error) {
+	if segment == nil {
+		segment = map[string][]string{}
+	}
+	segmentJson, err := json.Marshal(segment)
+	if err != nil {
+		return nil, err
+	}
+	return clause.Expr{
+		SQL:  fmt.Sprintf("%s = %s", canonicalSegment("segment"), canonicalSegment("?::jsonb")),
+		Vars: []interface{}{string(segmentJson)},
+	}, nil
+}
+
+// TimeWindow matches the experiments that are at least partially running in the given window. If the start and end
+// times are equal, it matches the experiments running at that time, e.g., the current time.
+func TimeWindow(startTime time.Time, endTime time.Time) clause.Expression {
+	if startTime.Equal(endTime) {
+		return clause.Expr{
+			SQL:  "tstzrange(start_time, end_time, '[)') @> tstzrange(?, ?, '[]')",
+			Vars: []interface{}{startTime, endTime},
+		}
+	}
+	// One of the following should match:
+	// * the start time should fall within the experiment's [start and end) times
+	// * the end time should fall within the experiment's (start and end) times
+	// * the experiment starts and ends within the [start time and end time) duration
+	return clause.Expr{
+		SQL: "(tstzrange(start_time, end_time, '[)') @> tstzrange(?, ?, '[]') OR " +
+			"tstzrange(start_time, end_time, '()') @> tstzrange(?, ?, '[]') OR " +
+			"tstzrange(?, ?, '[]') @> tstzrange(start_time, end_time, '[)'))",
+		Vars: []interface{}{startTime, startTime, endTime, endTime, startTime, endTime},
+	}
+}
+
+// segmenterAnyOfPredicate builds the predicate matching any of the segmenter values. The segments to match are passed
+// as bind variables, so that the segmenter names and values are never interpolated into the query.
+func segmenterAnyOfPredicate(name string, values []string, storage SegmentStorage) (string, []interface{}) {
+	placeholders := []string{}
+	vars := []interface{}{}
+	for _, val := range values {
+		placeholders = append(placeholders, "?::jsonb")
+		vars = append(vars, fmt.Sprintf("{%s: [%s]}", formatJsonString(name), storage.FormatValue(name, val)))
+	}
+	return fmt.Sprintf("%s @> ANY (ARRAY [%s])", storage.Column(), strings.Join(placeholders, ",")), vars
+}
+
+func segmenterUnconstrainedPredicate(name string, storage SegmentStorage) (string, []interface{}) {
+	column := storage.Column()
+	return fmt.Sprintf("(%s OR %s)",
+		fmt.Sprintf("NOT jsonb_exists(%s, ?)", column), // The segment does not exist in the experiment
+		fmt.Sprintf("%s -> ? = '[]'", column),          // The segment is set as []
+	), []interface{}{name, name}
+}
+
+// formatJsonString formats the value as a JSON string literal, escaping any quotes and control characters
+func formatJsonString(value string) string {
+	// Marshalling a string never fails
+	formatted, _ := json.Marshal(value)
+	return string(formatted)
+}
+
+// canonicalSegment builds the canonical form of the given segment expression, where the values of each segmenter are
+// sorted and the segmenters without values are left out
+func canonicalSegment(segment string) string {
+	return fmt.Sprintf("(SELECT COALESCE(jsonb_object_agg(entries.key, "+
+		"(SELECT jsonb_agg(elements.value ORDER BY elements.value) FROM jsonb_array_elements(entries.value) AS elements)"+
+		"), '{}'::jsonb) FROM jsonb_each(%s) AS entries WHERE jsonb_array_length(entries.value) > 0)", segment)
+}
@@ -0,0 +1,143 @@
+package querybuilder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm/clause"
+
+	"github.com/caraml-dev/xp/common/api/schema"
+)
+
+var typedStorage = SegmentStorage{
+	SegmenterTypes: map[string]schema.SegmenterType{
+		"string_segmenter":  schema.SegmenterTypeString,
+		"integer_segmenter": schema.SegmenterTypeInteger,
+	},
+}
+
+func TestSegmentStorage(t *testing.T) {
+	assert.False(t, SegmentStorage{}.IsTyped())
+	assert.Equal(t, "segment", SegmentStorage{}.Column())
+	assert.Equal(t, "\"1\"", SegmentStorage{}.FormatValue("integer_segmenter", "1"))
+
+	assert.True(t, typedStorage.IsTyped())
+	assert.Equal(t, "typed_segment", typedStorage.Column())
+	assert.Equal(t, "1", typedStorage.FormatValue("integer_segmenter", "1"))
+	assert.Equal(t, "\"seg-1\"", typedStorage.FormatValue("string_segmenter", "seg-1"))
+	// The values are escaped, and the invalid typed values are formatted as strings
+	assert.Equal(t, `"seg\"]}'"`, SegmentStorage{}.FormatValue("string_segmenter", `seg"]}'`))
+	assert.Equal(t, `"1]"`, typedStorage.FormatValue("integer_segmenter", "1]"))
+}
+
+func TestSegmenterAnyOf(t *testing.T) {
+	tests := map[string]struct {
+		values           []string
+		includeWeakMatch bool
+		storage          SegmentStorage
+		expected         clause.Expression
+	}{
+		"no values": {
+			storage: SegmentStorage{},
+		},
+		"string storage": {
+			values:  []string{"1", "2"},
+			storage: SegmentStorage{},
+			expected: clause.Expr{
+				SQL:  "segment @> ANY (ARRAY [?::jsonb,?::jsonb])",
+				Vars: []interface{}{`{"integer_segmenter": ["1"]}`, `{"integer_segmenter": ["2"]}`},
+			},
+		},
+		"string storage | weak match": {
+			values:           []string{"1"},
+			includeWeakMatch: true,
+			storage:          SegmentStorage{},
+			expected: clause.Expr{
+				SQL: "(segment @> ANY (ARRAY [?::jsonb]) OR " +
+					"(NOT jsonb_exists(segment, ?) OR segment -> ? = '[]'))",
+				Vars: []interface{}{`{"integer_segmenter": ["1"]}`, "integer_segmenter", "integer_segmenter"},
+			},
+		},
+		"typed storage": {
+			values:  []string{"1"},
+			storage: typedStorage,
+			expected: clause.Expr{
+				SQL: "(CASE WHEN typed_segment IS NULL " +
+					"THEN segment @> ANY (ARRAY [?::jsonb]) " +
+					"ELSE typed_segment @> ANY (ARRAY [?::jsonb]) END)",
+				Vars: []interface{}{`{"integer_segmenter": ["1"]}`, `{"integer_segmenter": [1]}`},
+			},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			expr := SegmenterAnyOf("integer_segmenter", data.values, data.includeWeakMatch, data.storage)
+			assert.Equal(t, data.expected, expr)
+		})
+	}
+}
+
+func TestSegmenterNoneOf(t *testing.T) {
+	assert.Nil(t, SegmenterNoneOf("string_segmenter", []string{}, SegmentStorage{}))
+	assert.Equal(t, clause.Expr{
+		SQL:  "NOT (segment @> ANY (ARRAY [?::jsonb]))",
+		Vars: []interface{}{`{"string_segmenter": ["seg-1"]}`},
+	}, SegmenterNoneOf("string_segmenter", []string{"seg-1"}, SegmentStorage{}))
+}
+
+func TestSegmenterUnconstrained(t *testing.T) {
+	assert.Equal(t, clause.Expr{
+		SQL:  "(NOT jsonb_exists(typed_segment, ?) OR typed_segment -> ? = '[]')",
+		Vars: []interface{}{"string_segmenter", "string_segmenter"},
+	}, SegmenterUnconstrained("string_segmenter", typedStorage))
+}
+
+func TestSegmentSharesAnyValue(t *testing.T) {
+	assert.Nil(t, SegmentSharesAnyValue(map[string][]string{"string_segmenter": {}}, SegmentStorage{}))
+	assert.Equal(t, clause.Expr{
+		SQL:  "(segment @> ANY (ARRAY [?::jsonb]) OR segment @> ANY (ARRAY [?::jsonb]))",
+		Vars: []interface{}{`{"integer_segmenter": ["1"]}`, `{"string_segmenter": ["seg-1"]}`},
+	}, SegmentSharesAnyValue(map[string][]string{
+		"string_segmenter":  {"seg-1"},
+		"integer_segmenter": {"1"},
+		"bool_segmenter":    {},
+	}, SegmentStorage{}))
+}
+
+func TestSegmentEquals(t *testing.T) {
+	canonicalSegment := func(segment string) string {
+		return "(SELECT COALESCE(jsonb_object_agg(entries.key, " +
+			"(SELECT jsonb_agg(elements.value ORDER BY elements.value) FROM jsonb_array_elements(entries.value) AS elements)" +
+			"), '{}'::jsonb) FROM jsonb_each(" + segment + ") AS entries WHERE jsonb_array_length(entries.value) > 0)"
+	}
+
+	expr, err := SegmentEquals(map[string][]string{"string_segmenter": {"seg-2", "seg-1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, clause.Expr{
+		SQL:  canonicalSegment("segment") + " = " + canonicalSegment("?::jsonb"),
+		Vars: []interface{}{`{"string_segmenter":["seg-2","seg-1"]}`},
+	}, expr)
+
+	// An unset segment is compared as an empty one
+	expr, err = SegmentEquals(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"{}"}, expr.(clause.Expr).Vars)
+}
+
+func TestTimeWindow(t *testing.T) {
+	startTime := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(time.Hour)
+
+	assert.Equal(t, clause.Expr{
+		SQL:  "tstzrange(start_time, end_time, '[)') @> tstzrange(?, ?, '[]')",
+		Vars: []interface{}{startTime, startTime},
+	}, TimeWindow(startTime, startTime))
+	assert.Equal(t, clause.Expr{
+		SQL: "(tstzrange(start_time, end_time, '[)') @> tstzrange(?, ?, '[]') OR " +
+			"tstzrange(start_time, end_time, '()') @> tstzrange(?, ?, '[]') OR " +
+			"tstzrange(?, ?, '[]') @> tstzrange(start_time, end_time, '[)'))",
+		Vars: []interface{}{startTime, startTime, endTime, endTime, startTime, endTime},
+	}, TimeWindow(startTime, endTime))
+}
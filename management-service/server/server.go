@@ -15,6 +15,7 @@ import (
 	"github.com/gojek/mlp/api/pkg/instrumentation/newrelic"
 	"github.com/gojek/mlp/api/pkg/instrumentation/sentry"
 	"github.com/heptiolabs/healthcheck"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 
 	"github.com/caraml-dev/xp/common/web"
@@ -143,6 +144,7 @@ func NewServer(configFiles []string) (*Server, error) {
 	mux := http.NewServeMux()
 	mux.Handle("/v1/", http.StripPrefix("/v1", apiHandler))
 	mux.Handle("/v1/internal/", http.StripPrefix("/v1/internal", healthHandler))
+	mux.Handle("/v1/metrics", http.StripPrefix("/v1", promhttp.Handler()))
 	// Serve Swagger Specs
 	mux.Handle("/experiments.yaml", web.FileHandler(path.Join(cfg.OpenAPISpecsPath, "experiments.yaml"), false))
 	mux.Handle("/schema.yaml", web.FileHandler(path.Join(cfg.OpenAPISpecsPath, "schema.yaml"), false))
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"log"
+	"sync"
+
+	"github.com/caraml-dev/xp/management-service/models"
+)
+
+type ExperimentEventOp string
+
+// Defines values for ExperimentEventOp.
+const (
+	ExperimentEventOpCreate ExperimentEventOp = "create"
+
+	ExperimentEventOpUpdate ExperimentEventOp = "update"
+
+	ExperimentEventOpEnable ExperimentEventOp = "enable"
+
+	ExperimentEventOpDisable ExperimentEventOp = "disable"
+)
+
+// experimentEventBufferSize is the number of events buffered for each subscriber. Events that do not fit in the
+// buffer of a subscriber are dropped for that subscriber, so that a slow subscriber never blocks the changes.
+const experimentEventBufferSize = 64
+
+// ExperimentEvent describes a successful change to an experiment, for the in-process subscribers of the experiment
+// service
+type ExperimentEvent struct {
+	// Op is the operation that changed the experiment
+	Op ExperimentEventOp
+	// Experiment is the experiment as it was saved by the operation
+	Experiment models.Experiment
+}
+
+// experimentSubscribers holds the channels of the subscribers to the experiment events
+type experimentSubscribers struct {
+	lock     sync.RWMutex
+	nextId   int
+	channels map[int]chan ExperimentEvent
+}
+
+// subscribe registers a new subscriber, returning its channel of events and the function that unsubscribes it and
+// closes the channel. The function may be called more than once.
+func (s *experimentSubscribers) subscribe() (<-chan ExperimentEvent, func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.channels == nil {
+		s.channels = map[int]chan ExperimentEvent{}
+	}
+	id := s.nextId
+	s.nextId++
+	ch := make(chan ExperimentEvent, experimentEventBufferSize)
+	s.channels[id] = ch
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			delete(s.channels, id)
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// publish sends the event to every subscriber without blocking, dropping it for the subscribers whose buffers are full
+func (s *experimentSubscribers) publish(op ExperimentEventOp, experiment *models.Experiment) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for id, ch := range s.channels {
+		select {
+		case ch <- ExperimentEvent{Op: op, Experiment: *experiment}:
+		default:
+			log.Printf("Warning: dropped %s event of experiment id %d for subscriber %d, as its buffer is full",
+				op, experiment.ID, id)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/caraml-dev/xp/management-service/models"
+)
+
+func TestExperimentSubscribers(t *testing.T) {
+	subscribers := experimentSubscribers{}
+	ch1, unsubscribe1 := subscribers.subscribe()
+	ch2, unsubscribe2 := subscribers.subscribe()
+
+	experiment := &models.Experiment{ID: models.ID(1), Name: "exp-1"}
+	subscribers.publish(ExperimentEventOpCreate, experiment)
+	// Changes to the experiment after it is published do not affect the event
+	experiment.Name = "exp-1-updated"
+
+	expected := ExperimentEvent{Op: ExperimentEventOpCreate, Experiment: models.Experiment{ID: models.ID(1), Name: "exp-1"}}
+	assert.Equal(t, expected, <-ch1)
+	assert.Equal(t, expected, <-ch2)
+
+	// Unsubscribing closes the channel, and may be done more than once
+	unsubscribe1()
+	unsubscribe1()
+	_, ok := <-ch1
+	assert.False(t, ok)
+
+	subscribers.publish(ExperimentEventOpUpdate, experiment)
+	assert.Equal(t, ExperimentEvent{Op: ExperimentEventOpUpdate, Experiment: *experiment}, <-ch2)
+	unsubscribe2()
+}
+
+func TestExperimentSubscribersDropWhenFull(t *testing.T) {
+	subscribers := experimentSubscribers{}
+	ch, unsubscribe := subscribers.subscribe()
+	defer unsubscribe()
+
+	// Publishing does not block once the buffer is full
+	for i := 0; i < experimentEventBufferSize+10; i++ {
+		subscribers.publish(ExperimentEventOpUpdate, &models.Experiment{ID: models.ID(i)})
+	}
+	assert.Len(t, ch, experimentEventBufferSize)
+
+	// The events that did not fit are dropped
+	for i := 0; i < experimentEventBufferSize; i++ {
+		assert.Equal(t, models.ID(i), (<-ch).Experiment.ID)
+	}
+	assert.Len(t, ch, 0)
+}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"sort"
+	"time"
+
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
@@ -16,8 +19,12 @@ type ListExperimentHistoryParams struct {
 type ExperimentHistoryService interface {
 	ListExperimentHistory(experimentId int64, params ListExperimentHistoryParams) ([]*models.ExperimentHistory, *pagination.Paging, error)
 	GetExperimentHistory(experimentId int64, version int64) (*models.ExperimentHistory, error)
+	GetExperimentVersion(projectId int64, experimentId int64, version int64) (*models.ExperimentHistory, error)
+	ListChangesByUser(projectId int64, user string, from time.Time, to time.Time) ([]*models.ExperimentHistory, error)
 	CreateExperimentHistory(*models.Experiment) (*models.ExperimentHistory, error)
+	CreateExperimentHistories(experiments []*models.Experiment) error
 	GetDBRecord(experimentId models.ID, version int64) (*models.ExperimentHistory, error)
+	WithTransaction(tx *gorm.DB) ExperimentHistoryService
 }
 
 type experimentHistoryService struct {
@@ -81,24 +88,112 @@ func (svc *experimentHistoryService) GetExperimentHistory(
 	return history, nil
 }
 
+// GetExperimentVersion returns the history record of the given version of the experiment, if the experiment belongs to
+// the given project. A NotFound error is returned if there is no such record.
+func (svc *experimentHistoryService) GetExperimentVersion(
+	projectId int64,
+	experimentId int64,
+	version int64,
+) (*models.ExperimentHistory, error) {
+	var history models.ExperimentHistory
+	err := svc.query().
+		Where("experiment_id IN (?)", svc.query().Model(&models.Experiment{}).
+			Select("id").
+			Where("project_id = ?", projectId).
+			Where("id = ?", experimentId)).
+		Where("version = ?", version).
+		First(&history).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.Newf(errors.NotFound,
+				"version %d of experiment %d not found in project %d", version, experimentId, projectId)
+		}
+		return nil, errors.Newf(errors.Internal, err.Error())
+	}
+	return &history, nil
+}
+
+// ListChangesByUser returns the versions of the project's experiments that were created by the given user within the
+// given time range, e.g., to review the changes made by a user during an incident. The versions are taken from the
+// history records, and from the experiments themselves for their current versions, which are not in the history until
+// they are replaced. Both ends of the range are inclusive. The versions are sorted chronologically.
+func (svc *experimentHistoryService) ListChangesByUser(
+	projectId int64,
+	user string,
+	from time.Time,
+	to time.Time,
+) ([]*models.ExperimentHistory, error) {
+	if from.After(to) {
+		return nil, errors.Newf(errors.BadInput, "from must not be after to")
+	}
+
+	// The history records are created at the time that their versions were created
+	history := []*models.ExperimentHistory{}
+	err := svc.query().
+		Where("experiment_id IN (?)", svc.query().Model(&models.Experiment{}).
+			Select("id").
+			Where("project_id = ?", projectId)).
+		Where("updated_by = ?", user).
+		Where("created_at >= ?", from).
+		Where("created_at <= ?", to).
+		Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var experiments []*models.Experiment
+	err = svc.query().
+		Where("project_id = ?", projectId).
+		Where("updated_by = ?", user).
+		Where("updated_at >= ?", from).
+		Where("updated_at <= ?", to).
+		Find(&experiments).Error
+	if err != nil {
+		return nil, err
+	}
+	recorded := map[models.ID]map[int64]bool{}
+	for _, record := range history {
+		if recorded[record.ExperimentID] == nil {
+			recorded[record.ExperimentID] = map[int64]bool{}
+		}
+		recorded[record.ExperimentID][record.Version] = true
+	}
+	for _, experiment := range experiments {
+		if !recorded[experiment.ID][experiment.Version] {
+			history = append(history, newExperimentHistory(experiment))
+		}
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		if !history[i].CreatedAt.Equal(history[j].CreatedAt) {
+			return history[i].CreatedAt.Before(history[j].CreatedAt)
+		}
+		if history[i].ExperimentID != history[j].ExperimentID {
+			return history[i].ExperimentID < history[j].ExperimentID
+		}
+		return history[i].Version < history[j].Version
+	})
+	return history, nil
+}
+
 func (svc *experimentHistoryService) CreateExperimentHistory(experiment *models.Experiment) (*models.ExperimentHistory, error) {
-	return svc.save(&models.ExperimentHistory{
-		Model: models.Model{
-			CreatedAt: experiment.UpdatedAt,
-		},
-		ExperimentID: experiment.ID,
-		Version:      experiment.Version,
-		Description:  experiment.Description,
-		EndTime:      experiment.EndTime,
-		Interval:     experiment.Interval,
-		Name:         experiment.Name,
-		Segment:      experiment.Segment,
-		Status:       experiment.Status,
-		Treatments:   experiment.Treatments,
-		Tier:         experiment.Tier,
-		Type:         experiment.Type,
-		StartTime:    experiment.StartTime,
-		UpdatedBy:    experiment.UpdatedBy,
+	return svc.save(newExperimentHistory(experiment))
+}
+
+// CreateExperimentHistories records the histories of all the given experiments in a single transaction, such that
+// either all or none of them are written. This is meant for the operations that update multiple experiments at once.
+func (svc *experimentHistoryService) CreateExperimentHistories(experiments []*models.Experiment) error {
+	if len(experiments) == 0 {
+		return nil
+	}
+	histories := []*models.ExperimentHistory{}
+	for _, experiment := range experiments {
+		histories = append(histories, newExperimentHistory(experiment))
+	}
+	return svc.query().Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			UpdateAll: true,
+		}).Create(&histories).Error
 	})
 }
 
@@ -117,6 +212,14 @@ func (svc *experimentHistoryService) GetDBRecord(
 	return &history, nil
 }
 
+// WithTransaction returns a copy of the service that runs its queries in the given transaction, so that the histories
+// are written or rolled back together with the other changes made in the transaction
+func (svc *experimentHistoryService) WithTransaction(tx *gorm.DB) ExperimentHistoryService {
+	return &experimentHistoryService{
+		db: tx,
+	}
+}
+
 func (svc *experimentHistoryService) query() *gorm.DB {
 	return svc.db
 }
@@ -129,3 +232,29 @@ func (svc *experimentHistoryService) save(history *models.ExperimentHistory) (*m
 	}
 	return svc.GetDBRecord(history.ExperimentID, history.Version)
 }
+
+// newExperimentHistory creates a snapshot of the current contents of the experiment
+func newExperimentHistory(experiment *models.Experiment) *models.ExperimentHistory {
+	return &models.ExperimentHistory{
+		Model: models.Model{
+			CreatedAt: experiment.UpdatedAt,
+		},
+		ExperimentID: experiment.ID,
+		Version:      experiment.Version,
+		Description:  experiment.Description,
+		EndTime:      experiment.EndTime,
+		Interval:     experiment.Interval,
+		Name:         experiment.Name,
+		Segment:      experiment.Segment,
+		Status:       experiment.Status,
+		Treatments:   experiment.Treatments,
+		Tier:         experiment.Tier,
+		Type:         experiment.Type,
+		StartTime:    experiment.StartTime,
+		UpdatedBy:    experiment.UpdatedBy,
+		MutexGroup:   experiment.MutexGroup,
+		Recurrence:   experiment.Recurrence,
+		Priority:     experiment.Priority,
+		Shadow:       experiment.Shadow,
+	}
+}
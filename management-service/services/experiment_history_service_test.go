@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
 
+	"github.com/caraml-dev/xp/management-service/errors"
 	tu "github.com/caraml-dev/xp/management-service/internal/testutils"
 	"github.com/caraml-dev/xp/management-service/models"
 	"github.com/caraml-dev/xp/management-service/pagination"
@@ -19,6 +20,7 @@ import (
 type ExperimentHistoryServiceTestSuite struct {
 	suite.Suite
 	services.ExperimentHistoryService
+	DB          *gorm.DB
 	CleanUpFunc func()
 
 	Experiments       []*models.Experiment
@@ -34,6 +36,7 @@ func (s *ExperimentHistoryServiceTestSuite) SetupSuite() {
 		s.Suite.T().Fatalf("Could not create test DB: %v", err)
 	}
 	s.CleanUpFunc = cleanup
+	s.DB = db
 
 	// Init experiment history service
 	s.ExperimentHistoryService = services.NewExperimentHistoryService(db)
@@ -61,10 +64,93 @@ func (s *ExperimentHistoryServiceTestSuite) TestExperimentHistoryServiceGetInteg
 	s.Suite.Require().EqualError(err, "record not found")
 }
 
+func (s *ExperimentHistoryServiceTestSuite) TestGetExperimentVersion() {
+	// Existing version
+	histResponse, err := s.ExperimentHistoryService.GetExperimentVersion(1, 1, 2)
+	s.Suite.Require().NoError(err)
+	tu.AssertEqualValues(s.Suite.T(), s.ExperimentHistory[1], histResponse)
+
+	// Non-existing version
+	_, err = s.ExperimentHistoryService.GetExperimentVersion(1, 1, 200)
+	s.Suite.Assert().EqualError(err, "version 200 of experiment 1 not found in project 1")
+	s.Suite.Assert().Equal(errors.NotFound, errors.GetType(err))
+
+	// Existing version of an experiment in another project
+	_, err = s.ExperimentHistoryService.GetExperimentVersion(2, 1, 2)
+	s.Suite.Assert().EqualError(err, "version 2 of experiment 1 not found in project 2")
+	s.Suite.Assert().Equal(errors.NotFound, errors.GetType(err))
+}
+
+func (s *ExperimentHistoryServiceTestSuite) TestListChangesByUser() {
+	// Use new versions of the experiments, to tell the records apart from the existing history
+	changes := []struct {
+		experiment *models.Experiment
+		version    int64
+		updatedBy  string
+		updatedAt  time.Time
+	}{
+		{s.Experiments[1], 200, "forensics-user-1", time.Date(2021, 3, 2, 10, 0, 0, 0, time.UTC)},
+		{s.Experiments[2], 200, "forensics-user-1", time.Date(2021, 3, 2, 9, 0, 0, 0, time.UTC)},
+		{s.Experiments[1], 201, "forensics-user-2", time.Date(2021, 3, 2, 11, 0, 0, 0, time.UTC)},
+		{s.Experiments[2], 201, "forensics-user-1", time.Date(2021, 3, 3, 10, 0, 0, 0, time.UTC)},
+		{s.Experiments[0], 200, "forensics-user-1", time.Date(2021, 3, 1, 23, 59, 59, 0, time.UTC)},
+		// Experiment of another project
+		{s.Experiments[3], 200, "forensics-user-1", time.Date(2021, 3, 2, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, change := range changes {
+		experiment := *change.experiment
+		experiment.Version = change.version
+		experiment.UpdatedBy = change.updatedBy
+		experiment.UpdatedAt = change.updatedAt
+		_, err := s.ExperimentHistoryService.CreateExperimentHistory(&experiment)
+		s.Suite.Require().NoError(err)
+	}
+	// The current version of an experiment is not in the history
+	currentExperiment := *s.Experiments[0]
+	currentExperiment.ID = 0
+	currentExperiment.Name = "forensics-current-exp"
+	currentExperiment.Version = 3
+	currentExperiment.UpdatedBy = "forensics-user-1"
+	currentExperiment.Model = models.Model{
+		CreatedAt: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2021, 3, 2, 12, 30, 0, 0, time.UTC),
+	}
+	s.Suite.Require().NoError(s.DB.Create(&currentExperiment).Error)
+	from := time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 3, 2, 23, 59, 59, 0, time.UTC)
+
+	history, err := s.ExperimentHistoryService.ListChangesByUser(1, "forensics-user-1", from, to)
+	s.Suite.Require().NoError(err)
+	s.Suite.Require().Len(history, 3)
+	for i, expected := range []struct {
+		experimentId models.ID
+		version      int64
+	}{
+		{s.Experiments[2].ID, 200},
+		{s.Experiments[1].ID, 200},
+		{currentExperiment.ID, 3},
+	} {
+		s.Suite.Assert().Equal(expected.experimentId, history[i].ExperimentID)
+		s.Suite.Assert().Equal(expected.version, history[i].Version)
+		s.Suite.Assert().Equal("forensics-user-1", history[i].UpdatedBy)
+	}
+
+	// No changes by the user
+	history, err = s.ExperimentHistoryService.ListChangesByUser(1, "forensics-user-3", from, to)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Empty(history)
+
+	// Invalid window
+	_, err = s.ExperimentHistoryService.ListChangesByUser(1, "forensics-user-1", to, from)
+	s.Suite.Assert().EqualError(err, "from must not be after to")
+	s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+}
+
 func (s *ExperimentHistoryServiceTestSuite) TestExperimentHistoryServiceListCreateIntegration() {
 	// Test list experiment history first, since the create could affect the results
 	testListExperimentHistory(s)
 	testCreateExperimentHistory(s)
+	testCreateExperimentHistories(s)
 }
 
 func testListExperimentHistory(s *ExperimentHistoryServiceTestSuite) {
@@ -91,8 +177,14 @@ func testListExperimentHistory(s *ExperimentHistoryServiceTestSuite) {
 }
 
 func testCreateExperimentHistory(s *ExperimentHistoryServiceTestSuite) {
-	experiment := s.Experiments[1]
-	expHist, err := s.ExperimentHistoryService.CreateExperimentHistory(experiment)
+	// Set the fields that are not in the fixtures, to check that they are snapshotted
+	mutexGroup := "checkout"
+	experiment := *s.Experiments[1]
+	experiment.MutexGroup = &mutexGroup
+	experiment.Priority = 3
+	experiment.Shadow = true
+	experiment.Recurrence = &models.RecurrenceSpec{Period: 7 * 24 * time.Hour, Occurrences: 4, Occurrence: 1}
+	expHist, err := s.ExperimentHistoryService.CreateExperimentHistory(&experiment)
 
 	s.Suite.Require().NoError(err)
 
@@ -131,6 +223,40 @@ func testCreateExperimentHistory(s *ExperimentHistoryServiceTestSuite) {
 	s.Suite.Assert().JSONEq(string(expectedJSON), string(expHistJSON))
 }
 
+func testCreateExperimentHistories(s *ExperimentHistoryServiceTestSuite) {
+	// Use new versions of the experiments, to tell the records apart from the existing history
+	experiments := []*models.Experiment{}
+	for _, exp := range s.Experiments[:2] {
+		experiment := *exp
+		experiment.Version = 100
+		experiments = append(experiments, &experiment)
+	}
+
+	err := s.ExperimentHistoryService.CreateExperimentHistories(experiments)
+	s.Suite.Require().NoError(err)
+	for _, experiment := range experiments {
+		expHist, err := s.ExperimentHistoryService.GetExperimentHistory(int64(experiment.ID), 100)
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Equal(experiment.Name, expHist.Name)
+		s.Suite.Assert().Equal(experiment.Segment, expHist.Segment)
+	}
+
+	// None of the histories are written if any of them fails, here because the experiment does not exist
+	validExperiment := *experiments[0]
+	validExperiment.Version = 101
+	missingExperiment := *experiments[1]
+	missingExperiment.ID = models.ID(9999)
+	err = s.ExperimentHistoryService.CreateExperimentHistories(
+		[]*models.Experiment{&validExperiment, &missingExperiment},
+	)
+	s.Suite.Assert().Error(err)
+	_, err = s.ExperimentHistoryService.GetExperimentHistory(int64(validExperiment.ID), 101)
+	s.Suite.Assert().EqualError(err, "record not found")
+
+	// Nothing is written for an empty list
+	s.Suite.Assert().NoError(s.ExperimentHistoryService.CreateExperimentHistories([]*models.Experiment{}))
+}
+
 func TestExperimentHistoryService(t *testing.T) {
 	suite.Run(t, new(ExperimentHistoryServiceTestSuite))
 }
@@ -1,19 +1,32 @@
 package services
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/caraml-dev/xp/management-service/utils"
 	"github.com/golang-collections/collections/set"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
+	"github.com/caraml-dev/xp/common/api/schema"
+	_segmenters "github.com/caraml-dev/xp/common/segmenters"
 	"github.com/caraml-dev/xp/management-service/errors"
 	"github.com/caraml-dev/xp/management-service/models"
 	"github.com/caraml-dev/xp/management-service/pagination"
+	"github.com/caraml-dev/xp/management-service/querybuilder"
 )
 
 type ExperimentStatusFriendly string
@@ -24,33 +37,41 @@ const (
 	ExperimentStatusFriendlyDeactivated ExperimentStatusFriendly = "deactivated"
 	ExperimentStatusFriendlyRunning     ExperimentStatusFriendly = "running"
 	ExperimentStatusFriendlyScheduled   ExperimentStatusFriendly = "scheduled"
+	ExperimentStatusFriendlyDraft       ExperimentStatusFriendly = "draft"
 )
 
 type CreateExperimentRequestBody struct {
 	Description *string                     `json:"description"`
 	EndTime     time.Time                   `json:"end_time" validate:"required,gtfield=StartTime"`
 	Interval    *int32                      `json:"interval"`
+	MutexGroup  *string                     `json:"mutex_group"`
 	Name        string                      `json:"name" validate:"required,notBlank"`
+	Priority    *int32                      `json:"priority"`
+	Recurrence  *models.RecurrenceSpec      `json:"recurrence"`
 	Segment     models.ExperimentSegmentRaw `json:"segment"`
+	Shadow      bool                        `json:"shadow"`
 	StartTime   time.Time                   `json:"start_time" validate:"required"`
-	Status      models.ExperimentStatus     `json:"status" validate:"required,oneof=inactive active"`
+	Status      models.ExperimentStatus     `json:"status" validate:"required,oneof=inactive active draft"`
 	Treatments  models.ExperimentTreatments `json:"treatments" validate:"unique=Name,dive,required,notBlank"`
 	Tier        models.ExperimentTier       `json:"tier" validate:"required,oneof=default override"`
 	Type        models.ExperimentType       `json:"type" validate:"required,oneof=A/B Switchback"`
-	UpdatedBy   *string                     `json:"updated_by,omitempty"`
+	UpdatedBy   *string                     `json:"updated_by,omitempty" validate:"required,notBlank"`
 }
 
 type UpdateExperimentRequestBody struct {
 	Description *string                     `json:"description"`
 	EndTime     time.Time                   `json:"end_time" validate:"required,gtfield=StartTime"`
 	Interval    *int32                      `json:"interval"`
+	MutexGroup  *string                     `json:"mutex_group"`
+	Priority    *int32                      `json:"priority"`
 	Segment     models.ExperimentSegmentRaw `json:"segment"`
+	Shadow      *bool                       `json:"shadow"`
 	StartTime   time.Time                   `json:"start_time" validate:"required"`
-	Status      models.ExperimentStatus     `json:"status" validate:"required,oneof=inactive active"`
+	Status      models.ExperimentStatus     `json:"status" validate:"required,oneof=inactive active draft"`
 	Treatments  models.ExperimentTreatments `json:"treatments" validate:"unique=Name,dive,required,notBlank"`
 	Tier        models.ExperimentTier       `json:"tier" validate:"required,oneof=default override"`
 	Type        models.ExperimentType       `json:"type" validate:"required,oneof=A/B Switchback"`
-	UpdatedBy   *string                     `json:"updated_by,omitempty"`
+	UpdatedBy   *string                     `json:"updated_by,omitempty" validate:"required,notBlank"`
 }
 
 type ListExperimentsParams struct {
@@ -67,21 +88,301 @@ type ListExperimentsParams struct {
 	Segment          models.ExperimentSegment   `json:"segment,omitempty"`
 	IncludeWeakMatch bool                       `json:"include_weak_match"`
 	Fields           *[]models.ExperimentField  `json:"fields,omitempty"`
+	MinVersion       *int64                     `json:"min_version,omitempty"`
+	MaxVersion       *int64                     `json:"max_version,omitempty"`
+	// TreatmentConfigHasKey selects experiments with at least one treatment whose configuration has the given top-level key
+	TreatmentConfigHasKey *string `json:"treatment_config_has_key,omitempty"`
+	// ValidationStatus selects experiments by the outcome of their last custom validation
+	ValidationStatus *string `json:"validation_status,omitempty"`
+	// SearchFuzzy matches the search term against the experiment names by trigram similarity instead of as a
+	// substring of the name or description, so that misspelt terms still match. The results are ordered by similarity.
+	SearchFuzzy bool `json:"search_fuzzy"`
+	// Names selects the experiments with any of the given names, and cannot be combined with Name
+	Names []string `json:"names,omitempty"`
+	// ExcludeSegment leaves out the experiments that target any of the given values of each segmenter. Experiments
+	// that do not constrain the segmenter at all are kept, as they do not target the values explicitly.
+	ExcludeSegment models.ExperimentSegment `json:"exclude_segment,omitempty"`
+	// SegmentRange selects the experiments with at least one value of each of the given integer segmenters in the
+	// given range, comparing the values as numbers. Weak matches are included as for Segment.
+	SegmentRange map[string]SegmenterRange `json:"segment_range,omitempty"`
+	// UnconstrainedSegmenter selects the experiments that do not constrain the given segmenter, i.e., that do not set
+	// it or set it as [], so that they match all of its values
+	UnconstrainedSegmenter *string `json:"unconstrained_segmenter,omitempty"`
+	// MinTreatments and MaxTreatments select the experiments by the number of their treatments, inclusively
+	MinTreatments *int `json:"min_treatments,omitempty"`
+	MaxTreatments *int `json:"max_treatments,omitempty"`
+	// WeakMatchPerSegmenter enables or disables the weak matches of the given segmenters, overriding IncludeWeakMatch
+	// for them. The segmenters must be filtered by Segment or SegmentRange. A weak match of a segmenter is an experiment
+	// that does not constrain it, unless the project's default values of the segmenter do not match the filter. The
+	// filters of the segmenters are combined with AND, so an experiment is selected only if, for every segmenter, it
+	// either matches the filter or is a weak match, e.g., with weak matches, an experiment that sets neither of two
+	// filtered segmenters is selected, whereas one that sets only one of them to a different value is not.
+	WeakMatchPerSegmenter map[string]bool `json:"weak_match_per_segmenter,omitempty"`
+	// IdsOnly selects only the id column of the experiments, leaving the other fields unset, e.g., for the clients that
+	// only invalidate their caches by id. Unlike selecting the id with Fields, the results are still paginated by
+	// default. It cannot be combined with Fields.
+	IdsOnly bool `json:"ids_only"`
 }
 
+// SegmenterRange is the inclusive range of integer segmenter values [Min, Max]
+type SegmenterRange struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+// ExperimentWithRawSegment is an experiment, along with its segment in the raw schema
+type ExperimentWithRawSegment struct {
+	Experiment *models.Experiment          `json:"experiment"`
+	RawSegment models.ExperimentSegmentRaw `json:"raw_segment"`
+}
+
+// ExperimentHistoryExport is an experiment history record, along with its segment in the raw schema
+type ExperimentHistoryExport struct {
+	History    *models.ExperimentHistory   `json:"history"`
+	RawSegment models.ExperimentSegmentRaw `json:"raw_segment"`
+}
+
+// ExportExperimentHistoryParams restricts the exported history records to those updated in the given time range. Both
+// ends of the range are optional and inclusive.
+type ExportExperimentHistoryParams struct {
+	UpdatedAfter  *time.Time `json:"updated_after,omitempty"`
+	UpdatedBefore *time.Time `json:"updated_before,omitempty"`
+}
+
+// TimeWindow represents the time range [StartTime, EndTime)
+type TimeWindow struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// ExperimentRisk captures the orthogonality risk of an active experiment
+type ExperimentRisk struct {
+	Experiment *models.Experiment `json:"experiment"`
+	// Score is the number of other active experiments that the experiment nearly overlaps with
+	Score int `json:"score"`
+	// NearOverlaps holds the ids of the experiments that the experiment nearly overlaps with, in ascending order
+	NearOverlaps []int64 `json:"near_overlaps"`
+}
+
+// TreatmentChange captures a change of the treatments of an experiment, from one version to the next
+type TreatmentChange struct {
+	ExperimentID   int64                           `json:"experiment_id"`
+	ExperimentName string                          `json:"experiment_name"`
+	FromVersion    int64                           `json:"from_version"`
+	ToVersion      int64                           `json:"to_version"`
+	UpdatedAt      time.Time                       `json:"updated_at"`
+	UpdatedBy      string                          `json:"updated_by"`
+	Diff           models.ExperimentTreatmentsDiff `json:"diff"`
+}
+
+// ExperimentDiff captures the changes of an experiment from one version to the next
+type ExperimentDiff struct {
+	// Fields maps the names of the changed fields to their changes. The treatments are compared separately.
+	Fields map[string]FieldChange `json:"fields"`
+	// Treatments holds the names of the added, removed and modified treatments
+	Treatments models.ExperimentTreatmentsDiff `json:"treatments"`
+}
+
+// FieldChange captures the values of a field before and after a change
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// BulkResult captures the outcome of a bulk operation on experiments. Experiments that could not be updated are
+// mapped to the reason for the failure.
+type BulkResult struct {
+	Succeeded []int64          `json:"succeeded"`
+	Failed    map[int64]string `json:"failed"`
+}
+
+// PauseToken records the experiments that were active when the project's experiments were paused, in the order in
+// which they are to be resumed
+type PauseToken struct {
+	ProjectID     int64   `json:"project_id"`
+	ExperimentIds []int64 `json:"experiment_ids"`
+}
+
+// defaultValidationConcurrency is the maximum number of validations of an experiment that are run concurrently, unless
+// configured otherwise
+const defaultValidationConcurrency = 8
+
+// ValidationCheck is the name of a check run by ValidateExperimentAgainstSettings
+type ValidationCheck string
+
+// Defines values for ValidationCheck.
+const (
+	// ValidationCheckSegmentersExist checks that the segmenters used by the experiment are enabled in the settings
+	ValidationCheckSegmentersExist ValidationCheck = "segmenters_exist"
+	// ValidationCheckSegment checks that the experiment's segment values are valid for the enabled segmenters
+	ValidationCheckSegment ValidationCheck = "segment"
+	// ValidationCheckCustomValidation runs the treatment schema, the validation url and the custom validators
+	ValidationCheckCustomValidation ValidationCheck = "custom_validation"
+	// ValidationCheckTreatmentSchema checks the experiment's treatments against the treatment schema only
+	ValidationCheckTreatmentSchema ValidationCheck = "treatment_schema"
+	// ValidationCheckRequest checks the request data of an update, e.g., that the experiment type is unchanged
+	ValidationCheckRequest ValidationCheck = "request"
+	// ValidationCheckProjectUnlocked checks that the experiments of the project are not locked
+	ValidationCheckProjectUnlocked ValidationCheck = "project_unlocked"
+	// ValidationCheckMutexGroup checks that no other experiment of the same mutex group is active at the same time
+	ValidationCheckMutexGroup ValidationCheck = "mutex_group"
+	// ValidationCheckOrthogonality checks that the segment of an active experiment is orthogonal to those of the other
+	// active experiments of the same tier with overlapping time windows
+	ValidationCheckOrthogonality ValidationCheck = "orthogonality"
+	// ValidationCheckSwitchbackInterval checks that the interval of an active Switchback experiment is compatible with
+	// those of the other active Switchback experiments with overlapping segments and time windows
+	ValidationCheckSwitchbackInterval ValidationCheck = "switchback_interval"
+	// ValidationCheckActiveExperimentLimit checks that activating an experiment does not take the number of the
+	// project's running experiments beyond the project's hard maximum
+	ValidationCheckActiveExperimentLimit ValidationCheck = "active_experiment_limit"
+)
+
+// ValidationReport captures the outcome of validating an experiment against a set of project settings. Every check is
+// run; the checks that failed are mapped to the reason for the failure, and the warnings of the checks that passed are
+// listed.
+type ValidationReport struct {
+	Passed   bool                       `json:"passed"`
+	Failures map[ValidationCheck]string `json:"failures"`
+	// Warnings lists the problems that do not fail the checks, as the project does not block them
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ProjectHealthReport captures the problems found by ValidateAllExperiments. The experiments with problems are mapped
+// to the checks that they failed and the reasons for the failures; the experiments without problems are left out.
+type ProjectHealthReport struct {
+	Problems map[int64]map[ValidationCheck]string `json:"problems"`
+}
+
+// SettingsImpactReport captures the experiments that would newly fail validation under proposed project settings. The
+// experiments are mapped to the checks that they would newly fail and the reasons for the failures; the experiments
+// that are unaffected are left out.
+type SettingsImpactReport struct {
+	NewlyInvalid map[int64]map[ValidationCheck]string `json:"newly_invalid"`
+}
+
+// OrthogonalityConflict captures a pair of active experiments whose segments are not orthogonal. The experiment takes
+// precedence over the conflicting experiment, by its higher priority, or by being created first if the priorities are
+// equal.
+type OrthogonalityConflict struct {
+	ExperimentID            int64  `json:"experiment_id"`
+	ConflictingExperimentID int64  `json:"conflicting_experiment_id"`
+	Reason                  string `json:"reason"`
+}
+
+// ReconcileReport captures the orthogonality conflicts between a project's active experiments, and the outcome of
+// disabling the conflicting experiments, if they were to be disabled
+type ReconcileReport struct {
+	Conflicts []OrthogonalityConflict `json:"conflicts"`
+	Disabled  BulkResult              `json:"disabled"`
+}
+
+// ExperimentValidatorFunc is a custom validator that is run on experiments of the projects that enable it, by the
+// name it is registered with
+type ExperimentValidatorFunc func(
+	experiment models.Experiment,
+	settings models.Settings,
+	context ValidationContext,
+	operationType OperationType,
+) error
+
 type ExperimentService interface {
 	ListExperiments(
 		projectId int64,
 		params ListExperimentsParams,
 	) ([]*models.Experiment, *pagination.Paging, error)
 	ListAllExperiments(projectId models.ID, params ListExperimentsParams) ([]*models.Experiment, error)
+	ListExperimentsMultiProject(projectIds []int64, params ListExperimentsParams) (map[int64][]*models.Experiment, error)
+	ListExperimentsGroupedByStatus(
+		projectId int64,
+		params ListExperimentsParams,
+	) (map[ExperimentStatusFriendly][]*models.Experiment, error)
+	ListExperimentsWithTreatmentChanges(projectId int64, since time.Time) ([]TreatmentChange, error)
+	ListCompetingExperiments(
+		projectId int64,
+		segment models.ExperimentSegmentRaw,
+		tier models.ExperimentTier,
+		startTime time.Time,
+		endTime time.Time,
+		includeWeak bool,
+	) ([]*models.Experiment, error)
+	FindCoverageGaps(
+		projectId int64,
+		segment models.ExperimentSegmentRaw,
+		tier models.ExperimentTier,
+		from time.Time,
+		to time.Time,
+	) ([]TimeWindow, error)
+	FindDuplicateExperiments(projectId int64) ([][]*models.Experiment, error)
+	ListByOrthogonalityRisk(projectId int64) ([]ExperimentRisk, error)
+	ExportExperimentsCSV(projectId int64, params ListExperimentsParams, pivotSegments bool) ([]byte, error)
+	ExportExperimentHistory(projectId int64, params ExportExperimentHistoryParams) ([]ExperimentHistoryExport, error)
+	FindSegmentIntersections(
+		projectId int64,
+		segment models.ExperimentSegmentRaw,
+		tier models.ExperimentTier,
+	) ([]*models.Experiment, error)
+	ListExperimentsByExactSegment(
+		projectId int64,
+		segment models.ExperimentSegmentRaw,
+		tier models.ExperimentTier,
+	) ([]*models.Experiment, error)
+	GetSegmentCoverage(projectId int64, tier models.ExperimentTier) (float64, error)
+	MatchExperiments(
+		projectId int64,
+		tier models.ExperimentTier,
+		unitSegment map[string]string,
+		at time.Time,
+	) ([]*models.Experiment, error)
+	ResolveOverlap(
+		projectId int64,
+		segment map[string]string,
+		tier models.ExperimentTier,
+		at time.Time,
+	) (*models.Experiment, error)
 	GetExperiment(projectId int64, experimentId int64) (*models.Experiment, error)
+	GetExperimentWithRawSegment(projectId int64, experimentId int64) (*ExperimentWithRawSegment, error)
+	GetEffectiveSegment(projectId int64, experimentId int64) (models.ExperimentSegmentRaw, error)
+	PreviewProtoMessage(projectId int64, experimentId int64) ([]byte, error)
+	PreviewAssignmentDistribution(projectId int64, experimentId int64) (map[string]float64, error)
+	GetRawStoredSegment(projectId int64, experimentId int64) (json.RawMessage, error)
 	CreateExperiment(settings models.Settings, expData CreateExperimentRequestBody) (*models.Experiment, error)
 	UpdateExperiment(settings models.Settings, experimentId int64, expData UpdateExperimentRequestBody) (*models.Experiment, error)
+	PreviewUpdate(
+		settings models.Settings,
+		experimentId int64,
+		expData UpdateExperimentRequestBody,
+	) (*ExperimentDiff, ValidationReport, error)
+	AddTreatments(settings models.Settings, experimentId int64, treatments models.ExperimentTreatments) (*models.Experiment, error)
 	EnableExperiment(settings models.Settings, experimentId int64) error
+	GetActiveExperimentLimitWarning(settings models.Settings) (string, error)
+	PromoteExperiment(settings models.Settings, experimentId int64) (*models.Experiment, error)
 	DisableExperiment(projectId int64, experimentId int64) error
-	ValidatePairwiseExperimentOrthogonality(projectId int64, experiments []*models.Experiment, segmenters []string) error
+	BulkUpdateTier(settings models.Settings, experimentIds []int64, newTier models.ExperimentTier) (BulkResult, error)
+	SwapExperimentSchedules(
+		settings models.Settings,
+		experimentIdA int64,
+		experimentIdB int64,
+	) (*models.Experiment, *models.Experiment, error)
+	SweepRecurringExperiments(settings models.Settings) (BulkResult, error)
+	CancelRecurrence(projectId int64, experimentId int64) error
+	PauseAllExperiments(projectId int64) (PauseToken, error)
+	ResumeExperiments(settings models.Settings, token PauseToken) (BulkResult, error)
+	RepublishExperiment(projectId int64, experimentId int64) error
+	RepublishAllExperiments(projectId int64) error
+	LockExperiments(projectId int64) error
+	UnlockExperiments(projectId int64) error
+	ValidatePairwiseExperimentOrthogonality(
+		projectId int64,
+		experiments []*models.Experiment,
+		segmenters []string,
+		defaultSegment models.ExperimentSegmentRaw,
+	) error
+	SimulateOrthogonalityWithSegmenters(projectId int64, segmenters []string) (ValidationReport, error)
+	ReconcileOrthogonality(settings models.Settings, autoDisable bool) (ReconcileReport, error)
 	ValidateProjectExperimentSegmentersExist(projectId int64, experiments []*models.Experiment, segmenters []string) error
+	ValidateExperimentAgainstSettings(experiment models.Experiment, settings models.Settings) (ValidationReport, error)
+	ValidateAllExperiments(projectId int64) (ProjectHealthReport, error)
+	PreviewSettingsImpact(projectId int64, proposedSettings models.Settings) (SettingsImpactReport, error)
 
 	GetDBRecord(projectId models.ID, experimentId models.ID) (*models.Experiment, error)
 	RunCustomValidation(
@@ -90,78 +391,129 @@ type ExperimentService interface {
 		context ValidationContext,
 		operationType OperationType,
 	) error
+	RegisterValidator(name string, fn ExperimentValidatorFunc)
+	ValidateEnabledValidators(names []string) error
+	SetValidationConcurrency(limit int)
+	Subscribe() (<-chan ExperimentEvent, func())
+	Now() time.Time
+}
+
+// Clock provides the current time to the services, so that it can be fixed in tests
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
 }
 
 type experimentService struct {
 	services *Services
 	db       *gorm.DB
+	clock    Clock
+
+	validatorsLock        sync.RWMutex
+	validators            map[string]ExperimentValidatorFunc
+	validationConcurrency int
+
+	subscribers experimentSubscribers
 }
 
 func NewExperimentService(
 	services *Services,
 	db *gorm.DB,
+) ExperimentService {
+	return NewExperimentServiceWithClock(services, db, realClock{})
+}
+
+// NewExperimentServiceWithClock creates an experiment service that evaluates the time-based statuses of experiments,
+// e.g., whether they are running, at the times given by the clock
+func NewExperimentServiceWithClock(
+	services *Services,
+	db *gorm.DB,
+	clock Clock,
 ) ExperimentService {
 	return &experimentService{
-		services: services,
-		db:       db,
+		services:   services,
+		db:         db,
+		clock:      clock,
+		validators: map[string]ExperimentValidatorFunc{},
+
+		validationConcurrency: defaultValidationConcurrency,
+	}
+}
+
+// Now returns the current time according to the clock of the experiment service, at which the time-based statuses of
+// the experiments are evaluated
+func (svc *experimentService) Now() time.Time {
+	return svc.clock.Now()
+}
+
+// RegisterValidator registers a custom validator by the given name, replacing any validator previously registered by
+// the same name. The validator is run by RunCustomValidation for the projects that enable it in their settings.
+func (svc *experimentService) RegisterValidator(name string, fn ExperimentValidatorFunc) {
+	svc.validatorsLock.Lock()
+	defer svc.validatorsLock.Unlock()
+	svc.validators[name] = fn
+}
+
+// ValidateEnabledValidators checks that the custom validators of the given names, which a project is to enable, are
+// registered
+func (svc *experimentService) ValidateEnabledValidators(names []string) error {
+	_, err := svc.getEnabledValidators(&models.ExperimentationConfig{EnabledValidators: names})
+	return err
+}
+
+// SetValidationConcurrency sets the maximum number of validations that RunCustomValidation runs concurrently for an
+// experiment. A limit that is not positive restores the default limit.
+func (svc *experimentService) SetValidationConcurrency(limit int) {
+	svc.validatorsLock.Lock()
+	defer svc.validatorsLock.Unlock()
+	if limit <= 0 {
+		limit = defaultValidationConcurrency
 	}
+	svc.validationConcurrency = limit
+}
+
+// Subscribe returns a channel of the events of the successful changes to experiments, for in-process consumers, and a
+// function to unsubscribe. Events are not blocked on slow subscribers; they are dropped once the subscriber's buffer is
+// full.
+func (svc *experimentService) Subscribe() (<-chan ExperimentEvent, func()) {
+	return svc.subscribers.subscribe()
 }
 
 func (svc *experimentService) ListExperiments(
 	projectId int64,
 	params ListExperimentsParams,
 ) ([]*models.Experiment, *pagination.Paging, error) {
-	var err error
 	var exps []*models.Experiment
 
-	query := svc.query()
-
-	// Handle Field values
-	query, err = svc.filterFieldValues(query, params)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	query = query.
-		Where("project_id = ?", projectId).
-		Order("updated_at desc")
-
-	// Handle optional parameters
-	if params.Status != nil {
-		query = query.Where("status = ?", params.Status)
+	// Selecting all the fields explicitly is equivalent to not selecting any
+	if params.Fields != nil && models.IsAllExperimentFields(*params.Fields) {
+		params.Fields = nil
 	}
-	// Handle StatusFriendly values
-	if len(params.StatusFriendly) > 0 {
-		query = svc.filterExperimentStatusFriendly(query, params.StatusFriendly)
+	if err := svc.validateSegmentRanges(projectId, params.SegmentRange); err != nil {
+		return nil, nil, err
 	}
-
-	// Handle Start and EndTime values
-	query, err = svc.filterStartEndTimeValues(query, params)
+	defaultSegment, err := svc.getWeakMatchDefaultSegment(projectId, params)
 	if err != nil {
 		return nil, nil, err
 	}
-
-	if params.Tier != nil {
-		query = query.Where("tier = ?", params.Tier)
-	}
-	if params.Type != nil {
-		query = query.Where("type = ?", params.Type)
-	}
-	if params.Name != nil {
-		query = query.Where("name = ?", params.Name)
-	}
-	if params.UpdatedBy != nil {
-		query = query.Where(
-			fmt.Sprintf("updated_by ILIKE '%%%s%%'", *params.UpdatedBy),
-		)
+	storage, err := svc.getFilterSegmentStorage(projectId, params)
+	if err != nil {
+		return nil, nil, err
 	}
-	if params.Search != nil {
-		query = query.Where(
-			fmt.Sprintf("name ILIKE '%%%s%%' OR description ILIKE '%%%s%%'", *params.Search, *params.Search),
-		)
+	query, err := svc.filterListExperimentsParams(
+		svc.query().Where("project_id = ?", projectId),
+		params,
+		defaultSegment,
+		storage,
+	)
+	if err != nil {
+		return nil, nil, err
 	}
-	// Segmenters
-	query = svc.filterSegmenterValues(query, params.Segment, params.IncludeWeakMatch)
 
 	// Pagination
 	var pagingResponse *pagination.Paging
@@ -195,594 +547,3495 @@ func (svc *experimentService) ListExperiments(
 	return exps, pagingResponse, nil
 }
 
-func (svc *experimentService) GetExperiment(projectId int64, experimentId int64) (*models.Experiment, error) {
-	exp, err := svc.GetDBRecord(models.ID(projectId), models.ID(experimentId))
-	if err != nil {
-		return nil, errors.Newf(errors.NotFound, err.Error())
+// ListExperimentsMultiProject runs the same filtered query as ListExperiments across all the given projects and
+// returns the results grouped by project id. Every requested project is present in the result, even if it has no
+// matching experiments. Pagination is not supported as the page boundaries would not be meaningful across projects;
+// setting either the page or the page size returns an error.
+func (svc *experimentService) ListExperimentsMultiProject(
+	projectIds []int64,
+	params ListExperimentsParams,
+) (map[int64][]*models.Experiment, error) {
+	if params.Page != nil || params.PageSize != nil {
+		return nil, errors.Newf(errors.BadInput, "pagination is not supported when listing experiments across projects")
+	}
+	if len(params.SegmentRange) > 0 {
+		// The segmenter types may differ between projects
+		return nil, errors.Newf(errors.BadInput, "segment ranges are not supported when listing experiments across projects")
 	}
 
-	return exp, nil
-}
+	// Selecting all the fields explicitly is equivalent to not selecting any
+	if params.Fields != nil && models.IsAllExperimentFields(*params.Fields) {
+		params.Fields = nil
+	}
 
-func (svc *experimentService) CreateExperiment(
-	settings models.Settings,
-	expData CreateExperimentRequestBody,
-) (*models.Experiment, error) {
-	// Validate experiment data
-	err := svc.services.ValidationService.Validate(expData)
-	if err != nil {
-		return nil, errors.Newf(errors.BadInput, err.Error())
+	expsByProject := map[int64][]*models.Experiment{}
+	if len(projectIds) == 0 {
+		return expsByProject, nil
+	}
+	for _, projectId := range projectIds {
+		expsByProject[projectId] = []*models.Experiment{}
 	}
 
-	// Validate Segmenter data
-	err = svc.services.SegmenterService.ValidateExperimentSegment(
-		int64(settings.ProjectID),
-		settings.Config.Segmenters.Names,
-		expData.Segment,
+	// The default segments of the projects are not applied to the weak matches, and the segments are matched on their
+	// string values, as the settings may differ between projects
+	query, err := svc.filterListExperimentsParams(
+		svc.query().Where("project_id IN ?", projectIds),
+		params,
+		models.ExperimentSegment{},
+		querybuilder.SegmentStorage{},
 	)
 	if err != nil {
-		return nil, errors.Newf(errors.BadInput, err.Error())
+		return nil, err
 	}
 
-	// If new experiment is active, get other experiments active in the same time range
-	// and validate segment orthogonality
-	if expData.Status == models.ExperimentStatusActive {
-		err = svc.validateExperimentOrthogonalityInDuration(nil, settings, expData.Segment, expData.Tier, expData.StartTime, expData.EndTime)
-		if err != nil {
-			return nil, err
-		}
+	var exps []*models.Experiment
+	if (params.Fields != nil && len(*params.Fields) != 0) || params.IdsOnly {
+		// The project id is required to group the results, select it in addition to the requested fields
+		query = query.Select(append(query.Statement.Selects, "project_id"))
+	}
+	err = query.Find(&exps).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, exp := range exps {
+		projectId := exp.ProjectID.ToApiSchema()
+		expsByProject[projectId] = append(expsByProject[projectId], exp)
+	}
 
-		// Check if the set of segmenters contains all the segments specified by the experiment
-		err = validateExperimentSegmentersExist(
-			expData.Name,
-			expData.Segment,
-			utils.StringSliceToSet(settings.Config.Segmenters.Names),
-		)
-		if err != nil {
-			return nil, err
-		}
+	return expsByProject, nil
+}
+
+// ListExperimentsGroupedByStatus runs the same filtered query as ListExperiments and groups the results by their
+// friendly status, evaluated at the current time in the same way as the status_friendly filter. All the statuses are
+// present in the result, even if they have no experiments. Pagination is not supported.
+func (svc *experimentService) ListExperimentsGroupedByStatus(
+	projectId int64,
+	params ListExperimentsParams,
+) (map[ExperimentStatusFriendly][]*models.Experiment, error) {
+	if params.Page != nil || params.PageSize != nil {
+		return nil, errors.Newf(errors.BadInput, "pagination is not supported when grouping experiments by status")
+	}
+	if params.Fields != nil && len(*params.Fields) != 0 && !models.IsAllExperimentFields(*params.Fields) {
+		// The fields that determine the friendly status are required to group the results
+		fields := append([]models.ExperimentField{}, *params.Fields...)
+		fields = append(fields, models.ExperimentFieldStatusFriendly)
+		params.Fields = &fields
 	}
 
-	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
-	if err != nil {
+	if err := svc.validateSegmentRanges(projectId, params.SegmentRange); err != nil {
 		return nil, err
 	}
-	segmenterStorageSchema, err := expData.Segment.ToStorageSchema(segmenterTypes)
+	defaultSegment, err := svc.getWeakMatchDefaultSegment(projectId, params)
 	if err != nil {
 		return nil, err
 	}
-	// Create the experiment record
-	experiment := &models.Experiment{
-		ProjectID:   settings.ProjectID,
-		Name:        expData.Name,
-		Description: expData.Description,
-		Tier:        expData.Tier,
-		Type:        expData.Type,
-		Interval:    expData.Interval,
-		Treatments:  expData.Treatments,
-		Segment:     segmenterStorageSchema,
-		Status:      expData.Status,
-		StartTime:   expData.StartTime,
-		EndTime:     expData.EndTime,
-		UpdatedBy:   *expData.UpdatedBy,
-		Version:     1,
+	storage, err := svc.getFilterSegmentStorage(projectId, params)
+	if err != nil {
+		return nil, err
 	}
-
-	// Validate the experiment against the project settings' treatment schema and validation url
-	err = svc.RunCustomValidation(
-		*experiment,
-		settings,
-		ValidationContext{},
-		OperationTypeCreate,
+	query, err := svc.filterListExperimentsParams(
+		svc.query().Where("project_id = ?", projectId),
+		params,
+		defaultSegment,
+		storage,
 	)
 	if err != nil {
-		return nil, errors.Newf(errors.BadInput, err.Error())
+		return nil, err
+	}
+	if params.IdsOnly {
+		// The fields that determine the friendly status are required to group the results, select them in addition
+		// to the ids
+		query = query.Select(append(query.Statement.Selects,
+			string(models.ExperimentFieldStatus),
+			string(models.ExperimentFieldStartTime),
+			string(models.ExperimentFieldEndTime),
+		))
+	}
+	var exps []*models.Experiment
+	if err = query.Find(&exps).Error; err != nil {
+		return nil, err
 	}
 
-	// Save to DB
-	expDBRecord, err := svc.save(experiment)
+	return groupExperimentsByStatusFriendly(exps, svc.clock.Now()), nil
+}
+
+func (svc *experimentService) GetExperiment(projectId int64, experimentId int64) (*models.Experiment, error) {
+	return svc.GetDBRecord(models.ID(projectId), models.ID(experimentId))
+}
+
+func (svc *experimentService) GetExperimentWithRawSegment(
+	projectId int64,
+	experimentId int64,
+) (*ExperimentWithRawSegment, error) {
+	exp, err := svc.GetExperiment(projectId, experimentId)
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert to the format expected by the Message Queue
-	protoExpResponse, err := expDBRecord.ToProtoSchema(segmenterTypes)
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
 	if err != nil {
 		return nil, err
 	}
-	err = svc.services.PubSubPublisherService.PublishExperimentMessage("create", protoExpResponse)
+	rawSegment, err := exp.Segment.ToRawSchema(segmenterTypes)
 	if err != nil {
 		return nil, err
 	}
 
-	return expDBRecord, nil
+	return &ExperimentWithRawSegment{Experiment: exp, RawSegment: rawSegment}, nil
 }
 
-func (svc *experimentService) UpdateExperiment(
-	settings models.Settings,
+// GetEffectiveSegment returns the segment of the experiment, with the segmenters that it does not set taking on the
+// values of the project's default segment
+func (svc *experimentService) GetEffectiveSegment(
+	projectId int64,
 	experimentId int64,
-	expData UpdateExperimentRequestBody,
-) (*models.Experiment, error) {
-	// Validate experiment data
-	err := svc.services.ValidationService.Validate(expData)
+) (models.ExperimentSegmentRaw, error) {
+	exp, err := svc.GetExperiment(projectId, experimentId)
 	if err != nil {
-		return nil, errors.Newf(errors.BadInput, err.Error())
+		return nil, err
 	}
-
-	err = svc.services.SegmenterService.ValidateExperimentSegment(
-		int64(settings.ProjectID),
-		settings.Config.Segmenters.Names,
-		expData.Segment,
-	)
+	defaultSegment, err := svc.getDefaultSegment(projectId)
 	if err != nil {
-		return nil, errors.Newf(errors.BadInput, err.Error())
+		return nil, err
 	}
 
-	// Get current experiment
-	curExperiment, err := svc.GetDBRecord(settings.ProjectID, models.ID(experimentId))
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
 	if err != nil {
 		return nil, err
 	}
+	return exp.Segment.WithDefaults(defaultSegment).ToRawSchema(segmenterTypes)
+}
 
-	// If new experiment is active, get other experiments active in the same time range
-	// and validate segment orthogonality
-	if expData.Status == models.ExperimentStatusActive {
-		err = svc.validateExperimentOrthogonalityInDuration(&experimentId, settings, expData.Segment, expData.Tier, expData.StartTime, expData.EndTime)
-		if err != nil {
-			return nil, err
-		}
-
-		// Check if the set of segmenters contains all the segments specified by the experiment
-		err = validateExperimentSegmentersExist(
-			curExperiment.Name,
-			expData.Segment,
-			utils.StringSliceToSet(settings.Config.Segmenters.Names),
-		)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	// Validate experiment type
-	if expData.Type != curExperiment.Type {
-		return nil, errors.Newf(errors.BadInput, "experiment type cannot be changed")
+// PreviewProtoMessage returns the serialized proto message of the experiment, as it would be carried by the create
+// and update messages published to the message queue, without publishing anything
+func (svc *experimentService) PreviewProtoMessage(projectId int64, experimentId int64) ([]byte, error) {
+	exp, err := svc.GetExperiment(projectId, experimentId)
+	if err != nil {
+		return nil, err
 	}
 
-	//  Copy current experiment's contents as experiment history
-	_, err = svc.services.ExperimentHistoryService.CreateExperimentHistory(curExperiment)
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
 	if err != nil {
 		return nil, err
 	}
-
-	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
+	protoExp, err := exp.ToProtoSchema(segmenterTypes)
 	if err != nil {
 		return nil, err
 	}
-	segmenterStorageSchema, err := expData.Segment.ToStorageSchema(segmenterTypes)
+	return proto.Marshal(protoExp)
+}
+
+// PreviewAssignmentDistribution returns the expected percentage of the population targeted by the experiment's segment
+// that each treatment receives, by the treatment names. For the A/B experiments and the randomised switchback
+// experiments, it is the treatment's traffic. For the cyclic switchback experiments, where no traffic is set, it is the
+// share of the experiment's duration that the treatment is in effect, as the treatments take turns in every interval.
+func (svc *experimentService) PreviewAssignmentDistribution(
+	projectId int64,
+	experimentId int64,
+) (map[string]float64, error) {
+	exp, err := svc.GetDBRecord(models.ID(projectId), models.ID(experimentId))
 	if err != nil {
 		return nil, err
 	}
-	newExperiment := &models.Experiment{
-		// Copy the ID and the fixed fields
-		ID:        curExperiment.ID,
-		ProjectID: curExperiment.ProjectID,
-		Name:      curExperiment.Name,
-		Type:      curExperiment.Type,
-		// Increment the version
-		Version: curExperiment.Version + 1,
-		// Add the new data
-		Description: expData.Description,
-		Interval:    expData.Interval,
-		Treatments:  expData.Treatments,
-		Segment:     segmenterStorageSchema,
-		Status:      expData.Status,
-		StartTime:   expData.StartTime,
-		Tier:        expData.Tier,
-		EndTime:     expData.EndTime,
-		UpdatedBy:   *expData.UpdatedBy,
+
+	distribution := map[string]float64{}
+	trafficSum := int32(0)
+	for _, treatment := range exp.Treatments {
+		if treatment.Traffic != nil {
+			trafficSum += *treatment.Traffic
+		}
+	}
+	if exp.Type != models.ExperimentTypeSwitchback || trafficSum != 0 {
+		for _, treatment := range exp.Treatments {
+			distribution[treatment.Name] = 0
+			if treatment.Traffic != nil && trafficSum != 0 {
+				distribution[treatment.Name] = 100 * float64(*treatment.Traffic) / float64(trafficSum)
+			}
+		}
+		return distribution, nil
 	}
 
-	// Validate the experiment against the project settings' treatment schema and validation url
-	err = svc.RunCustomValidation(
-		*newExperiment,
-		settings,
-		ValidationContext{CurrentData: curExperiment},
-		OperationTypeUpdate,
-	)
-	if err != nil {
-		return nil, errors.Newf(errors.BadInput, err.Error())
+	// The treatments take turns from the start time, in the order that they are defined, so each treatment is in
+	// effect for an equal number of the complete cycles through the treatments, plus one interval if it is among the
+	// treatments that the last, incomplete cycle goes through. The treatment that the experiment ends with is only in
+	// effect for the rest of the duration.
+	duration := exp.EndTime.Sub(exp.StartTime)
+	if exp.IntervalOrZero() <= 0 || duration <= 0 || len(exp.Treatments) == 0 {
+		return nil, errors.Newf(errors.BadInput,
+			"the assignment distribution of the switchback experiment %d cannot be computed without an interval "+
+				"and a duration", experimentId)
+	}
+	interval := time.Duration(exp.IntervalOrZero()) * models.SwitchbackIntervalUnit
+	numIntervals, numTreatments := exp.NumberOfSwitchbackPeriods(), int64(len(exp.Treatments))
+	for i, treatment := range exp.Treatments {
+		treatmentDuration := time.Duration(numIntervals/numTreatments) * interval
+		if int64(i) < numIntervals%numTreatments {
+			treatmentDuration += interval
+		} else if int64(i) == numIntervals%numTreatments {
+			treatmentDuration += duration % interval
+		}
+		distribution[treatment.Name] += 100 * float64(treatmentDuration) / float64(duration)
 	}
+	return distribution, nil
+}
 
-	// Update current experiment and save to DB
-	expDBRecord, err := svc.save(newExperiment)
-	if err != nil {
+// GetRawStoredSegment returns the segment column of the experiment as it is stored in the DB, i.e., in the storage
+// schema, without any conversion. This is a debug API, e.g., for troubleshooting the segment filters; the segment of
+// the experiment should otherwise be read through GetExperiment.
+func (svc *experimentService) GetRawStoredSegment(projectId int64, experimentId int64) (json.RawMessage, error) {
+	if _, err := svc.GetDBRecord(models.ID(projectId), models.ID(experimentId)); err != nil {
 		return nil, err
 	}
 
-	// Publish pubsub update message
-	protoExpResponse, err := expDBRecord.ToProtoSchema(segmenterTypes)
+	var segment []byte
+	err := svc.query().
+		Model(&models.Experiment{}).
+		Select("segment").
+		Where("project_id = ?", projectId).
+		Where("id = ?", experimentId).
+		Row().
+		Scan(&segment)
 	if err != nil {
-		return nil, err
+		return nil, errors.Newf(errors.Internal, err.Error())
 	}
-	err = svc.services.PubSubPublisherService.PublishExperimentMessage("update", protoExpResponse)
+	return segment, nil
+}
+
+func (svc *experimentService) CreateExperiment(
+	settings models.Settings,
+	expData CreateExperimentRequestBody,
+) (*models.Experiment, error) {
+	expData.Name = strings.TrimSpace(expData.Name)
+	// Apply the project's defaults to the tier and type that are left empty, before the request is validated, so that
+	// they are only required if the project has no defaults, and the explicitly given values are still validated
+	if settings.Config != nil {
+		if expData.Tier == "" {
+			expData.Tier = settings.Config.DefaultTier
+		}
+		if expData.Type == "" {
+			expData.Type = settings.Config.DefaultType
+		}
+	}
+
+	// Validate experiment data
+	err := svc.services.ValidationService.Validate(expData)
 	if err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+	if expData.UpdatedBy == nil {
+		return nil, errors.Newf(errors.BadInput, "updated_by is required")
+	}
+	if err = svc.validateProjectUnlocked(int64(settings.ProjectID)); err != nil {
 		return nil, err
 	}
+	// Normalize the start and end times to UTC, so that the stored time window is unambiguous
+	expData.StartTime, expData.EndTime = expData.StartTime.UTC(), expData.EndTime.UTC()
+	// Active experiments that have already ended are rejected, as they would never be served. Inactive ones are
+	// allowed, so that historical experiments can still be recorded.
+	if expData.Status == models.ExperimentStatusActive && expData.EndTime.Before(svc.clock.Now()) {
+		return nil, errors.Newf(errors.BadInput,
+			"cannot create an active experiment that has already ended at %s",
+			expData.EndTime.Format(time.RFC3339))
+	}
 
-	return expDBRecord, nil
-}
-
-func (svc *experimentService) EnableExperiment(settings models.Settings, experimentId int64) error {
-	// Get experiment
-	experiment, err := svc.GetDBRecord(settings.ProjectID, models.ID(experimentId))
+	// Validate Segmenter data
+	err = svc.services.SegmenterService.ValidateExperimentSegment(
+		int64(settings.ProjectID),
+		settings.Config.Segmenters.Names,
+		expData.Segment,
+	)
 	if err != nil {
-		return err
+		return nil, errors.Newf(errors.BadInput, err.Error())
 	}
 
-	// Experiment is already active
-	if experiment.Status == models.ExperimentStatusActive {
-		return errors.Newf(errors.BadInput, fmt.Sprintf("experiment id %d is already active", experimentId))
+	// If new experiment is active, check if the set of segmenters contains all the segments specified by the
+	// experiment. It is checked against the other experiments when it is saved.
+	if expData.Status == models.ExperimentStatusActive {
+		err = validateExperimentSegmentersExist(
+			expData.Name,
+			expData.Segment,
+			utils.StringSliceToSet(settings.Config.Segmenters.Names),
+		)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// Validate that the experiment has all the required segmenters activated
-	rawSegments, err := experiment.Segment.ToRawSchema(segmenterTypes)
+	segmenterStorageSchema, err := expData.Segment.ToStorageSchema(segmenterTypes)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// Check if the set of segmenters contains all the segments specified by the experiment
-	err = validateExperimentSegmentersExist(
-		experiment.Name,
-		rawSegments,
-		utils.StringSliceToSet(settings.Config.Segmenters.Names),
-	)
+	typedSegment, err := getTypedSegment(settings.Config, segmenterStorageSchema, segmenterTypes)
 	if err != nil {
-		return errors.Newf(
-			errors.BadInput,
-			fmt.Sprintf("Error validating segmenters required for enabling experiment: %s", err.Error()),
-		)
+		return nil, err
 	}
-
-	// Get other experiments active in the same time range and validate segment orthogonality
-	rawSegments, err = experiment.Segment.ToRawSchema(segmenterTypes)
+	// The treatments are stored and published with the project's treatment config defaults applied, as they are
+	// validated
+	treatments, err := applyTreatmentConfigDefaults(expData.Treatments, settings.Config)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	err = svc.validateExperimentOrthogonalityInDuration(&experimentId, settings,
-		rawSegments, experiment.Tier, experiment.StartTime, experiment.EndTime)
+	// Create the experiment record
+	experiment := &models.Experiment{
+		ProjectID:    settings.ProjectID,
+		Name:         expData.Name,
+		Description:  expData.Description,
+		Tier:         expData.Tier,
+		Type:         expData.Type,
+		Interval:     expData.Interval,
+		Treatments:   treatments,
+		Segment:      segmenterStorageSchema,
+		TypedSegment: typedSegment,
+		Status:       expData.Status,
+		StartTime:    expData.StartTime,
+		EndTime:      expData.EndTime,
+		UpdatedBy:    *expData.UpdatedBy,
+		MutexGroup:   expData.MutexGroup,
+		Recurrence:   expData.Recurrence,
+		Priority:     priorityOrDefault(expData.Priority, 0),
+		Shadow:       expData.Shadow,
+		Version:      1,
+	}
+	// A new recurring experiment is the first of its occurrences, unless specified otherwise
+	if experiment.Recurrence != nil && experiment.Recurrence.Occurrence == 0 {
+		recurrence := *experiment.Recurrence
+		recurrence.Occurrence = 1
+		experiment.Recurrence = &recurrence
+	}
+	err = validateOverrideTierSegment(experiment.Name, experiment.Tier, experiment.Segment)
 	if err != nil {
-		return err
+		return nil, errors.Newf(errors.BadInput, err.Error())
 	}
 
-	//  Copy current experiment's contents as experiment history
-	_, err = svc.services.ExperimentHistoryService.CreateExperimentHistory(experiment)
+	// Validate the experiment against the project settings' treatment schema and validation url
+	err = svc.RunCustomValidation(
+		*experiment,
+		settings,
+		ValidationContext{},
+		OperationTypeCreate,
+	)
 	if err != nil {
-		return err
+		return nil, errors.Newf(errors.BadInput, err.Error())
 	}
+	validationStatus := models.ExperimentValidationStatusPassed
+	experiment.LastValidationStatus = &validationStatus
 
-	// Update Experiment
-	experiment.Status = models.ExperimentStatusActive
-	expDBRecord, err := svc.save(experiment)
+	// Save to DB. An active experiment is checked against the project's other experiments and saved under the
+	// project's lock, as in EnableExperiment, so that the experiments activated concurrently see each other.
+	var expDBRecord *models.Experiment
+	if experiment.Status == models.ExperimentStatusActive {
+		err = svc.withProjectLock(settings.ProjectID, func(txSvc *experimentService) error {
+			err := txSvc.validateActivation(settings, nil, expData.Segment, experiment, true)
+			if err != nil {
+				return err
+			}
+			expDBRecord, err = txSvc.save(experiment)
+			return err
+		})
+	} else {
+		expDBRecord, err = svc.save(experiment)
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Convert to the format expected by the Message Queue
 	protoExpResponse, err := expDBRecord.ToProtoSchema(segmenterTypes)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = svc.services.PubSubPublisherService.PublishExperimentMessage("update", protoExpResponse)
+	err = svc.services.PubSubPublisherService.PublishExperimentMessage("create", protoExpResponse)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	svc.subscribers.publish(ExperimentEventOpCreate, expDBRecord)
 
-	return nil
+	return expDBRecord, nil
 }
 
-func (svc *experimentService) DisableExperiment(projectId int64, experimentId int64) error {
-	// Get experiment
-	experiment, err := svc.GetDBRecord(models.ID(projectId), models.ID(experimentId))
+func (svc *experimentService) UpdateExperiment(
+	settings models.Settings,
+	experimentId int64,
+	expData UpdateExperimentRequestBody,
+) (*models.Experiment, error) {
+	update, err := svc.validateUpdate(settings, experimentId, expData, false, failOnCheckFailure)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// Experiment is already inactive
-	if experiment.Status == models.ExperimentStatusInactive {
-		return errors.Newf(errors.BadInput, fmt.Sprintf("experiment id %d is already inactive", experimentId))
+	for _, warning := range update.warnings {
+		log.Printf("Warning: %s", warning)
 	}
+	curExperiment, newExperiment := update.current, update.updated
+	validationStatus := models.ExperimentValidationStatusPassed
+	newExperiment.LastValidationStatus = &validationStatus
 
-	//  Copy current experiment's contents as experiment history
-	_, err = svc.services.ExperimentHistoryService.CreateExperimentHistory(experiment)
-	if err != nil {
-		return err
+	// Update current experiment and save to DB, together with the current experiment's contents as experiment history.
+	// If the experiment is active after the update, it is checked against the project's other experiments and saved
+	// under the project's lock, as in CreateExperiment.
+	var expDBRecord *models.Experiment
+	if requiresOrthogonalityCheck(expData.Status) {
+		err = svc.withProjectLock(settings.ProjectID, func(txSvc *experimentService) error {
+			// Only an experiment that is being activated adds to the number of the project's running experiments
+			activating := curExperiment.Status != models.ExperimentStatusActive
+			err := txSvc.validateActivation(settings, &experimentId, expData.Segment, newExperiment, activating)
+			if err != nil {
+				return err
+			}
+			expDBRecord, err = txSvc.saveWithHistory(newExperiment, curExperiment)
+			return err
+		})
+	} else {
+		expDBRecord, err = svc.saveWithHistory(newExperiment, curExperiment)
 	}
-
-	// Update Experiment
-	experiment.Status = models.ExperimentStatusInactive
-	expDBRecord, err := svc.save(experiment)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Convert to the format expected by the Message Queue
-	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
-	if err != nil {
-		return err
-	}
-	protoExpResponse, err := expDBRecord.ToProtoSchema(segmenterTypes)
+	// Publish pubsub update message
+	protoExpResponse, err := expDBRecord.ToProtoSchema(update.segmenterTypes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	err = svc.services.PubSubPublisherService.PublishExperimentMessage("update", protoExpResponse)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	svc.subscribers.publish(ExperimentEventOpUpdate, expDBRecord)
 
-	return nil
+	return expDBRecord, nil
 }
 
-func (svc *experimentService) GetDBRecord(projectId models.ID, experimentId models.ID) (*models.Experiment, error) {
-	var exp models.Experiment
-	query := svc.query().
-		Where("project_id = ?", projectId).
-		Where("id = ?", experimentId).
-		First(&exp)
-	if err := query.Error; err != nil {
-		return nil, err
+// PreviewUpdate builds the experiment as it would be after the update and runs all the validations of UpdateExperiment
+// on it, without saving anything. The validations are all run, rather than stopping at the first failure, and their
+// outcomes are returned in the report, together with the diff of the current and the would-be experiment. An error is
+// only returned if the preview cannot be built, e.g., if the experiment does not exist.
+func (svc *experimentService) PreviewUpdate(
+	settings models.Settings,
+	experimentId int64,
+	expData UpdateExperimentRequestBody,
+) (*ExperimentDiff, ValidationReport, error) {
+	report := ValidationReport{Passed: true, Failures: map[ValidationCheck]string{}}
+	addFailure := func(check ValidationCheck, err error) error {
+		report.Passed = false
+		if _, ok := report.Failures[check]; !ok {
+			report.Failures[check] = err.Error()
+		}
+		return nil
 	}
-	return &exp, nil
-}
 
-func (svc *experimentService) query() *gorm.DB {
-	return svc.db
+	update, err := svc.validateUpdate(settings, experimentId, expData, true, addFailure)
+	if err != nil {
+		return nil, ValidationReport{}, err
+	}
+	report.Warnings = update.warnings
+
+	return getExperimentDiff(update.current, update.updated), report, nil
 }
 
-func (svc *experimentService) save(exp *models.Experiment) (*models.Experiment, error) {
-	if err := svc.query().Clauses(clause.OnConflict{
-		UpdateAll: true,
-	}).Create(exp).Error; err != nil {
-		return nil, err
-	}
-	return svc.GetDBRecord(exp.ProjectID, exp.ID)
+// experimentUpdate is an update of an experiment, as validated by validateUpdate
+type experimentUpdate struct {
+	current        *models.Experiment
+	updated        *models.Experiment
+	segmenterTypes map[string]schema.SegmenterType
+	warnings       []string
 }
 
-func (svc *experimentService) filterFieldValues(query *gorm.DB, params ListExperimentsParams) (*gorm.DB, error) {
-	if params.Fields != nil && len(*params.Fields) != 0 {
-		err := validateListExperimentFieldNames(*params.Fields)
-		if err != nil {
+// validateUpdate runs the validations of an update of the given experiment with the given data, and returns the
+// current experiment and the experiment as it would be after the update, along with the warnings of the checks that
+// passed. The failed checks are passed to onFailure, and the validation stops at the first failure for which it returns
+// an error. In a preview, the checks against the project's other experiments are run as well, and the warnings of the
+// segment are collected; otherwise, UpdateExperiment runs those checks under the project's lock.
+func (svc *experimentService) validateUpdate(
+	settings models.Settings,
+	experimentId int64,
+	expData UpdateExperimentRequestBody,
+	preview bool,
+	onFailure checkFailureHandler,
+) (*experimentUpdate, error) {
+	update := &experimentUpdate{}
+
+	// Validate experiment data
+	if err := svc.services.ValidationService.Validate(expData); err != nil {
+		if err = onFailure(ValidationCheckRequest, errors.Newf(errors.BadInput, err.Error())); err != nil {
 			return nil, err
 		}
-
-		// Stores a set of unique field names which are unique column names to be selected in the db query
-		fieldNamesSet := set.New()
-		for _, field := range *params.Fields {
-			fieldName := field
-			// Add ExperimentFieldStatus to the query because status_friendly does not exist in the db as a column
-			if field == models.ExperimentFieldStatusFriendly {
-				// Add ExperimentFieldStartTime and ExperimentFieldEndTime to the query as they are required for
-				// determining the statusFriendly field; these two fields may or may not be returned depending on
-				// what is actually specified in params.Fields
-				fieldNamesSet.Insert(string(models.ExperimentFieldStartTime))
-				fieldNamesSet.Insert(string(models.ExperimentFieldEndTime))
-
-				fieldName = models.ExperimentFieldStatus
-			}
-			//fieldNamesSet[string(fieldName)] = struct{}{}
-			fieldNamesSet.Insert(string(fieldName))
+	}
+	if expData.UpdatedBy == nil {
+		if err := onFailure(ValidationCheckRequest, errors.Newf(errors.BadInput, "updated_by is required")); err != nil {
+			return nil, err
 		}
-
-		// Retrieve a slice of unique strings from fieldNamesSet; query.Select only accepts []string
-		var fieldNames []string
-		fieldNamesSet.Do(func(fieldName interface{}) {
-			fieldNames = append(fieldNames, fmt.Sprint(fieldName))
-		})
-
-		query = query.Select(fieldNames)
 	}
-	return query, nil
-}
-
-func (svc *experimentService) filterExperimentStatusFriendly(query *gorm.DB, statusesFriendly []ExperimentStatusFriendly) *gorm.DB {
-	orPredicates := svc.query().Where("false") // start with false and build OR query dynamically
-	for _, statusFriendly := range statusesFriendly {
-		predicates := svc.query()
-		if statusFriendly == ExperimentStatusFriendlyDeactivated {
-			predicates = predicates.Where("status = ?", models.ExperimentStatusInactive)
-		} else {
-			predicates = predicates.Where("status = ?", models.ExperimentStatusActive)
-			switch statusFriendly {
-			case ExperimentStatusFriendlyScheduled:
-				predicates = predicates.Where("start_time > current_timestamp")
-			case ExperimentStatusFriendlyCompleted:
-				predicates = predicates.Where("end_time < current_timestamp")
-			default:
-				// Status Running - current time should be present in the experiment duration.
-				predicates = predicates.Where("tstzrange(start_time, end_time, '[)') @> tstzrange(current_timestamp, current_timestamp, '[]')")
-			}
+	if err := svc.validateProjectUnlocked(int64(settings.ProjectID)); err != nil {
+		if err = onFailure(ValidationCheckProjectUnlocked, err); err != nil {
+			return nil, err
 		}
-		orPredicates = orPredicates.Or(predicates)
 	}
-	return query.Where(orPredicates)
-}
+	// Normalize the start and end times to UTC, so that the stored time window is unambiguous
+	expData.StartTime, expData.EndTime = expData.StartTime.UTC(), expData.EndTime.UTC()
 
-func (svc *experimentService) filterStartEndTimeValues(query *gorm.DB, params ListExperimentsParams) (*gorm.DB, error) {
-	if params.StartTime != nil && !params.StartTime.IsZero() && (params.EndTime == nil || params.EndTime.IsZero()) {
-		return nil, errors.Newf(errors.BadInput, "end_time parameter must be supplied as well")
+	var segmenterNames []string
+	if settings.Config != nil {
+		segmenterNames = settings.Config.Segmenters.Names
 	}
-	if params.EndTime != nil && !params.EndTime.IsZero() && (params.StartTime == nil || params.StartTime.IsZero()) {
-		return nil, errors.Newf(errors.BadInput, "start_time parameter must be supplied as well")
-	}
-	if params.StartTime != nil && !params.StartTime.IsZero() && params.EndTime != nil && !params.EndTime.IsZero() {
-		// Find experiments that are at least partially running in this window.
-		if params.StartTime.Equal(*params.EndTime) {
-			// To filter active experiments at a given timestamp (such as current timestamp),
-			// it needs to be passed in for both the start and end time.
-			query = query.Where("tstzrange(start_time, end_time, '[)') @> tstzrange(?, ?, '[]')", params.StartTime, params.EndTime)
-		} else {
-			// One of the following should match:
-			// * the start_time parameter should fall within the experiment's [start and end) times
-			// * the end_time parameter should fall within the experiment's (start and end) times
-			// * the experiment starts and ends within the [start_time and end_time) duration
-			query = query.Where(
-				svc.query().
-					Where("tstzrange(start_time, end_time, '[)') @> tstzrange(?, ?, '[]')", params.StartTime, params.StartTime).
-					Or("tstzrange(start_time, end_time, '()') @> tstzrange(?, ?, '[]')", params.EndTime, params.EndTime).
-					Or("tstzrange(?, ?, '[]') @> tstzrange(start_time, end_time, '[)')", params.StartTime, params.EndTime),
-			)
+	err := svc.services.SegmenterService.ValidateExperimentSegment(
+		int64(settings.ProjectID),
+		segmenterNames,
+		expData.Segment,
+	)
+	if err != nil {
+		if err = onFailure(ValidationCheckSegment, errors.Newf(errors.BadInput, err.Error())); err != nil {
+			return nil, err
+		}
+	} else if preview {
+		warnings, err := svc.services.SegmenterService.GetExperimentSegmentWarnings(
+			int64(settings.ProjectID),
+			segmenterNames,
+			expData.Segment,
+		)
+		if err != nil {
+			return nil, err
 		}
+		update.warnings = append(update.warnings, warnings...)
+	}
+
+	// Get current experiment
+	curExperiment, err := svc.GetDBRecord(settings.ProjectID, models.ID(experimentId))
+	if err != nil {
+		return nil, err
+	}
+	update.current = curExperiment
+
+	// Check if the set of segmenters contains all the segments specified by the experiment, regardless of its status,
+	// so that an inactive experiment cannot be saved with a segment that would prevent it from being enabled
+	err = validateExperimentSegmentersExist(
+		curExperiment.Name,
+		expData.Segment,
+		utils.StringSliceToSet(segmenterNames),
+	)
+	if err != nil {
+		if err = onFailure(ValidationCheckSegmentersExist, errors.Newf(errors.BadInput, err.Error())); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate experiment type
+	if expData.Type != curExperiment.Type {
+		err = onFailure(ValidationCheckRequest, errors.Newf(errors.BadInput, "experiment type cannot be changed"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = validateDraftStatusTransition(experimentId, curExperiment.Status, expData.Status); err != nil {
+		if err = onFailure(ValidationCheckRequest, err); err != nil {
+			return nil, err
+		}
+	}
+
+	update.segmenterTypes, err = svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
+	if err != nil {
+		return nil, err
+	}
+	// The experiment is still built if the missing updated_by did not stop the validation
+	if expData.UpdatedBy == nil {
+		expData.UpdatedBy = &curExperiment.UpdatedBy
+	}
+	newExperiment, err := buildUpdatedExperiment(curExperiment, expData, settings.Config, update.segmenterTypes)
+	if err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+	update.updated = newExperiment
+	err = validateOverrideTierSegment(newExperiment.Name, newExperiment.Tier, newExperiment.Segment)
+	if err != nil {
+		if err = onFailure(ValidationCheckSegment, errors.Newf(errors.BadInput, err.Error())); err != nil {
+			return nil, err
+		}
+	}
+
+	if preview && requiresOrthogonalityCheck(expData.Status) {
+		activating := curExperiment.Status != models.ExperimentStatusActive
+		err = svc.runActivationChecks(settings, &experimentId, expData.Segment, newExperiment, activating, onFailure)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate the experiment against the project settings' treatment schema and validation url
+	warnings, err := svc.runCustomValidation(
+		*newExperiment,
+		settings,
+		ValidationContext{CurrentData: curExperiment},
+		OperationTypeUpdate,
+	)
+	if err != nil {
+		if err = onFailure(ValidationCheckCustomValidation, errors.Newf(errors.BadInput, err.Error())); err != nil {
+			return nil, err
+		}
+	}
+	update.warnings = append(update.warnings, warnings...)
+
+	return update, nil
+}
+
+// buildUpdatedExperiment returns the experiment as it would be after the update with the given data, with its version
+// incremented. The ID and the fixed fields are copied from the current experiment.
+func buildUpdatedExperiment(
+	curExperiment *models.Experiment,
+	expData UpdateExperimentRequestBody,
+	config *models.ExperimentationConfig,
+	segmenterTypes map[string]schema.SegmenterType,
+) (*models.Experiment, error) {
+	segmenterStorageSchema, err := expData.Segment.ToStorageSchema(segmenterTypes)
+	if err != nil {
+		return nil, err
+	}
+	typedSegment, err := getTypedSegment(config, segmenterStorageSchema, segmenterTypes)
+	if err != nil {
+		return nil, err
+	}
+	// The treatments are stored and published with the project's treatment config defaults applied, as in
+	// CreateExperiment
+	treatments, err := applyTreatmentConfigDefaults(expData.Treatments, config)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Experiment{
+		// Copy the ID and the fixed fields
+		ID:        curExperiment.ID,
+		ProjectID: curExperiment.ProjectID,
+		Name:      curExperiment.Name,
+		Type:      curExperiment.Type,
+		// Increment the version
+		Version: curExperiment.Version + 1,
+		// Add the new data
+		Description:  expData.Description,
+		Interval:     expData.Interval,
+		Treatments:   treatments,
+		Segment:      segmenterStorageSchema,
+		TypedSegment: typedSegment,
+		Status:       expData.Status,
+		StartTime:    expData.StartTime,
+		Tier:         expData.Tier,
+		EndTime:      expData.EndTime,
+		UpdatedBy:    *expData.UpdatedBy,
+		MutexGroup:   expData.MutexGroup,
+		// The priority is kept if it is not given, e.g., by the clients that are not aware of it
+		Priority: priorityOrDefault(expData.Priority, curExperiment.Priority),
+		// Likewise, the experiment stays a shadow experiment, or not, if it is not given
+		Shadow: shadowOrDefault(expData.Shadow, curExperiment.Shadow),
+		// The recurrence is not updated, only cancelled
+		Recurrence: curExperiment.Recurrence,
+	}, nil
+}
+
+// getExperimentDiff returns the changes of the mutable fields of the experiment, from the current to the new version.
+// The version itself is not compared. Unset and empty segments are considered the same.
+func getExperimentDiff(curExperiment *models.Experiment, newExperiment *models.Experiment) *ExperimentDiff {
+	diff := &ExperimentDiff{
+		Fields:     map[string]FieldChange{},
+		Treatments: curExperiment.Treatments.Diff(newExperiment.Treatments),
+	}
+	addChange := func(field string, from interface{}, to interface{}, isSame bool) {
+		if !isSame {
+			diff.Fields[field] = FieldChange{From: from, To: to}
+		}
+	}
+
+	addChange("description", curExperiment.Description, newExperiment.Description,
+		reflect.DeepEqual(curExperiment.Description, newExperiment.Description))
+	addChange("interval", curExperiment.Interval, newExperiment.Interval,
+		reflect.DeepEqual(curExperiment.Interval, newExperiment.Interval))
+	addChange("tier", curExperiment.Tier, newExperiment.Tier, curExperiment.Tier == newExperiment.Tier)
+	addChange("segment", curExperiment.Segment, newExperiment.Segment,
+		(len(curExperiment.Segment) == 0 && len(newExperiment.Segment) == 0) ||
+			reflect.DeepEqual(curExperiment.Segment, newExperiment.Segment))
+	addChange("status", curExperiment.Status, newExperiment.Status, curExperiment.Status == newExperiment.Status)
+	addChange("start_time", curExperiment.StartTime, newExperiment.StartTime,
+		curExperiment.StartTime.Equal(newExperiment.StartTime))
+	addChange("end_time", curExperiment.EndTime, newExperiment.EndTime,
+		curExperiment.EndTime.Equal(newExperiment.EndTime))
+	addChange("updated_by", curExperiment.UpdatedBy, newExperiment.UpdatedBy,
+		curExperiment.UpdatedBy == newExperiment.UpdatedBy)
+	addChange("mutex_group", curExperiment.MutexGroup, newExperiment.MutexGroup,
+		reflect.DeepEqual(curExperiment.MutexGroup, newExperiment.MutexGroup))
+	addChange("priority", curExperiment.Priority, newExperiment.Priority,
+		curExperiment.Priority == newExperiment.Priority)
+	addChange("shadow", curExperiment.Shadow, newExperiment.Shadow, curExperiment.Shadow == newExperiment.Shadow)
+	return diff
+}
+
+// priorityOrDefault returns the given priority, or the default priority if it is not given
+func priorityOrDefault(priority *int32, defaultPriority int32) int32 {
+	if priority == nil {
+		return defaultPriority
+	}
+	return *priority
+}
+
+// shadowOrDefault returns whether the experiment is a shadow experiment as given, or the default if it is not given
+func shadowOrDefault(shadow *bool, defaultShadow bool) bool {
+	if shadow == nil {
+		return defaultShadow
+	}
+	return *shadow
+}
+
+// AddTreatments appends the given treatments to those of the experiment, e.g., to expand an A/B experiment to an A/B/n
+// experiment. The treatment names must not collide with the existing ones. The experiment is otherwise unchanged, and
+// is updated as in UpdateExperiment, so the full set of treatments is validated, the history is written, the version is
+// incremented and the update is published. The update is attributed to the last person/job that updated the experiment.
+func (svc *experimentService) AddTreatments(
+	settings models.Settings,
+	experimentId int64,
+	treatments models.ExperimentTreatments,
+) (*models.Experiment, error) {
+	curExperiment, err := svc.GetDBRecord(settings.ProjectID, models.ID(experimentId))
+	if err != nil {
+		return nil, err
+	}
+
+	treatmentNames := set.New()
+	for _, treatment := range curExperiment.Treatments {
+		treatmentNames.Insert(treatment.Name)
+	}
+	for _, treatment := range treatments {
+		if treatmentNames.Has(treatment.Name) {
+			return nil, errors.Newf(errors.BadInput, "treatment %s already exists in experiment %s",
+				treatment.Name, curExperiment.Name)
+		}
+		treatmentNames.Insert(treatment.Name)
+	}
+
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
+	if err != nil {
+		return nil, err
+	}
+	rawSegment, err := curExperiment.Segment.ToRawSchema(segmenterTypes)
+	if err != nil {
+		return nil, err
+	}
+	newTreatments := append(append(models.ExperimentTreatments{}, curExperiment.Treatments...), treatments...)
+
+	return svc.UpdateExperiment(settings, experimentId, UpdateExperimentRequestBody{
+		Description: curExperiment.Description,
+		EndTime:     curExperiment.EndTime,
+		Interval:    curExperiment.Interval,
+		MutexGroup:  curExperiment.MutexGroup,
+		Priority:    &curExperiment.Priority,
+		Segment:     rawSegment,
+		Shadow:      &curExperiment.Shadow,
+		StartTime:   curExperiment.StartTime,
+		Status:      curExperiment.Status,
+		Treatments:  newTreatments,
+		Tier:        curExperiment.Tier,
+		Type:        curExperiment.Type,
+		UpdatedBy:   &curExperiment.UpdatedBy,
+	})
+}
+
+func (svc *experimentService) EnableExperiment(settings models.Settings, experimentId int64) error {
+	if err := svc.validateProjectUnlocked(int64(settings.ProjectID)); err != nil {
+		return err
+	}
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
+	if err != nil {
+		return err
+	}
+
+	err = svc.withProjectLock(settings.ProjectID, func(txSvc *experimentService) error {
+		curExperiment, err := txSvc.enableExperiment(settings, experimentId, segmenterTypes)
+		if err != nil {
+			return err
+		}
+		//  Copy the experiment's contents before the update as experiment history
+		_, err = svc.services.ExperimentHistoryService.WithTransaction(txSvc.query()).CreateExperimentHistory(curExperiment)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return svc.publishExperimentChange(settings.ProjectID, models.ID(experimentId), segmenterTypes, ExperimentEventOpEnable)
+}
+
+// enableExperiment validates and enables the inactive experiment, and returns its contents before the update, for its
+// history to be written. It is meant to be run in a transaction that holds the project's lock, as in EnableExperiment,
+// so that the orthogonality checks see the experiments enabled concurrently. The experiment is only updated if it is
+// still inactive, and the updated row stays locked until the transaction completes, so that the concurrent calls see
+// the experiment as active and neither write the history nor publish again.
+func (svc *experimentService) enableExperiment(
+	settings models.Settings,
+	experimentId int64,
+	segmenterTypes map[string]schema.SegmenterType,
+) (*models.Experiment, error) {
+	// Get experiment
+	experiment, err := svc.GetDBRecord(settings.ProjectID, models.ID(experimentId))
+	if err != nil {
+		return nil, err
+	}
+
+	// Experiment is already active
+	if experiment.Status == models.ExperimentStatusActive {
+		return nil, errors.Newf(errors.BadInput, fmt.Sprintf("experiment id %d is already active", experimentId))
+	}
+	if experiment.Status == models.ExperimentStatusDraft {
+		return nil, errors.Newf(errors.BadInput, "experiment id %d is a draft and must be promoted first", experimentId)
+	}
+
+	// Validate that the experiment has all the required segmenters activated
+	rawSegments, err := experiment.Segment.ToRawSchema(segmenterTypes)
+	if err != nil {
+		return nil, err
+	}
+	// Check if the set of segmenters contains all the segments specified by the experiment
+	err = validateExperimentSegmentersExist(
+		experiment.Name,
+		rawSegments,
+		utils.StringSliceToSet(settings.Config.Segmenters.Names),
+	)
+	if err != nil {
+		return nil, errors.Newf(
+			errors.BadInput,
+			fmt.Sprintf("Error validating segmenters required for enabling experiment: %s", err.Error()),
+		)
+	}
+
+	// Update Experiment, only if it is still inactive
+	result := svc.query().Model(&models.Experiment{}).
+		Where("project_id = ?", settings.ProjectID).
+		Where("id = ?", experimentId).
+		Where("status = ?", models.ExperimentStatusInactive).
+		Update("status", models.ExperimentStatusActive)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.Newf(errors.BadInput, fmt.Sprintf("experiment id %d is already active", experimentId))
+	}
+
+	err = svc.validateActivation(settings, &experimentId, rawSegments, experiment, true)
+	if err != nil {
+		return nil, err
+	}
+	return experiment, nil
+}
+
+// GetActiveExperimentLimitWarning returns a warning if the number of the project's running experiments exceeds the
+// project's soft maximum, e.g., to be surfaced after an experiment is created or enabled, and an empty string otherwise
+func (svc *experimentService) GetActiveExperimentLimitWarning(settings models.Settings) (string, error) {
+	if settings.Config == nil || settings.Config.SoftMaxActiveExperiments <= 0 {
+		return "", nil
+	}
+	count, err := svc.countRunningExperiments(int64(settings.ProjectID), nil)
+	if err != nil {
+		return "", err
+	}
+	if count > int64(settings.Config.SoftMaxActiveExperiments) {
+		return fmt.Sprintf("project %d has %d running experiments, exceeding the recommended maximum of %d",
+			settings.ProjectID, count, settings.Config.SoftMaxActiveExperiments), nil
+	}
+	return "", nil
+}
+
+func (svc *experimentService) DisableExperiment(projectId int64, experimentId int64) error {
+	if err := svc.validateProjectUnlocked(projectId); err != nil {
+		return err
+	}
+
+	err := svc.query().Transaction(func(tx *gorm.DB) error {
+		curExperiment, err := svc.withTransaction(tx).disableExperiment(projectId, experimentId)
+		if err != nil {
+			return err
+		}
+		//  Copy the experiment's contents before the update as experiment history
+		_, err = svc.services.ExperimentHistoryService.WithTransaction(tx).CreateExperimentHistory(curExperiment)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Convert to the format expected by the Message Queue
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return err
+	}
+	return svc.publishExperimentChange(models.ID(projectId), models.ID(experimentId), segmenterTypes,
+		ExperimentEventOpDisable)
+}
+
+// disableExperiment validates and disables the active experiment, and returns its contents before the update, for its
+// history to be written. It is meant to be run in a transaction, as in DisableExperiment. The experiment is only
+// updated if it is still active, and the updated row stays locked until the transaction completes, so that the
+// concurrent calls see the experiment as inactive and neither write the history nor publish again.
+func (svc *experimentService) disableExperiment(projectId int64, experimentId int64) (*models.Experiment, error) {
+	// Get experiment
+	experiment, err := svc.GetDBRecord(models.ID(projectId), models.ID(experimentId))
+	if err != nil {
+		return nil, err
+	}
+
+	// Experiment is already inactive
+	if experiment.Status == models.ExperimentStatusInactive {
+		return nil, errors.Newf(errors.BadInput, fmt.Sprintf("experiment id %d is already inactive", experimentId))
+	}
+	if experiment.Status == models.ExperimentStatusDraft {
+		return nil, errors.Newf(errors.BadInput, "experiment id %d is a draft and must be promoted first", experimentId)
+	}
+
+	// Check that no active override experiment is left without an underlying default experiment
+	err = svc.validateNoOrphanedOverrides(experiment)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update Experiment, only if it is still active
+	result := svc.query().Model(&models.Experiment{}).
+		Where("project_id = ?", projectId).
+		Where("id = ?", experimentId).
+		Where("status = ?", models.ExperimentStatusActive).
+		Update("status", models.ExperimentStatusInactive)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.Newf(errors.BadInput, fmt.Sprintf("experiment id %d is already inactive", experimentId))
+	}
+	return experiment, nil
+}
+
+// publishExperimentChange publishes the update message of the stored experiment, and the event of the given operation
+// to the subscribers
+func (svc *experimentService) publishExperimentChange(
+	projectId models.ID,
+	experimentId models.ID,
+	segmenterTypes map[string]schema.SegmenterType,
+	op ExperimentEventOp,
+) error {
+	expDBRecord, err := svc.GetDBRecord(projectId, experimentId)
+	if err != nil {
+		return err
+	}
+	if err = svc.republishExperiment(expDBRecord, segmenterTypes); err != nil {
+		return err
+	}
+	svc.subscribers.publish(op, expDBRecord)
+	return nil
+}
+
+// PromoteExperiment turns a draft experiment into an inactive experiment, which can then be enabled. As drafts may be
+// saved incrementally, the segment and the custom validations are run again on the experiment before it is promoted.
+func (svc *experimentService) PromoteExperiment(settings models.Settings, experimentId int64) (*models.Experiment, error) {
+	if err := svc.validateProjectUnlocked(int64(settings.ProjectID)); err != nil {
+		return nil, err
+	}
+
+	curExperiment, err := svc.GetDBRecord(settings.ProjectID, models.ID(experimentId))
+	if err != nil {
+		return nil, err
+	}
+	if curExperiment.Status != models.ExperimentStatusDraft {
+		return nil, errors.Newf(errors.BadInput, "experiment id %d is not a draft", experimentId)
+	}
+
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
+	if err != nil {
+		return nil, err
+	}
+	rawSegment, err := curExperiment.Segment.ToRawSchema(segmenterTypes)
+	if err != nil {
+		return nil, err
+	}
+	err = svc.services.SegmenterService.ValidateExperimentSegment(
+		int64(settings.ProjectID),
+		settings.Config.Segmenters.Names,
+		rawSegment,
+	)
+	if err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+	err = validateExperimentSegmentersExist(
+		curExperiment.Name,
+		rawSegment,
+		utils.StringSliceToSet(settings.Config.Segmenters.Names),
+	)
+	if err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+
+	newExperiment := *curExperiment
+	newExperiment.Status = models.ExperimentStatusInactive
+	newExperiment.Version += 1
+	err = svc.RunCustomValidation(
+		newExperiment,
+		settings,
+		ValidationContext{CurrentData: curExperiment},
+		OperationTypeUpdate,
+	)
+	if err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+	validationStatus := models.ExperimentValidationStatusPassed
+	newExperiment.LastValidationStatus = &validationStatus
+
+	// Save the promoted experiment, together with the draft's contents as experiment history
+	expDBRecord, err := svc.saveWithHistory(&newExperiment, curExperiment)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish pubsub update message
+	protoExpResponse, err := expDBRecord.ToProtoSchema(segmenterTypes)
+	if err != nil {
+		return nil, err
+	}
+	err = svc.services.PubSubPublisherService.PublishExperimentMessage("update", protoExpResponse)
+	if err != nil {
+		return nil, err
+	}
+	svc.subscribers.publish(ExperimentEventOpUpdate, expDBRecord)
+
+	return expDBRecord, nil
+}
+
+// BulkUpdateTier moves the given experiments to the new tier, one at a time. Active experiments are checked for
+// orthogonality, mutex groups and switchback intervals against the other active experiments in the new tier, including
+// those moved earlier in the same call. The experiments are moved in a single transaction that holds the project's
+// lock, with each move rolled back on its own if it fails, and the histories of the moved experiments are written
+// together.
+// The outcome for each experiment is reported in the result; an error is only returned if the operation as a whole
+// cannot proceed.
+func (svc *experimentService) BulkUpdateTier(
+	settings models.Settings,
+	experimentIds []int64,
+	newTier models.ExperimentTier,
+) (BulkResult, error) {
+	result := BulkResult{Succeeded: []int64{}, Failed: map[int64]string{}}
+	if newTier != models.ExperimentTierDefault && newTier != models.ExperimentTierOverride {
+		return result, errors.Newf(errors.BadInput, "unknown experiment tier: %s", newTier)
+	}
+	if err := svc.validateProjectUnlocked(int64(settings.ProjectID)); err != nil {
+		return result, err
+	}
+
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
+	if err != nil {
+		return result, err
+	}
+
+	curExperiments := []*models.Experiment{}
+	err = svc.withProjectLock(settings.ProjectID, func(txSvc *experimentService) error {
+		for _, experimentId := range experimentIds {
+			var curExperiment *models.Experiment
+			err := txSvc.withSavepoint(func(itemSvc *experimentService) error {
+				var err error
+				curExperiment, err = itemSvc.updateExperimentTier(settings, experimentId, newTier, segmenterTypes)
+				return err
+			})
+			if err != nil {
+				result.Failed[experimentId] = err.Error()
+				continue
+			}
+			result.Succeeded = append(result.Succeeded, experimentId)
+			if curExperiment != nil {
+				curExperiments = append(curExperiments, curExperiment)
+			}
+		}
+		//  Copy the experiments' contents before the update as experiment histories
+		return svc.services.ExperimentHistoryService.WithTransaction(txSvc.query()).CreateExperimentHistories(curExperiments)
+	})
+	if err != nil {
+		return BulkResult{Succeeded: []int64{}, Failed: map[int64]string{}}, err
+	}
+
+	for _, curExperiment := range curExperiments {
+		err = svc.publishExperimentChange(settings.ProjectID, curExperiment.ID, segmenterTypes, ExperimentEventOpUpdate)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// updateExperimentTier validates and saves the experiment in the new tier, and returns its contents before the update,
+// for its history to be written, or nil if it is already in the new tier. It is meant to be run in a transaction that
+// holds the project's lock, as in BulkUpdateTier, so that the experiments moved concurrently see each other.
+func (svc *experimentService) updateExperimentTier(
+	settings models.Settings,
+	experimentId int64,
+	newTier models.ExperimentTier,
+	segmenterTypes map[string]schema.SegmenterType,
+) (*models.Experiment, error) {
+	experiment, err := svc.GetDBRecord(settings.ProjectID, models.ID(experimentId))
+	if err != nil {
+		return nil, err
+	}
+	if experiment.Tier == newTier {
+		return nil, nil
+	}
+	if err = validateOverrideTierSegment(experiment.Name, newTier, experiment.Segment); err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+
+	// Validate the experiment before its history is written, as it may have been saved before the model was validated
+	if err = experiment.Validate(); err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+
+	newExperiment := *experiment
+	newExperiment.Tier = newTier
+	newExperiment.Version += 1
+
+	// An active experiment is checked against the project's other active experiments in the new tier
+	if experiment.Status == models.ExperimentStatusActive {
+		rawSegment, err := experiment.Segment.ToRawSchema(segmenterTypes)
+		if err != nil {
+			return nil, err
+		}
+		// Moving an experiment does not add to the number of the project's running experiments
+		err = svc.validateActivation(settings, &experimentId, rawSegment, &newExperiment, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err = svc.save(&newExperiment); err != nil {
+		return nil, err
+	}
+	return experiment, nil
+}
+
+// SwapExperimentSchedules trades the time windows of the two experiments. The active experiments are validated for
+// orthogonality, mutex groups, switchback intervals and, if the swap starts them running, the active experiment limit,
+// against the active experiments as they would be after the swap, so that the two experiments are not checked against
+// each other's old windows. Both experiments are saved in a single transaction, with their histories written and their
+// versions incremented, and an update is published for each of them.
+func (svc *experimentService) SwapExperimentSchedules(
+	settings models.Settings,
+	experimentIdA int64,
+	experimentIdB int64,
+) (*models.Experiment, *models.Experiment, error) {
+	if experimentIdA == experimentIdB {
+		return nil, nil, errors.Newf(errors.BadInput, "cannot swap the schedule of experiment %d with itself", experimentIdA)
+	}
+	if err := svc.validateProjectUnlocked(int64(settings.ProjectID)); err != nil {
+		return nil, nil, err
+	}
+
+	curExperiments := []*models.Experiment{}
+	swappedExperiments := []*models.Experiment{}
+	for _, experimentId := range []int64{experimentIdA, experimentIdB} {
+		experiment, err := svc.GetDBRecord(settings.ProjectID, models.ID(experimentId))
+		if err != nil {
+			return nil, nil, err
+		}
+		swappedExperiment := *experiment
+		swappedExperiment.Version += 1
+		curExperiments = append(curExperiments, experiment)
+		swappedExperiments = append(swappedExperiments, &swappedExperiment)
+	}
+	swappedExperiments[0].StartTime, swappedExperiments[0].EndTime = curExperiments[1].StartTime, curExperiments[1].EndTime
+	swappedExperiments[1].StartTime, swappedExperiments[1].EndTime = curExperiments[0].StartTime, curExperiments[0].EndTime
+
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, experiment := range swappedExperiments {
+		if err = experiment.Validate(); err != nil {
+			return nil, nil, errors.Newf(errors.BadInput, err.Error())
+		}
+	}
+
+	// Save both experiments, with their histories, or neither. The active experiments are checked after they are
+	// saved in the transaction, so that they are checked against each other's new windows rather than the old ones.
+	now := svc.clock.Now()
+	err = svc.withProjectLock(settings.ProjectID, func(txSvc *experimentService) error {
+		//  Copy the current experiments' contents as experiment history
+		err := svc.services.ExperimentHistoryService.WithTransaction(txSvc.query()).
+			CreateExperimentHistories(curExperiments)
+		if err != nil {
+			return err
+		}
+		for _, experiment := range swappedExperiments {
+			if err := txSvc.query().Clauses(clause.OnConflict{UpdateAll: true}).Create(experiment).Error; err != nil {
+				return err
+			}
+		}
+
+		for i, experiment := range swappedExperiments {
+			if experiment.Status != models.ExperimentStatusActive {
+				continue
+			}
+			rawSegment, err := experiment.Segment.ToRawSchema(segmenterTypes)
+			if err != nil {
+				return err
+			}
+			// The experiment is counted against the active experiment limit if the swap starts it running
+			curExperiment := curExperiments[i]
+			activating := now.Before(curExperiment.StartTime) || !now.Before(curExperiment.EndTime)
+			experimentId := experiment.ID.ToApiSchema()
+			err = txSvc.validateActivation(settings, &experimentId, rawSegment, experiment, activating)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Publish pubsub update messages
+	expDBRecords := []*models.Experiment{}
+	for _, experiment := range swappedExperiments {
+		expDBRecord, err := svc.GetDBRecord(experiment.ProjectID, experiment.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = svc.republishExperiment(expDBRecord, segmenterTypes); err != nil {
+			return nil, nil, err
+		}
+		svc.subscribers.publish(ExperimentEventOpUpdate, expDBRecord)
+		expDBRecords = append(expDBRecords, expDBRecord)
+	}
+
+	return expDBRecords[0], expDBRecords[1], nil
+}
+
+// SweepRecurringExperiments creates the next occurrence of each of the project's recurring experiments that are active
+// and have completed. The next occurrence is validated as an experiment being created, and the recurrence is handed
+// over to it, so that the completed experiment is not swept again. The outcome for each completed experiment is
+// reported in the result; those that failed are retried by the next sweep.
+func (svc *experimentService) SweepRecurringExperiments(settings models.Settings) (BulkResult, error) {
+	result := BulkResult{Succeeded: []int64{}, Failed: map[int64]string{}}
+	if err := svc.validateProjectUnlocked(int64(settings.ProjectID)); err != nil {
+		return result, err
+	}
+
+	var exps []*models.Experiment
+	err := svc.query().
+		Where("project_id = ?", settings.ProjectID).
+		Where("status = ?", models.ExperimentStatusActive).
+		Where("recurrence IS NOT NULL").
+		Where("end_time <= ?", svc.clock.Now()).
+		Order("id").
+		Find(&exps).Error
+	if err != nil {
+		return result, err
+	}
+
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
+	if err != nil {
+		return result, err
+	}
+	for _, exp := range exps {
+		if !exp.Recurrence.HasNextOccurrence() {
+			continue
+		}
+		if err := svc.createNextOccurrence(settings, exp, segmenterTypes); err != nil {
+			result.Failed[exp.ID.ToApiSchema()] = err.Error()
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, exp.ID.ToApiSchema())
+	}
+
+	return result, nil
+}
+
+// createNextOccurrence validates and creates the next occurrence of the given experiment, and clears the recurrence of
+// the given experiment in the same transaction. The next occurrence is run through the custom validation, and checked
+// against the project's other experiments and saved under the project's lock, as in CreateExperiment. If the
+// recurrence has been cleared concurrently, e.g., by another sweep or a cancellation, nothing is created.
+func (svc *experimentService) createNextOccurrence(
+	settings models.Settings,
+	experiment *models.Experiment,
+	segmenterTypes map[string]schema.SegmenterType,
+) error {
+	next := experiment.NextOccurrence()
+	if err := next.Validate(); err != nil {
+		return errors.Newf(errors.BadInput, err.Error())
+	}
+	rawSegment, err := next.Segment.ToRawSchema(segmenterTypes)
+	if err != nil {
+		return err
+	}
+
+	// Validate the next occurrence against the project settings' treatment schema and validation url
+	err = svc.RunCustomValidation(*next, settings, ValidationContext{}, OperationTypeCreate)
+	if err != nil {
+		return errors.Newf(errors.BadInput, err.Error())
+	}
+	validationStatus := models.ExperimentValidationStatusPassed
+	next.LastValidationStatus = &validationStatus
+
+	created := false
+	err = svc.withProjectLock(settings.ProjectID, func(txSvc *experimentService) error {
+		if err := txSvc.validateActivation(settings, nil, rawSegment, next, true); err != nil {
+			return err
+		}
+
+		result := txSvc.query().Model(&models.Experiment{}).
+			Where("project_id = ?", experiment.ProjectID).
+			Where("id = ?", experiment.ID).
+			Where("recurrence IS NOT NULL").
+			Update("recurrence", nil)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		created = true
+		return txSvc.query().Create(next).Error
+	})
+	if err != nil || !created {
+		return err
+	}
+
+	expDBRecord, err := svc.GetDBRecord(next.ProjectID, next.ID)
+	if err != nil {
+		return err
+	}
+	protoExpResponse, err := expDBRecord.ToProtoSchema(segmenterTypes)
+	if err != nil {
+		return err
+	}
+	err = svc.services.PubSubPublisherService.PublishExperimentMessage("create", protoExpResponse)
+	if err != nil {
+		return err
+	}
+	svc.subscribers.publish(ExperimentEventOpCreate, expDBRecord)
+	return nil
+}
+
+// CancelRecurrence stops the experiment from recurring, so that no further occurrences are created by the sweep. The
+// experiment is saved as a new version without its recurrence, with its history written and an update published; the
+// occurrences that have already been created are not changed.
+func (svc *experimentService) CancelRecurrence(projectId int64, experimentId int64) error {
+	if err := svc.validateProjectUnlocked(projectId); err != nil {
+		return err
+	}
+	curExperiment, err := svc.GetDBRecord(models.ID(projectId), models.ID(experimentId))
+	if err != nil {
+		return err
+	}
+	if curExperiment.Recurrence == nil {
+		return errors.Newf(errors.BadInput, "experiment id %d is not the latest occurrence of a recurring experiment",
+			experimentId)
+	}
+
+	newExperiment := *curExperiment
+	newExperiment.Recurrence = nil
+	newExperiment.Version += 1
+	expDBRecord, err := svc.saveWithHistory(&newExperiment, curExperiment)
+	if err != nil {
+		return err
+	}
+
+	// Publish pubsub update message
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return err
+	}
+	if err = svc.republishExperiment(expDBRecord, segmenterTypes); err != nil {
+		return err
+	}
+	svc.subscribers.publish(ExperimentEventOpUpdate, expDBRecord)
+
+	return nil
+}
+
+// RepublishExperiment publishes the current state of the experiment as an update message, e.g., for the consumers that
+// missed a message. The experiment itself is left untouched.
+func (svc *experimentService) RepublishExperiment(projectId int64, experimentId int64) error {
+	experiment, err := svc.GetExperiment(projectId, experimentId)
+	if err != nil {
+		return err
+	}
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return err
+	}
+	return svc.republishExperiment(experiment, segmenterTypes)
+}
+
+// RepublishAllExperiments publishes the current state of all the experiments of the project as update messages, in
+// the order of their ids. It stops at the first experiment that cannot be published.
+func (svc *experimentService) RepublishAllExperiments(projectId int64) error {
+	var experiments []*models.Experiment
+	err := svc.query().Where("project_id = ?", projectId).Order("id").Find(&experiments).Error
+	if err != nil {
+		return err
+	}
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return err
+	}
+
+	for _, experiment := range experiments {
+		if err = svc.republishExperiment(experiment, segmenterTypes); err != nil {
+			return errors.Wrapf(err, "failed to republish experiment %d", experiment.ID)
+		}
+	}
+	return nil
+}
+
+func (svc *experimentService) republishExperiment(
+	experiment *models.Experiment,
+	segmenterTypes map[string]schema.SegmenterType,
+) error {
+	protoExpResponse, err := experiment.ToProtoSchema(segmenterTypes)
+	if err != nil {
+		return err
+	}
+	return svc.services.PubSubPublisherService.PublishExperimentMessage("update", protoExpResponse)
+}
+
+// validateNoOrphanedOverrides checks whether disabling the given experiment would leave any active override tier
+// experiment, overlapping with it in segment and time, without another default tier experiment beneath it. This is
+// an error if the project blocks orphaned overrides; otherwise, only a warning is logged.
+func (svc *experimentService) validateNoOrphanedOverrides(experiment *models.Experiment) error {
+	if experiment.Tier != models.ExperimentTierDefault {
+		return nil
+	}
+
+	projectId := experiment.ProjectID.ToApiSchema()
+	defaultSegment, err := svc.getDefaultSegment(projectId)
+	if err != nil {
+		return err
+	}
+	overrides, err := svc.findOverlappingActiveExperiments(
+		projectId, models.ExperimentTierOverride, experiment, nil, defaultSegment)
+	if err != nil {
+		return err
+	}
+	orphanedOverrides := []string{}
+	for _, override := range overrides {
+		defaults, err := svc.findOverlappingActiveExperiments(
+			projectId, models.ExperimentTierDefault, override, &experiment.ID, defaultSegment)
+		if err != nil {
+			return err
+		}
+		if len(defaults) == 0 {
+			orphanedOverrides = append(orphanedOverrides, fmt.Sprintf("%s (id %d)", override.Name, override.ID))
+		}
+	}
+	if len(orphanedOverrides) == 0 {
+		return nil
+	}
+
+	config, err := svc.getProjectConfig(projectId)
+	if err != nil {
+		return err
+	}
+	message := fmt.Sprintf(
+		"disabling experiment %s (id %d) leaves the active override experiments %s without an underlying default experiment",
+		experiment.Name, experiment.ID, strings.Join(orphanedOverrides, ", "))
+	if config.BlockOrphanedOverrides {
+		return errors.Newf(errors.BadInput, "%s", message)
+	}
+	log.Printf("Warning: %s", message)
+	return nil
+}
+
+// findOverlappingActiveExperiments returns the active experiments of the given tier whose segment and duration
+// overlap with those of the given experiment, less the excluded experiment, if any
+func (svc *experimentService) findOverlappingActiveExperiments(
+	projectId int64,
+	tier models.ExperimentTier,
+	experiment *models.Experiment,
+	excludedId *models.ID,
+	defaultSegment models.ExperimentSegment,
+) ([]*models.Experiment, error) {
+	query := svc.query().
+		Where("project_id = ?", projectId).
+		Where("status = ?", models.ExperimentStatusActive).
+		Where("tier = ?", tier)
+	if excludedId != nil {
+		query = query.Where("id != ?", *excludedId)
+	}
+	query, err := svc.filterStartEndTimeValues(query, ListExperimentsParams{
+		StartTime: &experiment.StartTime,
+		EndTime:   &experiment.EndTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	storage, err := svc.getSegmentStorage(projectId)
+	if err != nil {
+		return nil, err
+	}
+	query = svc.filterSegmenterValues(query, experiment.Segment, true, nil, defaultSegment, storage)
+
+	var exps []*models.Experiment
+	if err = query.Order("id").Find(&exps).Error; err != nil {
+		return nil, err
+	}
+	return exps, nil
+}
+
+// PauseAllExperiments disables all of the project's active experiments, e.g., during an incident, and returns a token
+// recording them, so that exactly those experiments can be resumed. The override tier experiments are disabled before
+// the default tier experiments, so that no override is left without an underlying default experiment in between. If an
+// experiment cannot be disabled, the pause stops and the token of the experiments disabled so far is returned with the
+// error.
+func (svc *experimentService) PauseAllExperiments(projectId int64) (PauseToken, error) {
+	token := PauseToken{ProjectID: projectId, ExperimentIds: []int64{}}
+	if err := svc.validateProjectUnlocked(projectId); err != nil {
+		return token, err
+	}
+
+	var exps []*models.Experiment
+	err := svc.query().
+		Where("project_id = ?", projectId).
+		Where("status = ?", models.ExperimentStatusActive).
+		Order("id").
+		Find(&exps).Error
+	if err != nil {
+		return token, err
+	}
+	// Order the experiments to be resumed, with the default tier experiments first
+	sort.SliceStable(exps, func(i, j int) bool {
+		return exps[i].Tier == models.ExperimentTierDefault && exps[j].Tier != models.ExperimentTierDefault
+	})
+
+	// Disable the experiments in the reverse order, and record them in the resume order. The experiments are disabled
+	// in a single transaction, with each rolled back on its own if it fails, and their histories are written together.
+	var pauseErr error
+	curExperiments := []*models.Experiment{}
+	err = svc.query().Transaction(func(tx *gorm.DB) error {
+		txSvc := svc.withTransaction(tx)
+		for i := len(exps) - 1; i >= 0; i-- {
+			var curExperiment *models.Experiment
+			pauseErr = txSvc.withSavepoint(func(itemSvc *experimentService) error {
+				var err error
+				curExperiment, err = itemSvc.disableExperiment(projectId, exps[i].ID.ToApiSchema())
+				return err
+			})
+			if pauseErr != nil {
+				break
+			}
+			curExperiments = append(curExperiments, curExperiment)
+		}
+		//  Copy the experiments' contents before the update as experiment histories
+		return svc.services.ExperimentHistoryService.WithTransaction(tx).CreateExperimentHistories(curExperiments)
+	})
+	if err != nil {
+		return token, err
+	}
+	for _, exp := range exps[len(exps)-len(curExperiments):] {
+		token.ExperimentIds = append(token.ExperimentIds, exp.ID.ToApiSchema())
+	}
+
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return token, err
+	}
+	for _, curExperiment := range curExperiments {
+		err = svc.publishExperimentChange(curExperiment.ProjectID, curExperiment.ID, segmenterTypes, ExperimentEventOpDisable)
+		if err != nil {
+			return token, err
+		}
+	}
+	return token, pauseErr
+}
+
+// ResumeExperiments enables the experiments recorded by the given pause token. Each experiment is validated as it is
+// enabled, including the orthogonality against the experiments enabled while the project was paused. The outcome for
+// each experiment is reported in the result; an error is only returned if the operation as a whole cannot proceed.
+func (svc *experimentService) ResumeExperiments(settings models.Settings, token PauseToken) (BulkResult, error) {
+	result := BulkResult{Succeeded: []int64{}, Failed: map[int64]string{}}
+	if token.ProjectID != int64(settings.ProjectID) {
+		return result, errors.Newf(errors.BadInput,
+			"the pause token of project %d cannot resume the experiments of project %d",
+			token.ProjectID, settings.ProjectID)
+	}
+	if err := svc.validateProjectUnlocked(int64(settings.ProjectID)); err != nil {
+		return result, err
+	}
+
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
+	if err != nil {
+		return result, err
+	}
+
+	// The experiments are enabled in a single transaction that holds the project's lock, with each rolled back on its
+	// own if it fails, and their histories are written together
+	curExperiments := []*models.Experiment{}
+	err = svc.withProjectLock(settings.ProjectID, func(txSvc *experimentService) error {
+		for _, experimentId := range token.ExperimentIds {
+			var curExperiment *models.Experiment
+			err := txSvc.withSavepoint(func(itemSvc *experimentService) error {
+				var err error
+				curExperiment, err = itemSvc.enableExperiment(settings, experimentId, segmenterTypes)
+				return err
+			})
+			if err != nil {
+				result.Failed[experimentId] = err.Error()
+				continue
+			}
+			result.Succeeded = append(result.Succeeded, experimentId)
+			curExperiments = append(curExperiments, curExperiment)
+		}
+		//  Copy the experiments' contents before the update as experiment histories
+		return svc.services.ExperimentHistoryService.WithTransaction(txSvc.query()).CreateExperimentHistories(curExperiments)
+	})
+	if err != nil {
+		return BulkResult{Succeeded: []int64{}, Failed: map[int64]string{}}, err
+	}
+
+	for _, curExperiment := range curExperiments {
+		err = svc.publishExperimentChange(settings.ProjectID, curExperiment.ID, segmenterTypes, ExperimentEventOpEnable)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// LockExperiments blocks all mutations of the project's experiments until they are unlocked. Reads are unaffected.
+func (svc *experimentService) LockExperiments(projectId int64) error {
+	return svc.setExperimentsLocked(projectId, true)
+}
+
+// UnlockExperiments allows the mutations of the project's experiments again
+func (svc *experimentService) UnlockExperiments(projectId int64) error {
+	return svc.setExperimentsLocked(projectId, false)
+}
+
+// setExperimentsLocked sets the project's experiments lock in a single statement, leaving the rest of the config as it
+// is stored, so that it does not overwrite the concurrent changes of the project settings
+func (svc *experimentService) setExperimentsLocked(projectId int64, locked bool) error {
+	result := svc.query().Model(&models.Settings{}).
+		Where("project_id = ?", projectId).
+		Update("config", gorm.Expr(
+			"jsonb_set(COALESCE(config, '{}'::jsonb), '{experiments_locked}', to_jsonb(?::boolean))",
+			locked,
+		))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.Newf(errors.NotFound, "project settings for project id %d not found", projectId)
+	}
+	return nil
+}
+
+// validateProjectUnlocked returns a Conflict error if the mutations of the project's experiments are locked. The lock
+// is read from the DB, so that it takes effect immediately.
+func (svc *experimentService) validateProjectUnlocked(projectId int64) error {
+	config, err := svc.getProjectConfig(projectId)
+	if err != nil {
+		return err
+	}
+	if config.ExperimentsLocked {
+		return errors.Newf(errors.Conflict, "project is locked")
+	}
+	return nil
+}
+
+// GetDBRecord retrieves the experiment with the given id. A missing experiment is reported as a NotFound error
+// and any other failure of the query as an Internal error.
+func (svc *experimentService) GetDBRecord(projectId models.ID, experimentId models.ID) (*models.Experiment, error) {
+	var exp models.Experiment
+	query := svc.query().
+		Where("project_id = ?", projectId).
+		Where("id = ?", experimentId).
+		First(&exp)
+	if err := query.Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.Newf(errors.NotFound, err.Error())
+		}
+		return nil, errors.Newf(errors.Internal, err.Error())
+	}
+	return &exp, nil
+}
+
+func (svc *experimentService) query() *gorm.DB {
+	return svc.db
+}
+
+// withTransaction returns a copy of the experiment service that runs its queries in the given transaction, e.g., so
+// that the checks of an experiment see the experiments written in the transaction. The copy is only meant for the
+// queries and the checks against the other experiments; it has no custom validators nor subscribers.
+func (svc *experimentService) withTransaction(tx *gorm.DB) *experimentService {
+	return &experimentService{
+		services: svc.services,
+		db:       tx,
+		clock:    svc.clock,
+	}
+}
+
+// withProjectLock runs the given function in a transaction that holds a transaction-level lock on the project, so that
+// the activations of the project's experiments are serialized, and the checks of an experiment against the others see
+// the experiments activated concurrently
+func (svc *experimentService) withProjectLock(projectId models.ID, fn func(txSvc *experimentService) error) error {
+	return svc.query().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", int64(projectId)).Error; err != nil {
+			return err
+		}
+		return fn(svc.withTransaction(tx))
+	})
+}
+
+// withSavepoint runs the given function in a savepoint of the service's transaction, so that the writes of an
+// experiment that fails in a bulk operation are rolled back without aborting the others
+func (svc *experimentService) withSavepoint(fn func(txSvc *experimentService) error) error {
+	return svc.query().Transaction(func(tx *gorm.DB) error {
+		return fn(svc.withTransaction(tx))
+	})
+}
+
+func (svc *experimentService) save(exp *models.Experiment) (*models.Experiment, error) {
+	if err := exp.Validate(); err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+	if err := svc.query().Clauses(clause.OnConflict{
+		UpdateAll: true,
+	}).Create(exp).Error; err != nil {
+		return nil, err
+	}
+	return svc.GetDBRecord(exp.ProjectID, exp.ID)
+}
+
+// saveWithHistory saves the experiment and the history of its previous version in a single transaction, so that neither
+// is written if the other fails
+func (svc *experimentService) saveWithHistory(
+	exp *models.Experiment,
+	prevExp *models.Experiment,
+) (*models.Experiment, error) {
+	if err := exp.Validate(); err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+	err := svc.query().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(exp).Error; err != nil {
+			return err
+		}
+		_, err := svc.services.ExperimentHistoryService.WithTransaction(tx).CreateExperimentHistory(prevExp)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return svc.GetDBRecord(exp.ProjectID, exp.ID)
+}
+
+// filterListExperimentsParams applies the field selection, ordering and all the optional filters in the given params
+// to the query. Pagination is left to the caller. The default segment is used to exclude the weak matches that are
+// ruled out by the project defaults.
+func (svc *experimentService) filterListExperimentsParams(
+	query *gorm.DB,
+	params ListExperimentsParams,
+	defaultSegment models.ExperimentSegment,
+	storage querybuilder.SegmentStorage,
+) (*gorm.DB, error) {
+	var err error
+
+	// Handle Field values
+	query, err = svc.filterFieldValues(query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Order by the id as well, so that experiments with the same updated_at are paged deterministically
+	if params.Search != nil && params.SearchFuzzy {
+		// Order the fuzzy search results by relevance first. The ordering is built as a single expression, as the
+		// expressions with arguments are not merged with other ORDER BY columns.
+		query = query.Clauses(clause.OrderBy{
+			Expression: clause.Expr{
+				SQL:  "similarity(name, ?) DESC, updated_at desc, id desc",
+				Vars: []interface{}{*params.Search},
+			},
+		})
+	} else {
+		query = query.Order("updated_at desc, id desc")
+	}
+
+	// Handle optional parameters
+	if params.Status != nil {
+		query = query.Where("status = ?", params.Status)
+	}
+	// Handle StatusFriendly values
+	if len(params.StatusFriendly) > 0 {
+		query = svc.filterExperimentStatusFriendly(query, params.StatusFriendly)
+	}
+
+	// Handle Start and EndTime values
+	query, err = svc.filterStartEndTimeValues(query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Tier != nil {
+		query = query.Where("tier = ?", params.Tier)
+	}
+	if params.Type != nil {
+		query = query.Where("type = ?", params.Type)
+	}
+	if params.Name != nil && len(params.Names) > 0 {
+		return nil, errors.Newf(errors.BadInput, "name and names cannot be combined")
+	}
+	if params.Name != nil {
+		query = query.Where("name = ?", params.Name)
+	}
+	if len(params.Names) > 0 {
+		query = query.Where("name IN ?", params.Names)
+	}
+	if params.UpdatedBy != nil {
+		query = query.Where(
+			fmt.Sprintf("updated_by ILIKE '%%%s%%'", *params.UpdatedBy),
+		)
+	}
+	if params.Search != nil && params.SearchFuzzy {
+		// The similarity threshold is that of pg_trgm.similarity_threshold (0.3 by default)
+		query = query.Where("name % ?", *params.Search)
+	} else if params.Search != nil {
+		query = query.Where(
+			fmt.Sprintf("name ILIKE '%%%s%%' OR description ILIKE '%%%s%%'", *params.Search, *params.Search),
+		)
+	}
+	if params.ValidationStatus != nil {
+		switch models.ExperimentValidationStatus(*params.ValidationStatus) {
+		case models.ExperimentValidationStatusPassed, models.ExperimentValidationStatusFailed:
+			query = query.Where("last_validation_status = ?", *params.ValidationStatus)
+		default:
+			return nil, errors.Newf(errors.BadInput, "unknown validation status: %s", *params.ValidationStatus)
+		}
+	}
+	if params.TreatmentConfigHasKey != nil {
+		// Treatments may be stored as a JSON null, so the array elements are only expanded for arrays
+		query = query.Where(
+			`CASE WHEN jsonb_typeof(treatments) = 'array' THEN EXISTS (
+				SELECT 1 FROM jsonb_array_elements(treatments) AS treatment
+				WHERE jsonb_exists(treatment->'configuration', ?)
+			) ELSE false END`,
+			*params.TreatmentConfigHasKey,
+		)
+	}
+	// Handle Version values
+	query, err = svc.filterVersionValues(query, params)
+	if err != nil {
+		return nil, err
+	}
+	// Handle the number of treatments
+	query, err = svc.filterTreatmentCount(query, params)
+	if err != nil {
+		return nil, err
+	}
+	// Segmenters
+	for name := range params.WeakMatchPerSegmenter {
+		_, inSegment := params.Segment[name]
+		_, inSegmentRange := params.SegmentRange[name]
+		if !inSegment && !inSegmentRange {
+			return nil, errors.Newf(errors.BadInput,
+				"weak match is set for segmenter %s, which is not filtered by segment or segment range", name)
+		}
+	}
+	query = svc.filterSegmenterValues(
+		query, params.Segment, params.IncludeWeakMatch, params.WeakMatchPerSegmenter, defaultSegment, storage)
+	for name, values := range params.ExcludeSegment {
+		if predicate := querybuilder.SegmenterNoneOf(name, values, storage); predicate != nil {
+			query = query.Where(predicate)
+		}
+	}
+	for name, segmenterRange := range params.SegmentRange {
+		weakMatch := getSegmenterWeakMatch(name, params.IncludeWeakMatch, params.WeakMatchPerSegmenter)
+		query = filterSegmenterRange(query, name, segmenterRange, weakMatch, defaultSegment[name])
+	}
+	if params.UnconstrainedSegmenter != nil {
+		// The segmenters set are the same in the string and typed storage, so the string storage is used
+		query = query.Where(querybuilder.SegmenterUnconstrained(*params.UnconstrainedSegmenter, querybuilder.SegmentStorage{}))
+	}
+
+	return query, nil
+}
+
+func (svc *experimentService) filterFieldValues(query *gorm.DB, params ListExperimentsParams) (*gorm.DB, error) {
+	if params.IdsOnly {
+		if params.Fields != nil && len(*params.Fields) != 0 {
+			return nil, errors.Newf(errors.BadInput, "ids_only cannot be combined with fields")
+		}
+		return query.Select(string(models.ExperimentFieldId)), nil
+	}
+	if params.Fields != nil && len(*params.Fields) != 0 && !models.IsAllExperimentFields(*params.Fields) {
+		err := validateListExperimentFieldNames(*params.Fields)
+		if err != nil {
+			return nil, err
+		}
+
+		// Stores a set of unique field names which are unique column names to be selected in the db query
+		fieldNamesSet := set.New()
+		for _, field := range *params.Fields {
+			fieldName := field
+			// Add ExperimentFieldStatus to the query because status_friendly does not exist in the db as a column
+			if field == models.ExperimentFieldStatusFriendly {
+				// Add ExperimentFieldStartTime and ExperimentFieldEndTime to the query as they are required for
+				// determining the statusFriendly field; these two fields may or may not be returned depending on
+				// what is actually specified in params.Fields
+				fieldNamesSet.Insert(string(models.ExperimentFieldStartTime))
+				fieldNamesSet.Insert(string(models.ExperimentFieldEndTime))
+
+				fieldName = models.ExperimentFieldStatus
+			}
+			//fieldNamesSet[string(fieldName)] = struct{}{}
+			fieldNamesSet.Insert(string(fieldName))
+		}
+
+		// Retrieve a slice of unique strings from fieldNamesSet; query.Select only accepts []string
+		var fieldNames []string
+		fieldNamesSet.Do(func(fieldName interface{}) {
+			fieldNames = append(fieldNames, fmt.Sprint(fieldName))
+		})
+
+		query = query.Select(fieldNames)
+	}
+	return query, nil
+}
+
+func (svc *experimentService) filterExperimentStatusFriendly(query *gorm.DB, statusesFriendly []ExperimentStatusFriendly) *gorm.DB {
+	now := svc.clock.Now()
+	orPredicates := svc.query().Where("false") // start with false and build OR query dynamically
+	for _, statusFriendly := range statusesFriendly {
+		predicates := svc.query()
+		if statusFriendly == ExperimentStatusFriendlyDeactivated {
+			predicates = predicates.Where("status = ?", models.ExperimentStatusInactive)
+		} else if statusFriendly == ExperimentStatusFriendlyDraft {
+			predicates = predicates.Where("status = ?", models.ExperimentStatusDraft)
+		} else {
+			predicates = predicates.Where("status = ?", models.ExperimentStatusActive)
+			switch statusFriendly {
+			case ExperimentStatusFriendlyScheduled:
+				predicates = predicates.Where("start_time > ?", now)
+			case ExperimentStatusFriendlyCompleted:
+				predicates = predicates.Where("end_time < ?", now)
+			default:
+				// Status Running - current time should be present in the experiment duration.
+				predicates = predicates.Where("tstzrange(start_time, end_time, '[)') @> tstzrange(?, ?, '[]')", now, now)
+			}
+		}
+		orPredicates = orPredicates.Or(predicates)
+	}
+	return query.Where(orPredicates)
+}
+
+// groupExperimentsByStatusFriendly buckets the experiments by their friendly status at the given time. The time
+// ranges match filterExperimentStatusFriendly, so an active experiment that ends exactly at the given time is not in
+// any of the buckets.
+func groupExperimentsByStatusFriendly(
+	experiments []*models.Experiment,
+	now time.Time,
+) map[ExperimentStatusFriendly][]*models.Experiment {
+	groups := map[ExperimentStatusFriendly][]*models.Experiment{
+		ExperimentStatusFriendlyCompleted:   {},
+		ExperimentStatusFriendlyDeactivated: {},
+		ExperimentStatusFriendlyRunning:     {},
+		ExperimentStatusFriendlyScheduled:   {},
+		ExperimentStatusFriendlyDraft:       {},
+	}
+	for _, exp := range experiments {
+		var statusFriendly ExperimentStatusFriendly
+		switch {
+		case exp.Status == models.ExperimentStatusDraft:
+			statusFriendly = ExperimentStatusFriendlyDraft
+		case exp.Status != models.ExperimentStatusActive:
+			statusFriendly = ExperimentStatusFriendlyDeactivated
+		case exp.StartTime.After(now):
+			statusFriendly = ExperimentStatusFriendlyScheduled
+		case exp.EndTime.Before(now):
+			statusFriendly = ExperimentStatusFriendlyCompleted
+		case exp.EndTime.After(now):
+			// Running - the current time is in the experiment's [start and end) times
+			statusFriendly = ExperimentStatusFriendlyRunning
+		default:
+			continue
+		}
+		groups[statusFriendly] = append(groups[statusFriendly], exp)
+	}
+	return groups
+}
+
+// ListExperimentsWithTreatmentChanges returns the changes of the treatments of the project's experiments, between each
+// version and the prior version, for the versions created since the given time. The versions that left the treatments
+// unchanged are left out. The changes are ordered by the experiment id and the version.
+func (svc *experimentService) ListExperimentsWithTreatmentChanges(
+	projectId int64,
+	since time.Time,
+) ([]TreatmentChange, error) {
+	// Any experiment with a version created since the given time has been updated since then
+	var exps []*models.Experiment
+	err := svc.query().
+		Where("project_id = ?", projectId).
+		Where("updated_at >= ?", since).
+		Order("id").
+		Find(&exps).Error
+	if err != nil {
+		return nil, err
+	}
+	changes := []TreatmentChange{}
+	if len(exps) == 0 {
+		return changes, nil
+	}
+	experimentIds := []models.ID{}
+	for _, exp := range exps {
+		experimentIds = append(experimentIds, exp.ID)
+	}
+	var histories []*models.ExperimentHistory
+	err = svc.query().
+		Where("experiment_id IN ?", experimentIds).
+		Order("experiment_id, version").
+		Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+	historiesByExperiment := map[models.ID][]*models.ExperimentHistory{}
+	for _, history := range histories {
+		historiesByExperiment[history.ExperimentID] = append(historiesByExperiment[history.ExperimentID], history)
+	}
+
+	for _, exp := range exps {
+		// The versions of the experiment in order, ending with the current version. The created_at timestamp of a
+		// history record is the time at which the version was created.
+		versions := []models.ExperimentHistory{}
+		for _, history := range historiesByExperiment[exp.ID] {
+			versions = append(versions, *history)
+		}
+		versions = append(versions, models.ExperimentHistory{
+			Model:      models.Model{CreatedAt: exp.UpdatedAt},
+			Version:    exp.Version,
+			Treatments: exp.Treatments,
+			UpdatedBy:  exp.UpdatedBy,
+		})
+		for i := 1; i < len(versions); i++ {
+			prev, cur := versions[i-1], versions[i]
+			if cur.CreatedAt.Before(since) {
+				continue
+			}
+			diff := prev.Treatments.Diff(cur.Treatments)
+			if diff.IsEmpty() {
+				continue
+			}
+			changes = append(changes, TreatmentChange{
+				ExperimentID:   exp.ID.ToApiSchema(),
+				ExperimentName: exp.Name,
+				FromVersion:    prev.Version,
+				ToVersion:      cur.Version,
+				UpdatedAt:      cur.CreatedAt,
+				UpdatedBy:      cur.UpdatedBy,
+				Diff:           diff,
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+func (svc *experimentService) filterStartEndTimeValues(query *gorm.DB, params ListExperimentsParams) (*gorm.DB, error) {
+	if params.StartTime != nil && !params.StartTime.IsZero() && (params.EndTime == nil || params.EndTime.IsZero()) {
+		return nil, errors.Newf(errors.BadInput, "end_time parameter must be supplied as well")
+	}
+	if params.EndTime != nil && !params.EndTime.IsZero() && (params.StartTime == nil || params.StartTime.IsZero()) {
+		return nil, errors.Newf(errors.BadInput, "start_time parameter must be supplied as well")
+	}
+	if params.StartTime != nil && !params.StartTime.IsZero() && params.EndTime != nil && !params.EndTime.IsZero() {
+		// Find experiments that are at least partially running in this window. To filter active experiments at a
+		// given timestamp (such as current timestamp), it needs to be passed in for both the start and end time.
+		query = query.Where(querybuilder.TimeWindow(*params.StartTime, *params.EndTime))
+	}
+	return query, nil
+}
+
+func (svc *experimentService) filterVersionValues(query *gorm.DB, params ListExperimentsParams) (*gorm.DB, error) {
+	if params.MinVersion != nil && params.MaxVersion != nil && *params.MinVersion > *params.MaxVersion {
+		return nil, errors.Newf(errors.BadInput, "min_version must not be greater than max_version")
+	}
+	if params.MinVersion != nil {
+		query = query.Where("version >= ?", *params.MinVersion)
+	}
+	if params.MaxVersion != nil {
+		query = query.Where("version <= ?", *params.MaxVersion)
+	}
+	return query, nil
+}
+
+func (svc *experimentService) filterTreatmentCount(query *gorm.DB, params ListExperimentsParams) (*gorm.DB, error) {
+	if params.MinTreatments != nil && params.MaxTreatments != nil && *params.MinTreatments > *params.MaxTreatments {
+		return nil, errors.Newf(errors.BadInput, "min_treatments must not be greater than max_treatments")
+	}
+	// Treatments may be stored as a JSON null, which has no treatments
+	treatmentCount := "CASE WHEN jsonb_typeof(treatments) = 'array' THEN jsonb_array_length(treatments) ELSE 0 END"
+	if params.MinTreatments != nil && params.MaxTreatments != nil {
+		return query.Where(treatmentCount+" BETWEEN ? AND ?", *params.MinTreatments, *params.MaxTreatments), nil
+	}
+	if params.MinTreatments != nil {
+		query = query.Where(treatmentCount+" >= ?", *params.MinTreatments)
+	}
+	if params.MaxTreatments != nil {
+		query = query.Where(treatmentCount+" <= ?", *params.MaxTreatments)
 	}
 	return query, nil
 }
 
-func (svc *experimentService) filterSegmenterValues(query *gorm.DB, segment models.ExperimentSegment, includeWeakMatch bool) *gorm.DB {
-	// No need to format the segmenter values according to their types since we're storing all values in string
-	for name, values := range segment {
-		query = filterSegmenterAnyOfPredicate(query, name, values, includeWeakMatch)
+func (svc *experimentService) filterSegmenterValues(
+	query *gorm.DB,
+	segment models.ExperimentSegment,
+	includeWeakMatch bool,
+	weakMatchPerSegmenter map[string]bool,
+	defaultSegment models.ExperimentSegment,
+	storage querybuilder.SegmentStorage,
+) *gorm.DB {
+	// The segmenter values are given in the string storage schema, and only formatted according to their types for
+	// the typed storage
+	for name, values := range segment {
+		// An experiment that does not set the segmenter takes on the project default values, if any, so it is only a
+		// weak match if the default values include any of the given values
+		weakMatch := getSegmenterWeakMatch(name, includeWeakMatch, weakMatchPerSegmenter)
+		if defaultValues := defaultSegment[name]; weakMatch && len(defaultValues) > 0 {
+			defaultValuesSet := utils.StringSliceToSet(defaultValues)
+			weakMatch = false
+			for _, value := range values {
+				if defaultValuesSet.Has(value) {
+					weakMatch = true
+					break
+				}
+			}
+		}
+		if predicate := querybuilder.SegmenterAnyOf(name, values, weakMatch, storage); predicate != nil {
+			query = query.Where(predicate)
+		}
+	}
+	return query
+}
+
+// getSegmenterWeakMatch returns whether the weak matches of the segmenter are included, as overridden for the
+// segmenter, if at all
+func getSegmenterWeakMatch(name string, includeWeakMatch bool, weakMatchPerSegmenter map[string]bool) bool {
+	if weakMatch, ok := weakMatchPerSegmenter[name]; ok {
+		return weakMatch
+	}
+	return includeWeakMatch
+}
+
+func validateListExperimentFieldNames(fields []models.ExperimentField) error {
+	allowedFieldList := []interface{}{
+		models.ExperimentFieldId,
+		models.ExperimentFieldName,
+		models.ExperimentFieldStartTime,
+		models.ExperimentFieldEndTime,
+		models.ExperimentFieldTier,
+		models.ExperimentFieldType,
+		models.ExperimentFieldStatusFriendly,
+		models.ExperimentFieldUpdatedAt,
+		models.ExperimentFieldTreatments,
+	}
+	allowedFields := set.New(allowedFieldList...)
+	for _, field := range fields {
+		if field == models.ExperimentFieldAll {
+			return fmt.Errorf("field %s cannot be combined with other fields", field)
+		}
+		if !allowedFields.Has(field) {
+			return fmt.Errorf("field %s is not supported, fields should only be name and/or id", field)
+		}
+	}
+	return nil
+}
+
+func (svc *experimentService) validateExperimentOrthogonality(
+	projectId int64,
+	experimentId *int64,
+	segment models.ExperimentSegmentRaw,
+	experiments []*models.Experiment,
+	segmenters []string,
+	exemptSegmenters []string,
+	defaultSegment models.ExperimentSegmentRaw,
+) error {
+	var err error
+	var filteredExps []models.Experiment
+	for _, exp := range experiments {
+		// Shadow experiments do not affect the traffic, so they cannot conflict with the given segment
+		if exp.Shadow {
+			continue
+		}
+		// Case: Update experiment ONLY; Exclude current experiment id's segments
+		if experimentId != nil {
+			if exp.ID.ToApiSchema() != *experimentId {
+				filteredExps = append(filteredExps, *exp)
+			}
+			continue
+		}
+		filteredExps = append(filteredExps, *exp)
+	}
+	if len(filteredExps) > 0 && len(defaultSegment) > 0 {
+		// Compare the effective segments, with the project defaults applied to the unset segmenters
+		storageDefaultSegment, err := svc.toStorageDefaultSegment(projectId, defaultSegment)
+		if err != nil {
+			return internalError(err)
+		}
+		segment = segment.WithDefaults(defaultSegment)
+		for i := range filteredExps {
+			filteredExps[i].Segment = filteredExps[i].Segment.WithDefaults(storageDefaultSegment)
+		}
+	}
+	if len(filteredExps) > 0 {
+		err = svc.services.SegmenterService.ValidateSegmentOrthogonality(
+			projectId,
+			getOrthogonalitySegmenters(segmenters, exemptSegmenters),
+			segment,
+			filteredExps,
+		)
+		if err != nil {
+			// The failures to look up the segmenters keep their types, only the conflicts are bad input
+			if errors.GetType(err) != errors.Unknown {
+				return err
+			}
+			return errors.Newf(errors.BadInput, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// validateSegmentRanges checks that the ranges are valid, and are given for the integer segmenters of the project
+func (svc *experimentService) validateSegmentRanges(projectId int64, segmentRanges map[string]SegmenterRange) error {
+	if len(segmentRanges) == 0 {
+		return nil
+	}
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return err
+	}
+	for name, segmenterRange := range segmentRanges {
+		if segmenterTypes[name] != schema.SegmenterTypeInteger {
+			return errors.Newf(errors.BadInput, "segment range is only supported for integer segmenters, got %s", name)
+		}
+		if segmenterRange.Min > segmenterRange.Max {
+			return errors.Newf(errors.BadInput, "segment range of %s must have min less than or equal to max", name)
+		}
+	}
+	return nil
+}
+
+// getWeakMatchDefaultSegment returns the project's default segment if it is needed by the weak matches of the given
+// list params, and an empty segment otherwise
+func (svc *experimentService) getWeakMatchDefaultSegment(
+	projectId int64,
+	params ListExperimentsParams,
+) (models.ExperimentSegment, error) {
+	hasWeakMatch := params.IncludeWeakMatch
+	for _, weakMatch := range params.WeakMatchPerSegmenter {
+		hasWeakMatch = hasWeakMatch || weakMatch
+	}
+	if !hasWeakMatch || (len(params.Segment) == 0 && len(params.SegmentRange) == 0) {
+		return models.ExperimentSegment{}, nil
+	}
+	return svc.getDefaultSegment(projectId)
+}
+
+// getFilterSegmentStorage returns the storage of the segments that the segment filters in the params are matched
+// against. The settings of the project are only retrieved if there are any segment filters.
+func (svc *experimentService) getFilterSegmentStorage(
+	projectId int64,
+	params ListExperimentsParams,
+) (querybuilder.SegmentStorage, error) {
+	if len(params.Segment) == 0 && len(params.ExcludeSegment) == 0 {
+		return querybuilder.SegmentStorage{}, nil
+	}
+	return svc.getSegmentStorage(projectId)
+}
+
+// getDefaultSegment returns the default segment of the project in the storage schema. Projects without settings have
+// no default segment.
+func (svc *experimentService) getDefaultSegment(projectId int64) (models.ExperimentSegment, error) {
+	config, err := svc.getProjectConfig(projectId)
+	if err != nil {
+		return nil, err
+	}
+	return svc.toStorageDefaultSegment(projectId, config.DefaultSegment)
+}
+
+// getProjectConfig returns the experimentation config of the project, as currently stored in the DB. Projects without
+// settings have an empty config.
+func (svc *experimentService) getProjectConfig(projectId int64) (*models.ExperimentationConfig, error) {
+	settings, err := svc.services.ProjectSettingsService.GetDBRecord(models.ID(projectId))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &models.ExperimentationConfig{}, nil
+		}
+		return nil, err
+	}
+	if settings.Config == nil {
+		return &models.ExperimentationConfig{}, nil
+	}
+	return settings.Config, nil
+}
+
+func (svc *experimentService) toStorageDefaultSegment(
+	projectId int64,
+	defaultSegment models.ExperimentSegmentRaw,
+) (models.ExperimentSegment, error) {
+	if len(defaultSegment) == 0 {
+		return models.ExperimentSegment{}, nil
+	}
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return nil, err
+	}
+	return defaultSegment.ToStorageSchema(segmenterTypes)
+}
+
+// validateDraftStatusTransition checks that an update does not change the status of a draft experiment, which is only
+// changed by promoting the experiment, and does not turn any other experiment back into a draft
+func validateDraftStatusTransition(
+	experimentId int64,
+	curStatus models.ExperimentStatus,
+	newStatus models.ExperimentStatus,
+) error {
+	if curStatus == models.ExperimentStatusDraft && newStatus != models.ExperimentStatusDraft {
+		return errors.Newf(errors.BadInput, "experiment id %d is a draft and must be promoted first", experimentId)
+	}
+	if curStatus != models.ExperimentStatusDraft && newStatus == models.ExperimentStatusDraft {
+		return errors.Newf(errors.BadInput, "experiment id %d cannot be changed back to a draft", experimentId)
+	}
+	return nil
+}
+
+// requiresOrthogonalityCheck decides whether the orthogonality of an experiment (as well as its mutex group) is
+// checked on an update to the new status. Only the status after the update matters, as an inactive experiment is never
+// served, whatever its current status:
+//   - -> active: checked, as the experiment was activated, or its segment or time range may have changed
+//   - -> inactive: skipped
+//   - -> draft: skipped, until the draft is promoted
+func requiresOrthogonalityCheck(newStatus models.ExperimentStatus) bool {
+	return newStatus == models.ExperimentStatusActive
+}
+
+// getOrthogonalitySegmenters returns the segmenters to be considered in the segment orthogonality checks, i.e., the
+// given segmenters less the exempted ones
+func getOrthogonalitySegmenters(segmenters []string, exemptSegmenters []string) []string {
+	if len(exemptSegmenters) == 0 {
+		return segmenters
+	}
+	exemptSegmentersSet := utils.StringSliceToSet(exemptSegmenters)
+	orthogonalitySegmenters := []string{}
+	for _, segmenter := range segmenters {
+		if !exemptSegmentersSet.Has(segmenter) {
+			orthogonalitySegmenters = append(orthogonalitySegmenters, segmenter)
+		}
+	}
+	return orthogonalitySegmenters
+}
+
+// filterSegmenterRange selects the experiments with at least one value of the integer segmenter in the given range. The
+// values are matched on their string storage, which is set for all experiments, and are cast to numbers for the
+// comparison. An experiment that does not set the segmenter takes on the project default values, if any, so it is only
+// a weak match if any of the default values is in the range.
+func filterSegmenterRange(
+	query *gorm.DB,
+	name string,
+	segmenterRange SegmenterRange,
+	includeWeakMatch bool,
+	defaultValues []string,
+) *gorm.DB {
+	predicate := `CASE WHEN jsonb_typeof(segment -> ?) = 'array' THEN EXISTS (
+		SELECT 1 FROM jsonb_array_elements_text(segment -> ?) AS segment_value
+		WHERE segment_value::bigint BETWEEN ? AND ?
+	) ELSE false END`
+	args := []interface{}{name, name, segmenterRange.Min, segmenterRange.Max}
+
+	weakMatch := includeWeakMatch
+	if weakMatch && len(defaultValues) > 0 {
+		weakMatch = false
+		for _, value := range defaultValues {
+			intValue, err := strconv.ParseInt(value, 10, 64)
+			if err == nil && intValue >= segmenterRange.Min && intValue <= segmenterRange.Max {
+				weakMatch = true
+				break
+			}
+		}
+	}
+	if weakMatch {
+		predicate = fmt.Sprintf("(%s OR NOT jsonb_exists(segment, ?) OR segment -> ? = '[]')", predicate)
+		args = append(args, name, name)
+	}
+	return query.Where(predicate, args...)
+}
+
+// getSegmentStorage returns the storage of the segments of the project's experiments
+func (svc *experimentService) getSegmentStorage(projectId int64) (querybuilder.SegmentStorage, error) {
+	config, err := svc.getProjectConfig(projectId)
+	if err != nil {
+		return querybuilder.SegmentStorage{}, err
+	}
+	if !config.TypedSegmentStorage {
+		return querybuilder.SegmentStorage{}, nil
+	}
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return querybuilder.SegmentStorage{}, err
+	}
+	return querybuilder.SegmentStorage{SegmenterTypes: segmenterTypes}, nil
+}
+
+// getTypedSegment returns the segment to be stored as typed JSON values, if the project stores typed segments
+func getTypedSegment(
+	config *models.ExperimentationConfig,
+	segment models.ExperimentSegment,
+	segmenterTypes map[string]schema.SegmenterType,
+) (models.ExperimentSegmentRaw, error) {
+	if config == nil || !config.TypedSegmentStorage {
+		return nil, nil
+	}
+	return segment.ToRawSchema(segmenterTypes)
+}
+
+// ListAllExperiments returns a list of all experiments based on the filters specified in params parameter,
+// to be used for performing orthogonality checks on.
+func (svc *experimentService) ListAllExperiments(projectId models.ID, params ListExperimentsParams) ([]*models.Experiment, error) {
+	// Get the first page of active experiments
+	filteredExperiments, paging, err := svc.ListExperiments(
+		projectId.ToApiSchema(),
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if paging == nil {
+		// This is not expected (the pagination data should always be set), but handle it.
+		return nil, fmt.Errorf("Missing pagination data for existing experiments")
+	}
+
+	// If there are multiple pages, get the subsequent pages
+	for i := int32(2); i <= paging.Pages; i++ {
+		exps, _, err := svc.ListExperiments(
+			projectId.ToApiSchema(),
+			ListExperimentsParams{
+				StartTime: params.StartTime,
+				EndTime:   params.EndTime,
+				Status:    params.Status,
+				PaginationOptions: pagination.PaginationOptions{
+					Page: &i,
+				},
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		filteredExperiments = append(filteredExperiments, exps...)
+	}
+
+	return filteredExperiments, nil
+}
+
+// ExportExperimentsCSV returns the experiments matching the params, across all pages, as CSV with a header row. The
+// segment is exported as a single JSON column, or, if pivotSegments is set, as one column for each of the project's
+// segmenters, holding the experiment's values of the segmenter joined by "|".
+func (svc *experimentService) ExportExperimentsCSV(
+	projectId int64,
+	params ListExperimentsParams,
+	pivotSegments bool,
+) ([]byte, error) {
+	exps := []*models.Experiment{}
+	for page := int32(1); ; page++ {
+		pageParams := params
+		pageParams.PaginationOptions = pagination.PaginationOptions{Page: &page}
+		pageExps, paging, err := svc.ListExperiments(projectId, pageParams)
+		if err != nil {
+			return nil, err
+		}
+		exps = append(exps, pageExps...)
+		if paging == nil || page >= paging.Pages {
+			break
+		}
+	}
+
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return nil, err
+	}
+	var segmenterNames []string
+	if pivotSegments {
+		config, err := svc.getProjectConfig(projectId)
+		if err != nil {
+			return nil, err
+		}
+		segmenterNames = config.Segmenters.Names
+	}
+
+	header := []string{"id", "name", "type", "tier", "status", "start_time", "end_time", "treatments"}
+	if pivotSegments {
+		header = append(header, segmenterNames...)
+	} else {
+		header = append(header, "segment")
+	}
+	header = append(header, "updated_by", "version")
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err = writer.Write(header); err != nil {
+		return nil, err
+	}
+	for _, exp := range exps {
+		treatmentNames := []string{}
+		for _, treatment := range exp.Treatments {
+			treatmentNames = append(treatmentNames, treatment.Name)
+		}
+		rawSegment, err := exp.Segment.ToRawSchema(segmenterTypes)
+		if err != nil {
+			return nil, err
+		}
+
+		record := []string{
+			strconv.FormatInt(exp.ID.ToApiSchema(), 10),
+			exp.Name,
+			string(exp.Type),
+			string(exp.Tier),
+			string(exp.Status),
+			exp.StartTime.UTC().Format(time.RFC3339),
+			exp.EndTime.UTC().Format(time.RFC3339),
+			strings.Join(treatmentNames, "|"),
+		}
+		if pivotSegments {
+			for _, name := range segmenterNames {
+				values := []string{}
+				rawValues, _ := rawSegment[name].([]interface{})
+				for _, value := range rawValues {
+					values = append(values, fmt.Sprint(value))
+				}
+				record = append(record, strings.Join(values, "|"))
+			}
+		} else {
+			segment, err := json.Marshal(rawSegment)
+			if err != nil {
+				return nil, err
+			}
+			record = append(record, string(segment))
+		}
+		record = append(record, exp.UpdatedBy, strconv.FormatInt(exp.Version, 10))
+
+		if err = writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// experimentHistoryExportBatchSize is the number of history records that ExportExperimentHistory reads at a time
+const experimentHistoryExportBatchSize = 500
+
+// ExportExperimentHistory returns every history record of the project's experiments, ordered by the experiment id and
+// then by the version, with their segments in the raw schema. The records are read in batches, so that large projects
+// are not loaded in a single query. The params optionally restrict the records to those updated in a time range.
+func (svc *experimentService) ExportExperimentHistory(
+	projectId int64,
+	params ExportExperimentHistoryParams,
+) ([]ExperimentHistoryExport, error) {
+	if params.UpdatedAfter != nil && params.UpdatedBefore != nil && params.UpdatedAfter.After(*params.UpdatedBefore) {
+		return nil, errors.Newf(errors.BadInput, "updated_after must not be after updated_before")
+	}
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := svc.query().
+		Where("experiment_id IN (?)", svc.query().Model(&models.Experiment{}).
+			Select("id").
+			Where("project_id = ?", projectId))
+	if params.UpdatedAfter != nil {
+		query = query.Where("updated_at >= ?", *params.UpdatedAfter)
+	}
+	if params.UpdatedBefore != nil {
+		query = query.Where("updated_at <= ?", *params.UpdatedBefore)
+	}
+	query = query.Order("experiment_id, version")
+
+	exports := []ExperimentHistoryExport{}
+	for offset := 0; ; offset += experimentHistoryExportBatchSize {
+		var batch []*models.ExperimentHistory
+		err = query.Session(&gorm.Session{}).
+			Offset(offset).
+			Limit(experimentHistoryExportBatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, history := range batch {
+			rawSegment, err := history.Segment.ToRawSchema(segmenterTypes)
+			if err != nil {
+				return nil, err
+			}
+			exports = append(exports, ExperimentHistoryExport{History: history, RawSegment: rawSegment})
+		}
+		if len(batch) < experimentHistoryExportBatchSize {
+			break
+		}
+	}
+	return exports, nil
+}
+
+// FindCoverageGaps returns the time windows within [from, to) in which no active experiment of the given tier covers
+// the given segment. An experiment that does not constrain a segmenter is considered to cover all its values.
+func (svc *experimentService) FindCoverageGaps(
+	projectId int64,
+	segment models.ExperimentSegmentRaw,
+	tier models.ExperimentTier,
+	from time.Time,
+	to time.Time,
+) ([]TimeWindow, error) {
+	if !from.Before(to) {
+		return nil, errors.Newf(errors.BadInput, "from must be before to")
+	}
+
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return nil, err
+	}
+	segmenterStorageSchema, err := segment.ToStorageSchema(segmenterTypes)
+	if err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+
+	query := svc.query().
+		Where("project_id = ?", projectId).
+		Where("status = ?", models.ExperimentStatusActive).
+		Where("tier = ?", tier)
+	query, err = svc.filterStartEndTimeValues(query, ListExperimentsParams{StartTime: &from, EndTime: &to})
+	if err != nil {
+		return nil, err
+	}
+	defaultSegment, err := svc.getDefaultSegment(projectId)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := svc.getSegmentStorage(projectId)
+	if err != nil {
+		return nil, err
+	}
+	query = svc.filterSegmenterValues(query, segmenterStorageSchema, true, nil, defaultSegment, storage)
+
+	var exps []*models.Experiment
+	if err = query.Order("start_time").Find(&exps).Error; err != nil {
+		return nil, err
+	}
+
+	// Walk through the experiments in order of their start times, recording the uncovered windows
+	gaps := []TimeWindow{}
+	coveredUntil := from
+	for _, exp := range exps {
+		if !coveredUntil.Before(to) {
+			break
+		}
+		if exp.StartTime.After(coveredUntil) {
+			gapEnd := exp.StartTime
+			if gapEnd.After(to) {
+				gapEnd = to
+			}
+			gaps = append(gaps, TimeWindow{StartTime: coveredUntil, EndTime: gapEnd})
+		}
+		if exp.EndTime.After(coveredUntil) {
+			coveredUntil = exp.EndTime
+		}
+	}
+	if coveredUntil.Before(to) {
+		gaps = append(gaps, TimeWindow{StartTime: coveredUntil, EndTime: to})
+	}
+
+	return gaps, nil
+}
+
+// FindDuplicateExperiments returns the clusters of active experiments that are suspected to be duplicates of each
+// other, i.e., that have the same tier and an identical segment, and whose time windows overlap. Unset segmenters are
+// ignored in the comparison of the segments. The clusters are advisory; the experiments are not modified.
+func (svc *experimentService) FindDuplicateExperiments(projectId int64) ([][]*models.Experiment, error) {
+	var exps []*models.Experiment
+	err := svc.query().
+		Where("project_id = ?", projectId).
+		Where("status = ?", models.ExperimentStatusActive).
+		Order("start_time, id").
+		Find(&exps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the experiments by their tier and segment, keeping the order in which the groups are first seen
+	groupKeys := []string{}
+	groups := map[string][]*models.Experiment{}
+	for _, exp := range exps {
+		key, err := getDuplicateExperimentKey(exp)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := groups[key]; !ok {
+			groupKeys = append(groupKeys, key)
+		}
+		groups[key] = append(groups[key], exp)
+	}
+
+	// Within each group, chain the experiments with overlapping windows, in order of their start times
+	clusters := [][]*models.Experiment{}
+	for _, key := range groupKeys {
+		var cluster []*models.Experiment
+		var clusterEnd time.Time
+		for _, exp := range groups[key] {
+			if len(cluster) > 0 && exp.StartTime.Before(clusterEnd) {
+				cluster = append(cluster, exp)
+				if exp.EndTime.After(clusterEnd) {
+					clusterEnd = exp.EndTime
+				}
+				continue
+			}
+			if len(cluster) > 1 {
+				clusters = append(clusters, cluster)
+			}
+			cluster, clusterEnd = []*models.Experiment{exp}, exp.EndTime
+		}
+		if len(cluster) > 1 {
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	return clusters, nil
+}
+
+// nearOverlapMinScore is the minimum overlap score of the segments of two experiments of the same tier with
+// overlapping windows, for them to be considered to nearly overlap
+const nearOverlapMinScore = 0.5
+
+// ListByOrthogonalityRisk scores each active experiment by the number of other active experiments of the same tier
+// that it nearly overlaps with, i.e., whose windows overlap and whose segments have an overlap score of at least
+// nearOverlapMinScore, and returns them sorted by their scores, descending. Experiments with the same score are
+// sorted by their ids. The scores are advisory; the experiments are not modified.
+func (svc *experimentService) ListByOrthogonalityRisk(projectId int64) ([]ExperimentRisk, error) {
+	var exps []*models.Experiment
+	err := svc.query().
+		Where("project_id = ?", projectId).
+		Where("status = ?", models.ExperimentStatusActive).
+		Order("id").
+		Find(&exps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	risks := make([]ExperimentRisk, len(exps))
+	for i, exp := range exps {
+		risks[i] = ExperimentRisk{Experiment: exp, NearOverlaps: []int64{}}
+	}
+	for i := range exps {
+		for j := i + 1; j < len(exps); j++ {
+			a, b := exps[i], exps[j]
+			if a.Tier != b.Tier || !a.StartTime.Before(b.EndTime) || !b.StartTime.Before(a.EndTime) {
+				continue
+			}
+			if getSegmentOverlapScore(a.Segment, b.Segment) < nearOverlapMinScore {
+				continue
+			}
+			risks[i].Score++
+			risks[i].NearOverlaps = append(risks[i].NearOverlaps, b.ID.ToApiSchema())
+			risks[j].Score++
+			risks[j].NearOverlaps = append(risks[j].NearOverlaps, a.ID.ToApiSchema())
+		}
+	}
+
+	sort.SliceStable(risks, func(i, j int) bool {
+		return risks[i].Score > risks[j].Score
+	})
+	return risks, nil
+}
+
+// getSegmentOverlapScore returns the fraction of the segmenters set in either segment on which the two segments
+// overlap, i.e., on which they have a value in common or one of them is unset, as unset segmenters match every value.
+// The score is 1 if the segments overlap on every segmenter, which would fail the orthogonality check, and 0 if they
+// have no value in common on any segmenter.
+func getSegmentOverlapScore(a models.ExperimentSegment, b models.ExperimentSegment) float64 {
+	segmenterNames := map[string]bool{}
+	for name, values := range a {
+		if len(values) > 0 {
+			segmenterNames[name] = true
+		}
+	}
+	for name, values := range b {
+		if len(values) > 0 {
+			segmenterNames[name] = true
+		}
+	}
+	if len(segmenterNames) == 0 {
+		return 1
+	}
+
+	overlapping := 0
+	for name := range segmenterNames {
+		if len(a[name]) == 0 || len(b[name]) == 0 {
+			overlapping++
+			continue
+		}
+		bValues := utils.StringSliceToSet(b[name])
+		for _, value := range a[name] {
+			if bValues.Has(value) {
+				overlapping++
+				break
+			}
+		}
+	}
+	return float64(overlapping) / float64(len(segmenterNames))
+}
+
+// getDuplicateExperimentKey returns a key that is identical for the experiments with the same tier and segment, where
+// the order of the segmenter values and the unset segmenters are disregarded
+func getDuplicateExperimentKey(exp *models.Experiment) (string, error) {
+	segment := models.ExperimentSegment{}
+	for name, values := range exp.Segment {
+		if len(values) == 0 {
+			continue
+		}
+		sortedValues := append([]string{}, values...)
+		sort.Strings(sortedValues)
+		segment[name] = sortedValues
+	}
+	// The keys of the map are sorted when marshalled
+	segmentKey, err := json.Marshal(segment)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", exp.Tier, segmentKey), nil
+}
+
+// ListExperimentsByExactSegment returns the experiments of the given tier, of any status, whose segment is exactly the
+// given segment, as opposed to a subset or superset of it. The order of the values and the segmenters that are unset
+// or set as [] are disregarded.
+func (svc *experimentService) ListExperimentsByExactSegment(
+	projectId int64,
+	segment models.ExperimentSegmentRaw,
+	tier models.ExperimentTier,
+) ([]*models.Experiment, error) {
+	if tier != models.ExperimentTierDefault && tier != models.ExperimentTierOverride {
+		return nil, errors.Newf(errors.BadInput, "unknown experiment tier: %s", tier)
+	}
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return nil, err
+	}
+	segmenterStorageSchema, err := segment.ToStorageSchema(segmenterTypes)
+	if err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+	predicate, err := querybuilder.SegmentEquals(segmenterStorageSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	var exps []*models.Experiment
+	err = svc.query().
+		Where("project_id = ?", projectId).
+		Where("tier = ?", tier).
+		Where(predicate).
+		Order("id").
+		Find(&exps).Error
+	if err != nil {
+		return nil, err
+	}
+	return exps, nil
+}
+
+// FindSegmentIntersections returns the active experiments of the given tier that share at least one value with the
+// given segment, on any of the segmenters set in the segment. This is weaker than an orthogonality conflict, which
+// requires the segments to intersect on every segmenter, and is meant for analysing the impact of a candidate segment.
+func (svc *experimentService) FindSegmentIntersections(
+	projectId int64,
+	segment models.ExperimentSegmentRaw,
+	tier models.ExperimentTier,
+) ([]*models.Experiment, error) {
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return nil, err
+	}
+	segmenterStorageSchema, err := segment.ToStorageSchema(segmenterTypes)
+	if err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+
+	hasValues := false
+	for _, values := range segmenterStorageSchema {
+		hasValues = hasValues || len(values) > 0
+	}
+	if !hasValues {
+		return []*models.Experiment{}, nil
+	}
+	storage, err := svc.getSegmentStorage(projectId)
+	if err != nil {
+		return nil, err
+	}
+	predicate := querybuilder.SegmentSharesAnyValue(segmenterStorageSchema, storage)
+
+	var exps []*models.Experiment
+	err = svc.query().
+		Where("project_id = ?", projectId).
+		Where("status = ?", models.ExperimentStatusActive).
+		Where("tier = ?", tier).
+		Where(predicate).
+		Order("id").
+		Find(&exps).Error
+	if err != nil {
+		return nil, err
+	}
+	return exps, nil
+}
+
+// ListCompetingExperiments returns the active experiments of the given tier that compete with the given segment in the
+// time window [startTime, endTime], i.e., whose segments match the segment and whose time windows overlap the window.
+// If includeWeak is set, the experiments that do not set some segmenter of the segment are matched as well, as per
+// the list filters. The experiments are ordered by their ids.
+func (svc *experimentService) ListCompetingExperiments(
+	projectId int64,
+	segment models.ExperimentSegmentRaw,
+	tier models.ExperimentTier,
+	startTime time.Time,
+	endTime time.Time,
+	includeWeak bool,
+) ([]*models.Experiment, error) {
+	if startTime.After(endTime) {
+		return nil, errors.Newf(errors.BadInput, "start time must not be after end time")
 	}
-	return query
+
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return nil, err
+	}
+	segmenterStorageSchema, err := segment.ToStorageSchema(segmenterTypes)
+	if err != nil {
+		return nil, errors.Newf(errors.BadInput, err.Error())
+	}
+
+	query := svc.query().
+		Where("project_id = ?", projectId).
+		Where("status = ?", models.ExperimentStatusActive).
+		Where("tier = ?", tier)
+	query, err = svc.filterStartEndTimeValues(query, ListExperimentsParams{StartTime: &startTime, EndTime: &endTime})
+	if err != nil {
+		return nil, err
+	}
+	defaultSegment, err := svc.getDefaultSegment(projectId)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := svc.getSegmentStorage(projectId)
+	if err != nil {
+		return nil, err
+	}
+	query = svc.filterSegmenterValues(query, segmenterStorageSchema, includeWeak, nil, defaultSegment, storage)
+
+	var exps []*models.Experiment
+	if err = query.Order("id").Find(&exps).Error; err != nil {
+		return nil, err
+	}
+	return exps, nil
 }
 
-func validateListExperimentFieldNames(fields []models.ExperimentField) error {
-	allowedFieldList := []interface{}{
-		models.ExperimentFieldId,
-		models.ExperimentFieldName,
-		models.ExperimentFieldStartTime,
-		models.ExperimentFieldEndTime,
-		models.ExperimentFieldTier,
-		models.ExperimentFieldType,
-		models.ExperimentFieldStatusFriendly,
-		models.ExperimentFieldUpdatedAt,
-		models.ExperimentFieldTreatments,
+// GetSegmentCoverage returns the fraction, in [0, 1], of the project's segment space that is covered by the running
+// active experiments of the given tier. The segment space is the product of the options of the project's segmenters,
+// with every combination of the options weighted equally. The following assumptions are made:
+//   - Segmenters without options, e.g., s2_ids, cannot be enumerated and are left out of the segment space, i.e., an
+//     experiment is considered to cover all their values, whether or not it sets them.
+//   - A segmenter that is not set by an experiment, nor by the project's default segment, covers all its options.
+//   - Values that are not among the options of a segmenter are disregarded.
+func (svc *experimentService) GetSegmentCoverage(projectId int64, tier models.ExperimentTier) (float64, error) {
+	config, err := svc.getProjectConfig(projectId)
+	if err != nil {
+		return 0, err
 	}
-	allowedFields := set.New(allowedFieldList...)
-	for _, field := range fields {
-		if !allowedFields.Has(field) {
-			return fmt.Errorf("field %s is not supported, fields should only be name and/or id", field)
+	segmenterConfigs, err := svc.services.SegmenterService.GetSegmenterConfigurations(
+		projectId,
+		config.Segmenters.Names,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defaultSegment, err := svc.toStorageDefaultSegment(projectId, config.DefaultSegment)
+	if err != nil {
+		return 0, err
+	}
+
+	// Collect the options of the enumerable segmenters, in the storage format of the experiment segments
+	segmenterOptions := []segmenterCoverageOptions{}
+	for _, segmenterConfig := range segmenterConfigs {
+		options := map[string]bool{}
+		for _, option := range segmenterConfig.GetOptions() {
+			options[formatSegmenterOption(option)] = true
+		}
+		if len(options) > 0 {
+			segmenterOptions = append(segmenterOptions, segmenterCoverageOptions{
+				name:    segmenterConfig.GetName(),
+				options: options,
+			})
 		}
 	}
-	return nil
+
+	now := svc.clock.Now()
+	var exps []*models.Experiment
+	err = svc.query().
+		Where("project_id = ?", projectId).
+		Where("status = ?", models.ExperimentStatusActive).
+		Where("tier = ?", tier).
+		Where("start_time <= ?", now).
+		Where("end_time > ?", now).
+		Find(&exps).Error
+	if err != nil {
+		return 0, err
+	}
+	segments := []models.ExperimentSegment{}
+	for _, exp := range exps {
+		segments = append(segments, exp.Segment.WithDefaults(defaultSegment))
+	}
+
+	return getSegmentCoverage(segmenterOptions, segments), nil
 }
 
-func (svc *experimentService) validateExperimentOrthogonality(
+// MatchExperiments returns the active experiments of the given tier, running at the given time, whose segments are
+// satisfied by the segmenter values of a unit, i.e., that have the unit's value of every segmenter that they constrain.
+// The segmenters that an experiment does not set take on the values of the project's default segment, if any. The
+// unit's values are compared in the string storage schema of the segments. This is the inverse of the segment filter
+// of ListExperiments, and is meant for finding the experiments that a unit was assigned to.
+func (svc *experimentService) MatchExperiments(
+	projectId int64,
+	tier models.ExperimentTier,
+	unitSegment map[string]string,
+	at time.Time,
+) ([]*models.Experiment, error) {
+	if tier != models.ExperimentTierDefault && tier != models.ExperimentTierOverride {
+		return nil, errors.Newf(errors.BadInput, "unknown experiment tier: %s", tier)
+	}
+	defaultSegment, err := svc.getDefaultSegment(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	var exps []*models.Experiment
+	err = svc.query().
+		Where("project_id = ?", projectId).
+		Where("status = ?", models.ExperimentStatusActive).
+		Where("tier = ?", tier).
+		Where("start_time <= ?", at).
+		Where("end_time > ?", at).
+		Order("id").
+		Find(&exps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []*models.Experiment{}
+	for _, exp := range exps {
+		if isSegmentSatisfiedByUnit(exp.Segment.WithDefaults(defaultSegment), unitSegment) {
+			matches = append(matches, exp)
+		}
+	}
+	return matches, nil
+}
+
+// ResolveOverlap returns the experiment that takes effect for a unit with the given segmenter values, of the active
+// experiments of the given tier that match the unit at the given time, as in MatchExperiments. Of the overlapping
+// experiments, the one with the highest priority wins, and of those with the same priority, the one created first, so
+// that the winner is deterministic. Nil is returned if no experiment matches. In the projects that enforce strict
+// orthogonality, at most one experiment matches, and it is returned regardless of its priority.
+func (svc *experimentService) ResolveOverlap(
 	projectId int64,
+	segment map[string]string,
+	tier models.ExperimentTier,
+	at time.Time,
+) (*models.Experiment, error) {
+	matches, err := svc.MatchExperiments(projectId, tier, segment, at)
+	if err != nil {
+		return nil, err
+	}
+
+	// The matches are ordered by id, so only a strictly higher priority replaces the winner
+	var winner *models.Experiment
+	for _, exp := range matches {
+		if winner == nil || exp.Priority > winner.Priority {
+			winner = exp
+		}
+	}
+	return winner, nil
+}
+
+// isSegmentSatisfiedByUnit returns whether the unit has one of the segment's values for every segmenter that the
+// segment constrains. A unit without a value for a constrained segmenter does not satisfy the segment.
+func isSegmentSatisfiedByUnit(segment models.ExperimentSegment, unitSegment map[string]string) bool {
+	for name, values := range segment {
+		if len(values) == 0 {
+			continue
+		}
+		unitValue, ok := unitSegment[name]
+		if !ok || !utils.StringSliceToSet(values).Has(unitValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// segmenterCoverageOptions holds the options of a segmenter that make up its dimension of the segment space
+type segmenterCoverageOptions struct {
+	name    string
+	options map[string]bool
+}
+
+// getSegmentCoverage returns the fraction of the space spanned by the given segmenters that is covered by the union of
+// the given segments. The space is partitioned by the options of one segmenter at a time, only descending into the
+// options that are covered by some segment, so that the space is not enumerated where it is not covered.
+func getSegmentCoverage(segmenters []segmenterCoverageOptions, segments []models.ExperimentSegment) float64 {
+	if len(segments) == 0 {
+		return 0
+	}
+	if len(segmenters) == 0 {
+		return 1
+	}
+
+	segmenter := segmenters[0]
+	coverage := 0.0
+	for option := range segmenter.options {
+		coveringSegments := []models.ExperimentSegment{}
+		for _, segment := range segments {
+			values := segment[segmenter.name]
+			if len(values) == 0 || utils.StringSliceToSet(values).Has(option) {
+				coveringSegments = append(coveringSegments, segment)
+			}
+		}
+		coverage += getSegmentCoverage(segmenters[1:], coveringSegments)
+	}
+	return coverage / float64(len(segmenter.options))
+}
+
+// formatSegmenterOption formats the value of a segmenter option in the storage format of the experiment segments
+func formatSegmenterOption(value *_segmenters.SegmenterValue) string {
+	switch val := value.GetValue().(type) {
+	case *_segmenters.SegmenterValue_String_:
+		return val.String_
+	case *_segmenters.SegmenterValue_Integer:
+		return strconv.FormatInt(val.Integer, 10)
+	case *_segmenters.SegmenterValue_Real:
+		return strconv.FormatFloat(val.Real, 'f', -1, 64)
+	case *_segmenters.SegmenterValue_Bool:
+		return strconv.FormatBool(val.Bool)
+	}
+	return ""
+}
+
+// checkFailureHandler handles the failure of a validation check. The validation stops at the first failure for which
+// the handler returns an error, and returns that error.
+type checkFailureHandler func(check ValidationCheck, err error) error
+
+// failOnCheckFailure is the checkFailureHandler that stops the validation at the first failure
+func failOnCheckFailure(_ ValidationCheck, err error) error {
+	return err
+}
+
+// validateActivation runs the checks of an active experiment against the project's other experiments, i.e., the segment
+// orthogonality, the mutex group and the switchback interval checks, and, if the experiment is being activated, the
+// active experiment limit check. The experiment's segment is given in its raw form.
+func (svc *experimentService) validateActivation(
+	settings models.Settings,
 	experimentId *int64,
 	segment models.ExperimentSegmentRaw,
-	experiments []*models.Experiment,
-	segmenters []string,
+	experiment *models.Experiment,
+	activating bool,
 ) error {
-	var err error
-	var filteredExps []models.Experiment
-	for _, exp := range experiments {
-		// Case: Update experiment ONLY; Exclude current experiment id's segments
-		if experimentId != nil {
-			if exp.ID.ToApiSchema() != *experimentId {
-				filteredExps = append(filteredExps, *exp)
-			}
-			continue
+	return svc.runActivationChecks(settings, experimentId, segment, experiment, activating, failOnCheckFailure)
+}
+
+// runActivationChecks runs the checks of validateActivation, passing the failed checks to onFailure
+func (svc *experimentService) runActivationChecks(
+	settings models.Settings,
+	experimentId *int64,
+	segment models.ExperimentSegmentRaw,
+	experiment *models.Experiment,
+	activating bool,
+	onFailure checkFailureHandler,
+) error {
+	err := svc.validateExperimentOrthogonalityInDuration(experimentId, settings, segment, experiment.Tier,
+		experiment.Shadow, experiment.StartTime, experiment.EndTime)
+	if err != nil {
+		if err = onFailure(ValidationCheckOrthogonality, err); err != nil {
+			return err
 		}
-		filteredExps = append(filteredExps, *exp)
 	}
-	if len(filteredExps) > 0 {
-		err = svc.services.SegmenterService.ValidateSegmentOrthogonality(projectId, segmenters, segment, filteredExps)
-		if err != nil {
-			return errors.Newf(errors.BadInput, err.Error())
+
+	err = svc.validateExperimentMutexGroup(experimentId, settings, experiment.MutexGroup, experiment.StartTime,
+		experiment.EndTime)
+	if err != nil {
+		if err = onFailure(ValidationCheckMutexGroup, err); err != nil {
+			return err
 		}
 	}
 
+	if err = svc.validateSwitchbackIntervals(experiment); err != nil {
+		if err = onFailure(ValidationCheckSwitchbackInterval, err); err != nil {
+			return err
+		}
+	}
+
+	if activating {
+		err = svc.validateActiveExperimentLimit(settings, experimentId, experiment.StartTime, experiment.EndTime)
+		if err != nil {
+			return onFailure(ValidationCheckActiveExperimentLimit, err)
+		}
+	}
 	return nil
 }
 
-func filterSegmenterAnyOfPredicate(query *gorm.DB, name string, values []string, includeWeakMatch bool) *gorm.DB {
-	if len(values) == 0 {
-		return query
+func (svc *experimentService) validateExperimentOrthogonalityInDuration(
+	experimentId *int64,
+	settings models.Settings,
+	segment models.ExperimentSegmentRaw,
+	tier models.ExperimentTier,
+	shadow bool,
+	startTime time.Time,
+	endTime time.Time,
+) error {
+	// Shadow experiments do not affect the traffic, so they cannot conflict with the other experiments
+	if shadow {
+		return nil
+	}
+	status := models.ExperimentStatusActive
+	listEndTime := endTime
+	if settings.Config.OrthogonalityLookahead > 0 {
+		listEndTime = endTime.Add(settings.Config.OrthogonalityLookahead)
+	}
+	listExpParams := ListExperimentsParams{StartTime: &startTime, EndTime: &listEndTime, Status: &status, Tier: &tier}
+	candidateExps, err := svc.ListAllExperiments(settings.ProjectID, listExpParams)
+	if err != nil {
+		return err
 	}
-	// Prepare SQL predicate and add to the query
-	matchArray := []string{}
-	for _, val := range values {
-		matchArray = append(matchArray, fmt.Sprintf("'{\"%s\": [\"%s\"]}'", name, val))
+
+	// Only the experiments that overlap the actual time window may fail the check; the conflicts with the experiments
+	// that start within the lookahead are only warned of
+	exps := []*models.Experiment{}
+	lookaheadExps := []*models.Experiment{}
+	for _, exp := range candidateExps {
+		if exp.StartTime.Before(endTime) {
+			exps = append(exps, exp)
+		} else {
+			lookaheadExps = append(lookaheadExps, exp)
+		}
 	}
-	predicate := fmt.Sprintf("segment @> ANY (ARRAY [%s]::jsonb[])", strings.Join(matchArray, ","))
-	// Include weak matches if the flag is set
-	if includeWeakMatch {
-		predicate = fmt.Sprintf("(%s OR %s OR %s)",
-			predicate,
-			fmt.Sprintf("NOT (segment ?| '{%v}')", name), // The segment does not exist in the experiment
-			fmt.Sprintf("segment-> '%s' = '[]'", name),   // The segment is set as []
+	if len(lookaheadExps) > 0 {
+		err = svc.validateExperimentOrthogonality(
+			int64(settings.ProjectID),
+			experimentId,
+			segment,
+			lookaheadExps,
+			settings.Config.Segmenters.Names,
+			settings.Config.OrthogonalityExemptSegmenters,
+			settings.Config.DefaultSegment,
 		)
+		if err != nil {
+			log.Printf("Warning: experiment scheduled within the orthogonality lookahead of %s: %s",
+				settings.Config.OrthogonalityLookahead, err.Error())
+		}
 	}
-	return query.Where(predicate)
-}
 
-// ListAllExperiments returns a list of all experiments based on the filters specified in params parameter,
-// to be used for performing orthogonality checks on.
-func (svc *experimentService) ListAllExperiments(projectId models.ID, params ListExperimentsParams) ([]*models.Experiment, error) {
-	// Get the first page of active experiments
-	filteredExperiments, paging, err := svc.ListExperiments(
-		projectId.ToApiSchema(),
-		params,
+	err = svc.validateExperimentOrthogonality(
+		int64(settings.ProjectID),
+		experimentId,
+		segment,
+		exps,
+		settings.Config.Segmenters.Names,
+		settings.Config.OrthogonalityExemptSegmenters,
+		settings.Config.DefaultSegment,
 	)
+	err = resolvableOrthogonalityError(settings.Config, err)
+	if err != nil && experimentId == nil {
+		// Report the conflicts of a new experiment with an identical one specifically, as they are likely to be
+		// unintended
+		duplicateExp, findErr := svc.findDuplicateExperiment(int64(settings.ProjectID), segment, tier, exps)
+		if findErr != nil {
+			return findErr
+		}
+		if duplicateExp != nil {
+			return errors.Newf(errors.Duplicate,
+				"experiment %s (id %d) has the same segment and tier, and an overlapping time window; "+
+					"edit the existing experiment instead of creating a duplicate",
+				duplicateExp.Name, duplicateExp.ID)
+		}
+	}
+	return err
+}
+
+// resolvableOrthogonalityError returns the given orthogonality error, unless the project resolves the overlaps between
+// its experiments by priority and the error is a conflict, in which case the error is only logged as a warning. The
+// other errors mean that the check could not be run, and are always returned.
+func resolvableOrthogonalityError(config *models.ExperimentationConfig, err error) error {
+	if err != nil && config.PriorityOverlapResolution && errors.GetType(err) == errors.BadInput {
+		log.Printf("Warning: overlapping experiments are resolved by priority: %s", err.Error())
+		return nil
+	}
+	return err
+}
+
+// findDuplicateExperiment returns the first of the given experiments whose segment and tier are identical to the given
+// ones, or nil if there is none
+func (svc *experimentService) findDuplicateExperiment(
+	projectId int64,
+	segment models.ExperimentSegmentRaw,
+	tier models.ExperimentTier,
+	experiments []*models.Experiment,
+) (*models.Experiment, error) {
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
 	if err != nil {
 		return nil, err
 	}
-	if paging == nil {
-		// This is not expected (the pagination data should always be set), but handle it.
-		return nil, fmt.Errorf("Missing pagination data for existing experiments")
+	segmenterStorageSchema, err := segment.ToStorageSchema(segmenterTypes)
+	if err != nil {
+		return nil, err
+	}
+	key, err := getDuplicateExperimentKey(&models.Experiment{Segment: segmenterStorageSchema, Tier: tier})
+	if err != nil {
+		return nil, err
 	}
 
-	// If there are multiple pages, get the subsequent pages
-	for i := int32(2); i <= paging.Pages; i++ {
-		exps, _, err := svc.ListExperiments(
-			projectId.ToApiSchema(),
-			ListExperimentsParams{
-				StartTime: params.StartTime,
-				EndTime:   params.EndTime,
-				Status:    params.Status,
-				PaginationOptions: pagination.PaginationOptions{
-					Page: &i,
-				},
-			},
-		)
+	for _, exp := range experiments {
+		expKey, err := getDuplicateExperimentKey(exp)
 		if err != nil {
 			return nil, err
 		}
-		filteredExperiments = append(filteredExperiments, exps...)
+		if expKey == key {
+			return exp, nil
+		}
+	}
+	return nil, nil
+}
+
+// validateExperimentMutexGroup checks that no other experiment in the given mutex group is active at any time within
+// the given duration, regardless of their segments. If the mutex group is not set, there is nothing to check.
+func (svc *experimentService) validateExperimentMutexGroup(
+	experimentId *int64,
+	settings models.Settings,
+	mutexGroup *string,
+	startTime time.Time,
+	endTime time.Time,
+) error {
+	if mutexGroup == nil {
+		return nil
+	}
+
+	query := svc.query().
+		Where("project_id = ?", settings.ProjectID).
+		Where("status = ?", models.ExperimentStatusActive).
+		Where("mutex_group = ?", *mutexGroup)
+	if experimentId != nil {
+		query = query.Where("id != ?", *experimentId)
+	}
+	query, err := svc.filterStartEndTimeValues(query, ListExperimentsParams{StartTime: &startTime, EndTime: &endTime})
+	if err != nil {
+		return err
+	}
+
+	var exps []*models.Experiment
+	if err = query.Order("id").Limit(1).Find(&exps).Error; err != nil {
+		return err
+	}
+	if len(exps) > 0 {
+		return errors.Newf(errors.BadInput,
+			"experiment %s (id %d) in the same mutex group %s is already active in the given time range",
+			exps[0].Name, exps[0].ID, *mutexGroup)
 	}
+	return nil
+}
+
+// validateSwitchbackIntervals checks that the interval of the given Switchback experiment is compatible with those of
+// the other active Switchback experiments, of either tier, whose segments and durations overlap with its own, so that
+// the assignments downstream switch at common boundaries. The intervals are compatible if one is a multiple of the
+// other. There is nothing to check for the other types of experiments.
+func (svc *experimentService) validateSwitchbackIntervals(experiment *models.Experiment) error {
+	if experiment.Type != models.ExperimentTypeSwitchback {
+		return nil
+	}
+
+	projectId := experiment.ProjectID.ToApiSchema()
+	defaultSegment, err := svc.getDefaultSegment(projectId)
+	if err != nil {
+		return err
+	}
+	interval := experiment.IntervalOrZero()
+	for _, tier := range []models.ExperimentTier{models.ExperimentTierDefault, models.ExperimentTierOverride} {
+		exps, err := svc.findOverlappingActiveExperiments(projectId, tier, experiment, &experiment.ID, defaultSegment)
+		if err != nil {
+			return err
+		}
+		for _, exp := range exps {
+			if exp.Type != models.ExperimentTypeSwitchback || switchbackIntervalsCompatible(interval, exp.IntervalOrZero()) {
+				continue
+			}
+			return errors.Newf(errors.BadInput,
+				"switchback interval of %d minutes is incompatible with the interval of %d minutes of the overlapping "+
+					"switchback experiment %s (id %d); the intervals must be equal or multiples of each other",
+				interval, exp.IntervalOrZero(), exp.Name, exp.ID)
+		}
+	}
+	return nil
+}
 
-	return filteredExperiments, nil
+// switchbackIntervalsCompatible returns true if one of the intervals is a multiple of the other. Unset intervals are
+// left to the validation of the request.
+func switchbackIntervalsCompatible(interval int32, otherInterval int32) bool {
+	if interval <= 0 || otherInterval <= 0 {
+		return true
+	}
+	return interval%otherInterval == 0 || otherInterval%interval == 0
 }
 
-func (svc *experimentService) validateExperimentOrthogonalityInDuration(
-	experimentId *int64,
+// validateActiveExperimentLimit checks that activating an experiment in the given duration does not take the number
+// of the project's running experiments beyond the project's hard maximum. Only the running experiments are counted, so
+// there is nothing to check if the experiment is not running at the current time.
+func (svc *experimentService) validateActiveExperimentLimit(
 	settings models.Settings,
-	segment models.ExperimentSegmentRaw,
-	tier models.ExperimentTier,
+	experimentId *int64,
 	startTime time.Time,
 	endTime time.Time,
 ) error {
-	status := models.ExperimentStatusActive
-	listExpParams := ListExperimentsParams{StartTime: &startTime, EndTime: &endTime, Status: &status, Tier: &tier}
-	exps, err := svc.ListAllExperiments(settings.ProjectID, listExpParams)
+	if settings.Config == nil || settings.Config.HardMaxActiveExperiments <= 0 {
+		return nil
+	}
+	now := svc.clock.Now()
+	if now.Before(startTime) || !now.Before(endTime) {
+		return nil
+	}
+
+	count, err := svc.countRunningExperiments(int64(settings.ProjectID), experimentId)
 	if err != nil {
 		return err
 	}
-	return svc.validateExperimentOrthogonality(
-		int64(settings.ProjectID),
-		experimentId,
-		segment,
-		exps,
-		settings.Config.Segmenters.Names,
-	)
+	if count+1 > int64(settings.Config.HardMaxActiveExperiments) {
+		return errors.Newf(errors.BadInput,
+			"project %d already has %d running experiments, the maximum allowed is %d",
+			settings.ProjectID, count, settings.Config.HardMaxActiveExperiments)
+	}
+	return nil
+}
+
+// countRunningExperiments counts the project's experiments that are active at the current time, other than the given
+// experiment, if any
+func (svc *experimentService) countRunningExperiments(projectId int64, experimentId *int64) (int64, error) {
+	query := svc.query().
+		Model(&models.Experiment{}).
+		Where("project_id = ?", projectId)
+	if experimentId != nil {
+		query = query.Where("id != ?", *experimentId)
+	}
+	query = svc.filterExperimentStatusFriendly(query, []ExperimentStatusFriendly{ExperimentStatusFriendlyRunning})
+
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
 }
 
+// ValidatePairwiseExperimentOrthogonality checks that no two of the given experiments of the same tier overlap on the
+// given segmenters, comparing their effective segments, with the given project default segment applied
 func (svc *experimentService) ValidatePairwiseExperimentOrthogonality(
 	projectId int64,
 	experiments []*models.Experiment,
 	segmenters []string,
+	defaultSegment models.ExperimentSegmentRaw,
 ) error {
 	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
 	if err != nil {
@@ -794,6 +4047,9 @@ func (svc *experimentService) ValidatePairwiseExperimentOrthogonality(
 	for i := 0; i < len(experiments)-1; i++ {
 		currExp := experiments[i]
 		otherExps := experiments[i+1:] // Take all the remaining elements
+		if currExp.Shadow {
+			continue
+		}
 		experimentId := currExp.ID.ToApiSchema()
 
 		// Filter other experiments by the same tier
@@ -814,6 +4070,8 @@ func (svc *experimentService) ValidatePairwiseExperimentOrthogonality(
 			rawSegments,
 			otherExpsByTier,
 			segmenters,
+			nil,
+			defaultSegment,
 		)
 		if err != nil {
 			return errors.Newf(
@@ -826,6 +4084,158 @@ func (svc *experimentService) ValidatePairwiseExperimentOrthogonality(
 	return nil
 }
 
+// SimulateOrthogonalityWithSegmenters checks the pairwise orthogonality of the project's active experiments that have
+// not ended, as it would be checked if the project's segmenters were the given ones, e.g., to see whether adding a
+// segmenter resolves the conflicts between existing experiments. The project's orthogonality exempt segmenters still
+// apply. Nothing is modified; an error is only returned if the check could not be run.
+func (svc *experimentService) SimulateOrthogonalityWithSegmenters(
+	projectId int64,
+	segmenters []string,
+) (ValidationReport, error) {
+	config, err := svc.getProjectConfig(projectId)
+	if err != nil {
+		return ValidationReport{}, err
+	}
+
+	status := models.ExperimentStatusActive
+	startTime := svc.clock.Now()
+	endTime := startTime.Add(855360 * time.Hour)
+	exps, err := svc.ListAllExperiments(
+		models.ID(projectId),
+		ListExperimentsParams{StartTime: &startTime, EndTime: &endTime, Status: &status},
+	)
+	if err != nil {
+		return ValidationReport{}, err
+	}
+
+	report := ValidationReport{Passed: true, Failures: map[ValidationCheck]string{}}
+	err = svc.ValidatePairwiseExperimentOrthogonality(
+		projectId,
+		exps,
+		getOrthogonalitySegmenters(segmenters, config.OrthogonalityExemptSegmenters),
+		config.DefaultSegment,
+	)
+	if err != nil {
+		// Only the conflicts are reported, the other errors mean that the check could not be run
+		if errors.GetType(err) != errors.BadInput {
+			return ValidationReport{}, err
+		}
+		report.Passed = false
+		report.Failures[ValidationCheckOrthogonality] = err.Error()
+	}
+	return report, nil
+}
+
+// ReconcileOrthogonality checks the pairwise orthogonality of the project's active experiments that have not ended,
+// e.g., after a migration of the stored segments, and reports the conflicting pairs of the same tier with overlapping
+// time windows. If autoDisable is set, the experiment of each conflicting pair that does not take precedence is
+// disabled, unless either has already been disabled for an earlier conflict. The experiments are disabled as by
+// DisableExperiment, in a single transaction with their histories written together, publishing the updates once it
+// completes, and the outcome for each is reported. Projects
+// that resolve the overlaps by priority have no conflicts to reconcile.
+func (svc *experimentService) ReconcileOrthogonality(settings models.Settings, autoDisable bool) (ReconcileReport, error) {
+	report := ReconcileReport{
+		Conflicts: []OrthogonalityConflict{},
+		Disabled:  BulkResult{Succeeded: []int64{}, Failed: map[int64]string{}},
+	}
+	projectId := int64(settings.ProjectID)
+	if autoDisable {
+		if err := svc.validateProjectUnlocked(projectId); err != nil {
+			return report, err
+		}
+	}
+
+	// Order the experiments by their precedence
+	var exps []*models.Experiment
+	err := svc.query().
+		Where("project_id = ?", projectId).
+		Where("status = ?", models.ExperimentStatusActive).
+		Where("end_time > ?", svc.clock.Now()).
+		Where("shadow = ?", false).
+		Order("priority DESC").
+		Order("created_at").
+		Order("id").
+		Find(&exps).Error
+	if err != nil {
+		return report, err
+	}
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return report, err
+	}
+
+	curExperiments := []*models.Experiment{}
+	err = svc.query().Transaction(func(tx *gorm.DB) error {
+		txSvc := svc.withTransaction(tx)
+		disabled := map[models.ID]bool{}
+		for i, exp := range exps {
+			rawSegment, err := exp.Segment.ToRawSchema(segmenterTypes)
+			if err != nil {
+				return err
+			}
+			experimentId := exp.ID.ToApiSchema()
+			for _, other := range exps[i+1:] {
+				if exp.Tier != other.Tier || !exp.StartTime.Before(other.EndTime) || !other.StartTime.Before(exp.EndTime) {
+					continue
+				}
+				err = txSvc.validateExperimentOrthogonality(
+					projectId,
+					&experimentId,
+					rawSegment,
+					[]*models.Experiment{other},
+					settings.Config.Segmenters.Names,
+					settings.Config.OrthogonalityExemptSegmenters,
+					settings.Config.DefaultSegment,
+				)
+				err = resolvableOrthogonalityError(settings.Config, err)
+				if err == nil {
+					continue
+				}
+				// Only the conflicts are reported, the other errors mean that the check could not be run
+				if errors.GetType(err) != errors.BadInput {
+					return err
+				}
+				report.Conflicts = append(report.Conflicts, OrthogonalityConflict{
+					ExperimentID:            experimentId,
+					ConflictingExperimentID: other.ID.ToApiSchema(),
+					Reason:                  err.Error(),
+				})
+
+				if !autoDisable || disabled[exp.ID] || disabled[other.ID] {
+					continue
+				}
+				var curExperiment *models.Experiment
+				err = txSvc.withSavepoint(func(itemSvc *experimentService) error {
+					var err error
+					curExperiment, err = itemSvc.disableExperiment(projectId, other.ID.ToApiSchema())
+					return err
+				})
+				if err != nil {
+					report.Disabled.Failed[other.ID.ToApiSchema()] = err.Error()
+					continue
+				}
+				disabled[other.ID] = true
+				report.Disabled.Succeeded = append(report.Disabled.Succeeded, other.ID.ToApiSchema())
+				curExperiments = append(curExperiments, curExperiment)
+			}
+		}
+		//  Copy the experiments' contents before the update as experiment histories
+		return svc.services.ExperimentHistoryService.WithTransaction(tx).CreateExperimentHistories(curExperiments)
+	})
+	if err != nil {
+		report.Disabled = BulkResult{Succeeded: []int64{}, Failed: map[int64]string{}}
+		return report, err
+	}
+
+	for _, curExperiment := range curExperiments {
+		err = svc.publishExperimentChange(settings.ProjectID, curExperiment.ID, segmenterTypes, ExperimentEventOpDisable)
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
 // ValidateProjectExperimentSegmentersExist checks if the set of segmenters given contains all the segments specified
 // by all the experiments
 func (svc *experimentService) ValidateProjectExperimentSegmentersExist(
@@ -857,6 +4267,212 @@ func (svc *experimentService) ValidateProjectExperimentSegmentersExist(
 	return nil
 }
 
+// ValidateExperimentAgainstSettings runs all the checks on the given experiment against the given settings, which need
+// not be the current settings of the project, and returns the full report. Unlike RunCustomValidation, the outcome is
+// not recorded on the experiment. An error is only returned if the checks could not be run.
+func (svc *experimentService) ValidateExperimentAgainstSettings(
+	experiment models.Experiment,
+	settings models.Settings,
+) (ValidationReport, error) {
+	report := ValidationReport{Passed: true, Failures: map[ValidationCheck]string{}}
+	addFailure := func(check ValidationCheck, err error) {
+		report.Passed = false
+		report.Failures[check] = err.Error()
+	}
+
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(int64(settings.ProjectID))
+	if err != nil {
+		return ValidationReport{}, err
+	}
+	rawSegment, err := experiment.Segment.ToRawSchema(segmenterTypes)
+	if err != nil {
+		return ValidationReport{}, err
+	}
+	var segmenterNames []string
+	if settings.Config != nil {
+		segmenterNames = settings.Config.Segmenters.Names
+	}
+
+	err = validateExperimentSegmentersExist(experiment.Name, rawSegment, utils.StringSliceToSet(segmenterNames))
+	if err != nil {
+		addFailure(ValidationCheckSegmentersExist, err)
+	}
+	err = svc.services.SegmenterService.ValidateExperimentSegment(int64(settings.ProjectID), segmenterNames, rawSegment)
+	if err != nil {
+		addFailure(ValidationCheckSegment, err)
+	} else {
+		warnings, err := svc.services.SegmenterService.GetExperimentSegmentWarnings(
+			int64(settings.ProjectID),
+			segmenterNames,
+			rawSegment,
+		)
+		if err != nil {
+			return ValidationReport{}, err
+		}
+		report.Warnings = append(report.Warnings, warnings...)
+	}
+
+	// Existing experiments are validated as an update to themselves
+	operationType, context := OperationTypeCreate, ValidationContext{}
+	if experiment.ID != 0 {
+		operationType, context = OperationTypeUpdate, ValidationContext{CurrentData: experiment}
+	}
+	warnings, err := svc.runCustomValidation(experiment, settings, context, operationType)
+	if err != nil {
+		addFailure(ValidationCheckCustomValidation, err)
+	}
+	report.Warnings = append(report.Warnings, warnings...)
+
+	return report, nil
+}
+
+// PreviewSettingsImpact validates every experiment of the project against both the current and the proposed settings,
+// as in ValidateExperimentAgainstSettings, and reports the checks that an experiment would fail under the proposed
+// settings, that it passes, or fails for a different reason, under the current settings. As the validation url of
+// the proposed settings is called for every experiment, this is only meant to be run on demand, e.g., before saving
+// the settings. Nothing is modified.
+func (svc *experimentService) PreviewSettingsImpact(
+	projectId int64,
+	proposedSettings models.Settings,
+) (SettingsImpactReport, error) {
+	currentSettings, err := svc.services.ProjectSettingsService.GetDBRecord(models.ID(projectId))
+	if err != nil {
+		return SettingsImpactReport{}, err
+	}
+	proposedSettings.ProjectID = models.ID(projectId)
+
+	var exps []*models.Experiment
+	if err = svc.query().Where("project_id = ?", projectId).Order("id").Find(&exps).Error; err != nil {
+		return SettingsImpactReport{}, err
+	}
+
+	report := SettingsImpactReport{NewlyInvalid: map[int64]map[ValidationCheck]string{}}
+	for _, exp := range exps {
+		proposedReport, err := svc.ValidateExperimentAgainstSettings(*exp, proposedSettings)
+		if err != nil {
+			return SettingsImpactReport{}, err
+		}
+		if proposedReport.Passed {
+			continue
+		}
+		currentReport, err := svc.ValidateExperimentAgainstSettings(*exp, *currentSettings)
+		if err != nil {
+			return SettingsImpactReport{}, err
+		}
+
+		failures := map[ValidationCheck]string{}
+		for check, reason := range proposedReport.Failures {
+			if currentReason, ok := currentReport.Failures[check]; !ok || currentReason != reason {
+				failures[check] = reason
+			}
+		}
+		if len(failures) > 0 {
+			report.NewlyInvalid[exp.ID.ToApiSchema()] = failures
+		}
+	}
+
+	return report, nil
+}
+
+// ValidateAllExperiments checks all the experiments of the project against the current project settings, and returns
+// every problem found. The segmenters, segment values and treatment schema are checked for all the experiments,
+// whereas the orthogonality is only checked for the active experiments, as the inactive ones are not served. The
+// validation url and custom validators are not run. Nothing is modified.
+func (svc *experimentService) ValidateAllExperiments(projectId int64) (ProjectHealthReport, error) {
+	settings, err := svc.services.ProjectSettingsService.GetDBRecord(models.ID(projectId))
+	if err != nil {
+		return ProjectHealthReport{}, err
+	}
+	config := settings.Config
+	if config == nil {
+		config = &models.ExperimentationConfig{}
+	}
+	segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
+	if err != nil {
+		return ProjectHealthReport{}, err
+	}
+
+	var exps []*models.Experiment
+	if err = svc.query().Where("project_id = ?", projectId).Order("id").Find(&exps).Error; err != nil {
+		return ProjectHealthReport{}, err
+	}
+
+	report := ProjectHealthReport{Problems: map[int64]map[ValidationCheck]string{}}
+	addProblem := func(exp *models.Experiment, check ValidationCheck, err error) {
+		experimentId := exp.ID.ToApiSchema()
+		if _, ok := report.Problems[experimentId]; !ok {
+			report.Problems[experimentId] = map[ValidationCheck]string{}
+		}
+		report.Problems[experimentId][check] = err.Error()
+	}
+
+	for _, exp := range exps {
+		rawSegment, err := exp.Segment.ToRawSchema(segmenterTypes)
+		if err != nil {
+			addProblem(exp, ValidationCheckSegment, err)
+		} else {
+			err = validateExperimentSegmentersExist(exp.Name, rawSegment, utils.StringSliceToSet(config.Segmenters.Names))
+			if err != nil {
+				addProblem(exp, ValidationCheckSegmentersExist, err)
+			}
+			err = svc.services.SegmenterService.ValidateExperimentSegment(projectId, config.Segmenters.Names, rawSegment)
+			if err != nil {
+				addProblem(exp, ValidationCheckSegment, err)
+			}
+		}
+
+		if err = validateTreatmentsWithTreatmentSchema(exp.Treatments, config, settings.TreatmentSchema); err != nil {
+			addProblem(exp, ValidationCheckTreatmentSchema, err)
+		}
+
+		if exp.Status != models.ExperimentStatusActive || exp.Shadow || rawSegment == nil {
+			continue
+		}
+		otherExps := []*models.Experiment{}
+		for _, other := range exps {
+			if other.ID != exp.ID && other.Status == models.ExperimentStatusActive && other.Tier == exp.Tier &&
+				other.StartTime.Before(exp.EndTime) && exp.StartTime.Before(other.EndTime) {
+				otherExps = append(otherExps, other)
+			}
+		}
+		experimentId := exp.ID.ToApiSchema()
+		err = svc.validateExperimentOrthogonality(
+			projectId,
+			&experimentId,
+			rawSegment,
+			otherExps,
+			config.Segmenters.Names,
+			config.OrthogonalityExemptSegmenters,
+			config.DefaultSegment,
+		)
+		if err != nil {
+			addProblem(exp, ValidationCheckOrthogonality, err)
+		}
+	}
+
+	return report, nil
+}
+
+// validateTreatmentsWithTreatmentSchema validates the configuration of each treatment, with the project's treatment
+// config defaults applied, against the treatment schema
+func validateTreatmentsWithTreatmentSchema(
+	treatments models.ExperimentTreatments,
+	config *models.ExperimentationConfig,
+	treatmentSchema *models.TreatmentSchema,
+) error {
+	treatments, err := applyTreatmentConfigDefaults(treatments, config)
+	if err != nil {
+		return err
+	}
+	for _, treatment := range treatments {
+		err = ValidateTreatmentConfigWithTreatmentSchema(treatment.Configuration, treatmentSchema)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // validateExperimentSegmentersExist checks if the set of segmenters contains all the segments given
 func validateExperimentSegmentersExist(
 	expName string,
@@ -873,16 +4489,92 @@ func validateExperimentSegmentersExist(
 	return nil
 }
 
+// validateOverrideTierSegment checks that an override tier experiment constrains at least one segmenter, as an
+// override that matches every request defeats the purpose of the tiers. Default tier experiments may be unconstrained.
+func validateOverrideTierSegment(
+	expName string,
+	tier models.ExperimentTier,
+	expSegment models.ExperimentSegment,
+) error {
+	if tier != models.ExperimentTierOverride {
+		return nil
+	}
+	for _, values := range expSegment {
+		if len(values) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("override tier experiment %s must constrain at least one segmenter", expName)
+}
+
 // RunCustomValidation validates the experiment by running all its treatments against the treatment schema AND itself
-// against the validation/url given in the settings concurrently; if either of them return an error, this method
-// returns an error
+// against the validation/url given in the settings AND the custom validators enabled in the settings concurrently; if
+// any of them return an error, this method returns an error. The project's treatment config defaults, if any, are
+// merged into the treatments beforehand. The outcome is recorded on the experiment, if the validated version is the
+// one that is stored, so that a failed update does not mark the stored version as failed.
 func (svc *experimentService) RunCustomValidation(
 	experiment models.Experiment,
 	settings models.Settings,
 	context ValidationContext,
 	operationType OperationType,
 ) error {
+	warnings, validationErr := svc.runCustomValidation(experiment, settings, context, operationType)
+	for _, warning := range warnings {
+		log.Printf("Warning: %s", warning)
+	}
+
+	// Record the outcome for existing experiments, so that the failing ones can be listed without re-running the
+	// validation. The column is updated directly, to leave the version and the update time untouched.
+	if experiment.ID != 0 {
+		validationStatus := models.ExperimentValidationStatusPassed
+		if validationErr != nil {
+			validationStatus = models.ExperimentValidationStatusFailed
+		}
+		err := svc.query().Model(&models.Experiment{}).
+			Where("project_id = ? AND id = ? AND version = ?", experiment.ProjectID, experiment.ID, experiment.Version).
+			UpdateColumn("last_validation_status", validationStatus).Error
+		if err != nil && validationErr == nil {
+			return err
+		}
+	}
+
+	return validationErr
+}
+
+// runCustomValidation runs the checks of RunCustomValidation, without recording the outcome, and returns the warnings
+// of the checks that passed
+func (svc *experimentService) runCustomValidation(
+	experiment models.Experiment,
+	settings models.Settings,
+	context ValidationContext,
+	operationType OperationType,
+) ([]string, error) {
+	if err := validateTreatmentConfigSizes(experiment.Treatments, settings.Config); err != nil {
+		return nil, err
+	}
+
+	treatments, err := applyTreatmentConfigDefaults(experiment.Treatments, settings.Config)
+	if err != nil {
+		return nil, err
+	}
+	experiment.Treatments = treatments
+
+	if err := validateTreatmentNames(experiment.Treatments, settings.Config); err != nil {
+		return nil, err
+	}
+	warnings, err := validateDistinctTreatmentConfigs(experiment.Treatments, settings.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	validators, err := svc.getEnabledValidators(settings.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bound the number of concurrent validations, as experiments may have many treatments
 	g := new(errgroup.Group)
+	g.SetLimit(svc.getValidationConcurrency())
 
 	for _, treatment := range experiment.Treatments {
 		treatment := treatment
@@ -895,6 +4587,12 @@ func (svc *experimentService) RunCustomValidation(
 	}
 
 	g.Go(func() error {
+		if rateLimit := getValidationUrlRateLimit(settings); rateLimit != nil {
+			err := svc.services.ValidationService.WaitForValidationUrlRateLimit(int64(settings.ProjectID), *rateLimit)
+			if err != nil {
+				return err
+			}
+		}
 		return svc.services.ValidationService.ValidateEntityWithExternalUrl(operationType, EntityTypeExperiment,
 			experiment,
 			context,
@@ -902,8 +4600,153 @@ func (svc *experimentService) RunCustomValidation(
 		)
 	})
 
+	for _, validator := range validators {
+		validator := validator
+		g.Go(func() error {
+			return validator(experiment, settings, context, operationType)
+		})
+	}
+
 	if err := g.Wait(); err != nil {
-		return err
+		return nil, err
+	}
+	return warnings, nil
+}
+
+func (svc *experimentService) getValidationConcurrency() int {
+	svc.validatorsLock.RLock()
+	defer svc.validatorsLock.RUnlock()
+	return svc.validationConcurrency
+}
+
+// getEnabledValidators returns the registered custom validators that are enabled in the given project config
+func (svc *experimentService) getEnabledValidators(config *models.ExperimentationConfig) ([]ExperimentValidatorFunc, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	svc.validatorsLock.RLock()
+	defer svc.validatorsLock.RUnlock()
+
+	validators := []ExperimentValidatorFunc{}
+	for _, name := range config.EnabledValidators {
+		validator, ok := svc.validators[name]
+		if !ok {
+			return nil, errors.Newf(errors.BadInput, "validator %s is not registered", name)
+		}
+		validators = append(validators, validator)
+	}
+	return validators, nil
+}
+
+// validateTreatmentNames checks that the names of the given treatments match the project's treatment name pattern, if
+// any. Empty names, and the names of the projects without a pattern, are left to the validation of the request.
+func validateTreatmentNames(treatments models.ExperimentTreatments, config *models.ExperimentationConfig) error {
+	if config == nil || config.TreatmentNamePattern == "" {
+		return nil
+	}
+	pattern := config.TreatmentNamePattern
+	nameRegex, err := regexp.Compile(pattern)
+	if err != nil {
+		return errors.Newf(errors.BadInput, "invalid treatment name pattern %s: %v", pattern, err)
+	}
+
+	for _, treatment := range treatments {
+		if treatment.Name != "" && !nameRegex.MatchString(treatment.Name) {
+			return errors.Newf(errors.BadInput, "treatment name %q does not match the pattern %s", treatment.Name, pattern)
+		}
 	}
 	return nil
 }
+
+// validateTreatmentConfigSizes checks that the configuration of each of the given treatments, marshaled as JSON, is
+// within the project's maximum size, if any. The configurations are measured as they are stored, i.e., without the
+// project's treatment config defaults.
+func validateTreatmentConfigSizes(treatments models.ExperimentTreatments, config *models.ExperimentationConfig) error {
+	if config == nil || config.MaxTreatmentConfigBytes <= 0 {
+		return nil
+	}
+	for _, treatment := range treatments {
+		treatmentConfig, err := json.Marshal(treatment.Configuration)
+		if err != nil {
+			return err
+		}
+		if len(treatmentConfig) > config.MaxTreatmentConfigBytes {
+			return errors.Newf(errors.BadInput, "treatment %q has a configuration of %d bytes, exceeding the maximum of %d bytes",
+				treatment.Name, len(treatmentConfig), config.MaxTreatmentConfigBytes)
+		}
+	}
+	return nil
+}
+
+// validateDistinctTreatmentConfigs checks that no two of the given treatments have identical configurations, as they
+// would be a single variant under different names. The configurations are compared in their canonical JSON form, in
+// which the keys are sorted. The duplicate pairs are only returned as warnings, unless the project blocks them.
+func validateDistinctTreatmentConfigs(
+	treatments models.ExperimentTreatments,
+	config *models.ExperimentationConfig,
+) ([]string, error) {
+	warnings := []string{}
+	treatmentNamesByConfig := map[string]string{}
+	for _, treatment := range treatments {
+		treatmentConfig, err := json.Marshal(treatment.Configuration)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := treatmentNamesByConfig[string(treatmentConfig)]
+		if !ok {
+			treatmentNamesByConfig[string(treatmentConfig)] = treatment.Name
+			continue
+		}
+
+		message := fmt.Sprintf("treatments %q and %q have identical configurations", name, treatment.Name)
+		if config != nil && config.BlockDuplicateTreatmentConfigs {
+			return nil, errors.Newf(errors.BadInput, "%s", message)
+		}
+		warnings = append(warnings, message)
+	}
+	return warnings, nil
+}
+
+// applyTreatmentConfigDefaults returns a copy of the given treatments, with the project's treatment config defaults
+// deep-merged into the configuration of each treatment. The treatments are returned as is if no defaults are set. As
+// the merge is idempotent, it is also applied to the treatments that are stored with the defaults already merged.
+func applyTreatmentConfigDefaults(
+	treatments models.ExperimentTreatments,
+	config *models.ExperimentationConfig,
+) (models.ExperimentTreatments, error) {
+	if config == nil || len(config.TreatmentConfigDefaults) == 0 {
+		return treatments, nil
+	}
+
+	var configDefaults map[string]interface{}
+	if err := json.Unmarshal(config.TreatmentConfigDefaults, &configDefaults); err != nil {
+		return nil, errors.Newf(errors.BadInput, "error parsing treatment config defaults: %v", err)
+	}
+
+	mergedTreatments := make(models.ExperimentTreatments, len(treatments))
+	for i, treatment := range treatments {
+		treatment.Configuration = mergeTreatmentConfig(configDefaults, treatment.Configuration)
+		mergedTreatments[i] = treatment
+	}
+	return mergedTreatments, nil
+}
+
+// mergeTreatmentConfig recursively merges the override config into the base config and returns the result as a new
+// map. Nested objects are merged key by key; for all other values, the override takes precedence.
+func mergeTreatmentConfig(base map[string]interface{}, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		baseValue, isBaseMap := merged[key].(map[string]interface{})
+		overrideValue, isOverrideMap := value.(map[string]interface{})
+		if isBaseMap && isOverrideMap {
+			merged[key] = mergeTreatmentConfig(baseValue, overrideValue)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
@@ -3,14 +3,25 @@
 package services_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/proto"
 	"gorm.io/gorm"
 
 	"github.com/caraml-dev/xp/common/api/schema"
+	_pubsub "github.com/caraml-dev/xp/common/pubsub"
+	_segmenters "github.com/caraml-dev/xp/common/segmenters"
+	"github.com/caraml-dev/xp/management-service/config"
 	"github.com/caraml-dev/xp/management-service/errors"
 	tu "github.com/caraml-dev/xp/management-service/internal/testutils"
 	"github.com/caraml-dev/xp/management-service/models"
@@ -23,10 +34,21 @@ type ExperimentServiceTestSuite struct {
 	suite.Suite
 	services.ExperimentService
 	ExperimentHistoryService *mocks.ExperimentHistoryService
+	Services                 *services.Services
 	CleanUpFunc              func()
+	DB                       *gorm.DB
 
 	Settings    models.Settings
 	Experiments []*models.Experiment
+
+	// The projects that the tests create for themselves are kept in a separate DB, with the services bound to it, so
+	// that their experiments do not take up the ids expected of the experiments created in the shared test data,
+	// whatever order the tests run in. The ids of the projects are allocated by the suite.
+	ProjectsDB                *gorm.DB
+	ProjectsCleanUpFunc       func()
+	ProjectsServices          *services.Services
+	ProjectsExperimentService services.ExperimentService
+	lastProjectId             int64
 }
 
 func (s *ExperimentServiceTestSuite) SetupSuite() {
@@ -38,6 +60,7 @@ func (s *ExperimentServiceTestSuite) SetupSuite() {
 		s.Suite.T().Fatalf("Could not create test DB: %v", err)
 	}
 	s.CleanUpFunc = cleanup
+	s.DB = db
 
 	// Init mock services
 	segmenterSvc := setupMockSegmenterService()
@@ -47,6 +70,7 @@ func (s *ExperimentServiceTestSuite) SetupSuite() {
 
 	// Init experiment history svc, mock calls will be set up during the test
 	s.ExperimentHistoryService = &mocks.ExperimentHistoryService{}
+	s.ExperimentHistoryService.On("WithTransaction", mock.Anything).Return(s.ExperimentHistoryService)
 
 	allServices := &services.Services{
 		TreatmentService:         configuredTreatmentSvc,
@@ -58,6 +82,8 @@ func (s *ExperimentServiceTestSuite) SetupSuite() {
 
 	// Init experiment service
 	s.ExperimentService = services.NewExperimentService(allServices, db)
+	allServices.ProjectSettingsService = services.NewProjectSettingsService(allServices, db)
+	s.Services = allServices
 
 	// Create test data
 	s.Settings, s.Experiments, err = createTestExperiments(db)
@@ -65,13 +91,85 @@ func (s *ExperimentServiceTestSuite) SetupSuite() {
 	if err != nil {
 		s.Suite.T().Fatalf("Could not set up test data: %v", err)
 	}
+
+	// Create the DB of the projects created by the tests, and the services bound to it
+	s.ProjectsDB, s.ProjectsCleanUpFunc, err = tu.CreateTestDB()
+	if err != nil {
+		s.Suite.T().Fatalf("Could not create test projects DB: %v", err)
+	}
+	projectsServices := *allServices
+	projectsServices.ProjectSettingsService = services.NewProjectSettingsService(&projectsServices, s.ProjectsDB)
+	s.ProjectsServices = &projectsServices
+	s.ProjectsExperimentService = services.NewExperimentService(s.ProjectsServices, s.ProjectsDB)
 }
 
 func (s *ExperimentServiceTestSuite) TearDownSuite() {
 	s.Suite.T().Log("Cleaning up ExperimentServiceTestSuite")
+	s.ProjectsCleanUpFunc()
 	s.CleanUpFunc()
 }
 
+// newProjectId allocates the id of a new project for the test
+func (s *ExperimentServiceTestSuite) newProjectId() int64 {
+	return atomic.AddInt64(&s.lastProjectId, 1)
+}
+
+// createProject creates the settings of a new project for the test, with an allocated id, and the given experiments in
+// the project. The defaults of the experiments' fields are filled in, where they are not set.
+func (s *ExperimentServiceTestSuite) createProject(experiments []models.Experiment) (int64, []*models.Experiment, error) {
+	projectId := s.newProjectId()
+	err := s.ProjectsDB.Create(&models.Settings{
+		ProjectID: models.ID(projectId),
+		Username:  fmt.Sprintf("user_%d", projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{
+				Names: []string{"string_segmenter"},
+			},
+		},
+	}).Error
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var records []*models.Experiment
+	for _, exp := range experiments {
+		exp := exp
+		exp.ProjectID = models.ID(projectId)
+		if exp.Type == "" {
+			exp.Type = models.ExperimentTypeAB
+		}
+		if exp.Tier == "" {
+			exp.Tier = models.ExperimentTierDefault
+		}
+		if exp.Status == "" {
+			exp.Status = models.ExperimentStatusActive
+		}
+		if exp.Segment == nil {
+			exp.Segment = models.ExperimentSegment{}
+		}
+		if exp.StartTime.IsZero() {
+			exp.StartTime = time.Date(2020, 2, 2, 4, 5, 6, 0, time.UTC)
+		}
+		if exp.EndTime.IsZero() {
+			exp.EndTime = time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+		}
+		if exp.CreatedAt.IsZero() {
+			exp.CreatedAt = time.Date(2020, 4, 1, 4, 5, 6, 0, time.UTC)
+		}
+		if exp.UpdatedAt.IsZero() {
+			exp.UpdatedAt = exp.CreatedAt
+		}
+		if exp.Version == 0 {
+			exp.Version = 1
+		}
+		if err := s.ProjectsDB.Create(&exp).Error; err != nil {
+			return 0, nil, err
+		}
+		records = append(records, &exp)
+	}
+	return projectId, records, nil
+}
+
 func TestExperimentService(t *testing.T) {
 	suite.Run(t, new(ExperimentServiceTestSuite))
 }
@@ -137,6 +235,23 @@ func (s *ExperimentServiceTestSuite) TestExperimentServiceGetIntegration() {
 	tu.AssertEqualValues(s.Suite.T(), s.Experiments[0], expResponse)
 }
 
+func (s *ExperimentServiceTestSuite) TestExperimentServiceGetWithRawSegmentIntegration() {
+	expResponse, err := s.ExperimentService.GetExperimentWithRawSegment(1, 1)
+	s.Suite.Require().NoError(err)
+	tu.AssertEqualValues(s.Suite.T(), s.Experiments[0], expResponse.Experiment)
+	s.Suite.Assert().Equal(models.ExperimentSegmentRaw{
+		"integer_segmenter":   []interface{}{float64(1), float64(2)},
+		"integer_2_segmenter": []interface{}{float64(1), float64(2), float64(3), float64(4), float64(5)},
+		"float_segmenter":     []interface{}{1.0, 2.0},
+		"string_segmenter":    []interface{}{"seg-1"},
+		"bool_segmenter":      []interface{}{true},
+	}, expResponse.RawSegment)
+
+	// Experiment not found
+	_, err = s.ExperimentService.GetExperimentWithRawSegment(1, 100)
+	s.Suite.Assert().Equal(errors.NotFound, errors.GetType(err))
+}
+
 func (s *ExperimentServiceTestSuite) TestExperimentServiceListCreateUpdateIntegration() {
 	// Test list experiments first, since the create/update of experiments
 	// could affect the results
@@ -294,11 +409,31 @@ func testListExperiments(s *ExperimentServiceTestSuite) {
 		{Name: s.Experiments[1].Name},
 		{Name: s.Experiments[2].Name},
 	}, actualResponsesList)
+
+	// Select all fields explicitly
+	actualResponsesList, pagingResponse, err = svc.ListExperiments(1,
+		services.ListExperimentsParams{
+			Fields: &[]models.ExperimentField{models.ExperimentFieldAll},
+		},
+	)
+	s.Suite.Require().NoError(err)
+	tu.AssertEqualValues(t, &pagination.Paging{Page: 1, Pages: 1, Total: 3}, pagingResponse)
+	tu.AssertEqualValues(t, []*models.Experiment{s.Experiments[0], s.Experiments[1], s.Experiments[2]}, actualResponsesList)
+
+	// Select all fields together with other fields
+	_, _, err = svc.ListExperiments(1,
+		services.ListExperimentsParams{
+			Fields: &[]models.ExperimentField{models.ExperimentFieldAll, models.ExperimentFieldName},
+		},
+	)
+	s.Suite.Assert().EqualError(err, "field * cannot be combined with other fields")
 }
 
 func testCreateUpdateExperiment(s *ExperimentServiceTestSuite) {
 	t := s.Suite.T()
-	svc := s.ExperimentService
+	// The clock is frozen before the window of the created experiment, so that it can be created as active
+	clock := fixedClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	svc := services.NewExperimentServiceWithClock(s.Services, s.DB, clock)
 
 	// Create Experiment
 	projectId := int64(1)
@@ -408,165 +543,5486 @@ func testCreateUpdateExperiment(s *ExperimentServiceTestSuite) {
 	s.Suite.Require().Equal(models.ExperimentStatusActive, exp.Status)
 }
 
-func (s *ExperimentServiceTestSuite) TestRunCustomValidation() {
+func (s *ExperimentServiceTestSuite) TestUpdateExperimentAddTreatments() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:      "add-treatments-exp",
+			Type:      models.ExperimentTypeSwitchback,
+			Interval:  &[]int32{60}[0],
+			UpdatedBy: "test-user",
+			Treatments: models.ExperimentTreatments{
+				{Name: "control", Configuration: map[string]interface{}{"model_version": "v1"}},
+				{Name: "treatment-a", Configuration: map[string]interface{}{"model_version": "v2"}},
+			},
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+
+	// Colliding treatment names are rejected
+	_, err = svc.AddTreatments(settings, exps[0].ID.ToApiSchema(), models.ExperimentTreatments{
+		{Name: "treatment-b", Configuration: map[string]interface{}{"model_version": "v3"}},
+		{Name: "control", Configuration: map[string]interface{}{"model_version": "v3"}},
+	})
+	s.Suite.Assert().EqualError(err, "treatment control already exists in experiment add-treatments-exp")
+	s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+
+	exp, err := svc.AddTreatments(settings, exps[0].ID.ToApiSchema(), models.ExperimentTreatments{
+		{Name: "treatment-b", Configuration: map[string]interface{}{"model_version": "v3"}},
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(int64(2), exp.Version)
+	s.Suite.Assert().Equal([]string{"control", "treatment-a", "treatment-b"}, getTreatmentNames(exp.Treatments))
+	s.Suite.Assert().Equal("test-user", exp.UpdatedBy)
+	s.Suite.Assert().Equal(exps[0].Interval, exp.Interval)
+
+	_, err = svc.AddTreatments(settings, 999, models.ExperimentTreatments{})
+	s.Suite.Assert().Error(err)
+}
+
+func (s *ExperimentServiceTestSuite) TestCreateUpdateExperimentNilUpdatedBy() {
+	// Set up a validation service that accepts any data, so that the nil check does not rely on the validator
+	validationSvc := &mocks.ValidationService{}
+	validationSvc.On("Validate", mock.Anything).Return(nil)
+	svc := services.NewExperimentService(&services.Services{ValidationService: validationSvc}, s.DB)
+
+	s.Suite.Require().NotPanics(func() {
+		_, err := svc.CreateExperiment(s.Settings, services.CreateExperimentRequestBody{
+			Name:   "test-experiment-nil-updated-by",
+			Status: models.ExperimentStatusActive,
+			Tier:   models.ExperimentTierDefault,
+			Type:   models.ExperimentTypeAB,
+		})
+		s.Suite.Assert().EqualError(err, "updated_by is required")
+		s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+	})
+	s.Suite.Require().NotPanics(func() {
+		_, err := svc.UpdateExperiment(s.Settings, 1, services.UpdateExperimentRequestBody{
+			Status: models.ExperimentStatusActive,
+			Tier:   models.ExperimentTierDefault,
+			Type:   models.ExperimentTypeAB,
+		})
+		s.Suite.Assert().EqualError(err, "updated_by is required")
+		s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+	})
+}
+
+func (s *ExperimentServiceTestSuite) TestFindCoverageGaps() {
+	day := func(d int) time.Time { return time.Date(2022, 1, d, 0, 0, 0, 0, time.UTC) }
+	projectId, _, err := s.createProject([]models.Experiment{
+		// Contiguous experiments
+		{
+			Name:      "contiguous-exp-1",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-a"}},
+			StartTime: day(2),
+			EndTime:   day(5),
+		},
+		{
+			Name:      "contiguous-exp-2",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-a", "seg-x"}},
+			StartTime: day(5),
+			EndTime:   day(8),
+		},
+		// Gapped experiments
+		{
+			Name:      "gapped-exp-1",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-b"}},
+			StartTime: day(1),
+			EndTime:   day(3),
+		},
+		{
+			Name:      "gapped-exp-2",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-b"}},
+			StartTime: day(5),
+			EndTime:   day(7),
+		},
+		{
+			Name:      "gapped-exp-inactive",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-b"}},
+			Status:    models.ExperimentStatusInactive,
+			StartTime: day(3),
+			EndTime:   day(5),
+		},
+		{
+			Name:      "gapped-exp-override",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-b"}},
+			Tier:      models.ExperimentTierOverride,
+			StartTime: day(7),
+			EndTime:   day(10),
+		},
+		// Overlapping experiments, covering the whole duration
+		{
+			Name:      "covered-exp-1",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-c"}},
+			StartTime: time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   day(6),
+		},
+		{
+			Name:      "covered-exp-2",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-c"}},
+			StartTime: day(4),
+			EndTime:   time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+
 	tests := map[string]struct {
-		experiment    models.Experiment
-		settings      models.Settings
-		context       services.ValidationContext
-		operationType services.OperationType
-		errString     string
+		segment   models.ExperimentSegmentRaw
+		from      time.Time
+		to        time.Time
+		expected  []services.TimeWindow
+		errString string
 	}{
-		"failure | incorrect value assertion from template schema rule returns error": {
-			experiment: models.Experiment{
-				Treatments: []models.ExperimentTreatment{
-					{
-						Configuration: map[string]interface{}{
-							"field1": "abc",
-							"field2": "def",
-							"field3": map[string]interface{}{
-								"field4": 0.1,
-							},
-						},
-					},
-				},
-			},
-			settings: models.Settings{
-				TreatmentSchema: &models.TreatmentSchema{
-					Rules: []models.Rule{
-						{
-							Name:      "test-rule",
-							Predicate: "{{- (eq .field1 \"def\") -}}",
-						},
-					},
-				},
-				ValidationUrl: &successValidationUrl,
-			},
-			context:       services.ValidationContext{},
-			operationType: services.OperationTypeCreate,
-			errString:     "Go template rule test-rule returns false",
+		"failure | invalid time range": {
+			segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-a"}},
+			from:      day(10),
+			to:        day(1),
+			errString: "from must be before to",
 		},
-		"failure | validation url returns an error": {
-			experiment: models.Experiment{
-				Treatments: []models.ExperimentTreatment{
-					{
-						Configuration: map[string]interface{}{
-							"field1": "abc",
-							"field2": "def",
-							"field3": map[string]interface{}{
-								"field4": 0.1,
-							},
-							"field5": 1,
-						},
-					},
-				},
-			},
-			settings: models.Settings{
-				TreatmentSchema: &models.TreatmentSchema{
-					Rules: []models.Rule{},
-				},
-				ValidationUrl: &failureValidationUrl,
+		"success | contiguous": {
+			segment: models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-a"}},
+			from:    day(1),
+			to:      day(10),
+			expected: []services.TimeWindow{
+				{StartTime: day(1), EndTime: day(2)},
+				{StartTime: day(8), EndTime: day(10)},
 			},
-			context:       services.ValidationContext{},
-			operationType: services.OperationTypeCreate,
-			errString:     "Error validating data with validation URL: 500 Internal Server Error",
 		},
-		"success": {
-			experiment: models.Experiment{
-				Treatments: []models.ExperimentTreatment{
-					{
-						Configuration: map[string]interface{}{
-							"field1": "abc",
-							"field2": "def",
-							"field3": map[string]interface{}{
-								"field4": 0.1,
-							},
-							"field5": 1,
-						},
-					},
-				},
+		"success | gapped": {
+			segment: models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-b"}},
+			from:    day(1),
+			to:      day(10),
+			expected: []services.TimeWindow{
+				{StartTime: day(3), EndTime: day(5)},
+				{StartTime: day(7), EndTime: day(10)},
 			},
-			settings: models.Settings{
-				TreatmentSchema: &models.TreatmentSchema{
-					Rules: []models.Rule{
-						{
-							Name:      "test-rule-1",
-							Predicate: "{{- (eq .field1 \"abc\") -}}",
-						},
-					},
-				},
-				ValidationUrl: &successValidationUrl,
+		},
+		"success | fully covered": {
+			segment:  models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-c"}},
+			from:     day(1),
+			to:       day(10),
+			expected: []services.TimeWindow{},
+		},
+		"success | not covered": {
+			segment: models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-d"}},
+			from:    day(1),
+			to:      day(10),
+			expected: []services.TimeWindow{
+				{StartTime: day(1), EndTime: day(10)},
 			},
-			context:       services.ValidationContext{},
-			operationType: services.OperationTypeCreate,
 		},
 	}
-	for name, test := range tests {
+
+	for name, data := range tests {
 		s.Suite.T().Run(name, func(t *testing.T) {
-			err := s.ExperimentService.RunCustomValidation(
-				test.experiment,
-				test.settings,
-				test.context,
-				test.operationType,
-			)
-			if test.errString == "" {
+			gaps, err := svc.FindCoverageGaps(projectId, data.segment, models.ExperimentTierDefault, data.from, data.to)
+			if data.errString == "" {
 				s.Suite.Require().NoError(err)
+				tu.AssertEqualValues(t, data.expected, gaps)
 			} else {
-				s.Suite.Assert().EqualError(err, test.errString)
+				s.Suite.Assert().EqualError(err, data.errString)
 			}
 		})
 	}
 }
 
-func createTestExperiments(db *gorm.DB) (models.Settings, []*models.Experiment, error) {
-	// Create test project settings (with project_id=1)
-	var settings models.Settings
-	err := db.Create(&models.Settings{
-		ProjectID: models.ID(1),
+func (s *ExperimentServiceTestSuite) TestDuplicateExperimentConflict() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "duplicate-conflict-exp", Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1", "seg-2"}}},
+	})
+	s.Suite.Require().NoError(err)
+
+	// All the experiments overlap, so any orthogonality check fails
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything, mock.Anything).
+		Return(fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID))
+	// The clock is frozen before the windows of the experiments, so that the active experiments can be created
+	clock := fixedClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	svc := newPermissiveExperimentServiceWithClock(s.ProjectsDB, segmenterSvc, clock)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
 		Config: &models.ExperimentationConfig{
-			Segmenters: models.ProjectSegmenters{
-				Names:     []string{"string_segmenter"},
-				Variables: nil,
-			},
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
 		},
-	}).Error
-	if err != nil {
-		return settings, []*models.Experiment{}, err
 	}
-	err = db.Create(&models.Settings{
-		ProjectID: models.ID(2),
-		Username:  "user_2",
-		Config: &models.ExperimentationConfig{
-			Segmenters: models.ProjectSegmenters{
-				Names:     []string{"string_segmenter"},
-				Variables: nil,
-			},
+	updatedBy := "test-user"
+
+	tests := map[string]struct {
+		segment   models.ExperimentSegmentRaw
+		errType   errors.ErrorType
+		errString string
+	}{
+		"partial overlap": {
+			segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+			errType:   errors.BadInput,
+			errString: fmt.Sprintf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID),
+		},
+		"full duplicate": {
+			segment: models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-2", "seg-1"}},
+			errType: errors.Duplicate,
+			errString: fmt.Sprintf("experiment duplicate-conflict-exp (id %d) has the same segment and tier, and an "+
+				"overlapping time window; edit the existing experiment instead of creating a duplicate", exps[0].ID),
 		},
-	}).Error
-	if err != nil {
-		return settings, []*models.Experiment{}, err
 	}
-	// Query the created settings
-	query := db.Where("project_id = 1").First(&settings)
-	if err := query.Error; err != nil {
-		return settings, []*models.Experiment{}, err
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			_, err := svc.CreateExperiment(settings, services.CreateExperimentRequestBody{
+				Name:      "duplicate-conflict-exp-new",
+				Segment:   data.segment,
+				StartTime: exps[0].StartTime,
+				EndTime:   exps[0].EndTime,
+				Status:    models.ExperimentStatusActive,
+				Tier:      exps[0].Tier,
+				Type:      exps[0].Type,
+				UpdatedBy: &updatedBy,
+			})
+			s.Suite.Assert().EqualError(err, data.errString)
+			s.Suite.Assert().Equal(data.errType, errors.GetType(err))
+		})
 	}
+}
 
-	// Test segmenters
-	integerSegmenter := []string{"1", "2"}
-	integer2Segmenter := []string{"1", "2", "3", "4", "5"}
-	floatSegmenter := []string{"1.0", "2.0"}
-	float2Segmenter := []string{"3.0", "4.0"}
-	stringSegmenter := []string{"seg-1"}
-	boolSegmenter := []string{"true"}
+func (s *ExperimentServiceTestSuite) TestFindDuplicateExperiments() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		// Duplicates, with the segment values in a different order
+		{Name: "duplicate-exp-1", Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1", "seg-2"}}},
+		{
+			Name:      "duplicate-exp-2",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-2", "seg-1"}},
+			StartTime: time.Date(2020, 2, 2, 12, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 2, 4, 0, 0, 0, 0, time.UTC),
+		},
+		// Same segment, but a different tier
+		{
+			Name:    "distinct-exp-tier",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1", "seg-2"}},
+			Tier:    models.ExperimentTierOverride,
+		},
+		// Same segment and tier, but a non-overlapping window
+		{
+			Name:      "distinct-exp-window",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-1", "seg-2"}},
+			StartTime: time.Date(2020, 2, 4, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 2, 5, 0, 0, 0, 0, time.UTC),
+		},
+		// Same segment and tier, but inactive
+		{
+			Name:    "distinct-exp-inactive",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1", "seg-2"}},
+			Status:  models.ExperimentStatusInactive,
+		},
+		// Overlapping segment, but not identical
+		{Name: "distinct-exp-segment", Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}}},
+	})
+	s.Suite.Require().NoError(err)
 
-	description := "test-desc-1"
+	clusters, err := s.ProjectsExperimentService.FindDuplicateExperiments(projectId)
+	s.Suite.Require().NoError(err)
+	s.Suite.Require().Len(clusters, 1)
+	s.Suite.Assert().Equal(getExperimentNames([]*models.Experiment{exps[0], exps[1]}), getExperimentNames(clusters[0]))
 
-	// Define test experiments
-	experiments := []models.Experiment{
+	clusters, err = s.ProjectsExperimentService.FindDuplicateExperiments(s.newProjectId())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Empty(clusters)
+}
+
+func (s *ExperimentServiceTestSuite) TestListByOrthogonalityRisk() {
+	newSegment := func(stringValues []string, integerValues []string) models.ExperimentSegment {
+		return models.ExperimentSegment{"string_segmenter": stringValues, "integer_segmenter": integerValues}
+	}
+	projectId, exps, err := s.createProject([]models.Experiment{
+		// Overlaps with each of the experiments below on the string segmenter, but not on the integer segmenter
+		{Name: "risk-exp-high", Segment: newSegment([]string{"seg-1", "seg-2", "seg-3"}, []string{"1"})},
+		{Name: "risk-exp-1", Segment: newSegment([]string{"seg-1"}, []string{"2"})},
+		{Name: "risk-exp-2", Segment: newSegment([]string{"seg-2"}, []string{"3"})},
+		{Name: "risk-exp-3", Segment: newSegment([]string{"seg-3"}, []string{"4"})},
+		// Does not overlap with any experiment on any segmenter
+		{Name: "risk-exp-isolated", Segment: newSegment([]string{"seg-9"}, []string{"9"})},
+		// Would nearly overlap with the high-risk experiment, but has a different tier, window or status
 		{
-			ProjectID:  models.ID(1),
-			Name:       "test-exp-1",
-			Type:       models.ExperimentTypeAB,
+			Name:    "risk-exp-override",
+			Segment: newSegment([]string{"seg-1"}, []string{"5"}),
+			Tier:    models.ExperimentTierOverride,
+		},
+		{
+			Name:      "risk-exp-window",
+			Segment:   newSegment([]string{"seg-1"}, []string{"6"}),
+			StartTime: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:    "risk-exp-inactive",
+			Segment: newSegment([]string{"seg-1"}, []string{"7"}),
+			Status:  models.ExperimentStatusInactive,
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	risks, err := s.ProjectsExperimentService.ListByOrthogonalityRisk(projectId)
+	s.Suite.Require().NoError(err)
+	type risk struct {
+		name         string
+		score        int
+		nearOverlaps []int64
+	}
+	actual := []risk{}
+	for _, r := range risks {
+		actual = append(actual, risk{name: r.Experiment.Name, score: r.Score, nearOverlaps: r.NearOverlaps})
+	}
+	s.Suite.Assert().Equal([]risk{
+		{
+			name:  "risk-exp-high",
+			score: 3,
+			nearOverlaps: []int64{
+				exps[1].ID.ToApiSchema(),
+				exps[2].ID.ToApiSchema(),
+				exps[3].ID.ToApiSchema(),
+			},
+		},
+		{name: "risk-exp-1", score: 1, nearOverlaps: []int64{exps[0].ID.ToApiSchema()}},
+		{name: "risk-exp-2", score: 1, nearOverlaps: []int64{exps[0].ID.ToApiSchema()}},
+		{name: "risk-exp-3", score: 1, nearOverlaps: []int64{exps[0].ID.ToApiSchema()}},
+		{name: "risk-exp-isolated", score: 0, nearOverlaps: []int64{}},
+		{name: "risk-exp-override", score: 0, nearOverlaps: []int64{}},
+		{name: "risk-exp-window", score: 0, nearOverlaps: []int64{}},
+	}, actual)
+
+	risks, err = s.ProjectsExperimentService.ListByOrthogonalityRisk(s.newProjectId())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Empty(risks)
+}
+
+func (s *ExperimentServiceTestSuite) TestMatchExperiments() {
+	projectId, _, err := s.createProject([]models.Experiment{
+		{Name: "match-exp-string", Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1", "seg-2"}}},
+		{
+			Name: "match-exp-string-integer",
+			Segment: models.ExperimentSegment{
+				"string_segmenter":  []string{"seg-1"},
+				"integer_segmenter": []string{"1"},
+			},
+		},
+		{Name: "match-exp-unconstrained", Segment: models.ExperimentSegment{"string_segmenter": []string{}}},
+		{
+			Name:    "match-exp-override",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			Tier:    models.ExperimentTierOverride,
+		},
+		{
+			Name:    "match-exp-inactive",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			Status:  models.ExperimentStatusInactive,
+		},
+		{
+			Name:      "match-exp-later",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			StartTime: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	s.Suite.Require().NoError(err)
+	at := time.Date(2020, 2, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		tier        models.ExperimentTier
+		unitSegment map[string]string
+		at          time.Time
+		expected    []string
+		errString   string
+	}{
+		"multiple matches": {
+			tier:        models.ExperimentTierDefault,
+			unitSegment: map[string]string{"string_segmenter": "seg-1", "integer_segmenter": "1"},
+			at:          at,
+			expected:    []string{"match-exp-string", "match-exp-string-integer", "match-exp-unconstrained"},
+		},
+		"unit without a value of a constrained segmenter": {
+			tier:        models.ExperimentTierDefault,
+			unitSegment: map[string]string{"string_segmenter": "seg-1"},
+			at:          at,
+			expected:    []string{"match-exp-string", "match-exp-unconstrained"},
+		},
+		"single match in the override tier": {
+			tier:        models.ExperimentTierOverride,
+			unitSegment: map[string]string{"string_segmenter": "seg-1"},
+			at:          at,
+			expected:    []string{"match-exp-override"},
+		},
+		"single match at a later time": {
+			tier:        models.ExperimentTierDefault,
+			unitSegment: map[string]string{"string_segmenter": "seg-1"},
+			at:          time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC),
+			expected:    []string{"match-exp-later"},
+		},
+		"no match": {
+			tier:        models.ExperimentTierOverride,
+			unitSegment: map[string]string{"string_segmenter": "seg-3"},
+			at:          at,
+			expected:    []string{},
+		},
+		"unknown tier": {
+			tier:      models.ExperimentTier("unknown"),
+			at:        at,
+			errString: "unknown experiment tier: unknown",
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			exps, err := s.ProjectsExperimentService.MatchExperiments(projectId, data.tier, data.unitSegment, data.at)
+			if data.errString == "" {
+				s.Suite.Require().NoError(err)
+				s.Suite.Assert().Equal(data.expected, getExperimentNames(exps))
+			} else {
+				s.Suite.Assert().EqualError(err, data.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsGroupedByStatus() {
+	now := time.Now().UTC()
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "grouped-exp-scheduled", StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour)},
+		{Name: "grouped-exp-running", StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)},
+		{Name: "grouped-exp-completed", StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour)},
+		{
+			Name:      "grouped-exp-deactivated",
+			Status:    models.ExperimentStatusInactive,
+			StartTime: now.Add(-time.Hour),
+			EndTime:   now.Add(time.Hour),
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	expected := map[services.ExperimentStatusFriendly][]string{
+		services.ExperimentStatusFriendlyCompleted:   {"grouped-exp-completed"},
+		services.ExperimentStatusFriendlyDeactivated: {"grouped-exp-deactivated"},
+		services.ExperimentStatusFriendlyRunning:     {"grouped-exp-running"},
+		services.ExperimentStatusFriendlyScheduled:   {"grouped-exp-scheduled"},
+		services.ExperimentStatusFriendlyDraft:       {},
+	}
+	getGroupedNames := func(groups map[services.ExperimentStatusFriendly][]*models.Experiment) map[services.ExperimentStatusFriendly][]string {
+		names := map[services.ExperimentStatusFriendly][]string{}
+		for statusFriendly, exps := range groups {
+			names[statusFriendly] = getExperimentNames(exps)
+		}
+		return names
+	}
+
+	groups, err := s.ProjectsExperimentService.ListExperimentsGroupedByStatus(projectId, services.ListExperimentsParams{})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(expected, getGroupedNames(groups))
+
+	// The results are grouped even if the fields that determine the status are not selected
+	groups, err = s.ProjectsExperimentService.ListExperimentsGroupedByStatus(projectId, services.ListExperimentsParams{
+		Fields: &[]models.ExperimentField{models.ExperimentFieldName},
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(expected, getGroupedNames(groups))
+
+	// The results are grouped if only the ids are selected
+	groups, err = s.ProjectsExperimentService.ListExperimentsGroupedByStatus(projectId, services.ListExperimentsParams{
+		IdsOnly: true,
+	})
+	s.Suite.Require().NoError(err)
+	expectedIds := map[services.ExperimentStatusFriendly][]models.ID{
+		services.ExperimentStatusFriendlyCompleted:   {exps[2].ID},
+		services.ExperimentStatusFriendlyDeactivated: {exps[3].ID},
+		services.ExperimentStatusFriendlyRunning:     {exps[1].ID},
+		services.ExperimentStatusFriendlyScheduled:   {exps[0].ID},
+		services.ExperimentStatusFriendlyDraft:       {},
+	}
+	for statusFriendly, ids := range expectedIds {
+		actualIds := []models.ID{}
+		for _, exp := range groups[statusFriendly] {
+			actualIds = append(actualIds, exp.ID)
+		}
+		s.Suite.Assert().Equal(ids, actualIds, statusFriendly)
+	}
+
+	page := int32(1)
+	_, err = s.ProjectsExperimentService.ListExperimentsGroupedByStatus(projectId, services.ListExperimentsParams{
+		PaginationOptions: pagination.PaginationOptions{Page: &page},
+	})
+	s.Suite.Assert().EqualError(err, "pagination is not supported when grouping experiments by status")
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsStatusFriendlyFrozenClock() {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	projectId, _, err := s.createProject([]models.Experiment{
+		{Name: "clock-exp-scheduled", StartTime: now.Add(time.Second), EndTime: now.Add(time.Hour)},
+		{Name: "clock-exp-starting-now", StartTime: now, EndTime: now.Add(time.Hour)},
+		{Name: "clock-exp-ending-now", StartTime: now.Add(-time.Hour), EndTime: now},
+		{Name: "clock-exp-completed", StartTime: now.Add(-time.Hour), EndTime: now.Add(-time.Second)},
+		{
+			Name:      "clock-exp-deactivated",
+			Status:    models.ExperimentStatusInactive,
+			StartTime: now.Add(-time.Hour),
+			EndTime:   now.Add(time.Hour),
+		},
+	})
+	s.Suite.Require().NoError(err)
+	svc := services.NewExperimentServiceWithClock(&services.Services{}, s.ProjectsDB, fixedClock(now))
+
+	// An experiment is running from its start time, up to but excluding its end time
+	expected := map[services.ExperimentStatusFriendly][]string{
+		services.ExperimentStatusFriendlyCompleted:   {"clock-exp-completed"},
+		services.ExperimentStatusFriendlyDeactivated: {"clock-exp-deactivated"},
+		services.ExperimentStatusFriendlyRunning:     {"clock-exp-starting-now"},
+		services.ExperimentStatusFriendlyScheduled:   {"clock-exp-scheduled"},
+		services.ExperimentStatusFriendlyDraft:       {},
+	}
+	for statusFriendly, names := range expected {
+		exps, _, err := svc.ListExperiments(projectId, services.ListExperimentsParams{
+			StatusFriendly: []services.ExperimentStatusFriendly{statusFriendly},
+		})
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Equal(names, getExperimentNames(exps), statusFriendly)
+	}
+
+	groups, err := svc.ListExperimentsGroupedByStatus(projectId, services.ListExperimentsParams{})
+	s.Suite.Require().NoError(err)
+	for statusFriendly, names := range expected {
+		s.Suite.Assert().Equal(names, getExperimentNames(groups[statusFriendly]), statusFriendly)
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsExcludeSegment() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "exclude-exp-sg", Segment: models.ExperimentSegment{"string_segmenter": []string{"SG"}}},
+		{Name: "exclude-exp-sg-jkt", Segment: models.ExperimentSegment{"string_segmenter": []string{"SG", "JKT"}}},
+		{Name: "exclude-exp-jkt", Segment: models.ExperimentSegment{"string_segmenter": []string{"JKT"}}},
+		{Name: "exclude-exp-unset", Segment: models.ExperimentSegment{"string_segmenter": []string{}}},
+		{Name: "exclude-exp-absent"},
+	})
+	s.Suite.Require().NoError(err)
+
+	// The experiments that do not constrain the segmenter are kept, with or without weak matches
+	for _, includeWeakMatch := range []bool{false, true} {
+		actual, err := s.ProjectsExperimentService.ListAllExperiments(models.ID(projectId), services.ListExperimentsParams{
+			ExcludeSegment:   models.ExperimentSegment{"string_segmenter": []string{"SG"}},
+			IncludeWeakMatch: includeWeakMatch,
+		})
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().ElementsMatch(
+			getExperimentNames([]*models.Experiment{exps[2], exps[3], exps[4]}),
+			getExperimentNames(actual),
+		)
+	}
+
+	// Combined with a segment filter
+	actual, err := s.ProjectsExperimentService.ListAllExperiments(models.ID(projectId), services.ListExperimentsParams{
+		Segment:        models.ExperimentSegment{"string_segmenter": []string{"JKT"}},
+		ExcludeSegment: models.ExperimentSegment{"string_segmenter": []string{"SG"}},
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().ElementsMatch(getExperimentNames([]*models.Experiment{exps[2]}), getExperimentNames(actual))
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsFuzzySearch() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "checkout-discount"},
+		// The closer match is updated earlier, so that the ordering by similarity can be distinguished
+		{
+			Name:  "checkout-discounts-v2",
+			Model: models.Model{UpdatedAt: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{Name: "driver-allocation"},
+	})
+	s.Suite.Require().NoError(err)
+
+	search := "chekout-discont"
+	// The misspelt term is not matched as a substring
+	actual, _, err := s.ProjectsExperimentService.ListExperiments(projectId, services.ListExperimentsParams{Search: &search})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Empty(actual)
+
+	// The near matches are ranked by similarity, and the non-match is excluded
+	actual, _, err = s.ProjectsExperimentService.ListExperiments(projectId, services.ListExperimentsParams{
+		Search:      &search,
+		SearchFuzzy: true,
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(getExperimentNames([]*models.Experiment{exps[0], exps[1]}), getExperimentNames(actual))
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsMultiProject() {
+	var projectIds []int64
+	projectExps := map[int64][]*models.Experiment{}
+	for i := 1; i <= 3; i++ {
+		projectId, exps, err := s.createProject([]models.Experiment{
+			{
+				Name:   fmt.Sprintf("multi-project-exp-%d-1", i),
+				Status: models.ExperimentStatusActive,
+				Model:  models.Model{UpdatedAt: time.Date(2020, 4, 1, 4, 5, 6, 0, time.UTC)},
+			},
+			{
+				Name:   fmt.Sprintf("multi-project-exp-%d-2", i),
+				Status: models.ExperimentStatusInactive,
+				Model:  models.Model{UpdatedAt: time.Date(2020, 4, 2, 4, 5, 6, 0, time.UTC)},
+			},
+		})
+		s.Suite.Require().NoError(err)
+		projectIds = append(projectIds, projectId)
+		projectExps[projectId] = exps
+	}
+	p1, p2, p3, p4 := projectIds[0], projectIds[1], projectIds[2], s.newProjectId()
+	activeStatus := models.ExperimentStatusActive
+	page := int32(1)
+	allFields := []models.ExperimentField{models.ExperimentFieldAll}
+
+	tests := map[string]struct {
+		projectIds []int64
+		params     services.ListExperimentsParams
+		expected   map[int64][]*models.Experiment
+		errString  string
+	}{
+		"failure | pagination": {
+			projectIds: []int64{p1, p2},
+			params: services.ListExperimentsParams{
+				PaginationOptions: pagination.PaginationOptions{Page: &page},
+			},
+			errString: "pagination is not supported when listing experiments across projects",
+		},
+		"success | all projects": {
+			projectIds: []int64{p1, p2, p3},
+			expected: map[int64][]*models.Experiment{
+				p1: {projectExps[p1][1], projectExps[p1][0]},
+				p2: {projectExps[p2][1], projectExps[p2][0]},
+				p3: {projectExps[p3][1], projectExps[p3][0]},
+			},
+		},
+		"success | filtered": {
+			projectIds: []int64{p1, p3, p4},
+			params:     services.ListExperimentsParams{Status: &activeStatus},
+			expected: map[int64][]*models.Experiment{
+				p1: {projectExps[p1][0]},
+				p3: {projectExps[p3][0]},
+				p4: {},
+			},
+		},
+		"success | ids only": {
+			projectIds: []int64{p1, p2},
+			params:     services.ListExperimentsParams{IdsOnly: true},
+			expected: map[int64][]*models.Experiment{
+				p1: {
+					{ID: projectExps[p1][1].ID, ProjectID: models.ID(p1)},
+					{ID: projectExps[p1][0].ID, ProjectID: models.ID(p1)},
+				},
+				p2: {
+					{ID: projectExps[p2][1].ID, ProjectID: models.ID(p2)},
+					{ID: projectExps[p2][0].ID, ProjectID: models.ID(p2)},
+				},
+			},
+		},
+		"success | all fields": {
+			projectIds: []int64{p1, p2},
+			params:     services.ListExperimentsParams{Fields: &allFields},
+			expected: map[int64][]*models.Experiment{
+				p1: {projectExps[p1][1], projectExps[p1][0]},
+				p2: {projectExps[p2][1], projectExps[p2][0]},
+			},
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			exps, err := s.ProjectsExperimentService.ListExperimentsMultiProject(data.projectIds, data.params)
+			if data.errString == "" {
+				s.Suite.Require().NoError(err)
+				tu.AssertEqualValues(t, data.expected, exps)
+			} else {
+				s.Suite.Assert().EqualError(err, data.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsNamesFilter() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "names-exp-1"},
+		{Name: "names-exp-2"},
+		{Name: "names-exp-3"},
+	})
+	s.Suite.Require().NoError(err)
+
+	actual, err := s.ProjectsExperimentService.ListAllExperiments(models.ID(projectId), services.ListExperimentsParams{
+		Names: []string{"names-exp-1", "names-exp-3", "names-exp-unknown"},
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().ElementsMatch(getExperimentNames([]*models.Experiment{exps[0], exps[2]}), getExperimentNames(actual))
+
+	name := "names-exp-2"
+	_, err = s.ProjectsExperimentService.ListAllExperiments(models.ID(projectId), services.ListExperimentsParams{
+		Name:  &name,
+		Names: []string{"names-exp-1"},
+	})
+	s.Suite.Assert().EqualError(err, "name and names cannot be combined")
+	s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsStablePaging() {
+	// All experiments share the same updated_at
+	projectId, _, err := s.createProject([]models.Experiment{
+		{Name: "paging-exp-1"},
+		{Name: "paging-exp-2"},
+		{Name: "paging-exp-3"},
+		{Name: "paging-exp-4"},
+		{Name: "paging-exp-5"},
+	})
+	s.Suite.Require().NoError(err)
+
+	pageSize := int32(2)
+	actual := []*models.Experiment{}
+	for page := int32(1); page <= 3; page++ {
+		page := page
+		pageExps, pagingResponse, err := s.ProjectsExperimentService.ListExperiments(projectId, services.ListExperimentsParams{
+			PaginationOptions: pagination.PaginationOptions{Page: &page, PageSize: &pageSize},
+		})
+		s.Suite.Require().NoError(err)
+		tu.AssertEqualValues(s.Suite.T(), &pagination.Paging{Page: page, Pages: 3, Total: 5}, pagingResponse)
+		actual = append(actual, pageExps...)
+	}
+
+	// Experiments should be ordered by the id in descending order, with no duplicates across pages
+	s.Suite.Assert().Equal(
+		[]string{"paging-exp-5", "paging-exp-4", "paging-exp-3", "paging-exp-2", "paging-exp-1"},
+		getExperimentNames(actual),
+	)
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsIdsOnly() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "ids-only-exp-1", Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}}},
+		{Name: "ids-only-exp-2"},
+		{Name: "ids-only-exp-3"},
+	})
+	s.Suite.Require().NoError(err)
+
+	// Only the ids are populated, and the results are paginated
+	pageSize := int32(2)
+	actual := []*models.Experiment{}
+	for page := int32(1); page <= 2; page++ {
+		page := page
+		pageExps, pagingResponse, err := s.ProjectsExperimentService.ListExperiments(projectId, services.ListExperimentsParams{
+			PaginationOptions: pagination.PaginationOptions{Page: &page, PageSize: &pageSize},
+			IdsOnly:           true,
+		})
+		s.Suite.Require().NoError(err)
+		tu.AssertEqualValues(s.Suite.T(), &pagination.Paging{Page: page, Pages: 2, Total: 3}, pagingResponse)
+		actual = append(actual, pageExps...)
+	}
+	tu.AssertEqualValues(s.Suite.T(), []*models.Experiment{{ID: exps[2].ID}, {ID: exps[1].ID}, {ID: exps[0].ID}}, actual)
+
+	// The other filters still apply
+	actual, _, err = s.ProjectsExperimentService.ListExperiments(projectId, services.ListExperimentsParams{
+		Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		IdsOnly: true,
+	})
+	s.Suite.Require().NoError(err)
+	tu.AssertEqualValues(s.Suite.T(), []*models.Experiment{{ID: exps[0].ID}}, actual)
+
+	_, _, err = s.ProjectsExperimentService.ListExperiments(projectId, services.ListExperimentsParams{
+		Fields:  &[]models.ExperimentField{models.ExperimentFieldName},
+		IdsOnly: true,
+	})
+	s.Suite.Assert().EqualError(err, "ids_only cannot be combined with fields")
+	s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsTreatmentConfigKeyFilter() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name: "config-key-exp-1",
+			Treatments: models.ExperimentTreatments{
+				{Name: "control", Configuration: map[string]interface{}{"model_version": "v1"}},
+				{Name: "treatment", Configuration: map[string]interface{}{"model_version": "v2"}},
+			},
+		},
+		{
+			Name: "config-key-exp-2",
+			Treatments: models.ExperimentTreatments{
+				{Name: "control", Configuration: map[string]interface{}{"weight": 0.1}},
+				{Name: "treatment", Configuration: map[string]interface{}{"model_version": "v2"}},
+			},
+		},
+		{
+			Name: "config-key-exp-3",
+			Treatments: models.ExperimentTreatments{
+				{
+					Name: "control",
+					Configuration: map[string]interface{}{
+						"meta": map[string]interface{}{"model_version": "v1"},
+					},
+				},
+			},
+		},
+		{Name: "config-key-exp-4"},
+	})
+	s.Suite.Require().NoError(err)
+
+	tests := map[string]struct {
+		key      string
+		expected []*models.Experiment
+	}{
+		"top-level key in some treatments": {
+			key:      "model_version",
+			expected: []*models.Experiment{exps[0], exps[1]},
+		},
+		"top-level key in one treatment": {
+			key:      "weight",
+			expected: []*models.Experiment{exps[1]},
+		},
+		"key not present": {
+			key:      "model_version'; --",
+			expected: []*models.Experiment{},
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			key := data.key
+			actual, err := s.ProjectsExperimentService.ListAllExperiments(models.ID(projectId), services.ListExperimentsParams{
+				TreatmentConfigHasKey: &key,
+			})
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().ElementsMatch(getExperimentNames(data.expected), getExperimentNames(actual))
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsVersionFilter() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:    "version-exp-1",
+			Model:   models.Model{UpdatedAt: time.Date(2020, 4, 3, 4, 5, 6, 0, time.UTC)},
+			Version: 1,
+		},
+		{
+			Name:    "version-exp-2",
+			Model:   models.Model{UpdatedAt: time.Date(2020, 4, 2, 4, 5, 6, 0, time.UTC)},
+			Version: 3,
+		},
+		{
+			Name:    "version-exp-3",
+			Model:   models.Model{UpdatedAt: time.Date(2020, 4, 1, 4, 5, 6, 0, time.UTC)},
+			Version: 5,
+		},
+	})
+	s.Suite.Require().NoError(err)
+	version2 := int64(2)
+	version3 := int64(3)
+	version4 := int64(4)
+
+	tests := map[string]struct {
+		params    services.ListExperimentsParams
+		expected  []*models.Experiment
+		errString string
+	}{
+		"failure | min version greater than max version": {
+			params:    services.ListExperimentsParams{MinVersion: &version4, MaxVersion: &version2},
+			errString: "min_version must not be greater than max_version",
+		},
+		"success | min version": {
+			params:   services.ListExperimentsParams{MinVersion: &version2},
+			expected: []*models.Experiment{exps[1], exps[2]},
+		},
+		"success | max version": {
+			params:   services.ListExperimentsParams{MaxVersion: &version3},
+			expected: []*models.Experiment{exps[0], exps[1]},
+		},
+		"success | min and max version": {
+			params:   services.ListExperimentsParams{MinVersion: &version2, MaxVersion: &version4},
+			expected: []*models.Experiment{exps[1]},
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			actual, _, err := s.ProjectsExperimentService.ListExperiments(projectId, data.params)
+			if data.errString == "" {
+				s.Suite.Require().NoError(err)
+				tu.AssertEqualValues(t, data.expected, actual)
+			} else {
+				s.Suite.Assert().EqualError(err, data.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsTreatmentCount() {
+	newTreatments := func(count int) models.ExperimentTreatments {
+		treatments := models.ExperimentTreatments{}
+		for i := 0; i < count; i++ {
+			treatments = append(treatments, models.ExperimentTreatment{
+				Name:          fmt.Sprintf("treatment-%d", i),
+				Configuration: map[string]interface{}{},
+			})
+		}
+		return treatments
+	}
+	projectId, _, err := s.createProject([]models.Experiment{
+		{Name: "treatment-count-exp-none"},
+		{Name: "treatment-count-exp-2", Treatments: newTreatments(2)},
+		{Name: "treatment-count-exp-5", Treatments: newTreatments(5)},
+		{Name: "treatment-count-exp-7", Treatments: newTreatments(7)},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	count0, count2, count5, count6 := 0, 2, 5, 6
+
+	tests := map[string]struct {
+		params    services.ListExperimentsParams
+		expected  []string
+		errString string
+	}{
+		"failure | min treatments greater than max treatments": {
+			params:    services.ListExperimentsParams{MinTreatments: &count6, MaxTreatments: &count2},
+			errString: "min_treatments must not be greater than max_treatments",
+		},
+		"success | more than 5 treatments": {
+			params:   services.ListExperimentsParams{MinTreatments: &count6},
+			expected: []string{"treatment-count-exp-7"},
+		},
+		"success | at least 2 treatments": {
+			params:   services.ListExperimentsParams{MinTreatments: &count2},
+			expected: []string{"treatment-count-exp-2", "treatment-count-exp-5", "treatment-count-exp-7"},
+		},
+		"success | no treatments": {
+			params:   services.ListExperimentsParams{MaxTreatments: &count0},
+			expected: []string{"treatment-count-exp-none"},
+		},
+		"success | min and max treatments": {
+			params:   services.ListExperimentsParams{MinTreatments: &count2, MaxTreatments: &count5},
+			expected: []string{"treatment-count-exp-2", "treatment-count-exp-5"},
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.Run(name, func() {
+			exps, _, err := svc.ListExperiments(projectId, data.params)
+			if data.errString != "" {
+				s.Suite.Assert().EqualError(err, data.errString)
+				return
+			}
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().ElementsMatch(data.expected, getExperimentNames(exps))
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestMutexGroupEnableExperiment() {
+	mutexGroupA := "group-a"
+	mutexGroupB := "group-b"
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "mutex-exp-active", Status: models.ExperimentStatusActive, MutexGroup: &mutexGroupA},
+		{Name: "mutex-exp-same-group", Status: models.ExperimentStatusInactive, MutexGroup: &mutexGroupA},
+		{Name: "mutex-exp-other-group", Status: models.ExperimentStatusInactive, MutexGroup: &mutexGroupB},
+		{
+			Name:       "mutex-exp-other-window",
+			Status:     models.ExperimentStatusInactive,
+			MutexGroup: &mutexGroupA,
+			StartTime:  time.Date(2020, 3, 2, 4, 5, 6, 0, time.UTC),
+			EndTime:    time.Date(2020, 3, 3, 4, 5, 6, 0, time.UTC),
+		},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+
+	tests := map[string]struct {
+		experimentId int64
+		errString    string
+	}{
+		"failure | same mutex group": {
+			experimentId: exps[1].ID.ToApiSchema(),
+			errString: fmt.Sprintf(
+				"experiment mutex-exp-active (id %d) in the same mutex group group-a is already active in the given time range",
+				exps[0].ID),
+		},
+		"success | different mutex group": {
+			experimentId: exps[2].ID.ToApiSchema(),
+		},
+		"success | same mutex group in a different time range": {
+			experimentId: exps[3].ID.ToApiSchema(),
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			err := svc.EnableExperiment(settings, data.experimentId)
+			if data.errString == "" {
+				s.Suite.Require().NoError(err)
+			} else {
+				s.Suite.Assert().EqualError(err, data.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestNormalizeExperimentTimesToUTC() {
+	projectId := s.newProjectId()
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	sgt := time.FixedZone("SGT", 8*60*60)
+	updatedBy := "test-user"
+
+	exp, err := svc.CreateExperiment(settings, services.CreateExperimentRequestBody{
+		Name:      "utc-exp",
+		Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+		StartTime: time.Date(2022, 1, 1, 8, 0, 0, 0, sgt),
+		EndTime:   time.Date(2022, 1, 2, 8, 0, 0, 0, sgt),
+		Status:    models.ExperimentStatusInactive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Require().NoError(err)
+	tu.AssertEqualValues(s.Suite.T(), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), exp.StartTime)
+	tu.AssertEqualValues(s.Suite.T(), time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), exp.EndTime)
+
+	exp, err = svc.UpdateExperiment(settings, exp.ID.ToApiSchema(), services.UpdateExperimentRequestBody{
+		Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+		StartTime: time.Date(2022, 1, 3, 8, 0, 0, 0, sgt),
+		EndTime:   time.Date(2022, 1, 4, 8, 0, 0, 0, sgt),
+		Status:    models.ExperimentStatusInactive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Require().NoError(err)
+	tu.AssertEqualValues(s.Suite.T(), time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC), exp.StartTime)
+	tu.AssertEqualValues(s.Suite.T(), time.Date(2022, 1, 4, 0, 0, 0, 0, time.UTC), exp.EndTime)
+
+	// The stored window should be matched only by the equivalent time range
+	for _, data := range []struct {
+		startTime time.Time
+		endTime   time.Time
+		expected  int
+	}{
+		{time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2022, 1, 4, 7, 59, 0, 0, sgt), time.Date(2022, 1, 4, 7, 59, 0, 0, sgt), 1},
+		{time.Date(2022, 1, 3, 7, 59, 0, 0, sgt), time.Date(2022, 1, 3, 7, 59, 0, 0, sgt), 0},
+		{time.Date(2022, 1, 4, 0, 1, 0, 0, time.UTC), time.Date(2022, 1, 4, 0, 1, 0, 0, time.UTC), 0},
+	} {
+		startTime, endTime := data.startTime, data.endTime
+		exps, err := svc.ListAllExperiments(settings.ProjectID, services.ListExperimentsParams{
+			StartTime: &startTime,
+			EndTime:   &endTime,
+		})
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Len(exps, data.expected)
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestOrthogonalityLookahead() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:      "lookahead-exp-scheduled",
+			StartTime: time.Date(2020, 2, 3, 2, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 2, 4, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	// All the experiments overlap, so any orthogonality check fails; the checked experiments are recorded
+	var checkedExps []string
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			for _, exp := range args.Get(3).([]models.Experiment) {
+				checkedExps = append(checkedExps, exp.Name)
+			}
+		}).
+		Return(fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID))
+	// The clock is frozen before the windows of the experiments, so that the active experiments can be created
+	clock := fixedClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	svc := newPermissiveExperimentServiceWithClock(s.ProjectsDB, segmenterSvc, clock)
+	updatedBy := "test-user"
+
+	tests := map[string]struct {
+		lookahead   time.Duration
+		checkedExps []string
+	}{
+		"scheduled experiment within the lookahead": {
+			lookahead:   3 * time.Hour,
+			checkedExps: []string{"lookahead-exp-scheduled"},
+		},
+		"scheduled experiment beyond the lookahead": {
+			lookahead: 30 * time.Minute,
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			checkedExps = nil
+			settings := models.Settings{
+				ProjectID: models.ID(projectId),
+				Config: &models.ExperimentationConfig{
+					Segmenters:             models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+					OrthogonalityLookahead: data.lookahead,
+				},
+			}
+			// The conflict with the scheduled experiment does not fail the creation, as the windows do not overlap
+			exp, err := svc.CreateExperiment(settings, services.CreateExperimentRequestBody{
+				Name:      fmt.Sprintf("lookahead-exp-%s", data.lookahead),
+				Segment:   models.ExperimentSegmentRaw{},
+				StartTime: time.Date(2020, 2, 3, 0, 0, 0, 0, time.UTC),
+				EndTime:   time.Date(2020, 2, 3, 1, 0, 0, 0, time.UTC),
+				Status:    models.ExperimentStatusActive,
+				Tier:      models.ExperimentTierDefault,
+				Type:      models.ExperimentTypeAB,
+				UpdatedBy: &updatedBy,
+			})
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().Equal(data.checkedExps, checkedExps)
+
+			// Deactivate the experiment, so that it is not checked against in the next case
+			s.Suite.Require().NoError(svc.DisableExperiment(projectId, exp.ID.ToApiSchema()))
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestOrthogonalityOnUpdateStatusTransitions() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "transition-exp-other"},
+		{Name: "transition-exp-active-1"},
+		{Name: "transition-exp-active-2"},
+		{Name: "transition-exp-inactive-1", Status: models.ExperimentStatusInactive},
+		{Name: "transition-exp-inactive-2", Status: models.ExperimentStatusInactive},
+	})
+	s.Suite.Require().NoError(err)
+
+	// All the experiments overlap, so any orthogonality check fails
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything, mock.Anything).
+		Return(fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID))
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	updatedBy := "test-user"
+
+	tests := map[string]struct {
+		experiment *models.Experiment
+		newStatus  models.ExperimentStatus
+		errString  string
+	}{
+		"active to inactive": {
+			experiment: exps[1],
+			newStatus:  models.ExperimentStatusInactive,
+		},
+		"inactive to active": {
+			experiment: exps[3],
+			newStatus:  models.ExperimentStatusActive,
+			errString:  fmt.Sprintf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID),
+		},
+		"active to active": {
+			experiment: exps[2],
+			newStatus:  models.ExperimentStatusActive,
+			errString:  fmt.Sprintf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID),
+		},
+		"inactive to inactive": {
+			experiment: exps[4],
+			newStatus:  models.ExperimentStatusInactive,
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			_, err := svc.UpdateExperiment(settings, data.experiment.ID.ToApiSchema(), services.UpdateExperimentRequestBody{
+				Segment:   models.ExperimentSegmentRaw{},
+				StartTime: data.experiment.StartTime,
+				EndTime:   data.experiment.EndTime,
+				Status:    data.newStatus,
+				Tier:      data.experiment.Tier,
+				Type:      data.experiment.Type,
+				UpdatedBy: &updatedBy,
+			})
+			if data.errString == "" {
+				s.Suite.Require().NoError(err)
+			} else {
+				s.Suite.Assert().EqualError(err, data.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestOrthogonalityExemptSegmenters() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "exempt-exp-active", Status: models.ExperimentStatusActive},
+		{Name: "exempt-exp-inactive-1", Status: models.ExperimentStatusInactive},
+		{Name: "exempt-exp-inactive-2", Status: models.ExperimentStatusInactive},
+	})
+	s.Suite.Require().NoError(err)
+
+	// The experiments are only orthogonal on the debug segmenter
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality",
+		projectId, []string{"string_segmenter", "debug_segmenter"}, mock.Anything, mock.Anything,
+	).Return(nil)
+	segmenterSvc.On("ValidateSegmentOrthogonality",
+		projectId, []string{"string_segmenter"}, mock.Anything, mock.Anything,
+	).Return(fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID))
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+
+	tests := map[string]struct {
+		experimentId     int64
+		exemptSegmenters []string
+		errString        string
+	}{
+		"failure | exempted segmenter": {
+			experimentId:     exps[1].ID.ToApiSchema(),
+			exemptSegmenters: []string{"debug_segmenter"},
+			errString:        fmt.Sprintf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID),
+		},
+		"success | no exempted segmenter": {
+			experimentId: exps[2].ID.ToApiSchema(),
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			settings := models.Settings{
+				ProjectID: models.ID(projectId),
+				Config: &models.ExperimentationConfig{
+					Segmenters:                    models.ProjectSegmenters{Names: []string{"string_segmenter", "debug_segmenter"}},
+					OrthogonalityExemptSegmenters: data.exemptSegmenters,
+				},
+			}
+			err := svc.EnableExperiment(settings, data.experimentId)
+			if data.errString == "" {
+				s.Suite.Require().NoError(err)
+			} else {
+				s.Suite.Assert().EqualError(err, data.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestGetExperimentErrorTypes() {
+	projectId, exps, err := s.createProject([]models.Experiment{{Name: "get-exp-errors"}})
+	s.Suite.Require().NoError(err)
+
+	// A missing experiment is not found
+	_, err = s.ProjectsExperimentService.GetExperiment(projectId, 1000)
+	s.Suite.Assert().EqualError(err, "record not found")
+	s.Suite.Assert().Equal(errors.NotFound, errors.GetType(err))
+
+	// Any other failure of the query is an internal error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	svc := newPermissiveExperimentService(s.ProjectsDB.WithContext(ctx))
+	_, err = svc.GetExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Assert().ErrorContains(err, "context canceled")
+	s.Suite.Assert().Equal(errors.Internal, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsByExactSegment() {
+	projectId, _, err := s.createProject([]models.Experiment{
+		{
+			Name: "exact-exp",
+			Segment: models.ExperimentSegment{
+				"string_segmenter": []string{"seg-a", "seg-b"},
+				"other_segmenter":  []string{"other-a"},
+			},
+		},
+		{
+			Name: "exact-exp-reordered",
+			Segment: models.ExperimentSegment{
+				"string_segmenter": []string{"seg-b", "seg-a"},
+				"other_segmenter":  []string{"other-a"},
+				"unset_segmenter":  []string{},
+			},
+			Status: models.ExperimentStatusInactive,
+		},
+		{
+			Name:    "exact-exp-subset",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-a", "seg-b"}},
+		},
+		{
+			Name: "exact-exp-superset",
+			Segment: models.ExperimentSegment{
+				"string_segmenter": []string{"seg-a", "seg-b", "seg-c"},
+				"other_segmenter":  []string{"other-a"},
+			},
+		},
+		{
+			Name: "exact-exp-override",
+			Segment: models.ExperimentSegment{
+				"string_segmenter": []string{"seg-a", "seg-b"},
+				"other_segmenter":  []string{"other-a"},
+			},
+			Tier: models.ExperimentTierOverride,
+		},
+		{Name: "exact-exp-unset"},
+	})
+	s.Suite.Require().NoError(err)
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterTypes", projectId).Return(map[string]schema.SegmenterType{
+		"string_segmenter": schema.SegmenterTypeString,
+		"other_segmenter":  schema.SegmenterTypeString,
+		"unset_segmenter":  schema.SegmenterTypeString,
+	}, nil)
+	svc := newPermissiveExperimentServiceWithMocks(s.ProjectsDB, segmenterSvc, &mocks.PubSubPublisherService{})
+
+	tests := map[string]struct {
+		segment   models.ExperimentSegmentRaw
+		tier      models.ExperimentTier
+		expected  []string
+		errString string
+	}{
+		"exact matches in any order": {
+			segment: models.ExperimentSegmentRaw{
+				"other_segmenter":  []interface{}{"other-a"},
+				"string_segmenter": []interface{}{"seg-b", "seg-a"},
+			},
+			tier:     models.ExperimentTierDefault,
+			expected: []string{"exact-exp", "exact-exp-reordered"},
+		},
+		"subset of the segmenters": {
+			segment:  models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-a", "seg-b"}},
+			tier:     models.ExperimentTierDefault,
+			expected: []string{"exact-exp-subset"},
+		},
+		"unset segment": {
+			segment:  models.ExperimentSegmentRaw{"string_segmenter": []interface{}{}},
+			tier:     models.ExperimentTierDefault,
+			expected: []string{"exact-exp-unset"},
+		},
+		"override tier": {
+			segment: models.ExperimentSegmentRaw{
+				"other_segmenter":  []interface{}{"other-a"},
+				"string_segmenter": []interface{}{"seg-a", "seg-b"},
+			},
+			tier:     models.ExperimentTierOverride,
+			expected: []string{"exact-exp-override"},
+		},
+		"no match": {
+			segment:  models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-a"}},
+			tier:     models.ExperimentTierDefault,
+			expected: []string{},
+		},
+		"unknown tier": {
+			tier:      models.ExperimentTier("unknown"),
+			errString: "unknown experiment tier: unknown",
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			exps, err := svc.ListExperimentsByExactSegment(projectId, data.segment, data.tier)
+			if data.errString == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, data.expected, getExperimentNames(exps))
+			} else {
+				assert.EqualError(t, err, data.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestFindSegmentIntersections() {
+	projectId, _, err := s.createProject([]models.Experiment{
+		{
+			Name: "intersect-exp-full-match",
+			Segment: models.ExperimentSegment{
+				"string_segmenter": []string{"seg-a", "seg-b"},
+				"other_segmenter":  []string{"other-a"},
+			},
+		},
+		{
+			Name:    "intersect-exp-string-value",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-b", "seg-c"}},
+		},
+		{
+			Name: "intersect-exp-other-value",
+			Segment: models.ExperimentSegment{
+				"string_segmenter": []string{"seg-d"},
+				"other_segmenter":  []string{"other-a", "other-b"},
+			},
+		},
+		{
+			Name:    "disjoint-exp",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-d"}},
+		},
+		{
+			Name:    "unset-exp",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{}},
+		},
+		{
+			Name:    "intersect-exp-inactive",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-a"}},
+			Status:  models.ExperimentStatusInactive,
+		},
+		{
+			Name:    "intersect-exp-override",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-a"}},
+			Tier:    models.ExperimentTierOverride,
+		},
+	})
+	s.Suite.Require().NoError(err)
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterTypes", projectId).Return(map[string]schema.SegmenterType{
+		"string_segmenter": schema.SegmenterTypeString,
+		"other_segmenter":  schema.SegmenterTypeString,
+	}, nil)
+	svc := newPermissiveExperimentServiceWithMocks(s.ProjectsDB, segmenterSvc, &mocks.PubSubPublisherService{})
+
+	tests := map[string]struct {
+		segment  models.ExperimentSegmentRaw
+		tier     models.ExperimentTier
+		expected []string
+	}{
+		"full match": {
+			segment: models.ExperimentSegmentRaw{
+				"string_segmenter": []interface{}{"seg-a", "seg-b"},
+				"other_segmenter":  []interface{}{"other-a"},
+			},
+			tier: models.ExperimentTierDefault,
+			expected: []string{
+				"intersect-exp-full-match", "intersect-exp-string-value", "intersect-exp-other-value",
+			},
+		},
+		"shared value of one segmenter": {
+			segment: models.ExperimentSegmentRaw{
+				"string_segmenter": []interface{}{"seg-c", "seg-e"},
+				"other_segmenter":  []interface{}{"other-b"},
+			},
+			tier:     models.ExperimentTierDefault,
+			expected: []string{"intersect-exp-string-value", "intersect-exp-other-value"},
+		},
+		"no shared values": {
+			segment: models.ExperimentSegmentRaw{
+				"string_segmenter": []interface{}{"seg-e"},
+				"other_segmenter":  []interface{}{"other-c"},
+			},
+			tier:     models.ExperimentTierDefault,
+			expected: []string{},
+		},
+		"no values": {
+			segment:  models.ExperimentSegmentRaw{"string_segmenter": []interface{}{}},
+			tier:     models.ExperimentTierDefault,
+			expected: []string{},
+		},
+		"override tier": {
+			segment:  models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-a"}},
+			tier:     models.ExperimentTierOverride,
+			expected: []string{"intersect-exp-override"},
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.Run(name, func() {
+			exps, err := svc.FindSegmentIntersections(projectId, data.segment, data.tier)
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().Equal(data.expected, getExperimentNames(exps))
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestListCompetingExperiments() {
+	projectId, _, err := s.createProject([]models.Experiment{
+		{
+			Name:    "compete-exp-match",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-a", "seg-b"}},
+		},
+		{
+			Name:      "compete-exp-match-later",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-a"}},
+			StartTime: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:    "compete-exp-no-match",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-c"}},
+		},
+		{
+			Name:    "compete-exp-unset",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{}},
+		},
+		{
+			Name:    "compete-exp-inactive",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-a"}},
+			Status:  models.ExperimentStatusInactive,
+		},
+		{
+			Name:    "compete-exp-override",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-a"}},
+			Tier:    models.ExperimentTierOverride,
+		},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+
+	segmentA := models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-a"}}
+	segmentD := models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-d"}}
+	tests := map[string]struct {
+		segment     models.ExperimentSegmentRaw
+		tier        models.ExperimentTier
+		startTime   time.Time
+		endTime     time.Time
+		includeWeak bool
+		expected    []string
+		err         string
+	}{
+		"segment match, window overlap": {
+			segment:   segmentA,
+			tier:      models.ExperimentTierDefault,
+			startTime: time.Date(2020, 2, 2, 12, 0, 0, 0, time.UTC),
+			endTime:   time.Date(2020, 2, 4, 0, 0, 0, 0, time.UTC),
+			expected:  []string{"compete-exp-match"},
+		},
+		"segment match with weak matches, window overlap": {
+			segment:     segmentA,
+			tier:        models.ExperimentTierDefault,
+			startTime:   time.Date(2020, 2, 2, 12, 0, 0, 0, time.UTC),
+			endTime:     time.Date(2020, 2, 4, 0, 0, 0, 0, time.UTC),
+			includeWeak: true,
+			expected:    []string{"compete-exp-match", "compete-exp-unset"},
+		},
+		"segment match, window overlapping multiple experiments": {
+			segment:   segmentA,
+			tier:      models.ExperimentTierDefault,
+			startTime: time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC),
+			endTime:   time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC),
+			expected:  []string{"compete-exp-match", "compete-exp-match-later"},
+		},
+		"segment match, no window overlap": {
+			segment:     segmentA,
+			tier:        models.ExperimentTierDefault,
+			startTime:   time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC),
+			endTime:     time.Date(2020, 4, 2, 0, 0, 0, 0, time.UTC),
+			includeWeak: true,
+			expected:    []string{},
+		},
+		"no segment match, window overlap": {
+			segment:   segmentD,
+			tier:      models.ExperimentTierDefault,
+			startTime: time.Date(2020, 2, 2, 12, 0, 0, 0, time.UTC),
+			endTime:   time.Date(2020, 2, 4, 0, 0, 0, 0, time.UTC),
+			expected:  []string{},
+		},
+		"no segment match with weak matches, window overlap": {
+			segment:     segmentD,
+			tier:        models.ExperimentTierDefault,
+			startTime:   time.Date(2020, 2, 2, 12, 0, 0, 0, time.UTC),
+			endTime:     time.Date(2020, 2, 4, 0, 0, 0, 0, time.UTC),
+			includeWeak: true,
+			expected:    []string{"compete-exp-unset"},
+		},
+		"override tier": {
+			segment:   segmentA,
+			tier:      models.ExperimentTierOverride,
+			startTime: time.Date(2020, 2, 2, 12, 0, 0, 0, time.UTC),
+			endTime:   time.Date(2020, 2, 4, 0, 0, 0, 0, time.UTC),
+			expected:  []string{"compete-exp-override"},
+		},
+		"invalid window": {
+			segment:   segmentA,
+			tier:      models.ExperimentTierDefault,
+			startTime: time.Date(2020, 2, 4, 0, 0, 0, 0, time.UTC),
+			endTime:   time.Date(2020, 2, 2, 0, 0, 0, 0, time.UTC),
+			err:       "start time must not be after end time",
+		},
+	}
+	for name, data := range tests {
+		s.Suite.Run(name, func() {
+			exps, err := svc.ListCompetingExperiments(
+				projectId, data.segment, data.tier, data.startTime, data.endTime, data.includeWeak,
+			)
+			if data.err != "" {
+				s.Suite.Assert().EqualError(err, data.err)
+				return
+			}
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().Equal(data.expected, getExperimentNames(exps))
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestGetSegmentCoverage() {
+	runningStart := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	runningEnd := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	partialProjectId, _, err := s.createProject([]models.Experiment{
+		{
+			Name: "coverage-exp-partial-1",
+			Segment: models.ExperimentSegment{
+				"string_segmenter":  []string{"seg-a"},
+				"integer_segmenter": []string{"1", "2"},
+			},
+			StartTime: runningStart,
+			EndTime:   runningEnd,
+		},
+		{
+			Name:      "coverage-exp-partial-2",
+			Segment:   models.ExperimentSegment{"integer_segmenter": []string{"1"}, "s2_ids": []string{"123"}},
+			StartTime: runningStart,
+			EndTime:   runningEnd,
+		},
+		{
+			Name:      "coverage-exp-override-1",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-a"}},
+			Tier:      models.ExperimentTierOverride,
+			StartTime: runningStart,
+			EndTime:   runningEnd,
+		},
+		{
+			Name: "coverage-exp-override-2",
+			Segment: models.ExperimentSegment{
+				"string_segmenter":  []string{"seg-b", "seg-c"},
+				"integer_segmenter": []string{"1", "2", "3", "4"},
+			},
+			Tier:      models.ExperimentTierOverride,
+			StartTime: runningStart,
+			EndTime:   runningEnd,
+		},
+	})
+	s.Suite.Require().NoError(err)
+	emptyProjectId, _, err := s.createProject([]models.Experiment{
+		{
+			Name:      "coverage-exp-inactive",
+			Segment:   models.ExperimentSegment{},
+			Status:    models.ExperimentStatusInactive,
+			StartTime: runningStart,
+			EndTime:   runningEnd,
+		},
+		{
+			Name:    "coverage-exp-completed",
+			Segment: models.ExperimentSegment{},
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterConfigurations", mock.Anything, mock.Anything).
+		Return([]*_segmenters.SegmenterConfiguration{
+			{
+				Name: "string_segmenter",
+				Options: map[string]*_segmenters.SegmenterValue{
+					"A": {Value: &_segmenters.SegmenterValue_String_{String_: "seg-a"}},
+					"B": {Value: &_segmenters.SegmenterValue_String_{String_: "seg-b"}},
+				},
+			},
+			{
+				Name: "integer_segmenter",
+				Options: map[string]*_segmenters.SegmenterValue{
+					"1": {Value: &_segmenters.SegmenterValue_Integer{Integer: 1}},
+					"2": {Value: &_segmenters.SegmenterValue_Integer{Integer: 2}},
+					"3": {Value: &_segmenters.SegmenterValue_Integer{Integer: 3}},
+				},
+			},
+			{
+				Name:    "s2_ids",
+				Options: map[string]*_segmenters.SegmenterValue{},
+			},
+		}, nil)
+	svc := newPermissiveExperimentServiceWithMocks(s.ProjectsDB, segmenterSvc, &mocks.PubSubPublisherService{})
+
+	tests := map[string]struct {
+		projectId int64
+		tier      models.ExperimentTier
+		expected  float64
+	}{
+		"zero coverage": {
+			projectId: emptyProjectId,
+			tier:      models.ExperimentTierDefault,
+			expected:  0,
+		},
+		// seg-a x {1, 2} and {seg-a, seg-b} x 1, i.e., 3 of the 6 combinations
+		"partial coverage": {
+			projectId: partialProjectId,
+			tier:      models.ExperimentTierDefault,
+			expected:  0.5,
+		},
+		// seg-a x {1, 2, 3} and seg-b x {1, 2, 3}, with the values that are not options disregarded
+		"full coverage": {
+			projectId: partialProjectId,
+			tier:      models.ExperimentTierOverride,
+			expected:  1,
+		},
+	}
+	for name, data := range tests {
+		s.Suite.Run(name, func() {
+			coverage, err := svc.GetSegmentCoverage(data.projectId, data.tier)
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().InDelta(data.expected, coverage, 1e-9)
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestGetEffectiveSegment() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "effective-exp-unset"},
+		{
+			Name:    "effective-exp-set",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+		},
+		{Name: "effective-exp-inactive", Status: models.ExperimentStatusInactive},
+	})
+	s.Suite.Require().NoError(err)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters:     models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+			DefaultSegment: models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+		},
+	}
+	err = s.ProjectsDB.Model(&models.Settings{}).Where("project_id = ?", projectId).Update("config", settings.Config).Error
+	s.Suite.Require().NoError(err)
+
+	// The orthogonality check receives the effective segments
+	defaultedSegment := models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}}
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality",
+		projectId,
+		[]string{"string_segmenter"},
+		defaultedSegment,
+		mock.MatchedBy(func(others []models.Experiment) bool {
+			segments := map[string]models.ExperimentSegment{}
+			for _, exp := range others {
+				segments[exp.Name] = exp.Segment
+			}
+			return assert.ObjectsAreEqual(map[string]models.ExperimentSegment{
+				"effective-exp-unset": {"string_segmenter": []string{"seg-1"}},
+				"effective-exp-set":   {"string_segmenter": []string{"seg-2"}},
+			}, segments)
+		}),
+	).Return(nil)
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+
+	// Project defaults tighten the unset segmenters
+	segment, err := svc.GetEffectiveSegment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(defaultedSegment, segment)
+	// Project defaults do not affect the segmenters that are set
+	segment, err = svc.GetEffectiveSegment(projectId, exps[1].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-2"}}, segment)
+
+	// Weak matches are only included if the project defaults match
+	status := models.ExperimentStatusActive
+	for value, expected := range map[string][]string{
+		"seg-1": {"effective-exp-unset"},
+		"seg-2": {"effective-exp-set"},
+	} {
+		list, _, err := svc.ListExperiments(projectId, services.ListExperimentsParams{
+			Status:           &status,
+			Segment:          models.ExperimentSegment{"string_segmenter": []string{value}},
+			IncludeWeakMatch: true,
+		})
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Equal(expected, getExperimentNames(list))
+	}
+
+	s.Suite.Require().NoError(svc.EnableExperiment(settings, exps[2].ID.ToApiSchema()))
+	segmenterSvc.AssertExpectations(s.Suite.T())
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsWithTreatmentChanges() {
+	var traffic50, traffic100 int32 = 50, 100
+	control := models.ExperimentTreatment{
+		Name: "control", Configuration: map[string]interface{}{"key": "value"}, Traffic: &traffic50,
+	}
+	controlOnly := models.ExperimentTreatment{
+		Name: "control", Configuration: map[string]interface{}{"key": "value"}, Traffic: &traffic100,
+	}
+	treatment := models.ExperimentTreatment{
+		Name: "treatment", Configuration: map[string]interface{}{"key": "value-1"}, Traffic: &traffic50,
+	}
+	day := func(month time.Month, d int) time.Time { return time.Date(2022, month, d, 0, 0, 0, 0, time.UTC) }
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:       "changed-exp",
+			Model:      models.Model{UpdatedAt: day(3, 10)},
+			Treatments: models.ExperimentTreatments{control},
+			Version:    3,
+		},
+		{
+			Name:       "unchanged-exp",
+			Model:      models.Model{UpdatedAt: day(3, 5)},
+			Treatments: models.ExperimentTreatments{control, treatment},
+			Version:    2,
+		},
+		{
+			Name:       "changed-before-exp",
+			Model:      models.Model{UpdatedAt: day(1, 15)},
+			Treatments: models.ExperimentTreatments{control, treatment},
+			Version:    2,
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	// Seed the prior versions of the experiments
+	for _, history := range []struct {
+		experiment *models.Experiment
+		version    int64
+		createdAt  time.Time
+		treatments models.ExperimentTreatments
+	}{
+		{experiment: exps[0], version: 1, createdAt: day(1, 1), treatments: models.ExperimentTreatments{controlOnly}},
+		{
+			experiment: exps[0],
+			version:    2,
+			createdAt:  day(3, 1),
+			treatments: models.ExperimentTreatments{control, treatment},
+		},
+		{
+			experiment: exps[1],
+			version:    1,
+			createdAt:  day(1, 1),
+			treatments: models.ExperimentTreatments{treatment, control},
+		},
+		{experiment: exps[2], version: 1, createdAt: day(1, 1), treatments: models.ExperimentTreatments{controlOnly}},
+	} {
+		err = s.ProjectsDB.Create(&models.ExperimentHistory{
+			Model:        models.Model{CreatedAt: history.createdAt},
+			ExperimentID: history.experiment.ID,
+			Version:      history.version,
+			Name:         history.experiment.Name,
+			Type:         history.experiment.Type,
+			Tier:         history.experiment.Tier,
+			Treatments:   history.treatments,
+			Segment:      history.experiment.Segment,
+			Status:       history.experiment.Status,
+			StartTime:    history.experiment.StartTime,
+			EndTime:      history.experiment.EndTime,
+			UpdatedBy:    fmt.Sprintf("user-%d", history.version),
+		}).Error
+		s.Suite.Require().NoError(err)
+	}
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+
+	changes, err := svc.ListExperimentsWithTreatmentChanges(projectId, day(2, 1))
+	s.Suite.Require().NoError(err)
+	s.Suite.Require().Len(changes, 2)
+	s.Suite.Assert().Equal(services.TreatmentChange{
+		ExperimentID:   exps[0].ID.ToApiSchema(),
+		ExperimentName: "changed-exp",
+		FromVersion:    1,
+		ToVersion:      2,
+		UpdatedAt:      changes[0].UpdatedAt,
+		UpdatedBy:      "user-2",
+		Diff: models.ExperimentTreatmentsDiff{
+			Added:    []string{"treatment"},
+			Removed:  []string{},
+			Modified: []string{"control"},
+		},
+	}, changes[0])
+	s.Suite.Assert().True(day(3, 1).Equal(changes[0].UpdatedAt))
+	s.Suite.Assert().Equal(int64(2), changes[1].FromVersion)
+	s.Suite.Assert().Equal(int64(3), changes[1].ToVersion)
+	s.Suite.Assert().Equal(models.ExperimentTreatmentsDiff{
+		Added:    []string{},
+		Removed:  []string{"treatment"},
+		Modified: []string{},
+	}, changes[1].Diff)
+
+	// Only the versions created since the given time are compared
+	changes, err = svc.ListExperimentsWithTreatmentChanges(projectId, day(3, 5))
+	s.Suite.Require().NoError(err)
+	s.Suite.Require().Len(changes, 1)
+	s.Suite.Assert().Equal(int64(3), changes[0].ToVersion)
+
+	changes, err = svc.ListExperimentsWithTreatmentChanges(projectId, day(4, 1))
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Empty(changes)
+}
+
+func (s *ExperimentServiceTestSuite) TestExportExperimentHistory() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "history-export-exp-1", Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}}},
+		{Name: "history-export-exp-2", Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-2"}}},
+	})
+	s.Suite.Require().NoError(err)
+	day := func(d int) time.Time {
+		return time.Date(2022, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	// Seed the versions of the experiments, out of order
+	for _, history := range []struct {
+		experiment *models.Experiment
+		version    int64
+		updatedAt  time.Time
+	}{
+		{experiment: exps[0], version: 2, updatedAt: day(3)},
+		{experiment: exps[1], version: 1, updatedAt: day(2)},
+		{experiment: exps[0], version: 1, updatedAt: day(1)},
+		{experiment: exps[0], version: 3, updatedAt: day(4)},
+	} {
+		err = s.ProjectsDB.Create(&models.ExperimentHistory{
+			Model:        models.Model{CreatedAt: history.updatedAt, UpdatedAt: history.updatedAt},
+			ExperimentID: history.experiment.ID,
+			Version:      history.version,
+			Name:         history.experiment.Name,
+			Type:         history.experiment.Type,
+			Tier:         history.experiment.Tier,
+			Treatments:   history.experiment.Treatments,
+			Segment:      history.experiment.Segment,
+			Status:       history.experiment.Status,
+			StartTime:    history.experiment.StartTime,
+			EndTime:      history.experiment.EndTime,
+			UpdatedBy:    fmt.Sprintf("user-%d", history.version),
+		}).Error
+		s.Suite.Require().NoError(err)
+	}
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+
+	type exportedVersion struct {
+		experimentId int64
+		version      int64
+		rawSegment   models.ExperimentSegmentRaw
+	}
+	getExportedVersions := func(exports []services.ExperimentHistoryExport) []exportedVersion {
+		versions := []exportedVersion{}
+		for _, export := range exports {
+			versions = append(versions, exportedVersion{
+				experimentId: export.History.ExperimentID.ToApiSchema(),
+				version:      export.History.Version,
+				rawSegment:   export.RawSegment,
+			})
+		}
+		return versions
+	}
+	segment1 := models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}}
+	segment2 := models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-2"}}
+	updatedAfter, updatedBefore := day(2), day(3)
+
+	tests := map[string]struct {
+		params    services.ExportExperimentHistoryParams
+		expected  []exportedVersion
+		errString string
+	}{
+		"all versions": {
+			expected: []exportedVersion{
+				{experimentId: exps[0].ID.ToApiSchema(), version: 1, rawSegment: segment1},
+				{experimentId: exps[0].ID.ToApiSchema(), version: 2, rawSegment: segment1},
+				{experimentId: exps[0].ID.ToApiSchema(), version: 3, rawSegment: segment1},
+				{experimentId: exps[1].ID.ToApiSchema(), version: 1, rawSegment: segment2},
+			},
+		},
+		"updated in range": {
+			params: services.ExportExperimentHistoryParams{UpdatedAfter: &updatedAfter, UpdatedBefore: &updatedBefore},
+			expected: []exportedVersion{
+				{experimentId: exps[0].ID.ToApiSchema(), version: 2, rawSegment: segment1},
+				{experimentId: exps[1].ID.ToApiSchema(), version: 1, rawSegment: segment2},
+			},
+		},
+		"updated after": {
+			params: services.ExportExperimentHistoryParams{UpdatedAfter: &updatedBefore},
+			expected: []exportedVersion{
+				{experimentId: exps[0].ID.ToApiSchema(), version: 2, rawSegment: segment1},
+				{experimentId: exps[0].ID.ToApiSchema(), version: 3, rawSegment: segment1},
+			},
+		},
+		"invalid range": {
+			params: services.ExportExperimentHistoryParams{
+				UpdatedAfter:  &updatedBefore,
+				UpdatedBefore: &updatedAfter,
+			},
+			errString: "updated_after must not be after updated_before",
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			exports, err := svc.ExportExperimentHistory(projectId, data.params)
+			if data.errString == "" {
+				s.Suite.Require().NoError(err)
+				s.Suite.Assert().Equal(data.expected, getExportedVersions(exports))
+			} else {
+				s.Suite.Assert().EqualError(err, data.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsSegmentRange() {
+	projectId, _, err := s.createProject([]models.Experiment{
+		{
+			Name:    "range-exp-morning",
+			Segment: models.ExperimentSegment{"hours_of_day": []string{"8", "9"}},
+		},
+		{
+			Name:    "range-exp-late-morning",
+			Segment: models.ExperimentSegment{"hours_of_day": []string{"10", "11"}},
+		},
+		{
+			Name:    "range-exp-evening",
+			Segment: models.ExperimentSegment{"hours_of_day": []string{"18", "19"}},
+		},
+		{
+			Name:    "range-exp-unset",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		},
+	})
+	s.Suite.Require().NoError(err)
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterTypes", projectId).Return(map[string]schema.SegmenterType{
+		"hours_of_day":     schema.SegmenterTypeInteger,
+		"string_segmenter": schema.SegmenterTypeString,
+	}, nil)
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+
+	tests := map[string]struct {
+		segmentRange     map[string]services.SegmenterRange
+		includeWeakMatch bool
+		expected         []string
+		err              string
+	}{
+		// The values are compared as numbers, e.g., 10 and 11 are in the range, unlike their strings
+		"overlapping range": {
+			segmentRange: map[string]services.SegmenterRange{"hours_of_day": {Min: 9, Max: 12}},
+			expected:     []string{"range-exp-morning", "range-exp-late-morning"},
+		},
+		"single value range": {
+			segmentRange: map[string]services.SegmenterRange{"hours_of_day": {Min: 19, Max: 19}},
+			expected:     []string{"range-exp-evening"},
+		},
+		"non-overlapping range": {
+			segmentRange: map[string]services.SegmenterRange{"hours_of_day": {Min: 13, Max: 17}},
+			expected:     []string{},
+		},
+		"non-overlapping range with weak matches": {
+			segmentRange:     map[string]services.SegmenterRange{"hours_of_day": {Min: 13, Max: 17}},
+			includeWeakMatch: true,
+			expected:         []string{"range-exp-unset"},
+		},
+		"invalid range": {
+			segmentRange: map[string]services.SegmenterRange{"hours_of_day": {Min: 12, Max: 9}},
+			err:          "segment range of hours_of_day must have min less than or equal to max",
+		},
+		"non-integer segmenter": {
+			segmentRange: map[string]services.SegmenterRange{"string_segmenter": {Min: 1, Max: 2}},
+			err:          "segment range is only supported for integer segmenters, got string_segmenter",
+		},
+	}
+	for name, data := range tests {
+		s.Suite.Run(name, func() {
+			exps, _, err := svc.ListExperiments(projectId, services.ListExperimentsParams{
+				SegmentRange:     data.segmentRange,
+				IncludeWeakMatch: data.includeWeakMatch,
+			})
+			if data.err != "" {
+				s.Suite.Assert().EqualError(err, data.err)
+				return
+			}
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().ElementsMatch(data.expected, getExperimentNames(exps))
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsWeakMatchPerSegmenter() {
+	segment := models.ExperimentSegment{"string_segmenter": []string{"seg-1"}, "integer_segmenter": []string{"1"}}
+	projectId, _, err := s.createProject([]models.Experiment{
+		{Name: "weak-match-exp-both", Segment: segment},
+		{Name: "weak-match-exp-string-only", Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}}},
+		{Name: "weak-match-exp-integer-only", Segment: models.ExperimentSegment{"integer_segmenter": []string{"1"}}},
+		{Name: "weak-match-exp-neither"},
+		{
+			Name:    "weak-match-exp-other-string",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	// The experiments are listed in the reverse order of their creation, as they have the same update time
+	tests := map[string]struct {
+		includeWeakMatch      bool
+		weakMatchPerSegmenter map[string]bool
+		expected              []string
+		errString             string
+	}{
+		"no weak matches": {
+			expected: []string{"weak-match-exp-both"},
+		},
+		// Each segmenter is either matched or weakly matched, so the experiments that set only one segmenter, to a
+		// matching value, and those that set neither, are selected, but not those that set a non-matching value
+		"weak matches of all segmenters": {
+			includeWeakMatch: true,
+			expected: []string{
+				"weak-match-exp-neither",
+				"weak-match-exp-integer-only",
+				"weak-match-exp-string-only",
+				"weak-match-exp-both",
+			},
+		},
+		"weak matches of the integer segmenter only": {
+			weakMatchPerSegmenter: map[string]bool{"integer_segmenter": true},
+			expected:              []string{"weak-match-exp-string-only", "weak-match-exp-both"},
+		},
+		"weak matches of the string segmenter only": {
+			weakMatchPerSegmenter: map[string]bool{"string_segmenter": true},
+			expected:              []string{"weak-match-exp-integer-only", "weak-match-exp-both"},
+		},
+		"weak matches of all but the string segmenter": {
+			includeWeakMatch:      true,
+			weakMatchPerSegmenter: map[string]bool{"string_segmenter": false},
+			expected:              []string{"weak-match-exp-string-only", "weak-match-exp-both"},
+		},
+		"segmenter that is not filtered": {
+			weakMatchPerSegmenter: map[string]bool{"bool_segmenter": true},
+			errString: "weak match is set for segmenter bool_segmenter, which is not filtered by segment or " +
+				"segment range",
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			exps, _, err := s.ProjectsExperimentService.ListExperiments(projectId, services.ListExperimentsParams{
+				Segment:               segment,
+				IncludeWeakMatch:      data.includeWeakMatch,
+				WeakMatchPerSegmenter: data.weakMatchPerSegmenter,
+			})
+			if data.errString == "" {
+				s.Suite.Require().NoError(err)
+				s.Suite.Assert().Equal(data.expected, getExperimentNames(exps))
+			} else {
+				s.Suite.Assert().EqualError(err, data.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsUnconstrainedSegmenter() {
+	projectId, _, err := s.createProject([]models.Experiment{
+		{
+			Name:    "unconstrained-exp-constrained",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		},
+		{
+			Name:    "unconstrained-exp-empty",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{}},
+		},
+		{
+			Name:    "unconstrained-exp-unset",
+			Segment: models.ExperimentSegment{"days_of_week": []string{"1"}},
+		},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+
+	tests := map[string]struct {
+		segmenter string
+		expected  []string
+	}{
+		"constrained by some experiments": {
+			segmenter: "string_segmenter",
+			expected:  []string{"unconstrained-exp-empty", "unconstrained-exp-unset"},
+		},
+		"constrained by no experiments": {
+			segmenter: "hours_of_day",
+			expected:  []string{"unconstrained-exp-constrained", "unconstrained-exp-empty", "unconstrained-exp-unset"},
+		},
+	}
+	for name, data := range tests {
+		s.Suite.Run(name, func() {
+			exps, _, err := svc.ListExperiments(projectId, services.ListExperimentsParams{
+				UnconstrainedSegmenter: &data.segmenter,
+			})
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().ElementsMatch(data.expected, getExperimentNames(exps))
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestSimulateOrthogonalityWithSegmenters() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:      "simulate-exp-1",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:      "simulate-exp-2",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-1"}, "days_of_week": []string{"1"}},
+			StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	// The experiments only conflict on the project's current segmenters
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, []string{"string_segmenter"}, mock.Anything,
+		mock.Anything).Return(fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID))
+	segmenterSvc.On("ValidateSegmentOrthogonality", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+
+	// The experiments are listed in the descending order of their ids, so the later experiment is checked first
+	conflictReport := services.ValidationReport{
+		Passed: false,
+		Failures: map[services.ValidationCheck]string{
+			services.ValidationCheckOrthogonality: fmt.Sprintf(
+				"Orthogonality check for experiment ID %d: Segment Orthogonality check failed against experiment ID %d",
+				exps[1].ID, exps[0].ID,
+			),
+		},
+	}
+
+	// Current segmenters
+	report, err := svc.SimulateOrthogonalityWithSegmenters(projectId, []string{"string_segmenter"})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(conflictReport, report)
+
+	// Adding a segmenter that separates the experiments resolves the conflict
+	report, err = svc.SimulateOrthogonalityWithSegmenters(projectId, []string{"string_segmenter", "days_of_week"})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.ValidationReport{Passed: true, Failures: map[services.ValidationCheck]string{}}, report)
+
+	// Unless the added segmenter is exempt from the orthogonality checks
+	cfg := &models.ExperimentationConfig{
+		Segmenters:                    models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		OrthogonalityExemptSegmenters: []string{"days_of_week"},
+	}
+	err = s.ProjectsDB.Model(&models.Settings{}).Where("project_id = ?", projectId).Update("config", cfg).Error
+	s.Suite.Require().NoError(err)
+	report, err = svc.SimulateOrthogonalityWithSegmenters(projectId, []string{"string_segmenter", "days_of_week"})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(conflictReport, report)
+}
+
+func (s *ExperimentServiceTestSuite) TestValidatePairwiseExperimentOrthogonalityDefaultSegment() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "pairwise-exp-unset"},
+		{
+			Name:    "pairwise-exp-set",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+		},
+	})
+	s.Suite.Require().NoError(err)
+	defaultSegment := models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}}
+
+	// The orthogonality check receives the effective segments
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality",
+		projectId,
+		[]string{"string_segmenter"},
+		defaultSegment,
+		mock.MatchedBy(func(others []models.Experiment) bool {
+			return len(others) == 1 && assert.ObjectsAreEqual(
+				models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+				others[0].Segment,
+			)
+		}),
+	).Return(nil)
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+
+	err = svc.ValidatePairwiseExperimentOrthogonality(projectId, exps, []string{"string_segmenter"}, defaultSegment)
+	s.Suite.Require().NoError(err)
+	segmenterSvc.AssertExpectations(s.Suite.T())
+}
+
+func (s *ExperimentServiceTestSuite) TestSaveInvalidExperiment() {
+	// The experiment's window is invalid, as it was saved before the model was validated
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:      "invalid-exp-window",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			Status:    models.ExperimentStatusInactive,
+			StartTime: time.Date(2022, 2, 2, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:    "invalid-exp-other",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+			Status:  models.ExperimentStatusInactive,
+		},
+		{
+			Name:    "invalid-exp-switchback",
+			Type:    models.ExperimentTypeSwitchback,
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-3"}},
+			Status:  models.ExperimentStatusInactive,
+		},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+
+	result, err := svc.BulkUpdateTier(
+		settings,
+		[]int64{exps[0].ID.ToApiSchema(), exps[2].ID.ToApiSchema()},
+		models.ExperimentTierOverride,
+	)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.BulkResult{
+		Succeeded: []int64{},
+		Failed: map[int64]string{
+			exps[0].ID.ToApiSchema(): "experiment end time must be after the start time",
+			exps[2].ID.ToApiSchema(): "switchback experiment must have a positive interval",
+		},
+	}, result)
+
+	// The invalid window would be swapped onto the other experiment
+	_, _, err = svc.SwapExperimentSchedules(settings, exps[0].ID.ToApiSchema(), exps[1].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, "experiment end time must be after the start time")
+	s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+
+	// Nothing is saved
+	for _, exp := range exps {
+		dbRecord, err := svc.GetDBRecord(models.ID(projectId), exp.ID)
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Equal(int64(1), dbRecord.Version)
+		s.Suite.Assert().Equal(models.ExperimentTierDefault, dbRecord.Tier)
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestDraftExperiment() {
+	startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	projectId, _, err := s.createProject([]models.Experiment{
+		{
+			Name:      "draft-test-active",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			StartTime: startTime,
+			EndTime:   endTime,
+		},
+	})
+	s.Suite.Require().NoError(err)
+	settings, err := services.NewProjectSettingsService(&services.Services{}, s.ProjectsDB).GetDBRecord(models.ID(projectId))
+	s.Suite.Require().NoError(err)
+
+	// Record the experiments that the segments are checked against for orthogonality
+	orthogonalityCandidates := []string{}
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			for _, exp := range args.Get(3).([]models.Experiment) {
+				orthogonalityCandidates = append(orthogonalityCandidates, exp.Name)
+			}
+		}).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+	updatedBy := "test-user"
+
+	// A draft is not checked for orthogonality, even if it overlaps an active experiment
+	draft, err := svc.CreateExperiment(*settings, services.CreateExperimentRequestBody{
+		Name:      "draft-test-draft",
+		Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+		StartTime: startTime,
+		EndTime:   endTime,
+		Status:    models.ExperimentStatusDraft,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(models.ExperimentStatusDraft, draft.Status)
+	s.Suite.Assert().Empty(orthogonalityCandidates)
+	draftId := draft.ID.ToApiSchema()
+
+	// Nor is it a candidate in the orthogonality checks of the other experiments
+	_, err = svc.CreateExperiment(*settings, services.CreateExperimentRequestBody{
+		Name:      "draft-test-new-active",
+		Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-2"}},
+		StartTime: startTime,
+		EndTime:   endTime,
+		Status:    models.ExperimentStatusActive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal([]string{"draft-test-active"}, orthogonalityCandidates)
+
+	// The draft can be listed by its friendly status
+	exps, _, err := svc.ListExperiments(projectId, services.ListExperimentsParams{
+		StatusFriendly: []services.ExperimentStatusFriendly{services.ExperimentStatusFriendlyDraft},
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal([]string{"draft-test-draft"}, getExperimentNames(exps))
+
+	// The draft can be updated, but its status can only be changed by promoting it
+	updateData := services.UpdateExperimentRequestBody{
+		Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1", "seg-3"}},
+		StartTime: startTime,
+		EndTime:   endTime,
+		Status:    models.ExperimentStatusDraft,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	}
+	draft, err = svc.UpdateExperiment(*settings, draftId, updateData)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(int64(2), draft.Version)
+	updateData.Status = models.ExperimentStatusActive
+	_, err = svc.UpdateExperiment(*settings, draftId, updateData)
+	s.Suite.Assert().EqualError(err, fmt.Sprintf("experiment id %d is a draft and must be promoted first", draftId))
+	err = svc.EnableExperiment(*settings, draftId)
+	s.Suite.Assert().EqualError(err, fmt.Sprintf("experiment id %d is a draft and must be promoted first", draftId))
+	err = svc.DisableExperiment(projectId, draftId)
+	s.Suite.Assert().EqualError(err, fmt.Sprintf("experiment id %d is a draft and must be promoted first", draftId))
+
+	// Promote the draft, after which it can be enabled
+	promoted, err := svc.PromoteExperiment(*settings, draftId)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(models.ExperimentStatusInactive, promoted.Status)
+	s.Suite.Assert().Equal(int64(3), promoted.Version)
+	_, err = svc.PromoteExperiment(*settings, draftId)
+	s.Suite.Assert().EqualError(err, fmt.Sprintf("experiment id %d is not a draft", draftId))
+	err = svc.EnableExperiment(*settings, draftId)
+	s.Suite.Require().NoError(err)
+
+	// An experiment cannot be turned back into a draft
+	updateData.Status = models.ExperimentStatusDraft
+	_, err = svc.UpdateExperiment(*settings, draftId, updateData)
+	s.Suite.Assert().EqualError(err, fmt.Sprintf("experiment id %d cannot be changed back to a draft", draftId))
+}
+
+func (s *ExperimentServiceTestSuite) TestExportExperimentsCSV() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name: "csv-exp-1",
+			Segment: models.ExperimentSegment{
+				"string_segmenter":  []string{"seg-1", "seg-2"},
+				"integer_segmenter": []string{"1", "10"},
+			},
+			Treatments: models.ExperimentTreatments{
+				{Name: "control", Configuration: map[string]interface{}{}},
+				{Name: "treatment", Configuration: map[string]interface{}{}},
+			},
+			UpdatedBy: "test-user",
+		},
+		{
+			Name:      "csv-exp-2",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-3"}},
+			UpdatedBy: "test-user",
+		},
+	})
+	s.Suite.Require().NoError(err)
+	cfg := &models.ExperimentationConfig{
+		Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter", "integer_segmenter"}},
+	}
+	err = s.ProjectsDB.Model(&models.Settings{}).Where("project_id = ?", projectId).Update("config", cfg).Error
+	s.Suite.Require().NoError(err)
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterTypes", projectId).Return(map[string]schema.SegmenterType{
+		"string_segmenter":  schema.SegmenterTypeString,
+		"integer_segmenter": schema.SegmenterTypeInteger,
+	}, nil)
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+
+	// The experiments are exported in the order of the list, i.e., the later experiment first
+	tests := map[string]struct {
+		pivotSegments bool
+		expected      string
+	}{
+		"segment column": {
+			expected: "id,name,type,tier,status,start_time,end_time,treatments,segment,updated_by,version\n" +
+				fmt.Sprintf("%d,csv-exp-2,A/B,default,active,2020-02-02T04:05:06Z,2020-02-03T04:05:06Z,,"+
+					"\"{\"\"string_segmenter\"\":[\"\"seg-3\"\"]}\",test-user,1\n", exps[1].ID) +
+				fmt.Sprintf("%d,csv-exp-1,A/B,default,active,2020-02-02T04:05:06Z,2020-02-03T04:05:06Z,control|treatment,"+
+					"\"{\"\"integer_segmenter\"\":[1,10],\"\"string_segmenter\"\":[\"\"seg-1\"\",\"\"seg-2\"\"]}\","+
+					"test-user,1\n", exps[0].ID),
+		},
+		"pivoted segment columns": {
+			pivotSegments: true,
+			expected: "id,name,type,tier,status,start_time,end_time,treatments,string_segmenter,integer_segmenter," +
+				"updated_by,version\n" +
+				fmt.Sprintf("%d,csv-exp-2,A/B,default,active,2020-02-02T04:05:06Z,2020-02-03T04:05:06Z,,seg-3,,"+
+					"test-user,1\n", exps[1].ID) +
+				fmt.Sprintf("%d,csv-exp-1,A/B,default,active,2020-02-02T04:05:06Z,2020-02-03T04:05:06Z,control|treatment,"+
+					"seg-1|seg-2,1|10,test-user,1\n", exps[0].ID),
+		},
+	}
+	for name, data := range tests {
+		s.Suite.Run(name, func() {
+			csv, err := svc.ExportExperimentsCSV(projectId, services.ListExperimentsParams{}, data.pivotSegments)
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().Equal(data.expected, string(csv))
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestCreateExperimentDefaultTierAndType() {
+	projectId, _, err := s.createProject(nil)
+	s.Suite.Require().NoError(err)
+	// The request is validated by the validation service, to check the required and allowed tiers and types
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", mock.Anything, mock.Anything).Return(nil)
+	allServices := newPermissiveServices(s.ProjectsDB, &mocks.SegmenterService{}, pubSubSvc)
+	allServices.ValidationService, err = services.NewValidationService(config.ValidationConfig{})
+	s.Suite.Require().NoError(err)
+	svc := services.NewExperimentService(allServices, s.ProjectsDB)
+
+	defaultsSettings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters:  models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+			DefaultTier: models.ExperimentTierOverride,
+			DefaultType: models.ExperimentTypeSwitchback,
+		},
+	}
+	noDefaultsSettings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	startTime := time.Now().UTC().Truncate(time.Second).Add(time.Hour)
+	interval := int32(60)
+	traffic := int32(50)
+	updatedBy := "test-user"
+	newRequestBody := func(
+		name string,
+		tier models.ExperimentTier,
+		expType models.ExperimentType,
+		interval *int32,
+	) services.CreateExperimentRequestBody {
+		return services.CreateExperimentRequestBody{
+			Name:      name,
+			Interval:  interval,
+			Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+			StartTime: startTime,
+			EndTime:   startTime.Add(24 * time.Hour),
+			Status:    models.ExperimentStatusInactive,
+			Treatments: models.ExperimentTreatments{
+				{Name: "control", Configuration: map[string]interface{}{"model_version": "v1"}, Traffic: &traffic},
+				{Name: "treatment", Configuration: map[string]interface{}{"model_version": "v2"}, Traffic: &traffic},
+			},
+			Tier:      tier,
+			Type:      expType,
+			UpdatedBy: &updatedBy,
+		}
+	}
+
+	tests := map[string]struct {
+		settings     models.Settings
+		expData      services.CreateExperimentRequestBody
+		expectedTier models.ExperimentTier
+		expectedType models.ExperimentType
+		errString    string
+	}{
+		"success | omitted with defaults": {
+			settings:     defaultsSettings,
+			expData:      newRequestBody("default-exp-omitted", "", "", &interval),
+			expectedTier: models.ExperimentTierOverride,
+			expectedType: models.ExperimentTypeSwitchback,
+		},
+		"success | explicit values with defaults": {
+			settings: defaultsSettings,
+			expData: newRequestBody("default-exp-explicit",
+				models.ExperimentTierDefault, models.ExperimentTypeAB, nil),
+			expectedTier: models.ExperimentTierDefault,
+			expectedType: models.ExperimentTypeAB,
+		},
+		"failure | omitted without defaults": {
+			settings: noDefaultsSettings,
+			expData:  newRequestBody("default-exp-no-defaults", "", "", nil),
+			errString: "Key: 'CreateExperimentRequestBody.Tier' Error:Field validation for 'Tier' failed on the 'required' tag\n" +
+				"Key: 'CreateExperimentRequestBody.Type' Error:Field validation for 'Type' failed on the 'required' tag",
+		},
+		"failure | invalid explicit value with defaults": {
+			settings: defaultsSettings,
+			expData:  newRequestBody("default-exp-invalid", "unknown", "", &interval),
+			errString: "Key: 'CreateExperimentRequestBody.Tier' Error:Field validation for 'Tier' failed on the " +
+				"'oneof' tag",
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			exp, err := svc.CreateExperiment(data.settings, data.expData)
+			if data.errString == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, data.expectedTier, exp.Tier)
+				assert.Equal(t, data.expectedType, exp.Type)
+			} else {
+				assert.EqualError(t, err, data.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestCreateExperimentPastWindow() {
+	projectId := s.newProjectId()
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	svc := newPermissiveExperimentServiceWithClock(s.ProjectsDB, &mocks.SegmenterService{}, fixedClock(now))
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	updatedBy := "test-user"
+	newRequestBody := func(name string, status models.ExperimentStatus) services.CreateExperimentRequestBody {
+		return services.CreateExperimentRequestBody{
+			Name:      name,
+			Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+			StartTime: now.Add(-2 * time.Hour),
+			EndTime:   now.Add(-time.Hour),
+			Status:    status,
+			Tier:      models.ExperimentTierDefault,
+			Type:      models.ExperimentTypeAB,
+			UpdatedBy: &updatedBy,
+		}
+	}
+
+	// Active experiments that have already ended are rejected
+	_, err := svc.CreateExperiment(settings, newRequestBody("past-window-exp-active", models.ExperimentStatusActive))
+	s.Suite.Assert().EqualError(err,
+		"cannot create an active experiment that has already ended at 2022-05-31T23:00:00Z")
+	s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+
+	// Inactive experiments may be backdated, e.g., when importing historical experiments
+	exp, err := svc.CreateExperiment(
+		settings,
+		newRequestBody("past-window-exp-inactive", models.ExperimentStatusInactive),
+	)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(models.ExperimentStatusInactive, exp.Status)
+	tu.AssertEqualValues(s.Suite.T(), now.Add(-time.Hour), exp.EndTime)
+
+	exps, _, err := svc.ListExperiments(projectId, services.ListExperimentsParams{})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal([]string{"past-window-exp-inactive"}, getExperimentNames(exps))
+}
+
+func (s *ExperimentServiceTestSuite) TestActiveExperimentLimits() {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "limit-exp-running-1", StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)},
+		{Name: "limit-exp-running-2", StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)},
+		{Name: "limit-exp-scheduled", StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour)},
+		{
+			Name:      "limit-exp-inactive-1",
+			Status:    models.ExperimentStatusInactive,
+			StartTime: now.Add(-time.Hour),
+			EndTime:   now.Add(time.Hour),
+		},
+		{
+			Name:      "limit-exp-inactive-2",
+			Status:    models.ExperimentStatusInactive,
+			StartTime: now.Add(-time.Hour),
+			EndTime:   now.Add(time.Hour),
+		},
+	})
+	s.Suite.Require().NoError(err)
+	limitErr := fmt.Sprintf("project %d already has 3 running experiments, the maximum allowed is 3", projectId)
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithClock(s.ProjectsDB, segmenterSvc, fixedClock(now))
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters:               models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+			SoftMaxActiveExperiments: 2,
+			HardMaxActiveExperiments: 3,
+		},
+	}
+	updatedBy := "test-user"
+	newRequestBody := func(name string, startTime time.Time) services.CreateExperimentRequestBody {
+		return services.CreateExperimentRequestBody{
+			Name:      name,
+			Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+			StartTime: startTime,
+			EndTime:   startTime.Add(time.Hour),
+			Status:    models.ExperimentStatusActive,
+			Tier:      models.ExperimentTierDefault,
+			Type:      models.ExperimentTypeAB,
+			UpdatedBy: &updatedBy,
+		}
+	}
+
+	// At the soft maximum, there is no warning
+	warning, err := svc.GetActiveExperimentLimitWarning(settings)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal("", warning)
+
+	// Crossing the soft maximum is allowed, with a warning
+	s.Suite.Require().NoError(svc.EnableExperiment(settings, exps[3].ID.ToApiSchema()))
+	warning, err = svc.GetActiveExperimentLimitWarning(settings)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(
+		fmt.Sprintf("project %d has 3 running experiments, exceeding the recommended maximum of 2", projectId), warning)
+
+	// Crossing the hard maximum is blocked, by both enabling and creating running experiments
+	err = svc.EnableExperiment(settings, exps[4].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, limitErr)
+	s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+	_, err = svc.CreateExperiment(settings, newRequestBody("limit-exp-created-running", now))
+	s.Suite.Assert().EqualError(err, limitErr)
+	newUpdateRequestBody := func(status models.ExperimentStatus) services.UpdateExperimentRequestBody {
+		return services.UpdateExperimentRequestBody{
+			Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+			StartTime: now.Add(-time.Hour),
+			EndTime:   now.Add(time.Hour),
+			Status:    status,
+			Tier:      models.ExperimentTierDefault,
+			Type:      models.ExperimentTypeAB,
+			UpdatedBy: &updatedBy,
+		}
+	}
+	_, err = svc.UpdateExperiment(settings, exps[4].ID.ToApiSchema(), newUpdateRequestBody(models.ExperimentStatusActive))
+	s.Suite.Assert().EqualError(err, limitErr)
+
+	// Updating an experiment that is already running does not add to the count
+	_, err = svc.UpdateExperiment(settings, exps[0].ID.ToApiSchema(), newUpdateRequestBody(models.ExperimentStatusActive))
+	s.Suite.Require().NoError(err)
+
+	// Experiments that are not running yet are not counted
+	_, err = svc.CreateExperiment(settings, newRequestBody("limit-exp-created-scheduled", now.Add(time.Hour)))
+	s.Suite.Require().NoError(err)
+
+	exps, _, err = svc.ListExperiments(projectId, services.ListExperimentsParams{
+		StatusFriendly: []services.ExperimentStatusFriendly{services.ExperimentStatusFriendlyRunning},
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().ElementsMatch(
+		[]string{"limit-exp-running-1", "limit-exp-running-2", "limit-exp-inactive-1"},
+		getExperimentNames(exps),
+	)
+}
+
+func (s *ExperimentServiceTestSuite) TestPriorityOverlapResolution() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:     "priority-exp-low",
+			Segment:  models.ExperimentSegment{"string_segmenter": []string{"seg-1", "seg-3"}},
+			Priority: 1,
+		},
+	})
+	s.Suite.Require().NoError(err)
+	// Every experiment overlaps with the existing ones
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID))
+	svc := newPermissiveExperimentServiceWithClock(s.ProjectsDB, segmenterSvc,
+		fixedClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	strictSettings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	prioritySettings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters:                models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+			PriorityOverlapResolution: true,
+		},
+	}
+	updatedBy := "test-user"
+	newRequestBody := func(name string, values []interface{}, priority int32) services.CreateExperimentRequestBody {
+		return services.CreateExperimentRequestBody{
+			Name:      name,
+			Segment:   models.ExperimentSegmentRaw{"string_segmenter": values},
+			StartTime: exps[0].StartTime,
+			EndTime:   exps[0].EndTime,
+			Status:    models.ExperimentStatusActive,
+			Tier:      models.ExperimentTierDefault,
+			Type:      models.ExperimentTypeAB,
+			UpdatedBy: &updatedBy,
+			Priority:  &priority,
+		}
+	}
+	at := time.Date(2020, 2, 2, 12, 0, 0, 0, time.UTC)
+
+	// Strict orthogonality forbids the overlap, regardless of the priority
+	_, err = svc.CreateExperiment(strictSettings, newRequestBody("priority-exp-high", []interface{}{"seg-1", "seg-2"}, 5))
+	s.Suite.Assert().EqualError(err, fmt.Sprintf("Segment Orthogonality check failed against experiment ID %d",
+		exps[0].ID))
+	winner, err := svc.ResolveOverlap(projectId, map[string]string{"string_segmenter": "seg-1"}, models.ExperimentTierDefault, at)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal("priority-exp-low", winner.Name)
+
+	// Priority resolution allows the overlaps
+	high, err := svc.CreateExperiment(
+		prioritySettings,
+		newRequestBody("priority-exp-high", []interface{}{"seg-1", "seg-2"}, 5),
+	)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(int32(5), high.Priority)
+	_, err = svc.CreateExperiment(prioritySettings, newRequestBody("priority-exp-tied", []interface{}{"seg-3"}, 1))
+	s.Suite.Require().NoError(err)
+
+	tests := map[string]struct {
+		segment  map[string]string
+		expected string
+	}{
+		"higher priority wins": {
+			segment:  map[string]string{"string_segmenter": "seg-1"},
+			expected: "priority-exp-high",
+		},
+		"equal priorities | first created wins": {
+			segment:  map[string]string{"string_segmenter": "seg-3"},
+			expected: "priority-exp-low",
+		},
+		"no match": {
+			segment: map[string]string{"string_segmenter": "seg-4"},
+		},
+	}
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			winner, err := svc.ResolveOverlap(projectId, data.segment, models.ExperimentTierDefault, at)
+			assert.NoError(t, err)
+			if data.expected == "" {
+				assert.Nil(t, winner)
+			} else {
+				assert.Equal(t, data.expected, winner.Name)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestPriorityOverlapResolutionLookupFailure() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "priority-lookup-exp", Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}}},
+	})
+	s.Suite.Require().NoError(err)
+	// The orthogonality check cannot be run, rather than failing on a conflict
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(errors.Newf(errors.Internal, "custom segmenters cannot be retrieved"))
+	svc := newPermissiveExperimentServiceWithClock(s.ProjectsDB, segmenterSvc,
+		fixedClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters:                models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+			PriorityOverlapResolution: true,
+		},
+	}
+	updatedBy := "test-user"
+
+	// Only the conflicts are resolved by priority, the failures to run the check are returned
+	_, err = svc.CreateExperiment(settings, services.CreateExperimentRequestBody{
+		Name:      "priority-lookup-exp-2",
+		Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+		StartTime: exps[0].StartTime,
+		EndTime:   exps[0].EndTime,
+		Status:    models.ExperimentStatusActive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Assert().EqualError(err, "custom segmenters cannot be retrieved")
+	s.Suite.Assert().Equal(errors.Internal, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestSweepRecurringExperiments() {
+	startTime := time.Date(2022, 7, 4, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	// The clock is advanced by updating its value, as the service reads it through the pointer
+	clock := fixedClock(startTime.Add(-time.Hour))
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithClock(s.ProjectsDB, segmenterSvc, &clock)
+	projectId, _, err := s.createProject([]models.Experiment{})
+	s.Suite.Require().NoError(err)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	updatedBy := "test-user"
+	interval := int32(30)
+	newRequestBody := func(name string, value string) services.CreateExperimentRequestBody {
+		return services.CreateExperimentRequestBody{
+			Name:       name,
+			Interval:   &interval,
+			Recurrence: &models.RecurrenceSpec{Period: week, Occurrences: 3},
+			Segment:    models.ExperimentSegmentRaw{"string_segmenter": []interface{}{value}},
+			StartTime:  startTime,
+			EndTime:    startTime.Add(24 * time.Hour),
+			Status:     models.ExperimentStatusActive,
+			Tier:       models.ExperimentTierDefault,
+			Type:       models.ExperimentTypeSwitchback,
+			UpdatedBy:  &updatedBy,
+		}
+	}
+	first, err := svc.CreateExperiment(settings, newRequestBody("recurring-exp", "seg-1"))
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(&models.RecurrenceSpec{Period: week, Occurrences: 3, Occurrence: 1}, first.Recurrence)
+	cancelled, err := svc.CreateExperiment(settings, newRequestBody("recurring-exp-cancelled", "seg-2"))
+	s.Suite.Require().NoError(err)
+	s.Suite.Require().NoError(svc.CancelRecurrence(projectId, cancelled.ID.ToApiSchema()))
+
+	sweep := func(now time.Time) services.BulkResult {
+		clock = fixedClock(now)
+		result, err := svc.SweepRecurringExperiments(settings)
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Empty(result.Failed)
+		return result
+	}
+	getOccurrences := func() []*models.Experiment {
+		var exps []*models.Experiment
+		err := s.ProjectsDB.Where("project_id = ?", projectId).Where("name = ?", first.Name).Order("start_time").Find(&exps).Error
+		s.Suite.Require().NoError(err)
+		return exps
+	}
+
+	// Nothing is created while the first occurrence is running
+	s.Suite.Assert().Empty(sweep(startTime.Add(time.Hour)).Succeeded)
+	s.Suite.Assert().Len(getOccurrences(), 1)
+
+	// The second occurrence is created once the first completes, and only once
+	s.Suite.Assert().Equal([]int64{first.ID.ToApiSchema()}, sweep(startTime.Add(25*time.Hour)).Succeeded)
+	s.Suite.Assert().Empty(sweep(startTime.Add(26 * time.Hour)).Succeeded)
+	occurrences := getOccurrences()
+	s.Suite.Require().Len(occurrences, 2)
+	s.Suite.Assert().Nil(occurrences[0].Recurrence)
+	s.Suite.Assert().Equal(models.ExperimentStatusActive, occurrences[1].Status)
+	tu.AssertEqualValues(s.Suite.T(), startTime.Add(week), occurrences[1].StartTime)
+	tu.AssertEqualValues(s.Suite.T(), startTime.Add(week+24*time.Hour), occurrences[1].EndTime)
+	s.Suite.Assert().Equal(&models.RecurrenceSpec{Period: week, Occurrences: 3, Occurrence: 2}, occurrences[1].Recurrence)
+	s.Suite.Assert().Equal(first.Segment, occurrences[1].Segment)
+	s.Suite.Assert().Equal(first.Interval, occurrences[1].Interval)
+	// The next occurrence is run through the custom validation, as an experiment being created
+	s.Suite.Require().NotNil(occurrences[1].LastValidationStatus)
+	s.Suite.Assert().Equal(models.ExperimentValidationStatusPassed, *occurrences[1].LastValidationStatus)
+
+	// The third and last occurrence is created once the second completes
+	s.Suite.Assert().Equal(
+		[]int64{occurrences[1].ID.ToApiSchema()},
+		sweep(startTime.Add(week+25*time.Hour)).Succeeded,
+	)
+	occurrences = getOccurrences()
+	s.Suite.Require().Len(occurrences, 3)
+	tu.AssertEqualValues(s.Suite.T(), startTime.Add(2*week), occurrences[2].StartTime)
+	s.Suite.Assert().Equal(&models.RecurrenceSpec{Period: week, Occurrences: 3, Occurrence: 3}, occurrences[2].Recurrence)
+
+	// Nothing is created after the last occurrence, nor for the cancelled recurrence
+	s.Suite.Assert().Empty(sweep(startTime.Add(2*week + 25*time.Hour)).Succeeded)
+	s.Suite.Assert().Len(getOccurrences(), 3)
+	exps, err := svc.ListAllExperiments(models.ID(projectId), services.ListExperimentsParams{Name: &cancelled.Name})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Len(exps, 1)
+
+	err = svc.CancelRecurrence(projectId, cancelled.ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, fmt.Sprintf(
+		"experiment id %d is not the latest occurrence of a recurring experiment", cancelled.ID))
+}
+
+func (s *ExperimentServiceTestSuite) TestCancelRecurrence() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:       "cancel-recurrence-exp",
+			Segment:    models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			Recurrence: &models.RecurrenceSpec{Period: 7 * 24 * time.Hour, Occurrences: 3, Occurrence: 1},
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	var published []*_pubsub.Experiment
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", "update", mock.Anything).
+		Run(func(args mock.Arguments) { published = append(published, args.Get(1).(*_pubsub.Experiment)) }).
+		Return(nil)
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterTypes", mock.Anything).
+		Return(map[string]schema.SegmenterType{"string_segmenter": schema.SegmenterTypeString}, nil)
+	allServices := &services.Services{
+		ExperimentHistoryService: services.NewExperimentHistoryService(s.ProjectsDB),
+		SegmenterService:         segmenterSvc,
+		PubSubPublisherService:   pubSubSvc,
+	}
+	allServices.ProjectSettingsService = services.NewProjectSettingsService(allServices, s.ProjectsDB)
+	svc := services.NewExperimentService(allServices, s.ProjectsDB)
+	events, unsubscribe := svc.Subscribe()
+	defer unsubscribe()
+
+	// The experiment is saved as a new version, with its history written, and the update is published
+	s.Suite.Require().NoError(svc.CancelRecurrence(projectId, exps[0].ID.ToApiSchema()))
+	dbRecord, err := svc.GetDBRecord(models.ID(projectId), exps[0].ID)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Nil(dbRecord.Recurrence)
+	s.Suite.Assert().Equal(int64(2), dbRecord.Version)
+
+	var histories []*models.ExperimentHistory
+	err = s.ProjectsDB.Where("experiment_id = ?", exps[0].ID).Find(&histories).Error
+	s.Suite.Require().NoError(err)
+	s.Suite.Require().Len(histories, 1)
+	s.Suite.Assert().Equal(int64(1), histories[0].Version)
+
+	s.Suite.Require().Len(published, 1)
+	s.Suite.Assert().Equal(exps[0].Name, published[0].Name)
+	select {
+	case event := <-events:
+		s.Suite.Assert().Equal(services.ExperimentEventOpUpdate, event.Op)
+		s.Suite.Assert().Equal(exps[0].ID, event.Experiment.ID)
+	case <-time.After(time.Second):
+		s.Suite.Fail("expected an experiment event")
+	}
+
+	// The recurrence cannot be cancelled again
+	err = svc.CancelRecurrence(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, fmt.Sprintf(
+		"experiment id %d is not the latest occurrence of a recurring experiment", exps[0].ID))
+	s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestSwitchbackIntervalCompatibility() {
+	switchback := func(name string, interval int32, segment string, status models.ExperimentStatus) models.Experiment {
+		return models.Experiment{
+			Name:     name,
+			Type:     models.ExperimentTypeSwitchback,
+			Interval: &interval,
+			Segment:  models.ExperimentSegment{"string_segmenter": []string{segment}},
+			Status:   status,
+		}
+	}
+	projectId, exps, err := s.createProject([]models.Experiment{
+		switchback("interval-exp-active", 30, "seg-1", models.ExperimentStatusActive),
+		switchback("interval-exp-equal", 30, "seg-1", models.ExperimentStatusInactive),
+		switchback("interval-exp-multiple", 60, "seg-1", models.ExperimentStatusInactive),
+		switchback("interval-exp-divisor", 10, "seg-1", models.ExperimentStatusInactive),
+		switchback("interval-exp-incompatible", 45, "seg-1", models.ExperimentStatusInactive),
+		switchback("interval-exp-other-segment", 45, "seg-2", models.ExperimentStatusInactive),
+		{
+			Name:    "interval-exp-ab",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			Status:  models.ExperimentStatusInactive,
+		},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+
+	// The experiments are enabled in order, each being checked against those enabled before it
+	tests := []struct {
+		experiment *models.Experiment
+		errString  string
+	}{
+		{experiment: exps[1]},
+		{experiment: exps[2]},
+		{experiment: exps[3]},
+		{
+			experiment: exps[4],
+			errString: fmt.Sprintf("switchback interval of 45 minutes is incompatible with the interval of 30 minutes "+
+				"of the overlapping switchback experiment interval-exp-active (id %d); "+
+				"the intervals must be equal or multiples of each other", exps[0].ID),
+		},
+		{experiment: exps[5]},
+		{experiment: exps[6]},
+	}
+	for _, data := range tests {
+		s.Suite.T().Run(data.experiment.Name, func(t *testing.T) {
+			err := svc.EnableExperiment(settings, data.experiment.ID.ToApiSchema())
+			if data.errString == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, data.errString)
+				assert.Equal(t, errors.BadInput, errors.GetType(err))
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestReconcileOrthogonality() {
+	segment := func(value string) models.ExperimentSegment {
+		return models.ExperimentSegment{"string_segmenter": []string{value}}
+	}
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "reconcile-exp-high-priority", Segment: segment("seg-1"), Priority: 1,
+			Model: models.Model{CreatedAt: time.Date(2020, 4, 2, 4, 5, 6, 0, time.UTC)}},
+		{Name: "reconcile-exp-low-priority", Segment: segment("seg-1")},
+		{Name: "reconcile-exp-created-later", Segment: segment("seg-1"),
+			Model: models.Model{CreatedAt: time.Date(2020, 4, 2, 4, 5, 6, 0, time.UTC)}},
+		{Name: "reconcile-exp-orthogonal", Segment: segment("seg-2")},
+		{Name: "reconcile-exp-override", Segment: segment("seg-1"), Tier: models.ExperimentTierOverride},
+		{Name: "reconcile-exp-inactive", Segment: segment("seg-1"), Status: models.ExperimentStatusInactive},
+	})
+	s.Suite.Require().NoError(err)
+
+	// Only the segments seg-1 conflict with each other
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything, mock.Anything).
+		Return(func(_ int64, _ []string, segment models.ExperimentSegmentRaw, exps []models.Experiment) error {
+			rawSegment := models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}}
+			if assert.ObjectsAreEqual(rawSegment, segment) && exps[0].Segment["string_segmenter"][0] == "seg-1" {
+				return fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID)
+			}
+			return nil
+		})
+	svc := newPermissiveExperimentServiceWithClock(s.ProjectsDB, segmenterSvc,
+		fixedClock(time.Date(2020, 2, 2, 0, 0, 0, 0, time.UTC)))
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	conflict := func(exp *models.Experiment, conflictingExp *models.Experiment) services.OrthogonalityConflict {
+		return services.OrthogonalityConflict{
+			ExperimentID:            exp.ID.ToApiSchema(),
+			ConflictingExperimentID: conflictingExp.ID.ToApiSchema(),
+			Reason: fmt.Sprintf("Segment Orthogonality check failed against experiment ID %d",
+				conflictingExp.ID),
+		}
+	}
+	expectedConflicts := []services.OrthogonalityConflict{
+		conflict(exps[0], exps[1]),
+		conflict(exps[0], exps[2]),
+		conflict(exps[1], exps[2]),
+	}
+	assertStatuses := func(statuses ...models.ExperimentStatus) {
+		for i, status := range statuses {
+			exp, err := svc.GetExperiment(projectId, exps[i].ID.ToApiSchema())
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().Equal(status, exp.Status, exp.Name)
+		}
+	}
+	active, inactive := models.ExperimentStatusActive, models.ExperimentStatusInactive
+
+	// Report only
+	report, err := svc.ReconcileOrthogonality(settings, false)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.ReconcileReport{
+		Conflicts: expectedConflicts,
+		Disabled:  services.BulkResult{Succeeded: []int64{}, Failed: map[int64]string{}},
+	}, report)
+	assertStatuses(active, active, active, active, active, inactive)
+
+	// Auto disable the experiments that do not take precedence
+	report, err = svc.ReconcileOrthogonality(settings, true)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.ReconcileReport{
+		Conflicts: expectedConflicts,
+		Disabled: services.BulkResult{
+			Succeeded: []int64{exps[1].ID.ToApiSchema(), exps[2].ID.ToApiSchema()},
+			Failed:    map[int64]string{},
+		},
+	}, report)
+	assertStatuses(active, inactive, inactive, active, active, inactive)
+
+	// No conflicts are left
+	report, err = svc.ReconcileOrthogonality(settings, true)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Empty(report.Conflicts)
+	s.Suite.Assert().Empty(report.Disabled.Succeeded)
+}
+
+func (s *ExperimentServiceTestSuite) TestShadowExperimentOrthogonality() {
+	segment := models.ExperimentSegment{"string_segmenter": []string{"seg-1"}}
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "shadow-exp-active", Segment: segment},
+		{Name: "shadow-exp-shadow", Segment: segment, Shadow: true, Status: models.ExperimentStatusInactive},
+		{Name: "shadow-exp-inactive", Segment: segment, Status: models.ExperimentStatusInactive},
+	})
+	s.Suite.Require().NoError(err)
+
+	// All the experiments share the same segment, so any experiment checked against conflicts with it
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything, mock.Anything).
+		Return(func(_ int64, _ []string, _ models.ExperimentSegmentRaw, exps []models.Experiment) error {
+			if len(exps) > 0 {
+				return fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID)
+			}
+			return nil
+		})
+	var publishCount int32
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", "update", mock.Anything).
+		Run(func(args mock.Arguments) { atomic.AddInt32(&publishCount, 1) }).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithMocks(s.ProjectsDB, segmenterSvc, pubSubSvc)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+
+	// The shadow experiment is enabled and published, despite the active experiment of the same segment
+	s.Suite.Require().NoError(svc.EnableExperiment(settings, exps[1].ID.ToApiSchema()))
+	s.Suite.Assert().Equal(int32(1), atomic.LoadInt32(&publishCount))
+
+	// The active shadow experiment does not conflict with the other experiments enabled
+	s.Suite.Require().NoError(svc.DisableExperiment(projectId, exps[0].ID.ToApiSchema()))
+	s.Suite.Require().NoError(svc.EnableExperiment(settings, exps[2].ID.ToApiSchema()))
+
+	// The other experiments still conflict with each other
+	err = svc.EnableExperiment(settings, exps[0].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, fmt.Sprintf(
+		"Segment Orthogonality check failed against experiment ID %d", exps[2].ID))
+
+	// Nor is the shadow experiment reported in the conflicts
+	report, err := svc.ReconcileOrthogonality(settings, false)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Empty(report.Conflicts)
+}
+
+func (s *ExperimentServiceTestSuite) TestSubscribeExperimentEvents() {
+	projectId := s.newProjectId()
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	updatedBy := "test-user"
+	startTime := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2100, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	events, unsubscribe := svc.Subscribe()
+	assertEvent := func(op services.ExperimentEventOp, name string, status models.ExperimentStatus) {
+		select {
+		case event := <-events:
+			s.Suite.Assert().Equal(op, event.Op)
+			s.Suite.Assert().Equal(name, event.Experiment.Name)
+			s.Suite.Assert().Equal(status, event.Experiment.Status)
+		case <-time.After(time.Second):
+			s.Suite.Fail("expected an experiment event", op)
+		}
+	}
+
+	exp, err := svc.CreateExperiment(settings, services.CreateExperimentRequestBody{
+		Name:      "subscribe-exp",
+		Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+		StartTime: startTime,
+		EndTime:   endTime,
+		Status:    models.ExperimentStatusInactive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Require().NoError(err)
+	assertEvent(services.ExperimentEventOpCreate, "subscribe-exp", models.ExperimentStatusInactive)
+
+	_, err = svc.UpdateExperiment(settings, exp.ID.ToApiSchema(), services.UpdateExperimentRequestBody{
+		Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1", "seg-2"}},
+		StartTime: startTime,
+		EndTime:   endTime,
+		Status:    models.ExperimentStatusInactive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Require().NoError(err)
+	assertEvent(services.ExperimentEventOpUpdate, "subscribe-exp", models.ExperimentStatusInactive)
+
+	s.Suite.Require().NoError(svc.EnableExperiment(settings, exp.ID.ToApiSchema()))
+	assertEvent(services.ExperimentEventOpEnable, "subscribe-exp", models.ExperimentStatusActive)
+
+	s.Suite.Require().NoError(svc.DisableExperiment(projectId, exp.ID.ToApiSchema()))
+	assertEvent(services.ExperimentEventOpDisable, "subscribe-exp", models.ExperimentStatusInactive)
+
+	// Failed changes do not emit any event
+	s.Suite.Require().Error(svc.DisableExperiment(projectId, exp.ID.ToApiSchema()))
+	s.Suite.Assert().Len(events, 0)
+
+	// No more events are received after unsubscribing
+	unsubscribe()
+	s.Suite.Require().NoError(svc.EnableExperiment(settings, exp.ID.ToApiSchema()))
+	_, ok := <-events
+	s.Suite.Assert().False(ok)
+}
+
+func (s *ExperimentServiceTestSuite) TestOverrideTierSegmentRequired() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "override-segment-exp-unconstrained", Status: models.ExperimentStatusInactive},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	updatedBy := "test-user"
+	newRequestBody := func(
+		segment models.ExperimentSegmentRaw,
+		tier models.ExperimentTier,
+	) services.CreateExperimentRequestBody {
+		return services.CreateExperimentRequestBody{
+			Name:      fmt.Sprintf("override-segment-exp-%s-%d", tier, len(segment)),
+			Segment:   segment,
+			StartTime: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+			Status:    models.ExperimentStatusInactive,
+			Tier:      tier,
+			Type:      models.ExperimentTypeAB,
+			UpdatedBy: &updatedBy,
+		}
+	}
+
+	tests := map[string]struct {
+		segment   models.ExperimentSegmentRaw
+		tier      models.ExperimentTier
+		errString string
+	}{
+		"override with empty segment": {
+			segment:   models.ExperimentSegmentRaw{},
+			tier:      models.ExperimentTierOverride,
+			errString: "override tier experiment override-segment-exp-override-0 must constrain at least one segmenter",
+		},
+		"override with empty segmenter values": {
+			segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{}},
+			tier:      models.ExperimentTierOverride,
+			errString: "override tier experiment override-segment-exp-override-1 must constrain at least one segmenter",
+		},
+		"override with constrained segment": {
+			segment: models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+			tier:    models.ExperimentTierOverride,
+		},
+		"default with empty segment": {
+			segment: models.ExperimentSegmentRaw{},
+			tier:    models.ExperimentTierDefault,
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			_, err := svc.CreateExperiment(settings, newRequestBody(data.segment, data.tier))
+			if data.errString == "" {
+				s.Suite.Assert().NoError(err)
+			} else {
+				s.Suite.Assert().EqualError(err, data.errString)
+				s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+			}
+		})
+	}
+
+	// Existing unconstrained experiments cannot be moved to the override tier either
+	_, err = svc.UpdateExperiment(settings, exps[0].ID.ToApiSchema(), services.UpdateExperimentRequestBody{
+		Segment:   models.ExperimentSegmentRaw{},
+		StartTime: exps[0].StartTime,
+		EndTime:   exps[0].EndTime,
+		Status:    models.ExperimentStatusInactive,
+		Tier:      models.ExperimentTierOverride,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Assert().EqualError(err,
+		"override tier experiment override-segment-exp-unconstrained must constrain at least one segmenter")
+
+	result, err := svc.BulkUpdateTier(settings, []int64{exps[0].ID.ToApiSchema()}, models.ExperimentTierOverride)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal([]int64{}, result.Succeeded)
+	s.Suite.Assert().Equal(map[int64]string{
+		exps[0].ID.ToApiSchema(): "override tier experiment override-segment-exp-unconstrained must constrain " +
+			"at least one segmenter",
+	}, result.Failed)
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsTypedSegmentStorage() {
+	projectId, _, err := s.createProject([]models.Experiment{
+		// Saved before the typed segments were stored
+		{
+			Name:    "typed-exp-legacy",
+			Segment: models.ExperimentSegment{"days_of_week": []string{"5"}, "flag_segmenter": []string{"true"}},
+		},
+		// The typed segment holds the value 5 as a string
+		{
+			Name:         "typed-exp-string-value",
+			Segment:      models.ExperimentSegment{"days_of_week": []string{"5"}},
+			TypedSegment: models.ExperimentSegmentRaw{"days_of_week": []interface{}{"5"}},
+		},
+	})
+	s.Suite.Require().NoError(err)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{
+				Names: []string{"days_of_week", "flag_segmenter"},
+			},
+			TypedSegmentStorage: true,
+		},
+	}
+	err = s.ProjectsDB.Model(&models.Settings{}).Where("project_id = ?", projectId).Update("config", settings.Config).Error
+	s.Suite.Require().NoError(err)
+
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterTypes", projectId).Return(map[string]schema.SegmenterType{
+		"days_of_week":   schema.SegmenterTypeInteger,
+		"flag_segmenter": schema.SegmenterTypeBool,
+	}, nil)
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", "create", mock.Anything).Return(nil)
+	svc := newPermissiveExperimentServiceWithMocks(s.ProjectsDB, segmenterSvc, pubSubSvc)
+
+	updatedBy := "test-user"
+	for name, segment := range map[string]models.ExperimentSegmentRaw{
+		"typed-exp-5-true": {"days_of_week": []interface{}{float64(5)}, "flag_segmenter": []interface{}{true}},
+		"typed-exp-6-false": {
+			"days_of_week":   []interface{}{float64(6)},
+			"flag_segmenter": []interface{}{false},
+		},
+		"typed-exp-unset": {"days_of_week": []interface{}{}},
+	} {
+		exp, err := svc.CreateExperiment(settings, services.CreateExperimentRequestBody{
+			Name:      name,
+			Segment:   segment,
+			StartTime: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+			Status:    models.ExperimentStatusInactive,
+			Tier:      models.ExperimentTierDefault,
+			Type:      models.ExperimentTypeAB,
+			UpdatedBy: &updatedBy,
+		})
+		s.Suite.Require().NoError(err)
+		// The segment is stored with typed values, less the unset segmenters
+		var typedSegment string
+		err = s.ProjectsDB.Raw("SELECT typed_segment FROM experiments WHERE id = ?", exp.ID).Scan(&typedSegment).Error
+		s.Suite.Require().NoError(err)
+		expectedTypedSegment, _ := json.Marshal(models.ExperimentSegmentRaw{})
+		if name != "typed-exp-unset" {
+			expectedTypedSegment, _ = json.Marshal(segment)
+		}
+		s.Suite.Assert().JSONEq(string(expectedTypedSegment), typedSegment)
+	}
+
+	tests := map[string]struct {
+		params   services.ListExperimentsParams
+		expected []string
+	}{
+		"integer value": {
+			params: services.ListExperimentsParams{
+				Segment: models.ExperimentSegment{"days_of_week": []string{"5"}},
+			},
+			// The string value "5" in the typed segment does not match the integer value
+			expected: []string{"typed-exp-5-true", "typed-exp-legacy"},
+		},
+		"boolean value": {
+			params: services.ListExperimentsParams{
+				Segment: models.ExperimentSegment{"flag_segmenter": []string{"false"}},
+			},
+			expected: []string{"typed-exp-6-false"},
+		},
+		"weak match": {
+			params: services.ListExperimentsParams{
+				Segment:          models.ExperimentSegment{"days_of_week": []string{"6"}},
+				IncludeWeakMatch: true,
+			},
+			expected: []string{"typed-exp-unset", "typed-exp-6-false"},
+		},
+		"exclude segment": {
+			params: services.ListExperimentsParams{
+				ExcludeSegment: models.ExperimentSegment{"flag_segmenter": []string{"true"}},
+			},
+			expected: []string{"typed-exp-unset", "typed-exp-6-false", "typed-exp-string-value"},
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.Run(name, func() {
+			exps, _, err := svc.ListExperiments(projectId, data.params)
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().ElementsMatch(data.expected, getExperimentNames(exps))
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestListExperimentsValidationStatusFilter() {
+	passed := models.ExperimentValidationStatusPassed
+	failed := models.ExperimentValidationStatusFailed
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "validation-exp-passed", LastValidationStatus: &passed},
+		{Name: "validation-exp-failed", LastValidationStatus: &failed},
+		{Name: "validation-exp-unvalidated"},
+	})
+	s.Suite.Require().NoError(err)
+
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	svc.RegisterValidator("always-fail", func(
+		experiment models.Experiment,
+		settings models.Settings,
+		context services.ValidationContext,
+		operationType services.OperationType,
+	) error {
+		return fmt.Errorf("experiment %s failed validation", experiment.Name)
+	})
+
+	listByValidationStatus := func(validationStatus string) ([]string, error) {
+		list, _, err := svc.ListExperiments(projectId, services.ListExperimentsParams{ValidationStatus: &validationStatus})
+		return getExperimentNames(list), err
+	}
+
+	names, err := listByValidationStatus("failed")
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal([]string{"validation-exp-failed"}, names)
+	names, err = listByValidationStatus("passed")
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal([]string{"validation-exp-passed"}, names)
+	_, err = listByValidationStatus("unknown")
+	s.Suite.Assert().EqualError(err, "unknown validation status: unknown")
+
+	// Failing to update the experiment leaves the validation status of the stored version unchanged
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters:        models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+			EnabledValidators: []string{"always-fail"},
+		},
+	}
+	updatedBy := "test-user"
+	_, err = svc.UpdateExperiment(settings, exps[0].ID.ToApiSchema(), services.UpdateExperimentRequestBody{
+		Segment:   models.ExperimentSegmentRaw{},
+		StartTime: exps[0].StartTime,
+		EndTime:   exps[0].EndTime,
+		Status:    models.ExperimentStatusInactive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Assert().EqualError(err, "experiment validation-exp-passed failed validation")
+
+	names, err = listByValidationStatus("failed")
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal([]string{"validation-exp-failed"}, names)
+	// The experiment is otherwise unchanged
+	exp, err := svc.GetExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(int64(1), exp.Version)
+	s.Suite.Assert().Equal(models.ExperimentStatusActive, exp.Status)
+
+	// Validating the stored version records the failed validation
+	err = svc.RunCustomValidation(*exps[0], settings, services.ValidationContext{}, services.OperationTypeUpdate)
+	s.Suite.Assert().EqualError(err, "experiment validation-exp-passed failed validation")
+
+	names, err = listByValidationStatus("failed")
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().ElementsMatch([]string{"validation-exp-passed", "validation-exp-failed"}, names)
+}
+
+func (s *ExperimentServiceTestSuite) TestLockExperiments() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "locked-exp-active"},
+		{Name: "locked-exp-inactive", Status: models.ExperimentStatusInactive},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	updatedBy := "test-user"
+	expData := services.UpdateExperimentRequestBody{
+		Segment:   models.ExperimentSegmentRaw{},
+		StartTime: exps[1].StartTime,
+		EndTime:   exps[1].EndTime,
+		Status:    models.ExperimentStatusInactive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	}
+
+	s.Suite.Require().NoError(svc.LockExperiments(projectId))
+
+	// Only the lock is changed in the stored config
+	settingsSvc := services.NewProjectSettingsService(&services.Services{}, s.ProjectsDB)
+	storedSettings, err := settingsSvc.GetDBRecord(models.ID(projectId))
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().True(storedSettings.Config.ExperimentsLocked)
+	s.Suite.Assert().Equal([]string{"string_segmenter"}, storedSettings.Config.Segmenters.Names)
+
+	// Writes are blocked
+	_, err = svc.CreateExperiment(settings, services.CreateExperimentRequestBody{
+		Name:      "locked-exp-new",
+		Segment:   expData.Segment,
+		StartTime: expData.StartTime,
+		EndTime:   expData.EndTime,
+		Status:    expData.Status,
+		Tier:      expData.Tier,
+		Type:      expData.Type,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Assert().EqualError(err, "project is locked")
+	s.Suite.Assert().Equal(errors.Conflict, errors.GetType(err))
+	_, err = svc.UpdateExperiment(settings, exps[1].ID.ToApiSchema(), expData)
+	s.Suite.Assert().EqualError(err, "project is locked")
+	err = svc.EnableExperiment(settings, exps[1].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, "project is locked")
+	err = svc.DisableExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, "project is locked")
+	s.Suite.Assert().Equal(errors.Conflict, errors.GetType(err))
+
+	// Reads are allowed
+	exp, err := svc.GetExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(models.ExperimentStatusActive, exp.Status)
+	list, _, err := svc.ListExperiments(projectId, services.ListExperimentsParams{})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Len(list, 2)
+
+	// Writes are allowed again after unlocking
+	s.Suite.Require().NoError(svc.UnlockExperiments(projectId))
+	s.Suite.Require().NoError(svc.DisableExperiment(projectId, exps[0].ID.ToApiSchema()))
+	storedSettings, err = settingsSvc.GetDBRecord(models.ID(projectId))
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().False(storedSettings.Config.ExperimentsLocked)
+
+	err = svc.LockExperiments(s.newProjectId())
+	s.Suite.Assert().Equal(errors.NotFound, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestPauseAndResumeExperiments() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:    "pause-exp-default",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		},
+		{
+			Name:    "pause-exp-override",
+			Tier:    models.ExperimentTierOverride,
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		},
+		{
+			Name:    "pause-exp-orthogonal",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+		},
+		{
+			Name:    "pause-exp-inactive",
+			Status:  models.ExperimentStatusInactive,
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	// Only the segment seg-1 conflicts with the other active experiments
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality",
+		projectId, mock.Anything, models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}}, mock.Anything,
+	).Return(fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exps[3].ID))
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	assertStatuses := func(statuses ...models.ExperimentStatus) {
+		for i, status := range statuses {
+			exp, err := svc.GetExperiment(projectId, exps[i].ID.ToApiSchema())
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().Equal(status, exp.Status, exp.Name)
+		}
+	}
+	expectedToken := services.PauseToken{
+		ProjectID: projectId,
+		ExperimentIds: []int64{
+			exps[0].ID.ToApiSchema(),
+			exps[2].ID.ToApiSchema(),
+			exps[1].ID.ToApiSchema(),
+		},
+	}
+
+	// Round trip that restores exactly the active experiments
+	token, err := svc.PauseAllExperiments(projectId)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(expectedToken, token)
+	assertStatuses(models.ExperimentStatusInactive, models.ExperimentStatusInactive,
+		models.ExperimentStatusInactive, models.ExperimentStatusInactive)
+
+	result, err := svc.ResumeExperiments(settings, token)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.BulkResult{Succeeded: expectedToken.ExperimentIds, Failed: map[int64]string{}}, result)
+	assertStatuses(models.ExperimentStatusActive, models.ExperimentStatusActive,
+		models.ExperimentStatusActive, models.ExperimentStatusInactive)
+
+	// Round trip where an experiment enabled while paused fails the re-validation
+	token, err = svc.PauseAllExperiments(projectId)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(expectedToken, token)
+	s.Suite.Require().NoError(svc.EnableExperiment(settings, exps[3].ID.ToApiSchema()))
+
+	result, err = svc.ResumeExperiments(settings, token)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.BulkResult{
+		Succeeded: []int64{exps[2].ID.ToApiSchema(), exps[1].ID.ToApiSchema()},
+		Failed: map[int64]string{
+			exps[0].ID.ToApiSchema(): fmt.Sprintf("Segment Orthogonality check failed against experiment ID %d",
+				exps[3].ID),
+		},
+	}, result)
+	assertStatuses(models.ExperimentStatusInactive, models.ExperimentStatusActive,
+		models.ExperimentStatusActive, models.ExperimentStatusActive)
+
+	// The token cannot resume the experiments of another project
+	otherSettings := settings
+	otherProjectId := s.newProjectId()
+	otherSettings.ProjectID = models.ID(otherProjectId)
+	_, err = svc.ResumeExperiments(otherSettings, token)
+	s.Suite.Assert().EqualError(err, fmt.Sprintf(
+		"the pause token of project %d cannot resume the experiments of project %d", projectId, otherProjectId))
+}
+
+func (s *ExperimentServiceTestSuite) TestOrphanedOverridesOnDisable() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:    "orphan-exp-default-1",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		},
+		{
+			Name:    "orphan-exp-override-1",
+			Tier:    models.ExperimentTierOverride,
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		},
+		{
+			Name:    "orphan-exp-default-2",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+		},
+		{
+			Name:    "orphan-exp-default-3",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-2", "seg-3"}},
+		},
+		{
+			Name:    "orphan-exp-override-2",
+			Tier:    models.ExperimentTierOverride,
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+		},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	setBlockOrphanedOverrides := func(block bool) {
+		err := s.ProjectsDB.Model(&models.Settings{}).Where("project_id = ?", projectId).Update("config", models.ExperimentationConfig{
+			Segmenters:             models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+			BlockOrphanedOverrides: block,
+		}).Error
+		s.Suite.Require().NoError(err)
+	}
+
+	setBlockOrphanedOverrides(true)
+	// The override on seg-1 would be orphaned
+	err = svc.DisableExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, fmt.Sprintf(
+		"disabling experiment orphan-exp-default-1 (id %d) leaves the active override experiments "+
+			"orphan-exp-override-1 (id %d) without an underlying default experiment", exps[0].ID, exps[1].ID))
+	// The override on seg-2 is still covered by another default experiment
+	s.Suite.Require().NoError(svc.DisableExperiment(projectId, exps[2].ID.ToApiSchema()))
+
+	// Orphaned overrides are allowed when they are not blocked
+	setBlockOrphanedOverrides(false)
+	s.Suite.Require().NoError(svc.DisableExperiment(projectId, exps[0].ID.ToApiSchema()))
+}
+
+func (s *ExperimentServiceTestSuite) TestPreviewProtoMessage() {
+	projectId, _, err := s.createProject([]models.Experiment{})
+	s.Suite.Require().NoError(err)
+
+	// Capture the message published on creating the experiment
+	var published *_pubsub.Experiment
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", "create", mock.Anything).
+		Run(func(args mock.Arguments) {
+			published = args.Get(1).(*_pubsub.Experiment)
+		}).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithMocks(s.ProjectsDB, &mocks.SegmenterService{}, pubSubSvc)
+
+	updatedBy := "test-user"
+	exp, err := svc.CreateExperiment(models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}, services.CreateExperimentRequestBody{
+		Name:      "preview-exp",
+		Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1", "seg-2"}},
+		StartTime: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+		Status:    models.ExperimentStatusInactive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		Treatments: models.ExperimentTreatments{
+			{Name: "control", Configuration: map[string]interface{}{"key": "value"}},
+		},
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Require().NotNil(published)
+
+	payload, err := svc.PreviewProtoMessage(projectId, exp.ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	var preview _pubsub.Experiment
+	s.Suite.Require().NoError(proto.Unmarshal(payload, &preview))
+	s.Suite.Assert().True(proto.Equal(published, &preview))
+
+	_, err = svc.PreviewProtoMessage(projectId, 999)
+	s.Suite.Assert().Equal(errors.NotFound, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestPreviewAssignmentDistribution() {
+	var traffic20, traffic30, traffic50 int32 = 20, 30, 50
+	interval := int32(420)
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name: "distribution-exp-even",
+			Treatments: models.ExperimentTreatments{
+				{Name: "control", Traffic: &traffic50},
+				{Name: "treatment", Traffic: &traffic50},
+			},
+		},
+		{
+			Name: "distribution-exp-uneven",
+			Treatments: models.ExperimentTreatments{
+				{Name: "control", Traffic: &traffic20},
+				{Name: "treatment-1", Traffic: &traffic30},
+				{Name: "treatment-2", Traffic: &traffic50},
+			},
+		},
+		{
+			Name:     "distribution-exp-cyclic-switchback",
+			Type:     models.ExperimentTypeSwitchback,
+			Interval: &interval,
+			Treatments: models.ExperimentTreatments{
+				{Name: "control"},
+				{Name: "treatment-1"},
+				{Name: "treatment-2"},
+			},
+		},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+
+	// Even A/B split
+	distribution, err := svc.PreviewAssignmentDistribution(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(map[string]float64{"control": 50, "treatment": 50}, distribution)
+
+	// Uneven three-way split
+	distribution, err = svc.PreviewAssignmentDistribution(projectId, exps[1].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(map[string]float64{"control": 20, "treatment-1": 30, "treatment-2": 50}, distribution)
+
+	// The 24 hours of the switchback experiment are 3 complete intervals of 7 hours, one for each treatment, after
+	// which the first treatment is in effect for the remaining 3 hours
+	distribution, err = svc.PreviewAssignmentDistribution(projectId, exps[2].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().InDelta(100*10/24.0, distribution["control"], 1e-9)
+	s.Suite.Assert().InDelta(100*7/24.0, distribution["treatment-1"], 1e-9)
+	s.Suite.Assert().InDelta(100*7/24.0, distribution["treatment-2"], 1e-9)
+
+	_, err = svc.PreviewAssignmentDistribution(projectId, 999)
+	s.Suite.Assert().Equal(errors.NotFound, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestGetRawStoredSegment() {
+	projectId, _, err := s.createProject([]models.Experiment{})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+
+	updatedBy := "test-user"
+	exp, err := svc.CreateExperiment(models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}, services.CreateExperimentRequestBody{
+		Name:      "raw-segment-exp",
+		Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-2", "seg-1"}},
+		StartTime: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+		Status:    models.ExperimentStatusInactive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Require().NoError(err)
+
+	// The segment is returned as stored, in the storage schema with the values sorted, as formatted by the DB
+	segment, err := svc.GetRawStoredSegment(projectId, exp.ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(`{"string_segmenter": ["seg-1", "seg-2"]}`, string(segment))
+	var stored []byte
+	err = s.ProjectsDB.Raw("SELECT segment FROM experiments WHERE id = ?", exp.ID).Row().Scan(&stored)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(stored, []byte(segment))
+
+	_, err = svc.GetRawStoredSegment(projectId, 999)
+	s.Suite.Assert().Equal(errors.NotFound, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestRepublishExperiments() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "republish-exp-active"},
+		{Name: "republish-exp-inactive", Status: models.ExperimentStatusInactive},
+	})
+	s.Suite.Require().NoError(err)
+
+	var published []string
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", "update", mock.Anything).
+		Run(func(args mock.Arguments) {
+			published = append(published, args.Get(1).(*_pubsub.Experiment).Name)
+		}).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithMocks(s.ProjectsDB, &mocks.SegmenterService{}, pubSubSvc)
+
+	s.Suite.Require().NoError(svc.RepublishExperiment(projectId, exps[1].ID.ToApiSchema()))
+	s.Suite.Assert().Equal([]string{"republish-exp-inactive"}, published)
+
+	published = nil
+	s.Suite.Require().NoError(svc.RepublishAllExperiments(projectId))
+	s.Suite.Assert().Equal([]string{"republish-exp-active", "republish-exp-inactive"}, published)
+
+	// The experiments are not modified
+	for _, exp := range exps {
+		dbRecord, err := svc.GetExperiment(projectId, exp.ID.ToApiSchema())
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Equal(int64(1), dbRecord.Version)
+		s.Suite.Assert().True(exp.UpdatedAt.Equal(dbRecord.UpdatedAt))
+	}
+
+	err = svc.RepublishExperiment(projectId, 999)
+	s.Suite.Assert().Equal(errors.NotFound, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestSwapExperimentSchedules() {
+	seg1 := models.ExperimentSegment{"string_segmenter": []string{"seg-1"}}
+	seg2 := models.ExperimentSegment{"string_segmenter": []string{"seg-2"}}
+	day := func(d int) time.Time { return time.Date(2020, 3, d, 0, 0, 0, 0, time.UTC) }
+	projectId, exps, err := s.createProject([]models.Experiment{
+		// Clean swap: swap-exp-a moves next to the orthogonal swap-exp-c, in the window vacated by swap-exp-b
+		{Name: "swap-exp-a", Segment: seg1, StartTime: day(1), EndTime: day(2)},
+		{Name: "swap-exp-b", Segment: seg1, StartTime: day(3), EndTime: day(4)},
+		{Name: "swap-exp-c", Segment: seg2, StartTime: day(3), EndTime: day(4)},
+		// Conflicting swap: swap-exp-e moves next to swap-exp-f, with the same segment
+		{Name: "swap-exp-d", Segment: seg2, StartTime: day(10), EndTime: day(11)},
+		{Name: "swap-exp-e", Segment: seg1, StartTime: day(12), EndTime: day(13)},
+		{Name: "swap-exp-f", Segment: seg1, StartTime: day(10), EndTime: day(11)},
+	})
+	s.Suite.Require().NoError(err)
+
+	// The segments seg-1 overlap with each other
+	hasSeg1 := func(others []models.Experiment) bool {
+		for _, exp := range others {
+			if assert.ObjectsAreEqual(seg1, exp.Segment) {
+				return true
+			}
+		}
+		return false
+	}
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything,
+		models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}}, mock.MatchedBy(hasSeg1),
+	).Return(fmt.Errorf("Segment Orthogonality check failed"))
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	var published []string
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", "update", mock.Anything).
+		Run(func(args mock.Arguments) {
+			published = append(published, args.Get(1).(*_pubsub.Experiment).Name)
+		}).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithMocks(s.ProjectsDB, segmenterSvc, pubSubSvc)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+
+	expA, expB, err := svc.SwapExperimentSchedules(settings, exps[0].ID.ToApiSchema(), exps[1].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal([]string{"swap-exp-a", "swap-exp-b"}, published)
+	for _, data := range []struct {
+		experiment *models.Experiment
+		name       string
+		startTime  time.Time
+		endTime    time.Time
+	}{
+		{experiment: expA, name: "swap-exp-a", startTime: day(3), endTime: day(4)},
+		{experiment: expB, name: "swap-exp-b", startTime: day(1), endTime: day(2)},
+	} {
+		s.Suite.Assert().Equal(data.name, data.experiment.Name)
+		s.Suite.Assert().True(data.startTime.Equal(data.experiment.StartTime))
+		s.Suite.Assert().True(data.endTime.Equal(data.experiment.EndTime))
+		s.Suite.Assert().Equal(int64(2), data.experiment.Version)
+	}
+
+	// Neither experiment is updated if the swap creates a conflict
+	published = nil
+	_, _, err = svc.SwapExperimentSchedules(settings, exps[3].ID.ToApiSchema(), exps[4].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, "Segment Orthogonality check failed")
+	s.Suite.Assert().Empty(published)
+	for _, exp := range exps[3:5] {
+		dbRecord, err := svc.GetDBRecord(models.ID(projectId), exp.ID)
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Equal(int64(1), dbRecord.Version)
+		s.Suite.Assert().True(exp.StartTime.Equal(dbRecord.StartTime))
+	}
+
+	_, _, err = svc.SwapExperimentSchedules(settings, exps[0].ID.ToApiSchema(), exps[0].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, fmt.Sprintf("cannot swap the schedule of experiment %d with itself", exps[0].ID))
+}
+func (s *ExperimentServiceTestSuite) TestSwapExperimentSchedulesMutexGroup() {
+	mutexGroup := "swap-mutex-group"
+	day := func(d int) time.Time { return time.Date(2020, 3, d, 0, 0, 0, 0, time.UTC) }
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "swap-mutex-exp-1", MutexGroup: &mutexGroup, StartTime: day(20), EndTime: day(21)},
+		{Name: "swap-mutex-exp-2", StartTime: day(22), EndTime: day(23)},
+		{Name: "swap-mutex-exp-3", MutexGroup: &mutexGroup, StartTime: day(22), EndTime: day(23)},
+	})
+	s.Suite.Require().NoError(err)
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+
+	// The swap moves swap-mutex-exp-1 into the window of swap-mutex-exp-3, in the same mutex group
+	_, _, err = svc.SwapExperimentSchedules(settings, exps[0].ID.ToApiSchema(), exps[1].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, fmt.Sprintf(
+		"experiment swap-mutex-exp-3 (id %d) in the same mutex group %s is already active in the given time range",
+		exps[2].ID, mutexGroup))
+	for _, exp := range exps[:2] {
+		dbRecord, err := svc.GetDBRecord(models.ID(projectId), exp.ID)
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Equal(int64(1), dbRecord.Version)
+		s.Suite.Assert().True(exp.StartTime.Equal(dbRecord.StartTime))
+	}
+
+	// The experiments of the same mutex group can trade their windows, as they are checked in their new windows
+	_, _, err = svc.SwapExperimentSchedules(settings, exps[0].ID.ToApiSchema(), exps[2].ID.ToApiSchema())
+	s.Suite.Assert().NoError(err)
+}
+
+func (s *ExperimentServiceTestSuite) TestTrimExperimentName() {
+	projectId := s.newProjectId()
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	updatedBy := "test-user"
+
+	exp, err := svc.CreateExperiment(settings, services.CreateExperimentRequestBody{
+		Name:      "  trimmed-exp \t",
+		Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+		StartTime: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+		Status:    models.ExperimentStatusInactive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal("trimmed-exp", exp.Name)
+}
+
+func (s *ExperimentServiceTestSuite) TestUpdateExperimentWithRemovedSegmenter() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "removed-segmenter-exp-active"},
+		{Name: "removed-segmenter-exp-inactive", Status: models.ExperimentStatusInactive},
+	})
+	s.Suite.Require().NoError(err)
+
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, &mocks.SegmenterService{})
+	// string_segmenter has been removed from the project settings
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"integer_segmenter"}},
+		},
+	}
+	updatedBy := "test-user"
+
+	for _, exp := range exps {
+		_, err := svc.UpdateExperiment(settings, exp.ID.ToApiSchema(), services.UpdateExperimentRequestBody{
+			Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+			StartTime: exp.StartTime,
+			EndTime:   exp.EndTime,
+			Status:    exp.Status,
+			Tier:      exp.Tier,
+			Type:      exp.Type,
+			UpdatedBy: &updatedBy,
+		})
+		s.Suite.Require().Error(err)
+		s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+		s.Suite.Assert().EqualError(err, fmt.Sprintf("experiment %s requires segmenter: string_segmenter", exp.Name))
+	}
+
+	// The experiments are not modified
+	for _, exp := range exps {
+		dbRecord, err := svc.GetExperiment(projectId, exp.ID.ToApiSchema())
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Equal(int64(1), dbRecord.Version)
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestPreviewUpdate() {
+	traffic := int32(50)
+	treatments := models.ExperimentTreatments{
+		{Name: "control", Configuration: map[string]interface{}{"weight": 0.1}, Traffic: &traffic},
+		{Name: "treatment", Configuration: map[string]interface{}{"weight": 0.2}, Traffic: &traffic},
+	}
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:       "preview-exp",
+			Segment:    models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			Treatments: treatments,
+			UpdatedBy:  "test-user",
+		},
+		{
+			Name:    "preview-exp-other",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+		},
+	})
+	s.Suite.Require().NoError(err)
+	settings, err := services.NewProjectSettingsService(&services.Services{}, s.ProjectsDB).GetDBRecord(models.ID(projectId))
+	s.Suite.Require().NoError(err)
+
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything,
+		models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-2"}}, mock.Anything).
+		Return(fmt.Errorf("segment conflicts with preview-exp-other"))
+	segmenterSvc.On("ValidateSegmentOrthogonality", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	svc := newPermissiveExperimentServiceWithMocks(s.ProjectsDB, segmenterSvc, pubSubSvc)
+
+	newTraffic := int32(60)
+	otherTraffic := int32(40)
+	description := "new description"
+	updatedBy := "another-user"
+	tests := map[string]struct {
+		expData        services.UpdateExperimentRequestBody
+		expectedDiff   *services.ExperimentDiff
+		expectedReport services.ValidationReport
+	}{
+		"success": {
+			expData: services.UpdateExperimentRequestBody{
+				Description: &description,
+				EndTime:     exps[0].EndTime,
+				Segment:     models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1", "seg-3"}},
+				StartTime:   exps[0].StartTime,
+				Status:      models.ExperimentStatusActive,
+				Treatments: models.ExperimentTreatments{
+					{Name: "control", Configuration: map[string]interface{}{"weight": 0.1}, Traffic: &newTraffic},
+					{Name: "treatment", Configuration: map[string]interface{}{"weight": 0.2}, Traffic: &otherTraffic},
+				},
+				Type:      exps[0].Type,
+				Tier:      exps[0].Tier,
+				UpdatedBy: &updatedBy,
+			},
+			expectedDiff: &services.ExperimentDiff{
+				Fields: map[string]services.FieldChange{
+					"description": {From: (*string)(nil), To: &description},
+					"segment": {
+						From: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+						To:   models.ExperimentSegment{"string_segmenter": []string{"seg-1", "seg-3"}},
+					},
+					"updated_by": {From: "test-user", To: "another-user"},
+				},
+				Treatments: models.ExperimentTreatmentsDiff{
+					Added:    []string{},
+					Removed:  []string{},
+					Modified: []string{"control", "treatment"},
+				},
+			},
+			expectedReport: services.ValidationReport{Passed: true, Failures: map[services.ValidationCheck]string{}},
+		},
+		"failure": {
+			expData: services.UpdateExperimentRequestBody{
+				EndTime:    exps[0].EndTime,
+				Segment:    models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-2"}},
+				StartTime:  exps[0].StartTime,
+				Status:     models.ExperimentStatusActive,
+				Treatments: treatments[:1],
+				Type:       models.ExperimentTypeSwitchback,
+				Tier:       exps[0].Tier,
+				UpdatedBy:  &updatedBy,
+			},
+			expectedDiff: &services.ExperimentDiff{
+				Fields: map[string]services.FieldChange{
+					"segment": {
+						From: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+						To:   models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+					},
+					"updated_by": {From: "test-user", To: "another-user"},
+				},
+				Treatments: models.ExperimentTreatmentsDiff{
+					Added:    []string{},
+					Removed:  []string{"treatment"},
+					Modified: []string{},
+				},
+			},
+			expectedReport: services.ValidationReport{
+				Passed: false,
+				Failures: map[services.ValidationCheck]string{
+					services.ValidationCheckRequest:       "experiment type cannot be changed",
+					services.ValidationCheckOrthogonality: "segment conflicts with preview-exp-other",
+				},
+			},
+		},
+	}
+	for name, data := range tests {
+		s.Suite.Run(name, func() {
+			diff, report, err := svc.PreviewUpdate(*settings, exps[0].ID.ToApiSchema(), data.expData)
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().Equal(data.expectedDiff, diff)
+			s.Suite.Assert().Equal(data.expectedReport, report)
+		})
+	}
+
+	// Nothing is saved or published
+	exp, err := svc.GetExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(int64(1), exp.Version)
+	s.Suite.Assert().Nil(exp.Description)
+	s.Suite.Assert().Equal(models.ExperimentSegment{"string_segmenter": []string{"seg-1"}}, exp.Segment)
+	s.Suite.Assert().Equal(exps[0].UpdatedAt.UTC(), exp.UpdatedAt.UTC())
+	s.Suite.Assert().Nil(exp.LastValidationStatus)
+	pubSubSvc.AssertNotCalled(s.Suite.T(), "PublishExperimentMessage", mock.Anything, mock.Anything)
+
+	// Non-existing experiment
+	_, _, err = svc.PreviewUpdate(*settings, 9999, services.UpdateExperimentRequestBody{})
+	s.Suite.Assert().Equal(errors.NotFound, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestUpdateHistoryFailureRollback() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:      "rollback-exp",
+			Segment:   models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			UpdatedBy: "test-user",
+		},
+	})
+	s.Suite.Require().NoError(err)
+	settings, err := services.NewProjectSettingsService(&services.Services{}, s.ProjectsDB).GetDBRecord(models.ID(projectId))
+	s.Suite.Require().NoError(err)
+
+	// The history is written after the experiment, in the same transaction
+	experimentHistorySvc := &mocks.ExperimentHistoryService{}
+	experimentHistorySvc.On("CreateExperimentHistory", mock.Anything).Return(nil, fmt.Errorf("history write failed"))
+	experimentHistorySvc.On("CreateExperimentHistories", mock.Anything).Return(fmt.Errorf("history write failed"))
+	experimentHistorySvc.On("WithTransaction", mock.Anything).Return(experimentHistorySvc)
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterTypes", mock.Anything).
+		Return(map[string]schema.SegmenterType{"string_segmenter": schema.SegmenterTypeString}, nil)
+	segmenterSvc.On("ValidateExperimentSegment", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	segmenterSvc.On("ValidateSegmentOrthogonality", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	validationSvc := &mocks.ValidationService{}
+	validationSvc.On("Validate", mock.Anything).Return(nil)
+	validationSvc.On("ValidateEntityWithExternalUrl", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything).Return(nil)
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	allServices := &services.Services{
+		ExperimentHistoryService: experimentHistorySvc,
+		SegmenterService:         segmenterSvc,
+		ValidationService:        validationSvc,
+		PubSubPublisherService:   pubSubSvc,
+	}
+	allServices.ProjectSettingsService = services.NewProjectSettingsService(allServices, s.ProjectsDB)
+	svc := services.NewExperimentService(allServices, s.ProjectsDB)
+
+	// Update
+	description := "updated description"
+	updatedBy := "another-user"
+	_, err = svc.UpdateExperiment(*settings, exps[0].ID.ToApiSchema(), services.UpdateExperimentRequestBody{
+		Description: &description,
+		EndTime:     exps[0].EndTime,
+		Segment:     models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-2"}},
+		StartTime:   exps[0].StartTime,
+		Status:      models.ExperimentStatusActive,
+		Treatments:  exps[0].Treatments,
+		Type:        exps[0].Type,
+		Tier:        exps[0].Tier,
+		UpdatedBy:   &updatedBy,
+	})
+	s.Suite.Assert().EqualError(err, "history write failed")
+	exp, err := svc.GetExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(int64(1), exp.Version)
+	s.Suite.Assert().Nil(exp.Description)
+	s.Suite.Assert().Equal(models.ExperimentSegment{"string_segmenter": []string{"seg-1"}}, exp.Segment)
+	s.Suite.Assert().Equal("test-user", exp.UpdatedBy)
+
+	// Disable
+	err = svc.DisableExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, "history write failed")
+	exp, err = svc.GetExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(models.ExperimentStatusActive, exp.Status)
+
+	// Enable
+	err = s.ProjectsDB.Model(&models.Experiment{}).Where("id = ?", exps[0].ID).Update("status", models.ExperimentStatusInactive).Error
+	s.Suite.Require().NoError(err)
+	err = svc.EnableExperiment(*settings, exps[0].ID.ToApiSchema())
+	s.Suite.Assert().EqualError(err, "history write failed")
+	exp, err = svc.GetExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(models.ExperimentStatusInactive, exp.Status)
+
+	// Resume
+	_, err = svc.ResumeExperiments(*settings, services.PauseToken{
+		ProjectID:     projectId,
+		ExperimentIds: []int64{exps[0].ID.ToApiSchema()},
+	})
+	s.Suite.Assert().EqualError(err, "history write failed")
+	exp, err = svc.GetExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(models.ExperimentStatusInactive, exp.Status)
+
+	// Pause
+	err = s.ProjectsDB.Model(&models.Experiment{}).Where("id = ?", exps[0].ID).Update("status", models.ExperimentStatusActive).Error
+	s.Suite.Require().NoError(err)
+	token, err := svc.PauseAllExperiments(projectId)
+	s.Suite.Assert().EqualError(err, "history write failed")
+	s.Suite.Assert().Empty(token.ExperimentIds)
+	exp, err = svc.GetExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(models.ExperimentStatusActive, exp.Status)
+
+	// Nothing is published
+	pubSubSvc.AssertNotCalled(s.Suite.T(), "PublishExperimentMessage", mock.Anything, mock.Anything)
+}
+
+func (s *ExperimentServiceTestSuite) TestUpdateStatusConcurrentlyDisable() {
+	projectId, exps, err := s.createProject([]models.Experiment{{Name: "concurrent-disable-exp"}})
+	s.Suite.Require().NoError(err)
+
+	var publishCount int32
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", "update", mock.Anything).
+		Run(func(args mock.Arguments) { atomic.AddInt32(&publishCount, 1) }).
+		Return(nil)
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterTypes", mock.Anything).
+		Return(map[string]schema.SegmenterType{"string_segmenter": schema.SegmenterTypeString}, nil)
+	allServices := &services.Services{
+		ExperimentHistoryService: services.NewExperimentHistoryService(s.ProjectsDB),
+		SegmenterService:         segmenterSvc,
+		PubSubPublisherService:   pubSubSvc,
+	}
+	allServices.ProjectSettingsService = services.NewProjectSettingsService(allServices, s.ProjectsDB)
+	svc := services.NewExperimentService(allServices, s.ProjectsDB)
+
+	// Only one of the parallel calls disables the experiment
+	numCalls := 5
+	errs := make(chan error, numCalls)
+	var wg sync.WaitGroup
+	for i := 0; i < numCalls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- svc.DisableExperiment(projectId, exps[0].ID.ToApiSchema())
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	numSucceeded := 0
+	for err := range errs {
+		if err == nil {
+			numSucceeded++
+			continue
+		}
+		s.Suite.Assert().EqualError(err, fmt.Sprintf("experiment id %d is already inactive", exps[0].ID))
+	}
+	s.Suite.Assert().Equal(1, numSucceeded)
+	s.Suite.Assert().Equal(int32(1), atomic.LoadInt32(&publishCount))
+
+	var historyCount int64
+	err = s.ProjectsDB.Model(&models.ExperimentHistory{}).Where("experiment_id = ?", exps[0].ID).Count(&historyCount).Error
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(int64(1), historyCount)
+	exp, err := svc.GetExperiment(projectId, exps[0].ID.ToApiSchema())
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(models.ExperimentStatusInactive, exp.Status)
+}
+
+func (s *ExperimentServiceTestSuite) TestUpdateStatusConcurrentlyEnable() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:    "concurrent-enable-exp-1",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			Status:  models.ExperimentStatusInactive,
+		},
+		{
+			Name:    "concurrent-enable-exp-2",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1", "seg-2"}},
+			Status:  models.ExperimentStatusInactive,
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	var publishCount int32
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", "update", mock.Anything).
+		Run(func(args mock.Arguments) { atomic.AddInt32(&publishCount, 1) }).
+		Return(nil)
+	// The segments of the experiments overlap, so they conflict with any other active experiment
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterTypes", mock.Anything).
+		Return(map[string]schema.SegmenterType{"string_segmenter": schema.SegmenterTypeString}, nil)
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything,
+		mock.MatchedBy(func(others []models.Experiment) bool { return len(others) > 0 }),
+	).Return(fmt.Errorf("Segment Orthogonality check failed"))
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	allServices := &services.Services{
+		ExperimentHistoryService: services.NewExperimentHistoryService(s.ProjectsDB),
+		SegmenterService:         segmenterSvc,
+		PubSubPublisherService:   pubSubSvc,
+	}
+	allServices.ProjectSettingsService = services.NewProjectSettingsService(allServices, s.ProjectsDB)
+	svc := services.NewExperimentService(allServices, s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+
+	// Each experiment is enabled by several parallel calls, but only one experiment is enabled, once
+	numCalls := 3
+	errs := make(chan error, numCalls*len(exps))
+	var wg sync.WaitGroup
+	for i := 0; i < numCalls; i++ {
+		for _, exp := range exps {
+			wg.Add(1)
+			go func(experimentId int64) {
+				defer wg.Done()
+				errs <- svc.EnableExperiment(settings, experimentId)
+			}(exp.ID.ToApiSchema())
+		}
+	}
+	wg.Wait()
+	close(errs)
+	numSucceeded := 0
+	for err := range errs {
+		if err == nil {
+			numSucceeded++
+		}
+	}
+	s.Suite.Assert().Equal(1, numSucceeded)
+	s.Suite.Assert().Equal(int32(1), atomic.LoadInt32(&publishCount))
+
+	var historyCount int64
+	err = s.ProjectsDB.Model(&models.ExperimentHistory{}).
+		Where("experiment_id IN ?", []models.ID{exps[0].ID, exps[1].ID}).
+		Count(&historyCount).Error
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(int64(1), historyCount)
+	status := models.ExperimentStatusActive
+	activeExps, _, err := svc.ListExperiments(projectId, services.ListExperimentsParams{Status: &status})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Len(activeExps, 1)
+}
+func (s *ExperimentServiceTestSuite) TestCreateExperimentsConcurrently() {
+	projectId, _, err := s.createProject(nil)
+	s.Suite.Require().NoError(err)
+
+	var publishCount int32
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", "create", mock.Anything).
+		Run(func(args mock.Arguments) { atomic.AddInt32(&publishCount, 1) }).
+		Return(nil)
+	// The segments of the experiments overlap, so they conflict with any other active experiment
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterTypes", mock.Anything).
+		Return(map[string]schema.SegmenterType{"string_segmenter": schema.SegmenterTypeString}, nil)
+	segmenterSvc.On("ValidateExperimentSegment", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything,
+		mock.MatchedBy(func(others []models.Experiment) bool { return len(others) > 0 }),
+	).Return(fmt.Errorf("Segment Orthogonality check failed"))
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	validationSvc := &mocks.ValidationService{}
+	validationSvc.On("Validate", mock.Anything).Return(nil)
+	allServices := &services.Services{
+		ValidationService:        validationSvc,
+		ExperimentHistoryService: services.NewExperimentHistoryService(s.ProjectsDB),
+		SegmenterService:         segmenterSvc,
+		PubSubPublisherService:   pubSubSvc,
+	}
+	allServices.ProjectSettingsService = services.NewProjectSettingsService(allServices, s.ProjectsDB)
+	svc := services.NewExperimentService(allServices, s.ProjectsDB)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+	updatedBy := "test-user"
+
+	// Several active experiments with overlapping segments are created in parallel, but only one is created
+	numCalls := 5
+	errs := make(chan error, numCalls)
+	var wg sync.WaitGroup
+	for i := 0; i < numCalls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := svc.CreateExperiment(settings, services.CreateExperimentRequestBody{
+				Name:      fmt.Sprintf("concurrent-create-exp-%d", i),
+				Segment:   models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}},
+				StartTime: time.Now().Add(time.Hour),
+				EndTime:   time.Now().Add(24 * time.Hour),
+				Status:    models.ExperimentStatusActive,
+				Tier:      models.ExperimentTierDefault,
+				Type:      models.ExperimentTypeAB,
+				UpdatedBy: &updatedBy,
+			})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	numSucceeded := 0
+	for err := range errs {
+		if err == nil {
+			numSucceeded++
+		}
+	}
+	s.Suite.Assert().Equal(1, numSucceeded)
+	s.Suite.Assert().Equal(int32(1), atomic.LoadInt32(&publishCount))
+
+	status := models.ExperimentStatusActive
+	activeExps, _, err := svc.ListExperiments(projectId, services.ListExperimentsParams{Status: &status})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Len(activeExps, 1)
+}
+
+func (s *ExperimentServiceTestSuite) TestUpdateTierInBulk() {
+	mutexGroup := "bulk-group"
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:    "bulk-exp-override",
+			Tier:    models.ExperimentTierOverride,
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		},
+		{
+			Name:    "bulk-exp-conflicting",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		},
+		{
+			Name:    "bulk-exp-orthogonal",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+		},
+		{
+			Name:    "bulk-exp-inactive",
+			Status:  models.ExperimentStatusInactive,
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+		},
+		// The mutex group of the experiments is checked regardless of their tiers
+		{
+			Name:       "bulk-exp-mutex",
+			Segment:    models.ExperimentSegment{"string_segmenter": []string{"seg-3"}},
+			MutexGroup: &mutexGroup,
+		},
+		{
+			Name:       "bulk-exp-mutex-other",
+			Tier:       models.ExperimentTierOverride,
+			Segment:    models.ExperimentSegment{"string_segmenter": []string{"seg-4"}},
+			MutexGroup: &mutexGroup,
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	// Only the segment seg-1 overlaps with the existing override experiment
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality",
+		projectId, mock.Anything, models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}}, mock.Anything,
+	).Return(fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID))
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+
+	// Invalid tier
+	_, err = svc.BulkUpdateTier(settings, []int64{exps[1].ID.ToApiSchema()}, models.ExperimentTier("unknown"))
+	s.Suite.Assert().EqualError(err, "unknown experiment tier: unknown")
+
+	result, err := svc.BulkUpdateTier(settings, []int64{
+		exps[1].ID.ToApiSchema(),
+		exps[2].ID.ToApiSchema(),
+		exps[3].ID.ToApiSchema(),
+		exps[0].ID.ToApiSchema(),
+		exps[4].ID.ToApiSchema(),
+		int64(1000),
+	}, models.ExperimentTierOverride)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.BulkResult{
+		Succeeded: []int64{exps[2].ID.ToApiSchema(), exps[3].ID.ToApiSchema(), exps[0].ID.ToApiSchema()},
+		Failed: map[int64]string{
+			exps[1].ID.ToApiSchema(): fmt.Sprintf("Segment Orthogonality check failed against experiment ID %d",
+				exps[0].ID),
+			exps[4].ID.ToApiSchema(): fmt.Sprintf("experiment bulk-exp-mutex-other (id %d) in the same mutex group "+
+				"bulk-group is already active in the given time range", exps[5].ID),
+			int64(1000): "record not found",
+		},
+	}, result)
+
+	// Check the tiers and versions of the experiments
+	for _, data := range []struct {
+		experimentId models.ID
+		tier         models.ExperimentTier
+		version      int64
+	}{
+		{exps[0].ID, models.ExperimentTierOverride, 1},
+		{exps[1].ID, models.ExperimentTierDefault, 1},
+		{exps[2].ID, models.ExperimentTierOverride, 2},
+		{exps[3].ID, models.ExperimentTierOverride, 2},
+		{exps[4].ID, models.ExperimentTierDefault, 1},
+	} {
+		exp, err := svc.GetExperiment(projectId, data.experimentId.ToApiSchema())
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Equal(data.tier, exp.Tier)
+		s.Suite.Assert().Equal(data.version, exp.Version)
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestValidateAllExperiments() {
+	v1Treatments := models.ExperimentTreatments{
+		{Name: "control", Configuration: map[string]interface{}{"model_version": "v1"}},
+	}
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:       "health-exp-ok",
+			Segment:    models.ExperimentSegment{"string_segmenter": []string{"seg-2"}},
+			Treatments: v1Treatments,
+		},
+		{
+			Name:    "health-exp-removed-segmenter",
+			Status:  models.ExperimentStatusInactive,
+			Segment: models.ExperimentSegment{"integer_segmenter": []string{"1"}},
+		},
+		{
+			Name:    "health-exp-invalid-value",
+			Status:  models.ExperimentStatusInactive,
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-invalid"}},
+		},
+		{
+			Name:   "health-exp-schema",
+			Status: models.ExperimentStatusInactive,
+			Treatments: models.ExperimentTreatments{
+				{Name: "control", Configuration: map[string]interface{}{"model_version": "v0"}},
+			},
+		},
+		{
+			Name:       "health-exp-overlap",
+			Segment:    models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			Treatments: v1Treatments,
+		},
+	})
+	s.Suite.Require().NoError(err)
+	err = s.ProjectsDB.Model(&models.Settings{}).Where("project_id = ?", projectId).Update("treatment_schema", &models.TreatmentSchema{
+		Rules: []models.Rule{
+			{Name: "model-version", Predicate: "{{- (or (eq .model_version \"v1\") (eq .model_version \"v2\")) -}}"},
+		},
+	}).Error
+	s.Suite.Require().NoError(err)
+
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetSegmenterTypes", projectId).Return(map[string]schema.SegmenterType{
+		"string_segmenter":  schema.SegmenterTypeString,
+		"integer_segmenter": schema.SegmenterTypeInteger,
+	}, nil)
+	segmenterSvc.On("ValidateExperimentSegment", projectId, mock.Anything,
+		models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-invalid"}},
+	).Return(fmt.Errorf("segmenter string_segmenter has invalid value seg-invalid"))
+	// Only the segment seg-1 overlaps with the other experiments
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything,
+		models.ExperimentSegmentRaw{"string_segmenter": []interface{}{"seg-1"}}, mock.Anything,
+	).Return(fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exps[0].ID))
+	segmenterSvc.On("ValidateSegmentOrthogonality", projectId, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+
+	report, err := svc.ValidateAllExperiments(projectId)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.ProjectHealthReport{
+		Problems: map[int64]map[services.ValidationCheck]string{
+			exps[1].ID.ToApiSchema(): {
+				services.ValidationCheckSegmentersExist: "experiment health-exp-removed-segmenter requires segmenter: " +
+					"integer_segmenter",
+			},
+			exps[2].ID.ToApiSchema(): {
+				services.ValidationCheckSegment: "segmenter string_segmenter has invalid value seg-invalid",
+			},
+			exps[3].ID.ToApiSchema(): {
+				services.ValidationCheckTreatmentSchema: "Go template rule model-version returns false",
+			},
+			exps[4].ID.ToApiSchema(): {
+				services.ValidationCheckOrthogonality: fmt.Sprintf(
+					"Segment Orthogonality check failed against experiment ID %d", exps[0].ID),
+			},
+		},
+	}, report)
+
+	// The experiments are not modified
+	for _, exp := range exps {
+		dbRecord, err := svc.GetDBRecord(models.ID(projectId), exp.ID)
+		s.Suite.Require().NoError(err)
+		s.Suite.Assert().Equal(int64(1), dbRecord.Version)
+		s.Suite.Assert().True(exp.UpdatedAt.Equal(dbRecord.UpdatedAt))
+		s.Suite.Assert().Nil(dbRecord.LastValidationStatus)
+	}
+
+	_, err = svc.ValidateAllExperiments(s.newProjectId())
+	s.Suite.Assert().Error(err)
+}
+
+func (s *ExperimentServiceTestSuite) TestValidateExperimentAgainstSettings() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:    "settings-exp",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			Treatments: models.ExperimentTreatments{
+				{Name: "control", Configuration: map[string]interface{}{"model_version": "v1"}},
+			},
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	oldSettings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+		TreatmentSchema: &models.TreatmentSchema{
+			Rules: []models.Rule{
+				{Name: "model-version", Predicate: "{{- (or (eq .model_version \"v1\") (eq .model_version \"v2\")) -}}"},
+			},
+		},
+	}
+	// The new settings no longer enable the segmenter and reject the old model version
+	newSettings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"integer_segmenter"}},
+		},
+		TreatmentSchema: &models.TreatmentSchema{
+			Rules: []models.Rule{
+				{Name: "model-version-v2", Predicate: "{{- (eq .model_version \"v2\") -}}"},
+			},
+		},
+	}
+
+	report, err := svc.ValidateExperimentAgainstSettings(*exps[0], oldSettings)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.ValidationReport{Passed: true, Failures: map[services.ValidationCheck]string{}}, report)
+
+	report, err = svc.ValidateExperimentAgainstSettings(*exps[0], newSettings)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.ValidationReport{
+		Passed: false,
+		Failures: map[services.ValidationCheck]string{
+			services.ValidationCheckSegmentersExist:  "experiment settings-exp requires segmenter: string_segmenter",
+			services.ValidationCheckCustomValidation: "Go template rule model-version-v2 returns false",
+		},
+	}, report)
+
+	// The outcome is not recorded on the experiment
+	dbRecord, err := svc.GetDBRecord(models.ID(projectId), exps[0].ID)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Nil(dbRecord.LastValidationStatus)
+}
+
+func (s *ExperimentServiceTestSuite) TestValidateExperimentAgainstSettingsWarnings() {
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{
+			Name:    "warnings-exp",
+			Segment: models.ExperimentSegment{"string_segmenter": []string{"seg-1"}},
+			Treatments: models.ExperimentTreatments{
+				{Name: "control", Configuration: map[string]interface{}{"model_version": "v1"}},
+				{Name: "treatment", Configuration: map[string]interface{}{"model_version": "v1"}},
+			},
+		},
+	})
+	s.Suite.Require().NoError(err)
+
+	segmentWarning := "Segmenter string_segmenter lists 1 values against its 1 allowed values, matching all of them; " +
+		"leave it unset instead, or remove the stale values"
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("GetExperimentSegmentWarnings", projectId, mock.Anything, mock.Anything).
+		Return([]string{segmentWarning}, nil)
+	svc := newPermissiveExperimentServiceWithSegmenterService(s.ProjectsDB, segmenterSvc)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+	}
+
+	// The problems that the project does not block are reported as warnings, without failing the checks
+	report, err := svc.ValidateExperimentAgainstSettings(*exps[0], settings)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.ValidationReport{
+		Passed:   true,
+		Failures: map[services.ValidationCheck]string{},
+		Warnings: []string{
+			segmentWarning,
+			`treatments "control" and "treatment" have identical configurations`,
+		},
+	}, report)
+}
+
+func (s *ExperimentServiceTestSuite) TestPreviewSettingsImpact() {
+	newTreatments := func(modelVersion string) models.ExperimentTreatments {
+		return models.ExperimentTreatments{
+			{Name: "control", Configuration: map[string]interface{}{"model_version": modelVersion}},
+		}
+	}
+	projectId, exps, err := s.createProject([]models.Experiment{
+		{Name: "impact-exp-v1", Treatments: newTreatments("v1")},
+		{Name: "impact-exp-v2", Treatments: newTreatments("v2")},
+		{Name: "impact-exp-v3", Treatments: newTreatments("v3")},
+	})
+	s.Suite.Require().NoError(err)
+	// The current settings already reject v3
+	err = s.ProjectsDB.Model(&models.Settings{}).Where("project_id = ?", projectId).Update("treatment_schema", &models.TreatmentSchema{
+		Rules: []models.Rule{
+			{Name: "model-version", Predicate: "{{- (or (eq .model_version \"v1\") (eq .model_version \"v2\")) -}}"},
+		},
+	}).Error
+	s.Suite.Require().NoError(err)
+
+	svc := newPermissiveExperimentService(s.ProjectsDB)
+	// The stricter treatment schema rejects v1 as well
+	proposedSettings := models.Settings{
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+		},
+		TreatmentSchema: &models.TreatmentSchema{
+			Rules: []models.Rule{
+				{Name: "model-version", Predicate: "{{- (eq .model_version \"v2\") -}}"},
+			},
+		},
+	}
+
+	report, err := svc.PreviewSettingsImpact(projectId, proposedSettings)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(services.SettingsImpactReport{
+		NewlyInvalid: map[int64]map[services.ValidationCheck]string{
+			exps[0].ID.ToApiSchema(): {
+				services.ValidationCheckCustomValidation: "Go template rule model-version returns false",
+			},
+		},
+	}, report)
+}
+
+func (s *ExperimentServiceTestSuite) TestRunCustomValidation() {
+	tests := map[string]struct {
+		experiment    models.Experiment
+		settings      models.Settings
+		context       services.ValidationContext
+		operationType services.OperationType
+		errString     string
+	}{
+		"failure | incorrect value assertion from template schema rule returns error": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{
+						Configuration: map[string]interface{}{
+							"field1": "abc",
+							"field2": "def",
+							"field3": map[string]interface{}{
+								"field4": 0.1,
+							},
+						},
+					},
+				},
+			},
+			settings: models.Settings{
+				TreatmentSchema: &models.TreatmentSchema{
+					Rules: []models.Rule{
+						{
+							Name:      "test-rule",
+							Predicate: "{{- (eq .field1 \"def\") -}}",
+						},
+					},
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+			errString:     "Go template rule test-rule returns false",
+		},
+		"failure | validation url returns an error": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{
+						Configuration: map[string]interface{}{
+							"field1": "abc",
+							"field2": "def",
+							"field3": map[string]interface{}{
+								"field4": 0.1,
+							},
+							"field5": 1,
+						},
+					},
+				},
+			},
+			settings: models.Settings{
+				TreatmentSchema: &models.TreatmentSchema{
+					Rules: []models.Rule{},
+				},
+				ValidationUrl: &failureValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+			errString:     "Error validating data with validation URL: 500 Internal Server Error",
+		},
+		"failure | treatment config takes precedence over config defaults": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{
+						Configuration: map[string]interface{}{
+							"field1": "def",
+						},
+					},
+				},
+			},
+			settings: models.Settings{
+				Config: &models.ExperimentationConfig{
+					TreatmentConfigDefaults: json.RawMessage(`{"field1": "abc"}`),
+				},
+				TreatmentSchema: &models.TreatmentSchema{
+					Rules: []models.Rule{
+						{
+							Name:      "test-rule",
+							Predicate: "{{- (eq .field1 \"abc\") -}}",
+						},
+					},
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+			errString:     "Go template rule test-rule returns false",
+		},
+		"failure | invalid treatment config defaults": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{
+						Configuration: map[string]interface{}{
+							"field1": "abc",
+						},
+					},
+				},
+			},
+			settings: models.Settings{
+				Config: &models.ExperimentationConfig{
+					TreatmentConfigDefaults: json.RawMessage(`["field1"]`),
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+			errString: strings.Join([]string{
+				"error parsing treatment config defaults:",
+				"json: cannot unmarshal array into Go value of type map[string]interface {}",
+			}, " "),
+		},
+		"failure | treatment name does not match configured pattern": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{Name: "Control"},
+				},
+			},
+			settings: models.Settings{
+				Config: &models.ExperimentationConfig{
+					TreatmentNamePattern: "^[a-z ]+$",
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+			errString:     `treatment name "Control" does not match the pattern ^[a-z ]+$`,
+		},
+		"failure | invalid treatment name pattern": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{Name: "control"},
+				},
+			},
+			settings: models.Settings{
+				Config: &models.ExperimentationConfig{
+					TreatmentNamePattern: "^[a-z",
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+			errString:     "invalid treatment name pattern ^[a-z: error parsing regexp: missing closing ]: `[a-z`",
+		},
+		"success": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{
+						Configuration: map[string]interface{}{
+							"field1": "abc",
+							"field2": "def",
+							"field3": map[string]interface{}{
+								"field4": 0.1,
+							},
+							"field5": 1,
+						},
+					},
+				},
+			},
+			settings: models.Settings{
+				TreatmentSchema: &models.TreatmentSchema{
+					Rules: []models.Rule{
+						{
+							Name:      "test-rule-1",
+							Predicate: "{{- (eq .field1 \"abc\") -}}",
+						},
+					},
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+		},
+		"success | treatment names not checked without a configured pattern": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{Name: "control"},
+					{Name: "Treatment (1) #a"},
+				},
+			},
+			settings: models.Settings{
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+		},
+		"success | treatment names match configured pattern": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{Name: "control group"},
+				},
+			},
+			settings: models.Settings{
+				Config: &models.ExperimentationConfig{
+					TreatmentNamePattern: "^[a-z ]+$",
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+		},
+		"failure | duplicate treatment configurations blocked": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{
+						Name:          "control",
+						Configuration: map[string]interface{}{"field1": "abc", "field2": map[string]interface{}{"a": 1, "b": 2}},
+					},
+					{
+						Name:          "treatment-a",
+						Configuration: map[string]interface{}{"field2": map[string]interface{}{"b": 2, "a": 1}, "field1": "abc"},
+					},
+				},
+			},
+			settings: models.Settings{
+				Config: &models.ExperimentationConfig{
+					BlockDuplicateTreatmentConfigs: true,
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+			errString:     `treatments "control" and "treatment-a" have identical configurations`,
+		},
+		"success | duplicate treatment configurations allowed": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{Name: "control", Configuration: map[string]interface{}{"field1": "abc"}},
+					{Name: "treatment-a", Configuration: map[string]interface{}{"field1": "abc"}},
+				},
+			},
+			settings: models.Settings{
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+		},
+		"success | distinct treatment configurations": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{Name: "control", Configuration: map[string]interface{}{"field1": "abc"}},
+					{Name: "treatment-a", Configuration: map[string]interface{}{"field1": "def"}},
+				},
+			},
+			settings: models.Settings{
+				Config: &models.ExperimentationConfig{
+					BlockDuplicateTreatmentConfigs: true,
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+		},
+		"failure | treatment configuration exceeds the maximum size": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{Name: "control", Configuration: map[string]interface{}{"field1": "ab"}},
+					{Name: "treatment-a", Configuration: map[string]interface{}{"field1": "abc"}},
+				},
+			},
+			settings: models.Settings{
+				Config: &models.ExperimentationConfig{
+					MaxTreatmentConfigBytes: 15,
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+			errString:     `treatment "treatment-a" has a configuration of 16 bytes, exceeding the maximum of 15 bytes`,
+		},
+		"success | treatment configuration of the maximum size": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{Name: "control", Configuration: map[string]interface{}{"field1": "ab"}},
+					{Name: "treatment-a", Configuration: map[string]interface{}{"field1": "abc"}},
+				},
+			},
+			settings: models.Settings{
+				Config: &models.ExperimentationConfig{
+					MaxTreatmentConfigBytes: 16,
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+		},
+		"success | treatment config defaults merged": {
+			experiment: models.Experiment{
+				Treatments: []models.ExperimentTreatment{
+					{
+						Configuration: map[string]interface{}{
+							"field2": "def",
+							"field3": map[string]interface{}{
+								"field4": 0.2,
+							},
+						},
+					},
+				},
+			},
+			settings: models.Settings{
+				Config: &models.ExperimentationConfig{
+					TreatmentConfigDefaults: json.RawMessage(`{"field1": "abc", "field3": {"field4": 0.1, "field5": 1}}`),
+				},
+				TreatmentSchema: &models.TreatmentSchema{
+					Rules: []models.Rule{
+						{
+							Name:      "test-rule-1",
+							Predicate: "{{- (eq .field1 \"abc\") -}}",
+						},
+						{
+							Name:      "test-rule-2",
+							Predicate: "{{- (and (eq .field3.field4 0.2) (eq .field3.field5 1.0)) -}}",
+						},
+					},
+				},
+				ValidationUrl: &successValidationUrl,
+			},
+			context:       services.ValidationContext{},
+			operationType: services.OperationTypeCreate,
+		},
+	}
+	for name, test := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			err := s.ExperimentService.RunCustomValidation(
+				test.experiment,
+				test.settings,
+				test.context,
+				test.operationType,
+			)
+			if test.errString == "" {
+				s.Suite.Require().NoError(err)
+			} else {
+				s.Suite.Assert().EqualError(err, test.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestTreatmentConfigDefaultsPublished() {
+	projectId, _, err := s.createProject(nil)
+	s.Suite.Require().NoError(err)
+
+	var published []*_pubsub.Experiment
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { published = append(published, args.Get(1).(*_pubsub.Experiment)) }).
+		Return(nil)
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	svc := newPermissiveExperimentServiceWithMocks(s.ProjectsDB, segmenterSvc, pubSubSvc)
+	settings := models.Settings{
+		ProjectID: models.ID(projectId),
+		Config: &models.ExperimentationConfig{
+			Segmenters:              models.ProjectSegmenters{Names: []string{"string_segmenter"}},
+			TreatmentConfigDefaults: json.RawMessage(`{"field1": "abc", "field2": {"field3": 1, "field4": 2}}`),
+		},
+	}
+	updatedBy := "test-user"
+	traffic := int32(100)
+
+	// The defaults are merged into the stored and published treatment config, with the given values taking precedence
+	exp, err := svc.CreateExperiment(settings, services.CreateExperimentRequestBody{
+		Name:      "treatment-defaults-exp",
+		Segment:   models.ExperimentSegmentRaw{},
+		StartTime: time.Now().Add(time.Hour),
+		EndTime:   time.Now().Add(24 * time.Hour),
+		Status:    models.ExperimentStatusInactive,
+		Tier:      models.ExperimentTierDefault,
+		Type:      models.ExperimentTypeAB,
+		Treatments: models.ExperimentTreatments{
+			{
+				Name:          "control",
+				Configuration: map[string]interface{}{"field2": map[string]interface{}{"field4": 3}},
+				Traffic:       &traffic,
+			},
+		},
+		UpdatedBy: &updatedBy,
+	})
+	s.Suite.Require().NoError(err)
+	expectedConfig := map[string]interface{}{
+		"field1": "abc",
+		"field2": map[string]interface{}{"field3": float64(1), "field4": float64(3)},
+	}
+	s.Suite.Assert().Equal(expectedConfig, exp.Treatments[0].Configuration)
+	s.Suite.Require().Len(published, 1)
+	s.Suite.Assert().Equal(expectedConfig, published[0].Treatments[0].Config.AsMap())
+
+	// Likewise, for the treatments that are added
+	exp, err = svc.AddTreatments(settings, exp.ID.ToApiSchema(), models.ExperimentTreatments{
+		{Name: "treatment", Configuration: map[string]interface{}{"field1": "def"}},
+	})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(expectedConfig, exp.Treatments[0].Configuration)
+	expectedAddedConfig := map[string]interface{}{
+		"field1": "def",
+		"field2": map[string]interface{}{"field3": float64(1), "field4": float64(2)},
+	}
+	s.Suite.Assert().Equal(expectedAddedConfig, exp.Treatments[1].Configuration)
+	s.Suite.Require().Len(published, 2)
+	s.Suite.Assert().Equal(expectedConfig, published[1].Treatments[0].Config.AsMap())
+	s.Suite.Assert().Equal(expectedAddedConfig, published[1].Treatments[1].Config.AsMap())
+}
+
+func (s *ExperimentServiceTestSuite) TestRunCustomValidationWithRegisteredValidators() {
+	svc := newPermissiveExperimentService(s.DB)
+	svc.RegisterValidator("no-forbidden-names", func(
+		experiment models.Experiment,
+		settings models.Settings,
+		context services.ValidationContext,
+		operationType services.OperationType,
+	) error {
+		if strings.HasPrefix(experiment.Name, "forbidden") {
+			return fmt.Errorf("experiment name %s is not allowed in project %d", experiment.Name, settings.ProjectID)
+		}
+		return nil
+	})
+
+	tests := map[string]struct {
+		name              string
+		enabledValidators []string
+		errString         string
+	}{
+		"failure | rejected by enabled validator": {
+			name:              "forbidden-exp",
+			enabledValidators: []string{"no-forbidden-names"},
+			errString:         "experiment name forbidden-exp is not allowed in project 1",
+		},
+		"failure | unregistered validator": {
+			name:              "allowed-exp",
+			enabledValidators: []string{"no-forbidden-names", "unknown"},
+			errString:         "validator unknown is not registered",
+		},
+		"success | accepted by enabled validator": {
+			name:              "allowed-exp",
+			enabledValidators: []string{"no-forbidden-names"},
+		},
+		"success | validator not enabled": {
+			name: "forbidden-exp",
+		},
+	}
+
+	for name, test := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			err := svc.RunCustomValidation(
+				models.Experiment{Name: test.name},
+				models.Settings{
+					ProjectID: models.ID(1),
+					Config:    &models.ExperimentationConfig{EnabledValidators: test.enabledValidators},
+				},
+				services.ValidationContext{},
+				services.OperationTypeCreate,
+			)
+			if test.errString == "" {
+				s.Suite.Require().NoError(err)
+			} else {
+				s.Suite.Assert().EqualError(err, test.errString)
+			}
+		})
+	}
+}
+
+func (s *ExperimentServiceTestSuite) TestValidateEnabledValidators() {
+	svc := newPermissiveExperimentService(s.DB)
+	svc.RegisterValidator("registered", func(
+		experiment models.Experiment,
+		settings models.Settings,
+		context services.ValidationContext,
+		operationType services.OperationType,
+	) error {
+		return nil
+	})
+
+	s.Suite.Assert().NoError(svc.ValidateEnabledValidators([]string{"registered"}))
+	err := svc.ValidateEnabledValidators([]string{"registered", "unknown"})
+	s.Suite.Assert().EqualError(err, "validator unknown is not registered")
+	s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+}
+
+func (s *ExperimentServiceTestSuite) TestRunCustomValidationConcurrencyLimit() {
+	svc := newPermissiveExperimentService(s.DB)
+	svc.SetValidationConcurrency(3)
+
+	// Track the number of validators running at the same time
+	var running, maxRunning int32
+	validator := func(
+		experiment models.Experiment,
+		settings models.Settings,
+		context services.ValidationContext,
+		operationType services.OperationType,
+	) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			highest := atomic.LoadInt32(&maxRunning)
+			if current <= highest || atomic.CompareAndSwapInt32(&maxRunning, highest, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}
+	enabledValidators := []string{}
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("slow-validator-%d", i)
+		svc.RegisterValidator(name, validator)
+		enabledValidators = append(enabledValidators, name)
+	}
+	treatments := models.ExperimentTreatments{}
+	for i := 0; i < 200; i++ {
+		treatments = append(treatments, models.ExperimentTreatment{
+			Name:          fmt.Sprintf("treatment-%d", i),
+			Configuration: map[string]interface{}{"index": i},
+		})
+	}
+
+	err := svc.RunCustomValidation(
+		models.Experiment{Name: "many-treatments-exp", Treatments: treatments},
+		models.Settings{
+			ProjectID: models.ID(1),
+			Config:    &models.ExperimentationConfig{EnabledValidators: enabledValidators},
+		},
+		services.ValidationContext{},
+		services.OperationTypeCreate,
+	)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().LessOrEqual(atomic.LoadInt32(&maxRunning), int32(3))
+	s.Suite.Assert().Greater(atomic.LoadInt32(&maxRunning), int32(1))
+}
+
+func createTestExperiments(db *gorm.DB) (models.Settings, []*models.Experiment, error) {
+	// Create test project settings (with project_id=1)
+	var settings models.Settings
+	err := db.Create(&models.Settings{
+		ProjectID: models.ID(1),
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{
+				Names:     []string{"string_segmenter"},
+				Variables: nil,
+			},
+		},
+	}).Error
+	if err != nil {
+		return settings, []*models.Experiment{}, err
+	}
+	err = db.Create(&models.Settings{
+		ProjectID: models.ID(2),
+		Username:  "user_2",
+		Config: &models.ExperimentationConfig{
+			Segmenters: models.ProjectSegmenters{
+				Names:     []string{"string_segmenter"},
+				Variables: nil,
+			},
+		},
+	}).Error
+	if err != nil {
+		return settings, []*models.Experiment{}, err
+	}
+	// Query the created settings
+	query := db.Where("project_id = 1").First(&settings)
+	if err := query.Error; err != nil {
+		return settings, []*models.Experiment{}, err
+	}
+
+	// Test segmenters
+	integerSegmenter := []string{"1", "2"}
+	integer2Segmenter := []string{"1", "2", "3", "4", "5"}
+	floatSegmenter := []string{"1.0", "2.0"}
+	float2Segmenter := []string{"3.0", "4.0"}
+	stringSegmenter := []string{"seg-1"}
+	boolSegmenter := []string{"true"}
+
+	description := "test-desc-1"
+
+	// Define test experiments
+	experiments := []models.Experiment{
+		{
+			ProjectID:  models.ID(1),
+			Name:       "test-exp-1",
+			Type:       models.ExperimentTypeAB,
 			Tier:       models.ExperimentTierDefault,
 			Treatments: nil,
 			Segment: models.ExperimentSegment{
@@ -662,6 +6118,95 @@ func createTestExperiments(db *gorm.DB) (models.Settings, []*models.Experiment,
 	return settings, experimentRecords, nil
 }
 
+func getTreatmentNames(treatments models.ExperimentTreatments) []string {
+	names := []string{}
+	for _, treatment := range treatments {
+		names = append(names, treatment.Name)
+	}
+	return names
+}
+
+func getExperimentNames(experiments []*models.Experiment) []string {
+	names := []string{}
+	for _, exp := range experiments {
+		names = append(names, exp.Name)
+	}
+	return names
+}
+
+// newPermissiveExperimentService creates an experiment service whose dependent services accept any input, for tests
+// that exercise the checks of the experiment service itself against the data in the DB
+func newPermissiveExperimentService(db *gorm.DB) services.ExperimentService {
+	segmenterSvc := &mocks.SegmenterService{}
+	segmenterSvc.On("ValidateSegmentOrthogonality", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
+	return newPermissiveExperimentServiceWithSegmenterService(db, segmenterSvc)
+}
+
+// newPermissiveExperimentServiceWithSegmenterService is similar to newPermissiveExperimentService, but allows the
+// expectations for the segment orthogonality checks to be set on the given segmenter service
+func newPermissiveExperimentServiceWithSegmenterService(
+	db *gorm.DB,
+	segmenterSvc *mocks.SegmenterService,
+) services.ExperimentService {
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", mock.Anything, mock.Anything).Return(nil)
+	return newPermissiveExperimentServiceWithMocks(db, segmenterSvc, pubSubSvc)
+}
+
+// newPermissiveExperimentServiceWithMocks is similar to newPermissiveExperimentServiceWithSegmenterService, but also
+// allows the expectations for the published messages to be set on the given pubsub publisher service
+func newPermissiveExperimentServiceWithMocks(
+	db *gorm.DB,
+	segmenterSvc *mocks.SegmenterService,
+	pubSubSvc *mocks.PubSubPublisherService,
+) services.ExperimentService {
+	return services.NewExperimentService(newPermissiveServices(db, segmenterSvc, pubSubSvc), db)
+}
+
+// newPermissiveExperimentServiceWithClock is similar to newPermissiveExperimentServiceWithSegmenterService, but
+// evaluates the time-based checks at the times given by the clock
+func newPermissiveExperimentServiceWithClock(
+	db *gorm.DB,
+	segmenterSvc *mocks.SegmenterService,
+	clock services.Clock,
+) services.ExperimentService {
+	pubSubSvc := &mocks.PubSubPublisherService{}
+	pubSubSvc.On("PublishExperimentMessage", mock.Anything, mock.Anything).Return(nil)
+	return services.NewExperimentServiceWithClock(newPermissiveServices(db, segmenterSvc, pubSubSvc), db, clock)
+}
+
+// newPermissiveServices creates the dependent services of the permissive experiment services
+func newPermissiveServices(
+	db *gorm.DB,
+	segmenterSvc *mocks.SegmenterService,
+	pubSubSvc *mocks.PubSubPublisherService,
+) *services.Services {
+	segmenterSvc.On("GetSegmenterTypes", mock.Anything).
+		Return(map[string]schema.SegmenterType{"string_segmenter": schema.SegmenterTypeString}, nil)
+	segmenterSvc.On("ValidateExperimentSegment", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	segmenterSvc.On("GetExperimentSegmentWarnings", mock.Anything, mock.Anything, mock.Anything).Return([]string{}, nil)
+
+	validationSvc := &mocks.ValidationService{}
+	validationSvc.On("Validate", mock.Anything).Return(nil)
+	validationSvc.On("ValidateEntityWithExternalUrl", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything).Return(nil)
+
+	experimentHistorySvc := &mocks.ExperimentHistoryService{}
+	experimentHistorySvc.On("CreateExperimentHistory", mock.Anything).Return(nil, nil)
+	experimentHistorySvc.On("CreateExperimentHistories", mock.Anything).Return(nil)
+	experimentHistorySvc.On("WithTransaction", mock.Anything).Return(experimentHistorySvc)
+
+	allServices := &services.Services{
+		ValidationService:        validationSvc,
+		ExperimentHistoryService: experimentHistorySvc,
+		SegmenterService:         segmenterSvc,
+		PubSubPublisherService:   pubSubSvc,
+	}
+	allServices.ProjectSettingsService = services.NewProjectSettingsService(allServices, db)
+	return allServices
+}
+
 func setupMockSegmenterService() services.SegmenterService {
 	rawStringSegmenter := []interface{}{"seg-1"}
 	rawString2Segmenter := []interface{}{"seg-1", "seg-2"}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/caraml-dev/xp/management-service/models"
+)
+
+func TestGroupExperimentsByStatusFriendly(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	newExperiment := func(id int64, status models.ExperimentStatus, startTime time.Time, endTime time.Time) *models.Experiment {
+		return &models.Experiment{ID: models.ID(id), Status: status, StartTime: startTime, EndTime: endTime}
+	}
+	active := models.ExperimentStatusActive
+
+	startingNow := newExperiment(1, active, now, now.Add(time.Hour))
+	startingLater := newExperiment(2, active, now.Add(time.Nanosecond), now.Add(time.Hour))
+	endingLater := newExperiment(3, active, now.Add(-time.Hour), now.Add(time.Nanosecond))
+	endingNow := newExperiment(4, active, now.Add(-time.Hour), now)
+	ended := newExperiment(5, active, now.Add(-time.Hour), now.Add(-time.Nanosecond))
+	inactive := newExperiment(6, models.ExperimentStatusInactive, now.Add(-time.Hour), now.Add(time.Hour))
+	draft := newExperiment(7, models.ExperimentStatusDraft, now.Add(-time.Hour), now.Add(time.Hour))
+
+	groups := groupExperimentsByStatusFriendly(
+		[]*models.Experiment{startingNow, startingLater, endingLater, endingNow, ended, inactive, draft},
+		now,
+	)
+
+	assert.Equal(t, map[ExperimentStatusFriendly][]*models.Experiment{
+		ExperimentStatusFriendlyCompleted:   {ended},
+		ExperimentStatusFriendlyDeactivated: {inactive},
+		ExperimentStatusFriendlyRunning:     {startingNow, endingLater},
+		ExperimentStatusFriendlyScheduled:   {startingLater},
+		ExperimentStatusFriendlyDraft:       {draft},
+	}, groups)
+}
+
+func TestGroupExperimentsByStatusFriendlyEmpty(t *testing.T) {
+	groups := groupExperimentsByStatusFriendly([]*models.Experiment{}, time.Now())
+
+	assert.Len(t, groups, 5)
+	for _, exps := range groups {
+		assert.Empty(t, exps)
+	}
+}
+
+func TestRequiresOrthogonalityCheck(t *testing.T) {
+	tests := map[string]struct {
+		newStatus models.ExperimentStatus
+		expected  bool
+	}{
+		"to active": {
+			newStatus: models.ExperimentStatusActive,
+			expected:  true,
+		},
+		"to inactive": {
+			newStatus: models.ExperimentStatusInactive,
+			expected:  false,
+		},
+		"to draft": {
+			newStatus: models.ExperimentStatusDraft,
+			expected:  false,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, data.expected, requiresOrthogonalityCheck(data.newStatus))
+		})
+	}
+}
+
+func TestValidateDraftStatusTransition(t *testing.T) {
+	tests := map[string]struct {
+		curStatus models.ExperimentStatus
+		newStatus models.ExperimentStatus
+		errString string
+	}{
+		"draft to draft": {
+			curStatus: models.ExperimentStatusDraft,
+			newStatus: models.ExperimentStatusDraft,
+		},
+		"inactive to active": {
+			curStatus: models.ExperimentStatusInactive,
+			newStatus: models.ExperimentStatusActive,
+		},
+		"draft to inactive": {
+			curStatus: models.ExperimentStatusDraft,
+			newStatus: models.ExperimentStatusInactive,
+			errString: "experiment id 1 is a draft and must be promoted first",
+		},
+		"draft to active": {
+			curStatus: models.ExperimentStatusDraft,
+			newStatus: models.ExperimentStatusActive,
+			errString: "experiment id 1 is a draft and must be promoted first",
+		},
+		"inactive to draft": {
+			curStatus: models.ExperimentStatusInactive,
+			newStatus: models.ExperimentStatusDraft,
+			errString: "experiment id 1 cannot be changed back to a draft",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateDraftStatusTransition(1, data.curStatus, data.newStatus)
+			if data.errString == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, data.errString)
+			}
+		})
+	}
+}
@@ -6,8 +6,11 @@ import (
 	models "github.com/caraml-dev/xp/management-service/models"
 	pagination "github.com/caraml-dev/xp/management-service/pagination"
 	mock "github.com/stretchr/testify/mock"
+	gorm "gorm.io/gorm"
 
 	services "github.com/caraml-dev/xp/management-service/services"
+
+	time "time"
 )
 
 // ExperimentHistoryService is an autogenerated mock type for the ExperimentHistoryService type
@@ -15,6 +18,20 @@ type ExperimentHistoryService struct {
 	mock.Mock
 }
 
+// CreateExperimentHistories provides a mock function with given fields: experiments
+func (_m *ExperimentHistoryService) CreateExperimentHistories(experiments []*models.Experiment) error {
+	ret := _m.Called(experiments)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*models.Experiment) error); ok {
+		r0 = rf(experiments)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateExperimentHistory provides a mock function with given fields: _a0
 func (_m *ExperimentHistoryService) CreateExperimentHistory(_a0 *models.Experiment) (*models.ExperimentHistory, error) {
 	ret := _m.Called(_a0)
@@ -84,6 +101,52 @@ func (_m *ExperimentHistoryService) GetExperimentHistory(experimentId int64, ver
 	return r0, r1
 }
 
+// GetExperimentVersion provides a mock function with given fields: projectId, experimentId, version
+func (_m *ExperimentHistoryService) GetExperimentVersion(projectId int64, experimentId int64, version int64) (*models.ExperimentHistory, error) {
+	ret := _m.Called(projectId, experimentId, version)
+
+	var r0 *models.ExperimentHistory
+	if rf, ok := ret.Get(0).(func(int64, int64, int64) *models.ExperimentHistory); ok {
+		r0 = rf(projectId, experimentId, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.ExperimentHistory)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, int64, int64) error); ok {
+		r1 = rf(projectId, experimentId, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListChangesByUser provides a mock function with given fields: projectId, user, from, to
+func (_m *ExperimentHistoryService) ListChangesByUser(projectId int64, user string, from time.Time, to time.Time) ([]*models.ExperimentHistory, error) {
+	ret := _m.Called(projectId, user, from, to)
+
+	var r0 []*models.ExperimentHistory
+	if rf, ok := ret.Get(0).(func(int64, string, time.Time, time.Time) []*models.ExperimentHistory); ok {
+		r0 = rf(projectId, user, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.ExperimentHistory)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, string, time.Time, time.Time) error); ok {
+		r1 = rf(projectId, user, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListExperimentHistory provides a mock function with given fields: experimentId, params
 func (_m *ExperimentHistoryService) ListExperimentHistory(experimentId int64, params services.ListExperimentHistoryParams) ([]*models.ExperimentHistory, *pagination.Paging, error) {
 	ret := _m.Called(experimentId, params)
@@ -115,3 +178,19 @@ func (_m *ExperimentHistoryService) ListExperimentHistory(experimentId int64, pa
 
 	return r0, r1, r2
 }
+
+// WithTransaction provides a mock function with given fields: tx
+func (_m *ExperimentHistoryService) WithTransaction(tx *gorm.DB) services.ExperimentHistoryService {
+	ret := _m.Called(tx)
+
+	var r0 services.ExperimentHistoryService
+	if rf, ok := ret.Get(0).(func(*gorm.DB) services.ExperimentHistoryService); ok {
+		r0 = rf(tx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(services.ExperimentHistoryService)
+		}
+	}
+
+	return r0
+}
@@ -3,11 +3,15 @@
 package mocks
 
 import (
+	json "encoding/json"
+
 	models "github.com/caraml-dev/xp/management-service/models"
 	pagination "github.com/caraml-dev/xp/management-service/pagination"
 	mock "github.com/stretchr/testify/mock"
 
 	services "github.com/caraml-dev/xp/management-service/services"
+
+	time "time"
 )
 
 // ExperimentService is an autogenerated mock type for the ExperimentService type
@@ -15,6 +19,64 @@ type ExperimentService struct {
 	mock.Mock
 }
 
+// AddTreatments provides a mock function with given fields: settings, experimentId, treatments
+func (_m *ExperimentService) AddTreatments(settings models.Settings, experimentId int64, treatments models.ExperimentTreatments) (*models.Experiment, error) {
+	ret := _m.Called(settings, experimentId, treatments)
+
+	var r0 *models.Experiment
+	if rf, ok := ret.Get(0).(func(models.Settings, int64, models.ExperimentTreatments) *models.Experiment); ok {
+		r0 = rf(settings, experimentId, treatments)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Experiment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Settings, int64, models.ExperimentTreatments) error); ok {
+		r1 = rf(settings, experimentId, treatments)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BulkUpdateTier provides a mock function with given fields: settings, experimentIds, newTier
+func (_m *ExperimentService) BulkUpdateTier(settings models.Settings, experimentIds []int64, newTier models.ExperimentTier) (services.BulkResult, error) {
+	ret := _m.Called(settings, experimentIds, newTier)
+
+	var r0 services.BulkResult
+	if rf, ok := ret.Get(0).(func(models.Settings, []int64, models.ExperimentTier) services.BulkResult); ok {
+		r0 = rf(settings, experimentIds, newTier)
+	} else {
+		r0 = ret.Get(0).(services.BulkResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Settings, []int64, models.ExperimentTier) error); ok {
+		r1 = rf(settings, experimentIds, newTier)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CancelRecurrence provides a mock function with given fields: projectId, experimentId
+func (_m *ExperimentService) CancelRecurrence(projectId int64, experimentId int64) error {
+	ret := _m.Called(projectId, experimentId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, int64) error); ok {
+		r0 = rf(projectId, experimentId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateExperiment provides a mock function with given fields: settings, expData
 func (_m *ExperimentService) CreateExperiment(settings models.Settings, expData services.CreateExperimentRequestBody) (*models.Experiment, error) {
 	ret := _m.Called(settings, expData)
@@ -66,6 +128,142 @@ func (_m *ExperimentService) EnableExperiment(settings models.Settings, experime
 	return r0
 }
 
+// ExportExperimentHistory provides a mock function with given fields: projectId, params
+func (_m *ExperimentService) ExportExperimentHistory(projectId int64, params services.ExportExperimentHistoryParams) ([]services.ExperimentHistoryExport, error) {
+	ret := _m.Called(projectId, params)
+
+	var r0 []services.ExperimentHistoryExport
+	if rf, ok := ret.Get(0).(func(int64, services.ExportExperimentHistoryParams) []services.ExperimentHistoryExport); ok {
+		r0 = rf(projectId, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]services.ExperimentHistoryExport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, services.ExportExperimentHistoryParams) error); ok {
+		r1 = rf(projectId, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportExperimentsCSV provides a mock function with given fields: projectId, params, pivotSegments
+func (_m *ExperimentService) ExportExperimentsCSV(projectId int64, params services.ListExperimentsParams, pivotSegments bool) ([]byte, error) {
+	ret := _m.Called(projectId, params, pivotSegments)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(int64, services.ListExperimentsParams, bool) []byte); ok {
+		r0 = rf(projectId, params, pivotSegments)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, services.ListExperimentsParams, bool) error); ok {
+		r1 = rf(projectId, params, pivotSegments)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindCoverageGaps provides a mock function with given fields: projectId, segment, tier, from, to
+func (_m *ExperimentService) FindCoverageGaps(projectId int64, segment models.ExperimentSegmentRaw, tier models.ExperimentTier, from time.Time, to time.Time) ([]services.TimeWindow, error) {
+	ret := _m.Called(projectId, segment, tier, from, to)
+
+	var r0 []services.TimeWindow
+	if rf, ok := ret.Get(0).(func(int64, models.ExperimentSegmentRaw, models.ExperimentTier, time.Time, time.Time) []services.TimeWindow); ok {
+		r0 = rf(projectId, segment, tier, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]services.TimeWindow)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, models.ExperimentSegmentRaw, models.ExperimentTier, time.Time, time.Time) error); ok {
+		r1 = rf(projectId, segment, tier, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindDuplicateExperiments provides a mock function with given fields: projectId
+func (_m *ExperimentService) FindDuplicateExperiments(projectId int64) ([][]*models.Experiment, error) {
+	ret := _m.Called(projectId)
+
+	var r0 [][]*models.Experiment
+	if rf, ok := ret.Get(0).(func(int64) [][]*models.Experiment); ok {
+		r0 = rf(projectId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]*models.Experiment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(projectId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSegmentIntersections provides a mock function with given fields: projectId, segment, tier
+func (_m *ExperimentService) FindSegmentIntersections(projectId int64, segment models.ExperimentSegmentRaw, tier models.ExperimentTier) ([]*models.Experiment, error) {
+	ret := _m.Called(projectId, segment, tier)
+
+	var r0 []*models.Experiment
+	if rf, ok := ret.Get(0).(func(int64, models.ExperimentSegmentRaw, models.ExperimentTier) []*models.Experiment); ok {
+		r0 = rf(projectId, segment, tier)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Experiment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, models.ExperimentSegmentRaw, models.ExperimentTier) error); ok {
+		r1 = rf(projectId, segment, tier)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetActiveExperimentLimitWarning provides a mock function with given fields: settings
+func (_m *ExperimentService) GetActiveExperimentLimitWarning(settings models.Settings) (string, error) {
+	ret := _m.Called(settings)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(models.Settings) string); ok {
+		r0 = rf(settings)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Settings) error); ok {
+		r1 = rf(settings)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDBRecord provides a mock function with given fields: projectId, experimentId
 func (_m *ExperimentService) GetDBRecord(projectId models.ID, experimentId models.ID) (*models.Experiment, error) {
 	ret := _m.Called(projectId, experimentId)
@@ -89,6 +287,29 @@ func (_m *ExperimentService) GetDBRecord(projectId models.ID, experimentId model
 	return r0, r1
 }
 
+// GetEffectiveSegment provides a mock function with given fields: projectId, experimentId
+func (_m *ExperimentService) GetEffectiveSegment(projectId int64, experimentId int64) (models.ExperimentSegmentRaw, error) {
+	ret := _m.Called(projectId, experimentId)
+
+	var r0 models.ExperimentSegmentRaw
+	if rf, ok := ret.Get(0).(func(int64, int64) models.ExperimentSegmentRaw); ok {
+		r0 = rf(projectId, experimentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(models.ExperimentSegmentRaw)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(projectId, experimentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetExperiment provides a mock function with given fields: projectId, experimentId
 func (_m *ExperimentService) GetExperiment(projectId int64, experimentId int64) (*models.Experiment, error) {
 	ret := _m.Called(projectId, experimentId)
@@ -112,6 +333,73 @@ func (_m *ExperimentService) GetExperiment(projectId int64, experimentId int64)
 	return r0, r1
 }
 
+// GetExperimentWithRawSegment provides a mock function with given fields: projectId, experimentId
+func (_m *ExperimentService) GetExperimentWithRawSegment(projectId int64, experimentId int64) (*services.ExperimentWithRawSegment, error) {
+	ret := _m.Called(projectId, experimentId)
+
+	var r0 *services.ExperimentWithRawSegment
+	if rf, ok := ret.Get(0).(func(int64, int64) *services.ExperimentWithRawSegment); ok {
+		r0 = rf(projectId, experimentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*services.ExperimentWithRawSegment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(projectId, experimentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRawStoredSegment provides a mock function with given fields: projectId, experimentId
+func (_m *ExperimentService) GetRawStoredSegment(projectId int64, experimentId int64) (json.RawMessage, error) {
+	ret := _m.Called(projectId, experimentId)
+
+	var r0 json.RawMessage
+	if rf, ok := ret.Get(0).(func(int64, int64) json.RawMessage); ok {
+		r0 = rf(projectId, experimentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(json.RawMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(projectId, experimentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSegmentCoverage provides a mock function with given fields: projectId, tier
+func (_m *ExperimentService) GetSegmentCoverage(projectId int64, tier models.ExperimentTier) (float64, error) {
+	ret := _m.Called(projectId, tier)
+
+	var r0 float64
+	if rf, ok := ret.Get(0).(func(int64, models.ExperimentTier) float64); ok {
+		r0 = rf(projectId, tier)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, models.ExperimentTier) error); ok {
+		r1 = rf(projectId, tier)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListAllExperiments provides a mock function with given fields: projectId, params
 func (_m *ExperimentService) ListAllExperiments(projectId models.ID, params services.ListExperimentsParams) ([]*models.Experiment, error) {
 	ret := _m.Called(projectId, params)
@@ -135,6 +423,52 @@ func (_m *ExperimentService) ListAllExperiments(projectId models.ID, params serv
 	return r0, r1
 }
 
+// ListByOrthogonalityRisk provides a mock function with given fields: projectId
+func (_m *ExperimentService) ListByOrthogonalityRisk(projectId int64) ([]services.ExperimentRisk, error) {
+	ret := _m.Called(projectId)
+
+	var r0 []services.ExperimentRisk
+	if rf, ok := ret.Get(0).(func(int64) []services.ExperimentRisk); ok {
+		r0 = rf(projectId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]services.ExperimentRisk)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(projectId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCompetingExperiments provides a mock function with given fields: projectId, segment, tier, startTime, endTime, includeWeak
+func (_m *ExperimentService) ListCompetingExperiments(projectId int64, segment models.ExperimentSegmentRaw, tier models.ExperimentTier, startTime time.Time, endTime time.Time, includeWeak bool) ([]*models.Experiment, error) {
+	ret := _m.Called(projectId, segment, tier, startTime, endTime, includeWeak)
+
+	var r0 []*models.Experiment
+	if rf, ok := ret.Get(0).(func(int64, models.ExperimentSegmentRaw, models.ExperimentTier, time.Time, time.Time, bool) []*models.Experiment); ok {
+		r0 = rf(projectId, segment, tier, startTime, endTime, includeWeak)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Experiment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, models.ExperimentSegmentRaw, models.ExperimentTier, time.Time, time.Time, bool) error); ok {
+		r1 = rf(projectId, segment, tier, startTime, endTime, includeWeak)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListExperiments provides a mock function with given fields: projectId, params
 func (_m *ExperimentService) ListExperiments(projectId int64, params services.ListExperimentsParams) ([]*models.Experiment, *pagination.Paging, error) {
 	ret := _m.Called(projectId, params)
@@ -167,36 +501,45 @@ func (_m *ExperimentService) ListExperiments(projectId int64, params services.Li
 	return r0, r1, r2
 }
 
-// RunCustomValidation provides a mock function with given fields: experiment, settings, context, operationType
-func (_m *ExperimentService) RunCustomValidation(experiment models.Experiment, settings models.Settings, context services.ValidationContext, operationType services.OperationType) error {
-	ret := _m.Called(experiment, settings, context, operationType)
+// ListExperimentsByExactSegment provides a mock function with given fields: projectId, segment, tier
+func (_m *ExperimentService) ListExperimentsByExactSegment(projectId int64, segment models.ExperimentSegmentRaw, tier models.ExperimentTier) ([]*models.Experiment, error) {
+	ret := _m.Called(projectId, segment, tier)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(models.Experiment, models.Settings, services.ValidationContext, services.OperationType) error); ok {
-		r0 = rf(experiment, settings, context, operationType)
+	var r0 []*models.Experiment
+	if rf, ok := ret.Get(0).(func(int64, models.ExperimentSegmentRaw, models.ExperimentTier) []*models.Experiment); ok {
+		r0 = rf(projectId, segment, tier)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Experiment)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, models.ExperimentSegmentRaw, models.ExperimentTier) error); ok {
+		r1 = rf(projectId, segment, tier)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// UpdateExperiment provides a mock function with given fields: settings, experimentId, expData
-func (_m *ExperimentService) UpdateExperiment(settings models.Settings, experimentId int64, expData services.UpdateExperimentRequestBody) (*models.Experiment, error) {
-	ret := _m.Called(settings, experimentId, expData)
+// ListExperimentsGroupedByStatus provides a mock function with given fields: projectId, params
+func (_m *ExperimentService) ListExperimentsGroupedByStatus(projectId int64, params services.ListExperimentsParams) (map[services.ExperimentStatusFriendly][]*models.Experiment, error) {
+	ret := _m.Called(projectId, params)
 
-	var r0 *models.Experiment
-	if rf, ok := ret.Get(0).(func(models.Settings, int64, services.UpdateExperimentRequestBody) *models.Experiment); ok {
-		r0 = rf(settings, experimentId, expData)
+	var r0 map[services.ExperimentStatusFriendly][]*models.Experiment
+	if rf, ok := ret.Get(0).(func(int64, services.ListExperimentsParams) map[services.ExperimentStatusFriendly][]*models.Experiment); ok {
+		r0 = rf(projectId, params)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Experiment)
+			r0 = ret.Get(0).(map[services.ExperimentStatusFriendly][]*models.Experiment)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(models.Settings, int64, services.UpdateExperimentRequestBody) error); ok {
-		r1 = rf(settings, experimentId, expData)
+	if rf, ok := ret.Get(1).(func(int64, services.ListExperimentsParams) error); ok {
+		r1 = rf(projectId, params)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -204,13 +547,560 @@ func (_m *ExperimentService) UpdateExperiment(settings models.Settings, experime
 	return r0, r1
 }
 
-// ValidatePairwiseExperimentOrthogonality provides a mock function with given fields: projectId, experiments, segmenters
-func (_m *ExperimentService) ValidatePairwiseExperimentOrthogonality(projectId int64, experiments []*models.Experiment, segmenters []string) error {
-	ret := _m.Called(projectId, experiments, segmenters)
+// ListExperimentsMultiProject provides a mock function with given fields: projectIds, params
+func (_m *ExperimentService) ListExperimentsMultiProject(projectIds []int64, params services.ListExperimentsParams) (map[int64][]*models.Experiment, error) {
+	ret := _m.Called(projectIds, params)
+
+	var r0 map[int64][]*models.Experiment
+	if rf, ok := ret.Get(0).(func([]int64, services.ListExperimentsParams) map[int64][]*models.Experiment); ok {
+		r0 = rf(projectIds, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int64][]*models.Experiment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]int64, services.ListExperimentsParams) error); ok {
+		r1 = rf(projectIds, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListExperimentsWithTreatmentChanges provides a mock function with given fields: projectId, since
+func (_m *ExperimentService) ListExperimentsWithTreatmentChanges(projectId int64, since time.Time) ([]services.TreatmentChange, error) {
+	ret := _m.Called(projectId, since)
+
+	var r0 []services.TreatmentChange
+	if rf, ok := ret.Get(0).(func(int64, time.Time) []services.TreatmentChange); ok {
+		r0 = rf(projectId, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]services.TreatmentChange)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, time.Time) error); ok {
+		r1 = rf(projectId, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockExperiments provides a mock function with given fields: projectId
+func (_m *ExperimentService) LockExperiments(projectId int64) error {
+	ret := _m.Called(projectId)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int64, []*models.Experiment, []string) error); ok {
-		r0 = rf(projectId, experiments, segmenters)
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(projectId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MatchExperiments provides a mock function with given fields: projectId, tier, unitSegment, at
+func (_m *ExperimentService) MatchExperiments(projectId int64, tier models.ExperimentTier, unitSegment map[string]string, at time.Time) ([]*models.Experiment, error) {
+	ret := _m.Called(projectId, tier, unitSegment, at)
+
+	var r0 []*models.Experiment
+	if rf, ok := ret.Get(0).(func(int64, models.ExperimentTier, map[string]string, time.Time) []*models.Experiment); ok {
+		r0 = rf(projectId, tier, unitSegment, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Experiment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, models.ExperimentTier, map[string]string, time.Time) error); ok {
+		r1 = rf(projectId, tier, unitSegment, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Now provides a mock function with given fields:
+func (_m *ExperimentService) Now() time.Time {
+	ret := _m.Called()
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func() time.Time); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	return r0
+}
+
+// PauseAllExperiments provides a mock function with given fields: projectId
+func (_m *ExperimentService) PauseAllExperiments(projectId int64) (services.PauseToken, error) {
+	ret := _m.Called(projectId)
+
+	var r0 services.PauseToken
+	if rf, ok := ret.Get(0).(func(int64) services.PauseToken); ok {
+		r0 = rf(projectId)
+	} else {
+		r0 = ret.Get(0).(services.PauseToken)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(projectId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PreviewAssignmentDistribution provides a mock function with given fields: projectId, experimentId
+func (_m *ExperimentService) PreviewAssignmentDistribution(projectId int64, experimentId int64) (map[string]float64, error) {
+	ret := _m.Called(projectId, experimentId)
+
+	var r0 map[string]float64
+	if rf, ok := ret.Get(0).(func(int64, int64) map[string]float64); ok {
+		r0 = rf(projectId, experimentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]float64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(projectId, experimentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PreviewProtoMessage provides a mock function with given fields: projectId, experimentId
+func (_m *ExperimentService) PreviewProtoMessage(projectId int64, experimentId int64) ([]byte, error) {
+	ret := _m.Called(projectId, experimentId)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(int64, int64) []byte); ok {
+		r0 = rf(projectId, experimentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(projectId, experimentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PreviewSettingsImpact provides a mock function with given fields: projectId, proposedSettings
+func (_m *ExperimentService) PreviewSettingsImpact(projectId int64, proposedSettings models.Settings) (services.SettingsImpactReport, error) {
+	ret := _m.Called(projectId, proposedSettings)
+
+	var r0 services.SettingsImpactReport
+	if rf, ok := ret.Get(0).(func(int64, models.Settings) services.SettingsImpactReport); ok {
+		r0 = rf(projectId, proposedSettings)
+	} else {
+		r0 = ret.Get(0).(services.SettingsImpactReport)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, models.Settings) error); ok {
+		r1 = rf(projectId, proposedSettings)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PreviewUpdate provides a mock function with given fields: settings, experimentId, expData
+func (_m *ExperimentService) PreviewUpdate(settings models.Settings, experimentId int64, expData services.UpdateExperimentRequestBody) (*services.ExperimentDiff, services.ValidationReport, error) {
+	ret := _m.Called(settings, experimentId, expData)
+
+	var r0 *services.ExperimentDiff
+	if rf, ok := ret.Get(0).(func(models.Settings, int64, services.UpdateExperimentRequestBody) *services.ExperimentDiff); ok {
+		r0 = rf(settings, experimentId, expData)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*services.ExperimentDiff)
+		}
+	}
+
+	var r1 services.ValidationReport
+	if rf, ok := ret.Get(1).(func(models.Settings, int64, services.UpdateExperimentRequestBody) services.ValidationReport); ok {
+		r1 = rf(settings, experimentId, expData)
+	} else {
+		r1 = ret.Get(1).(services.ValidationReport)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(models.Settings, int64, services.UpdateExperimentRequestBody) error); ok {
+		r2 = rf(settings, experimentId, expData)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// PromoteExperiment provides a mock function with given fields: settings, experimentId
+func (_m *ExperimentService) PromoteExperiment(settings models.Settings, experimentId int64) (*models.Experiment, error) {
+	ret := _m.Called(settings, experimentId)
+
+	var r0 *models.Experiment
+	if rf, ok := ret.Get(0).(func(models.Settings, int64) *models.Experiment); ok {
+		r0 = rf(settings, experimentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Experiment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Settings, int64) error); ok {
+		r1 = rf(settings, experimentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReconcileOrthogonality provides a mock function with given fields: settings, autoDisable
+func (_m *ExperimentService) ReconcileOrthogonality(settings models.Settings, autoDisable bool) (services.ReconcileReport, error) {
+	ret := _m.Called(settings, autoDisable)
+
+	var r0 services.ReconcileReport
+	if rf, ok := ret.Get(0).(func(models.Settings, bool) services.ReconcileReport); ok {
+		r0 = rf(settings, autoDisable)
+	} else {
+		r0 = ret.Get(0).(services.ReconcileReport)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Settings, bool) error); ok {
+		r1 = rf(settings, autoDisable)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegisterValidator provides a mock function with given fields: name, fn
+func (_m *ExperimentService) RegisterValidator(name string, fn services.ExperimentValidatorFunc) {
+	_m.Called(name, fn)
+}
+
+// RepublishAllExperiments provides a mock function with given fields: projectId
+func (_m *ExperimentService) RepublishAllExperiments(projectId int64) error {
+	ret := _m.Called(projectId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(projectId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RepublishExperiment provides a mock function with given fields: projectId, experimentId
+func (_m *ExperimentService) RepublishExperiment(projectId int64, experimentId int64) error {
+	ret := _m.Called(projectId, experimentId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, int64) error); ok {
+		r0 = rf(projectId, experimentId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResolveOverlap provides a mock function with given fields: projectId, segment, tier, at
+func (_m *ExperimentService) ResolveOverlap(projectId int64, segment map[string]string, tier models.ExperimentTier, at time.Time) (*models.Experiment, error) {
+	ret := _m.Called(projectId, segment, tier, at)
+
+	var r0 *models.Experiment
+	if rf, ok := ret.Get(0).(func(int64, map[string]string, models.ExperimentTier, time.Time) *models.Experiment); ok {
+		r0 = rf(projectId, segment, tier, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Experiment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, map[string]string, models.ExperimentTier, time.Time) error); ok {
+		r1 = rf(projectId, segment, tier, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResumeExperiments provides a mock function with given fields: settings, token
+func (_m *ExperimentService) ResumeExperiments(settings models.Settings, token services.PauseToken) (services.BulkResult, error) {
+	ret := _m.Called(settings, token)
+
+	var r0 services.BulkResult
+	if rf, ok := ret.Get(0).(func(models.Settings, services.PauseToken) services.BulkResult); ok {
+		r0 = rf(settings, token)
+	} else {
+		r0 = ret.Get(0).(services.BulkResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Settings, services.PauseToken) error); ok {
+		r1 = rf(settings, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunCustomValidation provides a mock function with given fields: experiment, settings, context, operationType
+func (_m *ExperimentService) RunCustomValidation(experiment models.Experiment, settings models.Settings, context services.ValidationContext, operationType services.OperationType) error {
+	ret := _m.Called(experiment, settings, context, operationType)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.Experiment, models.Settings, services.ValidationContext, services.OperationType) error); ok {
+		r0 = rf(experiment, settings, context, operationType)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetValidationConcurrency provides a mock function with given fields: limit
+func (_m *ExperimentService) SetValidationConcurrency(limit int) {
+	_m.Called(limit)
+}
+
+// SimulateOrthogonalityWithSegmenters provides a mock function with given fields: projectId, segmenters
+func (_m *ExperimentService) SimulateOrthogonalityWithSegmenters(projectId int64, segmenters []string) (services.ValidationReport, error) {
+	ret := _m.Called(projectId, segmenters)
+
+	var r0 services.ValidationReport
+	if rf, ok := ret.Get(0).(func(int64, []string) services.ValidationReport); ok {
+		r0 = rf(projectId, segmenters)
+	} else {
+		r0 = ret.Get(0).(services.ValidationReport)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, []string) error); ok {
+		r1 = rf(projectId, segmenters)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Subscribe provides a mock function with given fields:
+func (_m *ExperimentService) Subscribe() (<-chan services.ExperimentEvent, func()) {
+	ret := _m.Called()
+
+	var r0 <-chan services.ExperimentEvent
+	if rf, ok := ret.Get(0).(func() <-chan services.ExperimentEvent); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan services.ExperimentEvent)
+		}
+	}
+
+	var r1 func()
+	if rf, ok := ret.Get(1).(func() func()); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	return r0, r1
+}
+
+// SwapExperimentSchedules provides a mock function with given fields: settings, experimentIdA, experimentIdB
+func (_m *ExperimentService) SwapExperimentSchedules(settings models.Settings, experimentIdA int64, experimentIdB int64) (*models.Experiment, *models.Experiment, error) {
+	ret := _m.Called(settings, experimentIdA, experimentIdB)
+
+	var r0 *models.Experiment
+	if rf, ok := ret.Get(0).(func(models.Settings, int64, int64) *models.Experiment); ok {
+		r0 = rf(settings, experimentIdA, experimentIdB)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Experiment)
+		}
+	}
+
+	var r1 *models.Experiment
+	if rf, ok := ret.Get(1).(func(models.Settings, int64, int64) *models.Experiment); ok {
+		r1 = rf(settings, experimentIdA, experimentIdB)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.Experiment)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(models.Settings, int64, int64) error); ok {
+		r2 = rf(settings, experimentIdA, experimentIdB)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SweepRecurringExperiments provides a mock function with given fields: settings
+func (_m *ExperimentService) SweepRecurringExperiments(settings models.Settings) (services.BulkResult, error) {
+	ret := _m.Called(settings)
+
+	var r0 services.BulkResult
+	if rf, ok := ret.Get(0).(func(models.Settings) services.BulkResult); ok {
+		r0 = rf(settings)
+	} else {
+		r0 = ret.Get(0).(services.BulkResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Settings) error); ok {
+		r1 = rf(settings)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnlockExperiments provides a mock function with given fields: projectId
+func (_m *ExperimentService) UnlockExperiments(projectId int64) error {
+	ret := _m.Called(projectId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(projectId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateExperiment provides a mock function with given fields: settings, experimentId, expData
+func (_m *ExperimentService) UpdateExperiment(settings models.Settings, experimentId int64, expData services.UpdateExperimentRequestBody) (*models.Experiment, error) {
+	ret := _m.Called(settings, experimentId, expData)
+
+	var r0 *models.Experiment
+	if rf, ok := ret.Get(0).(func(models.Settings, int64, services.UpdateExperimentRequestBody) *models.Experiment); ok {
+		r0 = rf(settings, experimentId, expData)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Experiment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Settings, int64, services.UpdateExperimentRequestBody) error); ok {
+		r1 = rf(settings, experimentId, expData)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateAllExperiments provides a mock function with given fields: projectId
+func (_m *ExperimentService) ValidateAllExperiments(projectId int64) (services.ProjectHealthReport, error) {
+	ret := _m.Called(projectId)
+
+	var r0 services.ProjectHealthReport
+	if rf, ok := ret.Get(0).(func(int64) services.ProjectHealthReport); ok {
+		r0 = rf(projectId)
+	} else {
+		r0 = ret.Get(0).(services.ProjectHealthReport)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(projectId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateEnabledValidators provides a mock function with given fields: names
+func (_m *ExperimentService) ValidateEnabledValidators(names []string) error {
+	ret := _m.Called(names)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string) error); ok {
+		r0 = rf(names)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ValidateExperimentAgainstSettings provides a mock function with given fields: experiment, settings
+func (_m *ExperimentService) ValidateExperimentAgainstSettings(experiment models.Experiment, settings models.Settings) (services.ValidationReport, error) {
+	ret := _m.Called(experiment, settings)
+
+	var r0 services.ValidationReport
+	if rf, ok := ret.Get(0).(func(models.Experiment, models.Settings) services.ValidationReport); ok {
+		r0 = rf(experiment, settings)
+	} else {
+		r0 = ret.Get(0).(services.ValidationReport)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Experiment, models.Settings) error); ok {
+		r1 = rf(experiment, settings)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidatePairwiseExperimentOrthogonality provides a mock function with given fields: projectId, experiments, segmenters, defaultSegment
+func (_m *ExperimentService) ValidatePairwiseExperimentOrthogonality(projectId int64, experiments []*models.Experiment, segmenters []string, defaultSegment models.ExperimentSegmentRaw) error {
+	ret := _m.Called(projectId, experiments, segmenters, defaultSegment)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, []*models.Experiment, []string, models.ExperimentSegmentRaw) error); ok {
+		r0 = rf(projectId, experiments, segmenters, defaultSegment)
 	} else {
 		r0 = ret.Error(0)
 	}
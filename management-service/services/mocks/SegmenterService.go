@@ -100,6 +100,29 @@ func (_m *SegmenterService) GetDBRecord(projectId models.ID, name string) (*mode
 	return r0, r1
 }
 
+// GetExperimentSegmentWarnings provides a mock function with given fields: projectId, userSegmenters, expSegment
+func (_m *SegmenterService) GetExperimentSegmentWarnings(projectId int64, userSegmenters []string, expSegment models.ExperimentSegmentRaw) ([]string, error) {
+	ret := _m.Called(projectId, userSegmenters, expSegment)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(int64, []string, models.ExperimentSegmentRaw) []string); ok {
+		r0 = rf(projectId, userSegmenters, expSegment)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, []string, models.ExperimentSegmentRaw) error); ok {
+		r1 = rf(projectId, userSegmenters, expSegment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFormattedSegmenters provides a mock function with given fields: projectId, expSegment
 func (_m *SegmenterService) GetFormattedSegmenters(projectId int64, expSegment models.ExperimentSegmentRaw) (map[string]*[]interface{}, error) {
 	ret := _m.Called(projectId, expSegment)
@@ -238,6 +261,29 @@ func (_m *SegmenterService) ListSegmenters(projectId int64, params services.List
 	return r0, r1
 }
 
+// SuggestSegmentValues provides a mock function with given fields: projectId, segmenterName, prefix, limit
+func (_m *SegmenterService) SuggestSegmentValues(projectId int64, segmenterName string, prefix string, limit int) ([]string, error) {
+	ret := _m.Called(projectId, segmenterName, prefix, limit)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(int64, string, string, int) []string); ok {
+		r0 = rf(projectId, segmenterName, prefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, string, string, int) error); ok {
+		r1 = rf(projectId, segmenterName, prefix, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateCustomSegmenter provides a mock function with given fields: projectId, name, customSegmenterData
 func (_m *SegmenterService) UpdateCustomSegmenter(projectId int64, name string, customSegmenterData services.UpdateCustomSegmenterRequestBody) (*models.CustomSegmenter, error) {
 	ret := _m.Called(projectId, name, customSegmenterData)
@@ -3,6 +3,7 @@
 package mocks
 
 import (
+	models "github.com/caraml-dev/xp/management-service/models"
 	services "github.com/caraml-dev/xp/management-service/services"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,6 +13,20 @@ type ValidationService struct {
 	mock.Mock
 }
 
+// CheckValidationUrl provides a mock function with given fields: validationUrl
+func (_m *ValidationService) CheckValidationUrl(validationUrl string) error {
+	ret := _m.Called(validationUrl)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(validationUrl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Validate provides a mock function with given fields: data
 func (_m *ValidationService) Validate(data interface{}) error {
 	ret := _m.Called(data)
@@ -53,3 +68,17 @@ func (_m *ValidationService) ValidateWithExternalUrl(reqBody []byte, validationU
 
 	return r0
 }
+
+// WaitForValidationUrlRateLimit provides a mock function with given fields: projectId, rateLimit
+func (_m *ValidationService) WaitForValidationUrlRateLimit(projectId int64, rateLimit models.ValidationUrlRateLimit) error {
+	ret := _m.Called(projectId, rateLimit)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, models.ValidationUrlRateLimit) error); ok {
+		r0 = rf(projectId, rateLimit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
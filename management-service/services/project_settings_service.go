@@ -1,6 +1,8 @@
 package services
 
 import (
+	"encoding/json"
+	"regexp"
 	"time"
 
 	"github.com/golang-collections/collections/set"
@@ -14,7 +16,31 @@ import (
 
 const PASSKEY_LENGTH = 32
 
+// ExperimentationConfigRequestBody holds the optional settings of the project's experiments. The settings that are not
+// given when the project settings are updated keep their current values.
+type ExperimentationConfigRequestBody struct {
+	TreatmentConfigDefaults        json.RawMessage                `json:"treatment_config_defaults,omitempty"`
+	OrthogonalityExemptSegmenters  *[]string                      `json:"orthogonality_exempt_segmenters,omitempty"`
+	EnabledValidators              *[]string                      `json:"enabled_validators,omitempty"`
+	DefaultSegment                 *models.ExperimentSegmentRaw   `json:"default_segment,omitempty"`
+	BlockOrphanedOverrides         *bool                          `json:"block_orphaned_overrides,omitempty"`
+	ValidationUrlRateLimit         *models.ValidationUrlRateLimit `json:"validation_url_rate_limit,omitempty"`
+	OrthogonalityLookaheadSeconds  *int                           `json:"orthogonality_lookahead_seconds,omitempty"`
+	RequiredSegmenterCombinations  *[][]string                    `json:"required_segmenter_combinations,omitempty"`
+	TypedSegmentStorage            *bool                          `json:"typed_segment_storage,omitempty"`
+	TreatmentNamePattern           *string                        `json:"treatment_name_pattern,omitempty"`
+	SoftMaxActiveExperiments       *int                           `json:"soft_max_active_experiments,omitempty"`
+	HardMaxActiveExperiments       *int                           `json:"hard_max_active_experiments,omitempty"`
+	PriorityOverlapResolution      *bool                          `json:"priority_overlap_resolution,omitempty"`
+	BlockDuplicateTreatmentConfigs *bool                          `json:"block_duplicate_treatment_configs,omitempty"`
+	DefaultTier                    *models.ExperimentTier         `json:"default_tier,omitempty"`
+	DefaultType                    *models.ExperimentType         `json:"default_type,omitempty"`
+	BlockExhaustiveSegmentValues   *bool                          `json:"block_exhaustive_segment_values,omitempty"`
+	MaxTreatmentConfigBytes        *int                           `json:"max_treatment_config_bytes,omitempty"`
+}
+
 type CreateProjectSettingsRequestBody struct {
+	ExperimentationConfigRequestBody
 	EnableS2idClustering *bool                    `json:"enable_s2id_clustering,omitempty"`
 	RandomizationKey     string                   `json:"randomization_key" validate:"required,notBlank"`
 	Segmenters           models.ProjectSegmenters `json:"segmenters" validate:"required"`
@@ -24,6 +50,7 @@ type CreateProjectSettingsRequestBody struct {
 }
 
 type UpdateProjectSettingsRequestBody struct {
+	ExperimentationConfigRequestBody
 	EnableS2idClustering *bool                    `json:"enable_s2id_clustering,omitempty"`
 	RandomizationKey     string                   `json:"randomization_key" validate:"required,notBlank"`
 	Segmenters           models.ProjectSegmenters `json:"segmenters" validate:"required,notBlank"`
@@ -123,6 +150,9 @@ func (svc *projectSettingsService) CreateProjectSettings(
 	if err != nil {
 		return nil, errors.Newf(errors.BadInput, err.Error())
 	}
+	if err = svc.validateValidationUrl(settings.ValidationUrl); err != nil {
+		return nil, err
+	}
 
 	// Validate segmenter are recognized and experiment variable mapping are accepted as system allowed
 	err = svc.services.SegmenterService.ValidateExperimentVariables(projectId, settings.Segmenters)
@@ -166,6 +196,10 @@ func (svc *projectSettingsService) CreateProjectSettings(
 	if settings.EnableS2idClustering != nil {
 		settingsRecord.Config.S2IDClusteringEnabled = *(settings.EnableS2idClustering)
 	}
+	settings.ExperimentationConfigRequestBody.applyTo(settingsRecord.Config)
+	if err = svc.validateExperimentationConfig(projectId, settingsRecord.Config); err != nil {
+		return nil, err
+	}
 
 	// Save to DB
 	dbRecord, err := svc.save(settingsRecord)
@@ -198,6 +232,9 @@ func (svc *projectSettingsService) UpdateProjectSettings(
 	if err != nil {
 		return nil, errors.Newf(errors.BadInput, err.Error())
 	}
+	if err = svc.validateValidationUrl(settings.ValidationUrl); err != nil {
+		return nil, err
+	}
 
 	// Validate segmenter are recognized and experiment variable mapping are accepted as system allowed
 	err = svc.services.SegmenterService.ValidateExperimentVariables(projectId, settings.Segmenters)
@@ -223,13 +260,8 @@ func (svc *projectSettingsService) UpdateProjectSettings(
 		return nil, err
 	}
 
-	// Verify pairwise orthogonality checks are valid for all experiments
-	err = svc.validateProjectSettingsUpdate(projectId, dbRecord.Config.Segmenters.Names, settings.Segmenters.Names)
-	if err != nil {
-		return nil, err
-	}
-
-	// Set the configurable fields
+	// Set the configurable fields, keeping the current config to validate the changes against
+	currentConfig := *dbRecord.Config
 	if settings.EnableS2idClustering != nil {
 		dbRecord.Config.S2IDClusteringEnabled = *(settings.EnableS2idClustering)
 	}
@@ -237,9 +269,32 @@ func (svc *projectSettingsService) UpdateProjectSettings(
 	dbRecord.Config.Segmenters = settings.Segmenters
 	dbRecord.TreatmentSchema = settings.TreatmentSchema
 	dbRecord.ValidationUrl = settings.ValidationUrl
+	settings.ExperimentationConfigRequestBody.applyTo(dbRecord.Config)
+	if err = svc.validateExperimentationConfig(projectId, dbRecord.Config); err != nil {
+		return nil, err
+	}
 
-	// Save to the DB
-	dbRecord, err = svc.save(dbRecord)
+	// Verify pairwise orthogonality checks are valid for all experiments
+	err = svc.validateProjectSettingsUpdate(projectId, &currentConfig, dbRecord.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save to the DB, keeping the project's experiments lock as it is stored, as it is only changed by locking and
+	// unlocking the experiments. The stored settings are locked for the update, so that a concurrent change of the lock
+	// is either seen here or applied after the save.
+	err = svc.query().Transaction(func(tx *gorm.DB) error {
+		var current models.Settings
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("project_id = ?", projectId).
+			First(&current).Error
+		if err != nil {
+			return err
+		}
+		dbRecord.Config.ExperimentsLocked = current.Config != nil && current.Config.ExperimentsLocked
+		dbRecord, err = (&projectSettingsService{services: svc.services, db: tx}).save(dbRecord)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -278,38 +333,205 @@ func (svc *projectSettingsService) save(settings *models.Settings) (*models.Sett
 	return svc.GetDBRecord(settings.ProjectID)
 }
 
+// validateValidationUrl checks that the validation url, if given, is allowed, so that the settings cannot be saved with
+// a url that the requests would be refused to
+func (svc *projectSettingsService) validateValidationUrl(validationUrl *string) error {
+	if validationUrl == nil {
+		return nil
+	}
+	return svc.services.ValidationService.CheckValidationUrl(*validationUrl)
+}
+
 func (svc *projectSettingsService) validateProjectSettingsUpdate(
 	projectId int64,
-	currentSegmenters []string,
-	updatedSegmenters []string,
+	currentConfig *models.ExperimentationConfig,
+	updatedConfig *models.ExperimentationConfig,
 ) error {
-	// Perform orthogonality checks when there are removed segmenter(s)
-	currentSegmentersSet := utils.StringSliceToSet(currentSegmenters)
-	updatedSegmentersSet := utils.StringSliceToSet(updatedSegmenters)
-	hasSetDifferences := currentSegmentersSet.Difference(updatedSegmentersSet)
+	currentSegmenters := currentConfig.Segmenters.Names
+	updatedSegmenters := updatedConfig.Segmenters.Names
+	// Perform orthogonality checks when there are segmenter(s) that are no longer checked, i.e., that are removed or
+	// newly exempted, as the experiments may then overlap on the remaining segmenters
+	orthogonalitySegmenters := getOrthogonalitySegmenters(updatedSegmenters, updatedConfig.OrthogonalityExemptSegmenters)
+	uncheckedSegmenters := utils.StringSliceToSet(
+		getOrthogonalitySegmenters(currentSegmenters, currentConfig.OrthogonalityExemptSegmenters),
+	).Difference(utils.StringSliceToSet(orthogonalitySegmenters))
+	removedSegmenters := utils.StringSliceToSet(currentSegmenters).Difference(utils.StringSliceToSet(updatedSegmenters))
 
 	status := models.ExperimentStatusActive
 	startTime := time.Now()
 	endTime := time.Now().Add(855360 * time.Hour)
 	listExpParams := ListExperimentsParams{StartTime: &startTime, EndTime: &endTime, Status: &status}
 
-	if hasSetDifferences.Len() > 0 {
+	if uncheckedSegmenters.Len() > 0 || removedSegmenters.Len() > 0 {
 		id := models.ID(projectId)
 		exps, err := svc.services.ExperimentService.ListAllExperiments(id, listExpParams)
 		if err != nil {
 			return err
 		}
 
-		err = svc.services.ExperimentService.ValidatePairwiseExperimentOrthogonality(projectId, exps, updatedSegmenters)
-		if err != nil {
+		if uncheckedSegmenters.Len() > 0 {
+			err = svc.services.ExperimentService.ValidatePairwiseExperimentOrthogonality(
+				projectId,
+				exps,
+				orthogonalitySegmenters,
+				updatedConfig.DefaultSegment,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		if removedSegmenters.Len() > 0 {
+			// Check if the set of updated segmenters contains all the segments specified by all the experiments
+			err = svc.services.ExperimentService.ValidateProjectExperimentSegmentersExist(projectId, exps, updatedSegmenters)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyTo sets the settings that are given on the config
+func (body ExperimentationConfigRequestBody) applyTo(config *models.ExperimentationConfig) {
+	if body.TreatmentConfigDefaults != nil {
+		config.TreatmentConfigDefaults = body.TreatmentConfigDefaults
+	}
+	if body.OrthogonalityExemptSegmenters != nil {
+		config.OrthogonalityExemptSegmenters = *body.OrthogonalityExemptSegmenters
+	}
+	if body.EnabledValidators != nil {
+		config.EnabledValidators = *body.EnabledValidators
+	}
+	if body.DefaultSegment != nil {
+		config.DefaultSegment = *body.DefaultSegment
+	}
+	if body.BlockOrphanedOverrides != nil {
+		config.BlockOrphanedOverrides = *body.BlockOrphanedOverrides
+	}
+	if body.ValidationUrlRateLimit != nil {
+		config.ValidationUrlRateLimit = body.ValidationUrlRateLimit
+	}
+	if body.OrthogonalityLookaheadSeconds != nil {
+		config.OrthogonalityLookahead = time.Duration(*body.OrthogonalityLookaheadSeconds) * time.Second
+	}
+	if body.RequiredSegmenterCombinations != nil {
+		config.RequiredSegmenterCombinations = *body.RequiredSegmenterCombinations
+	}
+	if body.TypedSegmentStorage != nil {
+		config.TypedSegmentStorage = *body.TypedSegmentStorage
+	}
+	if body.TreatmentNamePattern != nil {
+		config.TreatmentNamePattern = *body.TreatmentNamePattern
+	}
+	if body.SoftMaxActiveExperiments != nil {
+		config.SoftMaxActiveExperiments = *body.SoftMaxActiveExperiments
+	}
+	if body.HardMaxActiveExperiments != nil {
+		config.HardMaxActiveExperiments = *body.HardMaxActiveExperiments
+	}
+	if body.PriorityOverlapResolution != nil {
+		config.PriorityOverlapResolution = *body.PriorityOverlapResolution
+	}
+	if body.BlockDuplicateTreatmentConfigs != nil {
+		config.BlockDuplicateTreatmentConfigs = *body.BlockDuplicateTreatmentConfigs
+	}
+	if body.DefaultTier != nil {
+		config.DefaultTier = *body.DefaultTier
+	}
+	if body.DefaultType != nil {
+		config.DefaultType = *body.DefaultType
+	}
+	if body.BlockExhaustiveSegmentValues != nil {
+		config.BlockExhaustiveSegmentValues = *body.BlockExhaustiveSegmentValues
+	}
+	if body.MaxTreatmentConfigBytes != nil {
+		config.MaxTreatmentConfigBytes = *body.MaxTreatmentConfigBytes
+	}
+}
+
+// validateExperimentationConfig checks that the settings of the project's experiments are consistent with each other
+func (svc *projectSettingsService) validateExperimentationConfig(
+	projectId int64,
+	config *models.ExperimentationConfig,
+) error {
+	segmenters := utils.StringSliceToSet(config.Segmenters.Names)
+	for _, segmenter := range config.OrthogonalityExemptSegmenters {
+		if !segmenters.Has(segmenter) {
+			return errors.Newf(errors.BadInput, "orthogonality exempt segmenter %s is not a segmenter of the project", segmenter)
+		}
+	}
+	if len(config.TreatmentConfigDefaults) > 0 {
+		var configDefaults map[string]interface{}
+		if err := json.Unmarshal(config.TreatmentConfigDefaults, &configDefaults); err != nil || configDefaults == nil {
+			return errors.Newf(errors.BadInput, "treatment config defaults must be a JSON object")
+		}
+	}
+	if len(config.EnabledValidators) > 0 {
+		if err := svc.services.ExperimentService.ValidateEnabledValidators(config.EnabledValidators); err != nil {
 			return err
 		}
-		// Check if the set of updated segmenters contains all the segments specified by all the experiments
-		err = svc.services.ExperimentService.ValidateProjectExperimentSegmentersExist(projectId, exps, updatedSegmenters)
+	}
+	if len(config.DefaultSegment) > 0 {
+		for name, values := range config.DefaultSegment {
+			if !segmenters.Has(name) {
+				return errors.Newf(errors.BadInput, "default segmenter %s is not a segmenter of the project", name)
+			}
+			if _, ok := values.([]interface{}); !ok {
+				return errors.Newf(errors.BadInput, "default segment values of %s must be a list", name)
+			}
+		}
+		segmenterTypes, err := svc.services.SegmenterService.GetSegmenterTypes(projectId)
 		if err != nil {
 			return err
 		}
+		if _, err := config.DefaultSegment.ToStorageSchema(segmenterTypes); err != nil {
+			return errors.Newf(errors.BadInput, err.Error())
+		}
+	}
+	if rateLimit := config.ValidationUrlRateLimit; rateLimit != nil {
+		if rateLimit.RequestsPerSecond <= 0 {
+			return errors.Newf(errors.BadInput, "validation url rate limit requests per second must be positive")
+		}
+		if rateLimit.Burst < 1 {
+			return errors.Newf(errors.BadInput, "validation url rate limit burst must be at least 1")
+		}
+		if rateLimit.MaxWaitMillis < 0 {
+			return errors.Newf(errors.BadInput, "validation url rate limit max wait millis must not be negative")
+		}
+	}
+	if config.OrthogonalityLookahead < 0 {
+		return errors.Newf(errors.BadInput, "orthogonality lookahead must not be negative")
+	}
+	for _, combination := range config.RequiredSegmenterCombinations {
+		for _, segmenter := range combination {
+			if !segmenters.Has(segmenter) {
+				return errors.Newf(errors.BadInput, "required segmenter combination segmenter %s is not a segmenter of the project", segmenter)
+			}
+		}
+	}
+	if _, err := regexp.Compile(config.TreatmentNamePattern); err != nil {
+		return errors.Newf(errors.BadInput, "treatment name pattern is not a valid regular expression: %s", err.Error())
+	}
+	if config.SoftMaxActiveExperiments < 0 || config.HardMaxActiveExperiments < 0 {
+		return errors.Newf(errors.BadInput, "max active experiments must not be negative")
+	}
+	if config.SoftMaxActiveExperiments > 0 && config.HardMaxActiveExperiments > 0 &&
+		config.HardMaxActiveExperiments < config.SoftMaxActiveExperiments {
+		return errors.Newf(errors.BadInput, "hard max active experiments must not be less than the soft max active experiments")
+	}
+	switch config.DefaultTier {
+	case "", models.ExperimentTierDefault, models.ExperimentTierOverride:
+	default:
+		return errors.Newf(errors.BadInput, "unknown default experiment tier: %s", config.DefaultTier)
+	}
+	switch config.DefaultType {
+	case "", models.ExperimentTypeAB, models.ExperimentTypeSwitchback:
+	default:
+		return errors.Newf(errors.BadInput, "unknown default experiment type: %s", config.DefaultType)
+	}
+	if config.MaxTreatmentConfigBytes < 0 {
+		return errors.Newf(errors.BadInput, "max treatment config bytes must not be negative")
 	}
-
 	return nil
 }
@@ -3,6 +3,7 @@
 package services_test
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
 
+	"github.com/caraml-dev/xp/common/api/schema"
 	_segmenters "github.com/caraml-dev/xp/common/segmenters"
 	"github.com/caraml-dev/xp/management-service/errors"
 	tu "github.com/caraml-dev/xp/management-service/internal/testutils"
@@ -19,9 +21,13 @@ import (
 	"github.com/caraml-dev/xp/management-service/services/mocks"
 )
 
+// blockedSettingsValidationUrl is a validation url that is not allowed, as its host is blocked
+const blockedSettingsValidationUrl = "https://169.254.169.254/validate"
+
 type ProjectSettingsServiceTestSuite struct {
 	suite.Suite
 	services.ProjectSettingsService
+	DB                      *gorm.DB
 	CleanUpFunc             func()
 	ProjectSettings         []models.Settings
 	SegmenterConfigurations []*_segmenters.SegmenterConfiguration
@@ -36,6 +42,7 @@ func (s *ProjectSettingsServiceTestSuite) SetupSuite() {
 		s.Suite.T().Fatalf("Could not create test DB: %v", err)
 	}
 	s.CleanUpFunc = cleanup
+	s.DB = db
 
 	s.SegmenterConfigurations = []*_segmenters.SegmenterConfiguration{
 		{
@@ -64,6 +71,7 @@ func (s *ProjectSettingsServiceTestSuite) SetupSuite() {
 			int64(1),
 			mock.Anything,
 			[]string{"seg1"},
+			mock.Anything,
 		).
 		Return(nil)
 	expSvc.
@@ -80,6 +88,25 @@ func (s *ProjectSettingsServiceTestSuite) SetupSuite() {
 			),
 		)
 
+	expSvc.
+		On("ListAllExperiments",
+			models.ID(5),
+			mock.Anything,
+		).
+		Return([]*models.Experiment{}, nil)
+	expSvc.
+		On("ValidatePairwiseExperimentOrthogonality",
+			int64(5),
+			mock.Anything,
+			[]string{},
+			mock.Anything,
+		).
+		Return(errors.Newf(errors.BadInput, "experiments overlap on all the segmenters"))
+	expSvc.On("ValidateEnabledValidators", []string{"custom-validator"}).Return(nil)
+	expSvc.
+		On("ValidateEnabledValidators", []string{"unregistered-validator"}).
+		Return(errors.Newf(errors.BadInput, "validator unregistered-validator is not registered"))
+
 	// Init mock segmenter service
 	segmenterSvc := &mocks.SegmenterService{}
 	segmenterSvc.
@@ -115,6 +142,13 @@ func (s *ProjectSettingsServiceTestSuite) SetupSuite() {
 	segmenterSvc.On("ValidateExperimentVariables", int64(3), mock.Anything).Return(nil)
 	segmenterSvc.On("ValidateExperimentVariables", int64(2), mock.Anything).Return(nil)
 	segmenterSvc.On("ValidateExperimentVariables", int64(1), mock.Anything).Return(nil)
+	segmenterSvc.On("GetSegmenterConfigurations", int64(5), mock.Anything).Return(nil, nil)
+	segmenterSvc.On("ValidateRequiredSegmenters", int64(5), mock.Anything).Return(nil)
+	segmenterSvc.On("ValidatePrereqSegmenters", int64(5), mock.Anything).Return(nil)
+	segmenterSvc.On("ValidateExperimentVariables", int64(5), mock.Anything).Return(nil)
+	segmenterSvc.
+		On("GetSegmenterTypes", int64(5)).
+		Return(map[string]schema.SegmenterType{"seg9": schema.SegmenterTypeString, "seg10": schema.SegmenterTypeInteger}, nil)
 
 	// Init mock validation service
 	validationSvc := &mocks.ValidationService{}
@@ -175,6 +209,12 @@ func (s *ProjectSettingsServiceTestSuite) SetupSuite() {
 		},
 	).Return(nil)
 
+	validationSvc.On("Validate", mock.Anything).Return(nil)
+	validationSvc.On("CheckValidationUrl", blockedSettingsValidationUrl).
+		Return(errors.Newf(errors.BadInput, "Validation URL %s is not allowed: the host 169.254.169.254 is blocked",
+			blockedSettingsValidationUrl))
+	validationSvc.On("CheckValidationUrl", mock.Anything).Return(nil)
+
 	// Init mock pubsub service
 	pubSubSvc := &mocks.PubSubPublisherService{}
 	pubSubSvc.On(
@@ -408,6 +448,375 @@ func (s *ProjectSettingsServiceTestSuite) TestProjectSettingsServiceUpdateInvali
 	s.Suite.Require().Nil(settingsResponse)
 }
 
+func (s *ProjectSettingsServiceTestSuite) TestProjectSettingsServiceBlockedValidationUrl() {
+	validationUrl := blockedSettingsValidationUrl
+	expectedErr := "Validation URL https://169.254.169.254/validate is not allowed: the host 169.254.169.254 is blocked"
+
+	// Create Settings
+	settingsResponse, err := s.ProjectSettingsService.CreateProjectSettings(
+		int64(6),
+		services.CreateProjectSettingsRequestBody{
+			Username: "client-6",
+			Segmenters: models.ProjectSegmenters{
+				Names: []string{"seg5"},
+				Variables: map[string][]string{
+					"seg5": {"exp-var-5"},
+				}},
+			RandomizationKey: "rand-6",
+			ValidationUrl:    &validationUrl,
+		})
+	s.Suite.Assert().EqualError(err, expectedErr)
+	s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+	s.Suite.Assert().Nil(settingsResponse)
+	_, err = s.ProjectSettingsService.GetDBRecord(models.ID(6))
+	s.Suite.Assert().Error(err)
+
+	// Update Settings
+	settingsResponse, err = s.ProjectSettingsService.UpdateProjectSettings(
+		int64(4),
+		services.UpdateProjectSettingsRequestBody{
+			Segmenters: models.ProjectSegmenters{
+				Names: []string{"seg7", "seg8"},
+				Variables: map[string][]string{
+					"seg7": {"exp-var-7"},
+					"seg8": {"exp-var-8"},
+				}},
+			RandomizationKey: "rand-4",
+			ValidationUrl:    &validationUrl,
+		})
+	s.Suite.Assert().EqualError(err, expectedErr)
+	s.Suite.Assert().Equal(errors.BadInput, errors.GetType(err))
+	s.Suite.Assert().Nil(settingsResponse)
+	settings, err := s.ProjectSettingsService.GetDBRecord(models.ID(4))
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal("https://test-validation-url.io", *settings.ValidationUrl)
+}
+
+func (s *ProjectSettingsServiceTestSuite) TestProjectSettingsServiceExperimentationConfig() {
+	projectId := int64(5)
+	segmenters := models.ProjectSegmenters{
+		Names: []string{"seg9", "seg10"},
+		Variables: map[string][]string{
+			"seg9":  {"exp-var-9"},
+			"seg10": {"exp-var-10"},
+		}}
+	settingsResponse, err := s.ProjectSettingsService.CreateProjectSettings(
+		projectId,
+		services.CreateProjectSettingsRequestBody{
+			ExperimentationConfigRequestBody: services.ExperimentationConfigRequestBody{
+				OrthogonalityExemptSegmenters: &[]string{"seg10"},
+			},
+			Username:         "client-5",
+			Segmenters:       segmenters,
+			RandomizationKey: "rand-5",
+		})
+	s.Suite.Require().NoError(err)
+	// Remove the project, which the other tests do not expect
+	defer s.DB.Delete(&models.Settings{}, "project_id = ?", projectId)
+	s.Suite.Assert().Equal([]string{"seg10"}, settingsResponse.Config.OrthogonalityExemptSegmenters)
+
+	trueVar := true
+	lookaheadSeconds := 3600
+	negativeLookaheadSeconds := -1
+	treatmentNamePattern := "^[a-z-]+$"
+	invalidTreatmentNamePattern := "[a-z"
+	softMaxActiveExperiments := 5
+	hardMaxActiveExperiments := 10
+	overrideTier := models.ExperimentTierOverride
+	unknownTier := models.ExperimentTier("top")
+	switchbackType := models.ExperimentTypeSwitchback
+	maxTreatmentConfigBytes := 1024
+	negativeMaxTreatmentConfigBytes := -1
+
+	// The updates are applied in order, and the settings that are not given keep their current values
+	tests := []struct {
+		name      string
+		config    services.ExperimentationConfigRequestBody
+		errString string
+		check     func(t *testing.T, config *models.ExperimentationConfig)
+	}{
+		{
+			name: "orthogonality exempt segmenters not given",
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.Equal(t, []string{"seg10"}, config.OrthogonalityExemptSegmenters)
+			},
+		},
+		{
+			name: "orthogonality exempt segmenter not in the project",
+			config: services.ExperimentationConfigRequestBody{
+				OrthogonalityExemptSegmenters: &[]string{"seg11"},
+			},
+			errString: "orthogonality exempt segmenter seg11 is not a segmenter of the project",
+		},
+		{
+			name: "newly exempted segmenter checked for conflicts",
+			config: services.ExperimentationConfigRequestBody{
+				OrthogonalityExemptSegmenters: &[]string{"seg9", "seg10"},
+			},
+			errString: "experiments overlap on all the segmenters",
+		},
+		{
+			name: "orthogonality exempt segmenters cleared",
+			config: services.ExperimentationConfigRequestBody{
+				OrthogonalityExemptSegmenters: &[]string{},
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.Empty(t, config.OrthogonalityExemptSegmenters)
+			},
+		},
+		{
+			name: "treatment config defaults",
+			config: services.ExperimentationConfigRequestBody{
+				TreatmentConfigDefaults: json.RawMessage(`{"field1": "abc"}`),
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.JSONEq(t, `{"field1": "abc"}`, string(config.TreatmentConfigDefaults))
+			},
+		},
+		{
+			name: "treatment config defaults not an object",
+			config: services.ExperimentationConfigRequestBody{
+				TreatmentConfigDefaults: json.RawMessage(`["field1"]`),
+			},
+			errString: "treatment config defaults must be a JSON object",
+		},
+		{
+			name: "enabled validators",
+			config: services.ExperimentationConfigRequestBody{
+				EnabledValidators: &[]string{"custom-validator"},
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.Equal(t, []string{"custom-validator"}, config.EnabledValidators)
+			},
+		},
+		{
+			name: "enabled validator not registered",
+			config: services.ExperimentationConfigRequestBody{
+				EnabledValidators: &[]string{"unregistered-validator"},
+			},
+			errString: "validator unregistered-validator is not registered",
+		},
+		{
+			name: "default segment",
+			config: services.ExperimentationConfigRequestBody{
+				DefaultSegment: &models.ExperimentSegmentRaw{"seg10": []interface{}{float64(1), float64(2)}},
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.Equal(t, models.ExperimentSegmentRaw{"seg10": []interface{}{float64(1), float64(2)}},
+					config.DefaultSegment)
+			},
+		},
+		{
+			name: "default segmenter not in the project",
+			config: services.ExperimentationConfigRequestBody{
+				DefaultSegment: &models.ExperimentSegmentRaw{"seg11": []interface{}{"a"}},
+			},
+			errString: "default segmenter seg11 is not a segmenter of the project",
+		},
+		{
+			name: "default segment values not a list",
+			config: services.ExperimentationConfigRequestBody{
+				DefaultSegment: &models.ExperimentSegmentRaw{"seg9": "a"},
+			},
+			errString: "default segment values of seg9 must be a list",
+		},
+		{
+			name: "default segment values of the wrong type",
+			config: services.ExperimentationConfigRequestBody{
+				DefaultSegment: &models.ExperimentSegmentRaw{"seg10": []interface{}{"a"}},
+			},
+			errString: "received wrong type of segmenter value; seg10 expects type integer",
+		},
+		{
+			name: "block orphaned overrides",
+			config: services.ExperimentationConfigRequestBody{
+				BlockOrphanedOverrides: &trueVar,
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.True(t, config.BlockOrphanedOverrides)
+			},
+		},
+		{
+			name: "validation url rate limit",
+			config: services.ExperimentationConfigRequestBody{
+				ValidationUrlRateLimit: &models.ValidationUrlRateLimit{RequestsPerSecond: 2.5, Burst: 5},
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.Equal(t, &models.ValidationUrlRateLimit{RequestsPerSecond: 2.5, Burst: 5}, config.ValidationUrlRateLimit)
+			},
+		},
+		{
+			name: "validation url rate limit without a positive rate",
+			config: services.ExperimentationConfigRequestBody{
+				ValidationUrlRateLimit: &models.ValidationUrlRateLimit{Burst: 5},
+			},
+			errString: "validation url rate limit requests per second must be positive",
+		},
+		{
+			name: "validation url rate limit with a negative max wait",
+			config: services.ExperimentationConfigRequestBody{
+				ValidationUrlRateLimit: &models.ValidationUrlRateLimit{RequestsPerSecond: 2.5, Burst: 5, MaxWaitMillis: -1},
+			},
+			errString: "validation url rate limit max wait millis must not be negative",
+		},
+		{
+			name: "orthogonality lookahead",
+			config: services.ExperimentationConfigRequestBody{
+				OrthogonalityLookaheadSeconds: &lookaheadSeconds,
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.Equal(t, time.Hour, config.OrthogonalityLookahead)
+			},
+		},
+		{
+			name: "negative orthogonality lookahead",
+			config: services.ExperimentationConfigRequestBody{
+				OrthogonalityLookaheadSeconds: &negativeLookaheadSeconds,
+			},
+			errString: "orthogonality lookahead must not be negative",
+		},
+		{
+			name: "required segmenter combinations",
+			config: services.ExperimentationConfigRequestBody{
+				RequiredSegmenterCombinations: &[][]string{{"seg9", "seg10"}},
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.Equal(t, [][]string{{"seg9", "seg10"}}, config.RequiredSegmenterCombinations)
+			},
+		},
+		{
+			name: "required segmenter combination segmenter not in the project",
+			config: services.ExperimentationConfigRequestBody{
+				RequiredSegmenterCombinations: &[][]string{{"seg9", "seg11"}},
+			},
+			errString: "required segmenter combination segmenter seg11 is not a segmenter of the project",
+		},
+		{
+			name: "typed segment storage",
+			config: services.ExperimentationConfigRequestBody{
+				TypedSegmentStorage: &trueVar,
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.True(t, config.TypedSegmentStorage)
+			},
+		},
+		{
+			name: "treatment name pattern",
+			config: services.ExperimentationConfigRequestBody{
+				TreatmentNamePattern: &treatmentNamePattern,
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.Equal(t, "^[a-z-]+$", config.TreatmentNamePattern)
+			},
+		},
+		{
+			name: "invalid treatment name pattern",
+			config: services.ExperimentationConfigRequestBody{
+				TreatmentNamePattern: &invalidTreatmentNamePattern,
+			},
+			errString: "treatment name pattern is not a valid regular expression: error parsing regexp: missing closing ]: `[a-z`",
+		},
+		{
+			name: "max active experiments",
+			config: services.ExperimentationConfigRequestBody{
+				SoftMaxActiveExperiments: &softMaxActiveExperiments,
+				HardMaxActiveExperiments: &hardMaxActiveExperiments,
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.Equal(t, 5, config.SoftMaxActiveExperiments)
+				assert.Equal(t, 10, config.HardMaxActiveExperiments)
+			},
+		},
+		{
+			name: "hard max active experiments less than the soft max",
+			config: services.ExperimentationConfigRequestBody{
+				SoftMaxActiveExperiments: &hardMaxActiveExperiments,
+				HardMaxActiveExperiments: &softMaxActiveExperiments,
+			},
+			errString: "hard max active experiments must not be less than the soft max active experiments",
+		},
+		{
+			name: "priority overlap resolution",
+			config: services.ExperimentationConfigRequestBody{
+				PriorityOverlapResolution: &trueVar,
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.True(t, config.PriorityOverlapResolution)
+			},
+		},
+		{
+			name: "block duplicate treatment configs",
+			config: services.ExperimentationConfigRequestBody{
+				BlockDuplicateTreatmentConfigs: &trueVar,
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.True(t, config.BlockDuplicateTreatmentConfigs)
+			},
+		},
+		{
+			name: "default tier and type",
+			config: services.ExperimentationConfigRequestBody{
+				DefaultTier: &overrideTier,
+				DefaultType: &switchbackType,
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.Equal(t, models.ExperimentTierOverride, config.DefaultTier)
+				assert.Equal(t, models.ExperimentTypeSwitchback, config.DefaultType)
+			},
+		},
+		{
+			name: "unknown default tier",
+			config: services.ExperimentationConfigRequestBody{
+				DefaultTier: &unknownTier,
+			},
+			errString: "unknown default experiment tier: top",
+		},
+		{
+			name: "block exhaustive segment values",
+			config: services.ExperimentationConfigRequestBody{
+				BlockExhaustiveSegmentValues: &trueVar,
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.True(t, config.BlockExhaustiveSegmentValues)
+			},
+		},
+		{
+			name: "max treatment config bytes",
+			config: services.ExperimentationConfigRequestBody{
+				MaxTreatmentConfigBytes: &maxTreatmentConfigBytes,
+			},
+			check: func(t *testing.T, config *models.ExperimentationConfig) {
+				assert.Equal(t, 1024, config.MaxTreatmentConfigBytes)
+			},
+		},
+		{
+			name: "negative max treatment config bytes",
+			config: services.ExperimentationConfigRequestBody{
+				MaxTreatmentConfigBytes: &negativeMaxTreatmentConfigBytes,
+			},
+			errString: "max treatment config bytes must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Suite.T().Run(tt.name, func(t *testing.T) {
+			settingsResponse, err := s.ProjectSettingsService.UpdateProjectSettings(
+				projectId,
+				services.UpdateProjectSettingsRequestBody{
+					ExperimentationConfigRequestBody: tt.config,
+					Segmenters:                       segmenters,
+					RandomizationKey:                 "rand-5",
+				})
+			if tt.errString != "" {
+				assert.EqualError(t, err, tt.errString)
+				return
+			}
+			assert.NoError(t, err)
+			tt.check(t, settingsResponse.Config)
+		})
+	}
+}
+
 func createTestUsers(db *gorm.DB) ([]models.Settings, error) {
 	testValidationUrl := "https://test-validation-url.io"
 	// Set up test settings records
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"strings"
 
 	"cloud.google.com/go/pubsub"
 	"google.golang.org/api/iterator"
@@ -22,6 +23,8 @@ type pubSubPublisherService struct {
 	context context.Context
 	config  config.PubSubConfig
 	topic   *pubsub.Topic
+	// tierTopics holds the topics of the experiment tiers configured in the TierTopicMap
+	tierTopics map[string]*pubsub.Topic
 }
 
 func NewPubSubPublisherService(config *config.PubSubConfig) (PubSubPublisherService, error) {
@@ -31,7 +34,7 @@ func NewPubSubPublisherService(config *config.PubSubConfig) (PubSubPublisherServ
 		return nil, err
 	}
 
-	topicIsPresent := false
+	existingTopics := map[string]bool{}
 	topicIterator := client.Topics(ctx)
 	for {
 		topic, err := topicIterator.Next()
@@ -41,27 +44,58 @@ func NewPubSubPublisherService(config *config.PubSubConfig) (PubSubPublisherServ
 		if err != nil {
 			return nil, err
 		}
-		if topic.ID() == config.TopicName {
-			topicIsPresent = true
-		}
+		existingTopics[topic.ID()] = true
+	}
+
+	topic, err := getOrCreateTopic(ctx, client, config.TopicName, existingTopics)
+	if err != nil {
+		return nil, err
 	}
-	if !topicIsPresent {
-		_, err = client.CreateTopic(ctx, config.TopicName)
+	tierTopics := map[string]*pubsub.Topic{}
+	for tier, topicName := range config.TierTopicMap {
+		tierTopics[tier], err = getOrCreateTopic(ctx, client, topicName, existingTopics)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	topic := client.Topic(config.TopicName)
 	pubSubPublisher := pubSubPublisherService{
-		context: ctx,
-		config:  *config,
-		topic:   topic,
+		context:    ctx,
+		config:     *config,
+		topic:      topic,
+		tierTopics: tierTopics,
 	}
 
 	return &pubSubPublisher, nil
 }
 
+func getOrCreateTopic(
+	ctx context.Context,
+	client *pubsub.Client,
+	topicName string,
+	existingTopics map[string]bool,
+) (*pubsub.Topic, error) {
+	if !existingTopics[topicName] {
+		_, err := client.CreateTopic(ctx, topicName)
+		if err != nil {
+			return nil, err
+		}
+		existingTopics[topicName] = true
+	}
+	return client.Topic(topicName), nil
+}
+
+// getExperimentTopics returns the topics that the messages about the experiment are published to. The default topic,
+// which the treatment service subscribes to, always receives them, and so does the topic configured for the tier of
+// the experiment, if any.
+func (p *pubSubPublisherService) getExperimentTopics(experiment *_pubsub.Experiment) []*pubsub.Topic {
+	topics := []*pubsub.Topic{p.topic}
+	if topic, ok := p.tierTopics[strings.ToLower(experiment.GetTier().String())]; ok && topic.ID() != p.topic.ID() {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
 func serializeCreateExperiment(experiment *_pubsub.Experiment) ([]byte, error) {
 	updateClientState := _pubsub.MessagePublishState{
 		Update: &_pubsub.MessagePublishState_ExperimentCreated{
@@ -150,9 +184,11 @@ func (p *pubSubPublisherService) PublishExperimentMessage(updateType string, exp
 		Data: payload,
 	}
 
-	_, err = p.topic.Publish(p.context, &message).Get(p.context)
-	if err != nil {
-		return err
+	for _, topic := range p.getExperimentTopics(experiment) {
+		_, err = topic.Publish(p.context, &message).Get(p.context)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -27,6 +27,8 @@ import (
 const (
 	PUBSUB_PROJECT = "test"
 	PUBSUB_TOPIC   = "update"
+
+	PUBSUB_OVERRIDE_TOPIC = "override-update"
 )
 
 type PubSubServiceTestSuite struct {
@@ -60,10 +62,11 @@ func (s *PubSubServiceTestSuite) SetupSuite() {
 		s.FailNow("failed to start pub sub emulator", err.Error())
 	}
 	s.emulator = emulator
-	topics := []string{PUBSUB_TOPIC}
+	topics := []string{PUBSUB_TOPIC, PUBSUB_OVERRIDE_TOPIC}
 	pubSubConfig := config.PubSubConfig{
-		Project:   PUBSUB_PROJECT,
-		TopicName: PUBSUB_TOPIC,
+		Project:      PUBSUB_PROJECT,
+		TopicName:    PUBSUB_TOPIC,
+		TierTopicMap: map[string]string{"override": PUBSUB_OVERRIDE_TOPIC},
 	}
 	pubSubPublisher, err := services.NewPubSubPublisherService(&pubSubConfig)
 	if err != nil {
@@ -157,6 +160,7 @@ func (s *PubSubServiceTestSuite) SetupSuite() {
 
 	expHistSvc := &mocks.ExperimentHistoryService{}
 	expHistSvc.On("CreateExperimentHistory", mock.Anything).Return(nil, nil)
+	expHistSvc.On("WithTransaction", mock.Anything).Return(expHistSvc)
 
 	treatmentHistSvc := &mocks.TreatmentHistoryService{}
 	treatmentHistSvc.On("CreateTreatmentHistory", mock.Anything).Return(nil, nil)
@@ -230,8 +234,11 @@ func (s *PubSubServiceTestSuite) SetupSuite() {
 		TreatmentHistoryService:  treatmentHistSvc,
 	}
 
-	// Init experiment service
-	s.ExperimentService = services.NewExperimentService(allServices, db)
+	// Init experiment service, with the clock frozen before the windows of the test experiments so that they can be
+	// created as active
+	s.ExperimentService = services.NewExperimentServiceWithClock(
+		allServices, db, fixedClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)),
+	)
 
 	// Init treatment service
 	s.TreatmentService = services.NewTreatmentService(allServices, db)
@@ -240,6 +247,7 @@ func (s *PubSubServiceTestSuite) SetupSuite() {
 
 	// Init project settings service
 	s.ProjectSettingsService = services.NewProjectSettingsService(allServices, db)
+	allServices.ProjectSettingsService = s.ProjectSettingsService
 	s.PubSubPublisherService = allServices.PubSubPublisherService
 
 	// Create experiment test data
@@ -533,6 +541,38 @@ func (s *PubSubServiceTestSuite) TestProjectSettingPublish() {
 	s.Suite.Require().Equal(segmenterName, publishedUpdate.GetProjectSegmenterDeleted().SegmenterName)
 }
 
+func (s *PubSubServiceTestSuite) TestPublishExperimentMessageTierTopic() {
+	// Override tier experiments are published to the tier-specific topic,
+	err := s.PubSubPublisherService.PublishExperimentMessage("create", &_pubsub.Experiment{
+		Id:   10,
+		Tier: _pubsub.Experiment_Override,
+	})
+	s.Suite.Require().NoError(err)
+	publishedUpdate, err := getLastPublishedUpdate(s.ctx, 1*time.Second, s.subscriptions[PUBSUB_OVERRIDE_TOPIC])
+	s.Suite.Require().NoError(err)
+	s.Suite.Require().NotNil(publishedUpdate.Update)
+	s.Suite.Require().Equal(int64(10), publishedUpdate.GetExperimentCreated().GetExperiment().Id)
+	// and still to the default topic, which the treatment service subscribes to
+	publishedUpdate, err = getLastPublishedUpdate(s.ctx, 1*time.Second, s.subscriptions[PUBSUB_TOPIC])
+	s.Suite.Require().NoError(err)
+	s.Suite.Require().NotNil(publishedUpdate.Update)
+	s.Suite.Require().Equal(int64(10), publishedUpdate.GetExperimentCreated().GetExperiment().Id)
+
+	// Default tier experiments, whose tier is not mapped, are only published to the default topic
+	err = s.PubSubPublisherService.PublishExperimentMessage("update", &_pubsub.Experiment{
+		Id:   11,
+		Tier: _pubsub.Experiment_Default,
+	})
+	s.Suite.Require().NoError(err)
+	publishedUpdate, err = getLastPublishedUpdate(s.ctx, 1*time.Second, s.subscriptions[PUBSUB_TOPIC])
+	s.Suite.Require().NoError(err)
+	s.Suite.Require().NotNil(publishedUpdate.Update)
+	s.Suite.Require().Equal(int64(11), publishedUpdate.GetExperimentUpdated().GetExperiment().Id)
+	publishedUpdate, err = getLastPublishedUpdate(s.ctx, 1*time.Second, s.subscriptions[PUBSUB_OVERRIDE_TOPIC])
+	s.Suite.Require().NoError(err)
+	s.Suite.Require().Nil(publishedUpdate.Update)
+}
+
 func getLastPublishedUpdate(
 	ctx context.Context,
 	timeout time.Duration,
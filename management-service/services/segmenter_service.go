@@ -3,6 +3,9 @@ package services
 import (
 	"encoding/json"
 	"fmt"
+	"log"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/golang-collections/collections/set"
@@ -71,7 +74,13 @@ type ListSegmentersParams struct {
 type SegmenterService interface {
 	GetFormattedSegmenters(projectId int64, expSegment models.ExperimentSegmentRaw) (map[string]*[]interface{}, error)
 	GetSegmenterConfigurations(projectId int64, segmenterNames []string) ([]*_segmenters.SegmenterConfiguration, error)
+	SuggestSegmentValues(projectId int64, segmenterName string, prefix string, limit int) ([]string, error)
 	ValidateExperimentSegment(projectId int64, userSegmenters []string, expSegment models.ExperimentSegmentRaw) error
+	GetExperimentSegmentWarnings(
+		projectId int64,
+		userSegmenters []string,
+		expSegment models.ExperimentSegmentRaw,
+	) ([]string, error)
 	ValidateSegmentOrthogonality(
 		projectId int64,
 		userSegmenters []string,
@@ -583,6 +592,41 @@ func (svc *segmenterService) GetSegmenterConfigurations(
 	return segmenterConfigList, nil
 }
 
+// SuggestSegmentValues returns up to the given number of the allowed values of the segmenter, in the string storage
+// format of the experiment segments, that start with the given prefix or whose option names do, ignoring case, e.g.,
+// "tue" suggests the value of Tuesday. The values are sorted. Segmenters without options, whose values are not
+// enumerated, have no suggestions.
+func (svc *segmenterService) SuggestSegmentValues(
+	projectId int64,
+	segmenterName string,
+	prefix string,
+	limit int,
+) ([]string, error) {
+	if limit <= 0 {
+		return nil, errors.Newf(errors.BadInput, "limit must be positive")
+	}
+	segmenterConfigs, err := svc.GetSegmenterConfigurations(projectId, []string{segmenterName})
+	if err != nil {
+		return nil, err
+	}
+
+	prefix = strings.ToLower(prefix)
+	values := []string{}
+	for _, segmenterConfig := range segmenterConfigs {
+		for name, option := range segmenterConfig.GetOptions() {
+			value := formatSegmenterOption(option)
+			if strings.HasPrefix(strings.ToLower(value), prefix) || strings.HasPrefix(strings.ToLower(name), prefix) {
+				values = append(values, value)
+			}
+		}
+	}
+	sort.Strings(values)
+	if len(values) > limit {
+		values = values[:limit]
+	}
+	return values, nil
+}
+
 func (svc *segmenterService) GetFormattedSegmenters(
 	projectId int64,
 	expSegment models.ExperimentSegmentRaw,
@@ -634,6 +678,22 @@ func (svc *segmenterService) ValidateExperimentSegment(
 	userSegmenters []string,
 	expSegment models.ExperimentSegmentRaw,
 ) error {
+	err := validateSegmentValuesNotBlank(expSegment)
+	if err != nil {
+		return err
+	}
+
+	settings, err := svc.services.ProjectSettingsService.GetDBRecord(models.ID(projectId))
+	if err != nil {
+		return err
+	}
+	if settings.Config != nil {
+		err = validateRequiredSegmenterCombinations(expSegment, settings.Config.RequiredSegmenterCombinations)
+		if err != nil {
+			return err
+		}
+	}
+
 	segmenterTypes, err := svc.GetSegmenterTypes(projectId)
 	if err != nil {
 		return err
@@ -653,6 +713,123 @@ func (svc *segmenterService) ValidateExperimentSegment(
 		if err != nil {
 			return err
 		}
+		err = validateSegmentValueCount(*segmenter, expSegment, settings.Config)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetExperimentSegmentWarnings returns the warnings on the experiment segment, which do not fail its validation as the
+// project does not block them, i.e., the segmenters that list all of their allowed values
+func (svc *segmenterService) GetExperimentSegmentWarnings(
+	projectId int64,
+	userSegmenters []string,
+	expSegment models.ExperimentSegmentRaw,
+) ([]string, error) {
+	settings, err := svc.services.ProjectSettingsService.GetDBRecord(models.ID(projectId))
+	if err != nil {
+		return nil, err
+	}
+	if settings.Config != nil && settings.Config.BlockExhaustiveSegmentValues {
+		return []string{}, nil
+	}
+
+	warnings := []string{}
+	for _, s := range userSegmenters {
+		segmenter, err := svc.GetBaseSegmenter(projectId, s)
+		if err != nil {
+			return nil, err
+		}
+		warning, err := getSegmentValueCountWarning(*segmenter, expSegment)
+		if err != nil {
+			return nil, err
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings, nil
+}
+
+// validateSegmentValueCount checks that the segment does not list all the allowed values of the segmenter. This is an
+// error if the project blocks such segments; otherwise, only a warning is logged.
+func validateSegmentValueCount(
+	segmenter segmenters.Segmenter,
+	expSegment models.ExperimentSegmentRaw,
+	projectConfig *models.ExperimentationConfig,
+) error {
+	warning, err := getSegmentValueCountWarning(segmenter, expSegment)
+	if err != nil || warning == "" {
+		return err
+	}
+	if projectConfig != nil && projectConfig.BlockExhaustiveSegmentValues {
+		return errors.Newf(errors.BadInput, "%s", warning)
+	}
+	log.Printf("Warning: %s", warning)
+	return nil
+}
+
+// getSegmentValueCountWarning returns a warning if the segment lists as many values of the segmenter as the segmenter
+// allows, or more, which matches all of its values, like leaving it unset, and is likely a sign of stale data. Segmenters
+// without options, whose values are not enumerated, are not checked.
+func getSegmentValueCountWarning(segmenter segmenters.Segmenter, expSegment models.ExperimentSegmentRaw) (string, error) {
+	segmenterConfig, err := segmenter.GetConfiguration()
+	if err != nil {
+		return "", err
+	}
+	allowedCount := len(segmenterConfig.GetOptions())
+	values, ok := expSegment[segmenterConfig.GetName()].([]interface{})
+	if allowedCount == 0 || !ok || len(values) < allowedCount {
+		return "", nil
+	}
+	return fmt.Sprintf("Segmenter %s lists %d values against its %d allowed values, matching all of them; "+
+		"leave it unset instead, or remove the stale values", segmenterConfig.GetName(), len(values), allowedCount), nil
+}
+
+// validateRequiredSegmenterCombinations checks that, for each of the given groups of segmenters, either none or all of
+// the segmenters are set in the segment. A segmenter with an empty list of values is not set.
+func validateRequiredSegmenterCombinations(expSegment models.ExperimentSegmentRaw, combinations [][]string) error {
+	isSet := func(name string) bool {
+		values, ok := expSegment[name].([]interface{})
+		return ok && len(values) > 0
+	}
+	for _, combination := range combinations {
+		for _, name := range combination {
+			if !isSet(name) {
+				continue
+			}
+			for _, companion := range combination {
+				if !isSet(companion) {
+					return errors.Newf(errors.BadInput, "Segmenter %s requires segmenter %s to be set as well", name, companion)
+				}
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// validateSegmentValuesNotBlank checks that none of the segment's string values are empty or whitespace-only. An empty
+// list of values is allowed, as it is used to leave the segmenter unset.
+func validateSegmentValuesNotBlank(expSegment models.ExperimentSegmentRaw) error {
+	segmenterNames := []string{}
+	for name := range expSegment {
+		segmenterNames = append(segmenterNames, name)
+	}
+	sort.Strings(segmenterNames)
+
+	for _, name := range segmenterNames {
+		values, ok := expSegment[name].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, value := range values {
+			if stringValue, ok := value.(string); ok && strings.TrimSpace(stringValue) == "" {
+				return errors.Newf(errors.BadInput, "Segmenter %s has an empty value", name)
+			}
+		}
 	}
 	return nil
 }
@@ -667,12 +844,13 @@ func (svc *segmenterService) ValidateSegmentOrthogonality(
 	expSegment models.ExperimentSegmentRaw,
 	allExps []models.Experiment,
 ) error {
-	expSegmentFormatted, err := svc.GetFormattedSegmenters(projectId, expSegment)
+	segmenterTypes, err := svc.GetSegmenterTypes(projectId)
 	if err != nil {
-		return err
+		return internalError(err)
 	}
 
-	segmenterTypes, err := svc.GetSegmenterTypes(projectId)
+	// The given segment is the input being checked, so the failures to format it are not internal
+	expSegmentFormatted, err := svc.GetFormattedSegmenters(projectId, expSegment)
 	if err != nil {
 		return err
 	}
@@ -680,15 +858,17 @@ func (svc *segmenterService) ValidateSegmentOrthogonality(
 	for _, exp := range allExps {
 		rawSegments, err := exp.Segment.ToRawSchema(segmenterTypes)
 		if err != nil {
-			return err
+			return internalError(err)
 		}
 		otherSegmentFormatted, err := svc.GetFormattedSegmenters(projectId, rawSegments)
 		if err != nil {
-			return err
+			return internalError(err)
 		}
 
-		// Check that the current experiment segment and the other are orthogonal
+		// Check that the current experiment segment and the other are orthogonal, collecting the overlap of each
+		// segmenter so that the failure can be explained
 		segmentsOverlap := true
+		overlaps := []string{}
 		for _, name := range userSegmenters {
 			isCurrValEmpty, isOtherValEmpty := false, false
 			currValues, ok := expSegmentFormatted[name]
@@ -706,26 +886,61 @@ func (svc *segmenterService) ValidateSegmentOrthogonality(
 			if !isCurrValEmpty && !isOtherValEmpty {
 				currentSet := set.New(*currValues...)
 				otherSet := set.New(*otherValues...)
-				if currentSet.Intersection(otherSet).Len() == 0 {
+				sharedSet := currentSet.Intersection(otherSet)
+				if sharedSet.Len() == 0 {
 					// At least one segmenter does not overlap, we can terminate the check for
 					// this other experiment.
 					segmentsOverlap = false
 					break
 				}
+				overlaps = append(overlaps,
+					fmt.Sprintf("segmenter '%s' values [%s]", name, formatSharedSegmenterValues(sharedSet)))
 			} else if !isCurrValEmpty || !isOtherValEmpty {
 				segmentsOverlap = false
 				break
+			} else {
+				overlaps = append(overlaps, fmt.Sprintf("segmenter '%s' (all values)", name))
 			}
 		}
 
 		if segmentsOverlap {
-			return fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exp.ID)
+			if len(overlaps) == 0 {
+				return fmt.Errorf("Segment Orthogonality check failed against experiment ID %d", exp.ID)
+			}
+			return fmt.Errorf("Segment Orthogonality check failed against experiment ID %d: the experiments overlap on %s",
+				exp.ID, strings.Join(overlaps, ", "))
 		}
 	}
 
 	return nil
 }
 
+// internalError marks the untyped errors encountered while looking up the segmenters as internal errors, so that they
+// are not mistaken for the orthogonality conflicts between the segments
+func internalError(err error) error {
+	if errors.GetType(err) != errors.Unknown {
+		return err
+	}
+	return errors.Newf(errors.Internal, err.Error())
+}
+
+// formatSharedSegmenterValues formats the formatted segmenter values in the given set as a sorted, comma-separated
+// list, with the string values unquoted
+func formatSharedSegmenterValues(values *set.Set) string {
+	formatted := []string{}
+	values.Do(func(value interface{}) {
+		if str, ok := value.(string); ok {
+			if unquoted, err := strconv.Unquote(str); err == nil {
+				formatted = append(formatted, unquoted)
+				return
+			}
+		}
+		formatted = append(formatted, fmt.Sprint(value))
+	})
+	sort.Strings(formatted)
+	return strings.Join(formatted, ", ")
+}
+
 func (svc *segmenterService) ValidateRequiredSegmenters(projectId int64, segmenterNames []string) error {
 	providedSegmenterNames := utils.StringSliceToSet(segmenterNames)
 
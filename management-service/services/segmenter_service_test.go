@@ -55,16 +55,20 @@ func (s *SegmenterServiceTestSuite) SetupSuite() {
 	settingsSvc.On("GetDBRecord", models.ID(0)).Return(
 		&models.Settings{
 			ProjectID: models.ID(0),
-			Config: &models.ExperimentationConfig{Segmenters: models.ProjectSegmenters{
-				Names: []string{
-					"hours_of_day",
-					"days_of_week",
-					"country",
-					"area",
-					"bool_segmenter",
-					"s2_ids",
+			Config: &models.ExperimentationConfig{
+				Segmenters: models.ProjectSegmenters{
+					Names: []string{
+						"hours_of_day",
+						"days_of_week",
+						"country",
+						"area",
+						"bool_segmenter",
+						"s2_ids",
+					},
 				},
-			}},
+				RequiredSegmenterCombinations: [][]string{{"days_of_week", "hours_of_day"}},
+				BlockExhaustiveSegmentValues:  true,
+			},
 		},
 		nil,
 	)
@@ -196,6 +200,62 @@ func (s *SegmenterServiceTestSuite) TestGetSegmenterConfigurations() {
 	}
 }
 
+func (s *SegmenterServiceTestSuite) TestSuggestSegmentValues() {
+	tests := map[string]struct {
+		segmenterName string
+		prefix        string
+		limit         int
+		expected      []string
+		errString     string
+	}{
+		"success | prefix of the values": {
+			segmenterName: "hours_of_day",
+			prefix:        "2",
+			limit:         10,
+			expected:      []string{"2", "20", "21", "22", "23"},
+		},
+		"success | prefix of the option names": {
+			segmenterName: "days_of_week",
+			prefix:        "t",
+			limit:         10,
+			expected:      []string{"2", "4"},
+		},
+		"success | limit": {
+			segmenterName: "hours_of_day",
+			prefix:        "1",
+			limit:         3,
+			expected:      []string{"1", "10", "11"},
+		},
+		"success | empty prefix": {
+			segmenterName: "bool_segmenter",
+			limit:         10,
+			expected:      []string{"false", "true"},
+		},
+		"success | segmenter without options": {
+			segmenterName: "s2_ids",
+			prefix:        "1",
+			limit:         10,
+			expected:      []string{},
+		},
+		"failure | limit not positive": {
+			segmenterName: "hours_of_day",
+			errString:     "limit must be positive",
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			got, err := s.SegmenterService.SuggestSegmentValues(int64(0), data.segmenterName, data.prefix, data.limit)
+			if data.errString == "" {
+				s.Suite.Require().NoError(err)
+				s.Suite.Assert().Equal(data.expected, got)
+			} else {
+				s.Suite.Assert().EqualError(err, data.errString)
+			}
+		})
+	}
+}
+
 func (s *SegmenterServiceTestSuite) TestGetFormattedSegmenters() {
 	s2IdFloat := []interface{}{float64(3592210809859604480)}
 	daysOfWeekFloat := []interface{}{float64(1), float64(2)}
@@ -273,6 +333,26 @@ func (s *SegmenterServiceTestSuite) TestValidateExperimentSegment() {
 				"country": countryId,
 			},
 		},
+		"success | empty value list": {
+			userSegmenters: []string{"country", "area"},
+			expSegment: models.ExperimentSegmentRaw{
+				"country": []interface{}{},
+			},
+		},
+		"failure | empty value": {
+			userSegmenters: []string{"country", "area"},
+			expSegment: models.ExperimentSegmentRaw{
+				"country": []interface{}{""},
+			},
+			errString: "Segmenter country has an empty value",
+		},
+		"failure | whitespace-only value": {
+			userSegmenters: []string{"country", "area"},
+			expSegment: models.ExperimentSegmentRaw{
+				"country": []interface{}{"ID", " "},
+			},
+			errString: "Segmenter country has an empty value",
+		},
 		"failure | single value check failed": {
 			userSegmenters: []string{"area", "country"},
 			expSegment: models.ExperimentSegmentRaw{
@@ -297,6 +377,39 @@ func (s *SegmenterServiceTestSuite) TestValidateExperimentSegment() {
 				"s2_ids":  s2IdsValid,
 			},
 		},
+		"success | required combination satisfied": {
+			userSegmenters: []string{"days_of_week", "hours_of_day"},
+			expSegment: models.ExperimentSegmentRaw{
+				"days_of_week": []interface{}{float64(1)},
+				"hours_of_day": []interface{}{float64(8), float64(9)},
+			},
+		},
+		"failure | required combination not satisfied": {
+			userSegmenters: []string{"days_of_week", "hours_of_day"},
+			expSegment: models.ExperimentSegmentRaw{
+				"days_of_week": []interface{}{},
+				"hours_of_day": []interface{}{float64(8)},
+			},
+			errString: "Segmenter hours_of_day requires segmenter days_of_week to be set as well",
+		},
+		"success | subset of the allowed values": {
+			userSegmenters: []string{"days_of_week", "hours_of_day"},
+			expSegment: models.ExperimentSegmentRaw{
+				"days_of_week": []interface{}{float64(1), float64(2), float64(3), float64(4), float64(5), float64(6)},
+				"hours_of_day": []interface{}{float64(8)},
+			},
+		},
+		"failure | all the allowed values": {
+			userSegmenters: []string{"days_of_week", "hours_of_day"},
+			expSegment: models.ExperimentSegmentRaw{
+				"days_of_week": []interface{}{
+					float64(1), float64(2), float64(3), float64(4), float64(5), float64(6), float64(7),
+				},
+				"hours_of_day": []interface{}{float64(8)},
+			},
+			errString: "Segmenter days_of_week lists 7 values against its 7 allowed values, matching all of them; " +
+				"leave it unset instead, or remove the stale values",
+		},
 	}
 
 	for name, data := range tests {
@@ -311,6 +424,47 @@ func (s *SegmenterServiceTestSuite) TestValidateExperimentSegment() {
 	}
 }
 
+func (s *SegmenterServiceTestSuite) TestGetExperimentSegmentWarnings() {
+	allDaysOfWeek := models.ExperimentSegmentRaw{
+		"days_of_week": []interface{}{
+			float64(1), float64(2), float64(3), float64(4), float64(5), float64(6), float64(7),
+		},
+	}
+	tests := map[string]struct {
+		projectId  int64
+		expSegment models.ExperimentSegmentRaw
+		expected   []string
+	}{
+		"subset of the allowed values": {
+			projectId:  1,
+			expSegment: models.ExperimentSegmentRaw{"days_of_week": []interface{}{float64(1)}},
+			expected:   []string{},
+		},
+		"all the allowed values": {
+			projectId:  1,
+			expSegment: allDaysOfWeek,
+			expected: []string{
+				"Segmenter days_of_week lists 7 values against its 7 allowed values, matching all of them; " +
+					"leave it unset instead, or remove the stale values",
+			},
+		},
+		"all the allowed values | blocked by the project": {
+			projectId:  0,
+			expSegment: allDaysOfWeek,
+			expected:   []string{},
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			warnings, err := s.SegmenterService.GetExperimentSegmentWarnings(
+				data.projectId, []string{"days_of_week"}, data.expSegment)
+			s.Suite.Require().NoError(err)
+			s.Suite.Assert().Equal(data.expected, warnings)
+		})
+	}
+}
+
 func (s *SegmenterServiceTestSuite) TestValidateSegmentOrthogonality() {
 	s2IdRaw := []interface{}{float64(3592210809859604480), float64(3592210814154571776)}
 	daysOfWeekRaw := []interface{}{float64(1)}
@@ -375,7 +529,23 @@ func (s *SegmenterServiceTestSuite) TestValidateSegmentOrthogonality() {
 					},
 				},
 			},
-			errString: "Segment Orthogonality check failed against experiment ID 0",
+			errString: "Segment Orthogonality check failed against experiment ID 0: the experiments overlap on " +
+				"segmenter 's2_ids' values [3592210814154571776], segmenter 'days_of_week' values [1]",
+		},
+		"failure | overlap on string values": {
+			userSegmenters: []string{"country"},
+			expSegment: models.ExperimentSegmentRaw{
+				"country": testCountriesRaw1,
+			},
+			allExps: []models.Experiment{
+				{
+					Segment: models.ExperimentSegment{
+						"country": testCountries2,
+					},
+				},
+			},
+			errString: "Segment Orthogonality check failed against experiment ID 0: the experiments overlap on " +
+				"segmenter 'country' values [SG]",
 		},
 		"failure | both segmenters optional": {
 			userSegmenters: []string{"s2_ids", "days_of_week"},
@@ -395,7 +565,8 @@ func (s *SegmenterServiceTestSuite) TestValidateSegmentOrthogonality() {
 					},
 				},
 			},
-			errString: "Segment Orthogonality check failed against experiment ID 0",
+			errString: "Segment Orthogonality check failed against experiment ID 0: the experiments overlap on " +
+				"segmenter 's2_ids' values [3592210809859604480], segmenter 'days_of_week' (all values)",
 		},
 		"success | existing segmenter optional": {
 			userSegmenters: []string{"s2_ids", "days_of_week"},
@@ -294,6 +294,12 @@ func (svc *treatmentService) RunCustomValidation(
 		return ValidateTreatmentConfigWithTreatmentSchema(treatmentConfig, settings.TreatmentSchema)
 	})
 	g.Go(func() error {
+		if rateLimit := getValidationUrlRateLimit(settings); rateLimit != nil {
+			err := svc.services.ValidationService.WaitForValidationUrlRateLimit(int64(settings.ProjectID), *rateLimit)
+			if err != nil {
+				return err
+			}
+		}
 		return svc.services.ValidationService.ValidateEntityWithExternalUrl(
 			operationType,
 			EntityTypeTreatment,
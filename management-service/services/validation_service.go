@@ -2,36 +2,91 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Masterminds/sprig/v3"
 	"github.com/go-playground/validator/v10"
 	"github.com/go-playground/validator/v10/non-standard/validators"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/caraml-dev/xp/management-service/config"
 	"github.com/caraml-dev/xp/management-service/errors"
 	"github.com/caraml-dev/xp/management-service/models"
 )
 
-var nameRegex = regexp.MustCompile(`^[A-Za-z\d][\w\d \-()#$%&:.]{2,62}[\w\d\-()#$%&:.]$`)
+const (
+	minNameLength               = 4
+	defaultMaxNameLength        = 64
+	defaultMaxDescriptionLength = 4096
+	minSwitchbackPeriods        = 2
+	minSwitchbackTreatments     = 2
+	maxValidationUrlRedirects   = 10
+	// maxValidationUrlRetryBackoff bounds the wait before each retry of a request to a validation URL, as the
+	// requests are retried while the API request that is being validated waits
+	maxValidationUrlRetryBackoff = 2 * time.Second
+	// circuitBreakerIdleTimeout is the duration after which the circuit breaker of a validation URL host that has not
+	// been requested is evicted, so that the circuit breakers of the hosts that are no longer used are not kept
+	circuitBreakerIdleTimeout = time.Hour
+)
+
+// nameRegex matches the allowed characters of the names. The lengths of the names are checked separately, as they are
+// configurable and a regular expression cannot repeat a pattern more than 1000 times.
+var nameRegex = regexp.MustCompile(`^[A-Za-z\d]([\w\d \-()#$%&:.]*[\w\d\-()#$%&:.])?$`)
+
+var (
+	defaultValidationUrlAllowedSchemes = []string{"https"}
+	defaultValidationUrlBlockedHosts   = []string{"0.0.0.0/8", "127.0.0.0/8", "169.254.0.0/16", "::1", "fe80::/10"}
+)
+
+// validationUrlCircuitBreakerState tracks the state of the circuit breaker of each validation URL host. It is labelled
+// by the host, rather than the full URL, to bound the number of series and to leave out the credentials and the query
+// parameters that the URLs may hold.
+var validationUrlCircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "mlp",
+	Subsystem: "xp_management_service",
+	Name:      "validation_url_circuit_breaker_state",
+	Help:      "State of the circuit breaker of the validation URL host: 0 (closed), 1 (half-open) or 2 (open)",
+}, []string{"validation_host"})
 
 type ValidationService interface {
 	Validate(data interface{}) error
 	ValidateEntityWithExternalUrl(operation OperationType, entityType EntityType, data interface{}, context ValidationContext,
 		validationUrl *string) error
 	ValidateWithExternalUrl(reqBody []byte, validationUrl *string) error
+	CheckValidationUrl(validationUrl string) error
+	WaitForValidationUrlRateLimit(projectId int64, rateLimit models.ValidationUrlRateLimit) error
 }
 
 type validationService struct {
 	config                   config.ValidationConfig
 	v                        *validator.Validate
 	externalValidationClient http.Client
+	clock                    Clock
+
+	maxNameLength        int
+	maxDescriptionLength int
+
+	validationUrlAllowedSchemes []string
+	validationUrlAllowedHosts   []hostRule
+	validationUrlBlockedHosts   []hostRule
+
+	circuitBreakersLock sync.Mutex
+	circuitBreakers     map[string]*circuitBreaker
+
+	rateLimitersLock sync.Mutex
+	rateLimiters     map[int64]*tokenBucket
 }
 
 func (v *validationService) Validate(data interface{}) error {
@@ -40,42 +95,111 @@ func (v *validationService) Validate(data interface{}) error {
 
 // NewValidationService creates a new validator
 func NewValidationService(config config.ValidationConfig) (ValidationService, error) {
-	instance := validator.New()
+	maxNameLength := config.MaxNameLength
+	if maxNameLength == 0 {
+		maxNameLength = defaultMaxNameLength
+	}
+	if maxNameLength < minNameLength {
+		return nil, fmt.Errorf("max name length must be at least %d", minNameLength)
+	}
+	maxDescriptionLength := config.MaxDescriptionLength
+	if maxDescriptionLength == 0 {
+		maxDescriptionLength = defaultMaxDescriptionLength
+	}
+	allowedSchemes := config.ValidationUrlAllowedSchemes
+	if len(allowedSchemes) == 0 {
+		allowedSchemes = defaultValidationUrlAllowedSchemes
+	}
+	allowedHosts, err := parseHostRules(config.ValidationUrlAllowedHosts)
+	if err != nil {
+		return nil, err
+	}
+	blockedHostsConfig := config.ValidationUrlBlockedHosts
+	if len(blockedHostsConfig) == 0 {
+		blockedHostsConfig = defaultValidationUrlBlockedHosts
+	}
+	blockedHosts, err := parseHostRules(blockedHostsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &validationService{
+		config: config,
+		v:      validator.New(),
+		externalValidationClient: http.Client{
+			Timeout: time.Duration(config.ValidationUrlTimeoutSeconds) * time.Second,
+		},
+		clock:                       realClock{},
+		circuitBreakers:             map[string]*circuitBreaker{},
+		rateLimiters:                map[int64]*tokenBucket{},
+		maxNameLength:               maxNameLength,
+		maxDescriptionLength:        maxDescriptionLength,
+		validationUrlAllowedSchemes: allowedSchemes,
+		validationUrlAllowedHosts:   allowedHosts,
+		validationUrlBlockedHosts:   blockedHosts,
+	}
+	// The hosts are checked on every connection, at the IP addresses that are actually dialed, and not through a
+	// proxy, so that they cannot resolve to the addresses that are not allowed after they were checked
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = svc.dialValidationUrlHost
+	svc.externalValidationClient.Transport = transport
+	// The redirects are checked against the allowed schemes and hosts too, so that they cannot lead to the hosts that
+	// are not allowed
+	svc.externalValidationClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxValidationUrlRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxValidationUrlRedirects)
+		}
+		return svc.CheckValidationUrl(req.URL.String())
+	}
 
 	// Register custom validators
-	if err := instance.RegisterValidation("notBlank", validators.NotBlank); err != nil {
+	if err := svc.v.RegisterValidation("notBlank", validators.NotBlank); err != nil {
 		return nil, err
 	}
-	instance.RegisterStructValidation(validateCreateExperimentData, CreateExperimentRequestBody{})
-	instance.RegisterStructValidation(validateUpdateExperimentData, UpdateExperimentRequestBody{})
-	instance.RegisterStructValidation(validateCreateTreatmentData, CreateTreatmentRequestBody{})
-	instance.RegisterStructValidation(validateCreateProjectSettingsData, CreateProjectSettingsRequestBody{})
-	instance.RegisterStructValidation(validateUpdateProjectSettingsData, UpdateProjectSettingsRequestBody{})
+	svc.v.RegisterStructValidation(svc.validateCreateExperimentData, CreateExperimentRequestBody{})
+	svc.v.RegisterStructValidation(svc.validateUpdateExperimentData, UpdateExperimentRequestBody{})
+	svc.v.RegisterStructValidation(svc.validateCreateTreatmentData, CreateTreatmentRequestBody{})
+	svc.v.RegisterStructValidation(validateCreateProjectSettingsData, CreateProjectSettingsRequestBody{})
+	svc.v.RegisterStructValidation(validateUpdateProjectSettingsData, UpdateProjectSettingsRequestBody{})
+
+	return svc, nil
+}
 
-	externalValidationClient := http.Client{
-		Timeout: time.Duration(config.ValidationUrlTimeoutSeconds) * time.Second,
+// NewValidationServiceWithClock creates a new validator that checks the start times of the experiments against the
+// times given by the clock
+func NewValidationServiceWithClock(config config.ValidationConfig, clock Clock) (ValidationService, error) {
+	svc, err := NewValidationService(config)
+	if err != nil {
+		return nil, err
 	}
-	return &validationService{config: config, v: instance, externalValidationClient: externalValidationClient}, nil
+	validationSvc := svc.(*validationService)
+	validationSvc.clock = clock
+	return validationSvc, nil
 }
 
-func validateCreateExperimentData(sl validator.StructLevel) {
+func (v *validationService) validateCreateExperimentData(sl validator.StructLevel) {
 	field := sl.Current().Interface().(CreateExperimentRequestBody)
-	checkName(sl, "Name", field.Name)
-	checkStartTime(sl, field.StartTime)
+	v.checkName(sl, "Name", field.Name)
+	v.checkDescription(sl, field.Description)
+	v.checkStartTime(sl, field.StartTime)
 	checkInterval(sl, field.Type, field.Interval)
-	checkTreatments(sl, field.Type, field.Treatments)
+	checkSwitchbackPeriods(sl, field.Type, field.Interval, field.StartTime, field.EndTime)
+	v.checkTreatments(sl, field.Type, field.Treatments)
 }
 
-func validateUpdateExperimentData(sl validator.StructLevel) {
+func (v *validationService) validateUpdateExperimentData(sl validator.StructLevel) {
 	field := sl.Current().Interface().(UpdateExperimentRequestBody)
-	checkStartTime(sl, field.StartTime)
+	v.checkDescription(sl, field.Description)
+	v.checkStartTime(sl, field.StartTime)
 	checkInterval(sl, field.Type, field.Interval)
-	checkTreatments(sl, field.Type, field.Treatments)
+	checkSwitchbackPeriods(sl, field.Type, field.Interval, field.StartTime, field.EndTime)
+	v.checkTreatments(sl, field.Type, field.Treatments)
 }
 
-func validateCreateTreatmentData(sl validator.StructLevel) {
+func (v *validationService) validateCreateTreatmentData(sl validator.StructLevel) {
 	field := sl.Current().Interface().(CreateTreatmentRequestBody)
-	checkName(sl, "Name", field.Name)
+	v.checkName(sl, "Name", field.Name)
 }
 
 func validateCreateProjectSettingsData(sl validator.StructLevel) {
@@ -88,18 +212,26 @@ func validateUpdateProjectSettingsData(sl validator.StructLevel) {
 	checkTreatmentSchema(sl, field.TreatmentSchema)
 }
 
-func checkName(sl validator.StructLevel, fieldName string, value string) {
+func (v *validationService) checkName(sl validator.StructLevel, fieldName string, value string) {
 	nameRegexDescription := strings.Join([]string{
-		"Name must be between 4-64 characters long, and begin with an alphanumeric character",
+		fmt.Sprintf("Name must be between %d-%d characters long, and begin with an alphanumeric character",
+			minNameLength, v.maxNameLength),
 		"and have no trailing spaces and can contain letters, numbers, blank spaces and the following symbols: -_()#$&:.",
 	}, " ")
-	if !nameRegex.MatchString(value) {
+	if len(value) < minNameLength || len(value) > v.maxNameLength || !nameRegex.MatchString(value) {
 		sl.ReportError(value, fieldName, "name", nameRegexDescription, fmt.Sprintf("%v", value))
 	}
 }
 
-func checkStartTime(sl validator.StructLevel, startTime time.Time) {
-	if startTime.Before(time.Now()) {
+func (v *validationService) checkDescription(sl validator.StructLevel, description *string) {
+	if description != nil && len(*description) > v.maxDescriptionLength {
+		sl.ReportError(description, "Description", "description",
+			fmt.Sprintf("max-length-%d", v.maxDescriptionLength), fmt.Sprintf("%d", len(*description)))
+	}
+}
+
+func (v *validationService) checkStartTime(sl validator.StructLevel, startTime time.Time) {
+	if startTime.Before(v.clock.Now()) {
 		sl.ReportError(startTime, "StartTime", "start_time", "start-time-in-future", fmt.Sprintf("%v", startTime))
 	}
 }
@@ -119,7 +251,31 @@ func checkInterval(sl validator.StructLevel, experimentType models.ExperimentTyp
 	}
 }
 
-func checkTreatments(sl validator.StructLevel, experimentType models.ExperimentType, treatments models.ExperimentTreatments) {
+// checkSwitchbackPeriods checks that enough full intervals fit in the duration of a switchback experiment, for the
+// treatments to be switched at least once. Missing intervals and durations are reported by the other checks.
+func checkSwitchbackPeriods(
+	sl validator.StructLevel,
+	experimentType models.ExperimentType,
+	interval *int32,
+	startTime time.Time,
+	endTime time.Time,
+) {
+	experiment := models.Experiment{Interval: interval, StartTime: startTime, EndTime: endTime}
+	if experimentType != models.ExperimentTypeSwitchback || experiment.IntervalOrZero() <= 0 ||
+		!endTime.After(startTime) {
+		return
+	}
+	if experiment.NumberOfSwitchbackPeriods() < minSwitchbackPeriods {
+		sl.ReportError(interval, "Interval", "interval",
+			fmt.Sprintf("switchback-min-%d-periods", minSwitchbackPeriods), fmt.Sprintf("%d", experiment.IntervalOrZero()))
+	}
+}
+
+func (v *validationService) checkTreatments(
+	sl validator.StructLevel,
+	experimentType models.ExperimentType,
+	treatments models.ExperimentTreatments,
+) {
 	// This needs to be checked here because the OpenAPI tag generation does not work for arrays
 	err := sl.Validator().Var(treatments, "notBlank")
 	if err != nil {
@@ -128,7 +284,7 @@ func checkTreatments(sl validator.StructLevel, experimentType models.ExperimentT
 
 	// Check treatment names
 	for _, treatment := range treatments {
-		checkName(sl, "Treatments", treatment.Name)
+		v.checkName(sl, "Treatments", treatment.Name)
 	}
 
 	// Check that the traffic sum is 100 for AB and 0 or 100 for Switchback
@@ -154,6 +310,11 @@ func checkTreatments(sl validator.StructLevel, experimentType models.ExperimentT
 			sl.ReportError(treatments, "Treatments", "treatments", "traffic-sum-100", fmt.Sprintf("%d", trafficSum))
 		}
 	case models.ExperimentTypeSwitchback:
+		// Switchback experiments switch between their treatments, so there should be at least two of them
+		if len(treatments) < minSwitchbackTreatments {
+			sl.ReportError(treatments, "Treatments", "treatments",
+				fmt.Sprintf("switchback-min-%d-treatments", minSwitchbackTreatments), fmt.Sprintf("%d", len(treatments)))
+		}
 		// Switchback experiments can either have no traffic defined (cyclic switchback),
 		// or the traffic should add to 100 (randomised switchback)
 		if trafficSum != 0 && trafficSum != 100 {
@@ -244,19 +405,58 @@ func (v *validationService) ValidateEntityWithExternalUrl(
 }
 
 // ValidateWithExternalUrl validates the given payload request by sending it to a user-defined HTTP endpoint; if the
-// response from the endpoint is anything but a 200, this method will return an error
+// response from the endpoint is anything but a 200, this method will return an error. Transient failures (network
+// errors and 5xx responses) are retried with exponential backoff, and consecutive failed requests to the same URL trip
+// a circuit breaker, after which requests to the URL fail fast until the cooldown has elapsed.
 func (v *validationService) ValidateWithExternalUrl(
 	reqBody []byte,
 	validationUrl *string,
 ) error {
-	req, err := http.NewRequest("POST", *validationUrl, bytes.NewBuffer(reqBody))
+	if err := v.CheckValidationUrl(*validationUrl); err != nil {
+		return err
+	}
+
+	breaker := v.getCircuitBreaker(validationUrlHost(*validationUrl), v.clock.Now())
+	if !breaker.allow(v.clock.Now()) {
+		return errors.Newf(errors.BadInput,
+			"Custom validation endpoint is unavailable after %d consecutive failures, please try again later",
+			v.config.ValidationUrlCircuitBreakerThreshold)
+	}
+
+	backoff := time.Duration(v.config.ValidationUrlRetryBackoffMillis) * time.Millisecond
+	var err error
+	var transient bool
+	for attempt := 0; attempt <= v.config.ValidationUrlMaxRetries; attempt++ {
+		if attempt > 0 {
+			if backoff > maxValidationUrlRetryBackoff {
+				backoff = maxValidationUrlRetryBackoff
+			}
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		transient, err = v.sendValidationRequest(reqBody, *validationUrl)
+		if err == nil || !transient {
+			break
+		}
+	}
+
+	// Only transient failures count towards opening the circuit breaker; a non-200 response that is not a server error
+	// means that the endpoint is available but the data failed validation
+	breaker.record(!transient, v.clock.Now())
+	return err
+}
+
+// sendValidationRequest sends the given payload to the validation URL, returning an error if the response is anything
+// but a 200, along with whether the failure is transient
+func (v *validationService) sendValidationRequest(reqBody []byte, validationUrl string) (bool, error) {
+	req, err := http.NewRequest("POST", validationUrl, bytes.NewBuffer(reqBody))
 	if err != nil {
-		return errors.Newf(errors.BadInput, "Error creating the HTTP request: %v", err.Error())
+		return false, errors.Newf(errors.BadInput, "Error creating the HTTP request: %v", err.Error())
 	}
 
 	resp, err := v.externalValidationClient.Do(req)
 	if err != nil {
-		return errors.Newf(errors.BadInput, "Error sending request to custom validation endpoint: %v", err.Error())
+		return true, errors.Newf(errors.BadInput, "Error sending request to custom validation endpoint: %v", err.Error())
 	}
 
 	if resp.Body != nil {
@@ -264,8 +464,349 @@ func (v *validationService) ValidateWithExternalUrl(
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return errors.Newf(errors.BadInput, "Error validating data with validation URL: %v", resp.Status)
+		return resp.StatusCode >= http.StatusInternalServerError,
+			errors.Newf(errors.BadInput, "Error validating data with validation URL: %v", resp.Status)
 	}
 
+	return false, nil
+}
+
+// CheckValidationUrl returns an error if the validation URL does not use one of the allowed schemes, or its host is
+// not allowed, so that no requests are sent to the internal endpoints, e.g., the cloud metadata endpoints. It is run
+// when the project settings are saved, and before each request. The host is checked again when it is dialed, as it may
+// then resolve to different IP addresses.
+func (v *validationService) CheckValidationUrl(validationUrl string) error {
+	parsedUrl, err := url.Parse(validationUrl)
+	if err != nil {
+		return errors.Newf(errors.BadInput, "Validation URL %s is invalid: %v", validationUrl, err)
+	}
+	schemeAllowed := false
+	for _, scheme := range v.validationUrlAllowedSchemes {
+		schemeAllowed = schemeAllowed || strings.EqualFold(scheme, parsedUrl.Scheme)
+	}
+	if !schemeAllowed {
+		return errors.Newf(errors.BadInput, "Validation URL %s is not allowed: the scheme must be one of %s",
+			validationUrl, strings.Join(v.validationUrlAllowedSchemes, ", "))
+	}
+
+	if _, err := v.resolveValidationUrlHost(context.Background(), parsedUrl.Hostname()); err != nil {
+		return errors.Newf(errors.BadInput, "Validation URL %s is not allowed: %v", validationUrl, err)
+	}
 	return nil
 }
+
+// resolveValidationUrlHost resolves the host of a validation URL to its IP addresses, returning an error if the host
+// cannot be resolved, or if it, or any of its IP addresses, is blocked or not allowed
+func (v *validationService) resolveValidationUrlHost(ctx context.Context, host string) ([]net.IP, error) {
+	host = strings.ToLower(host)
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IP{ip}
+	} else {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil || len(addrs) == 0 {
+			return nil, fmt.Errorf("the host %s could not be resolved", host)
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	for _, rule := range v.validationUrlBlockedHosts {
+		if rule.matchesName(host) || rule.matchesAnyIP(ips) {
+			return nil, fmt.Errorf("the host %s is blocked", host)
+		}
+	}
+	if len(v.validationUrlAllowedHosts) > 0 && !hostRulesAllow(v.validationUrlAllowedHosts, host, ips) {
+		return nil, fmt.Errorf("the host %s is not one of the allowed hosts", host)
+	}
+	return ips, nil
+}
+
+// dialValidationUrlHost dials the host of a validation URL at the IP addresses that it was checked to resolve to, so
+// that it cannot be rebound to an address that is not allowed between the check and the connection
+func (v *validationService) dialValidationUrlHost(ctx context.Context, network string, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := v.resolveValidationUrlHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{}
+	var conn net.Conn
+	for _, ip := range ips {
+		conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// getCircuitBreaker returns the circuit breaker of the validation URL host, creating it if there is none. The circuit
+// breakers of the other hosts that have been idle for longer than circuitBreakerIdleTimeout are evicted.
+func (v *validationService) getCircuitBreaker(host string, now time.Time) *circuitBreaker {
+	v.circuitBreakersLock.Lock()
+	defer v.circuitBreakersLock.Unlock()
+
+	for otherHost, otherBreaker := range v.circuitBreakers {
+		if otherHost != host && otherBreaker.isIdle(now) {
+			delete(v.circuitBreakers, otherHost)
+			validationUrlCircuitBreakerState.DeleteLabelValues(otherHost)
+		}
+	}
+
+	breaker, ok := v.circuitBreakers[host]
+	if !ok {
+		breaker = &circuitBreaker{
+			threshold: v.config.ValidationUrlCircuitBreakerThreshold,
+			cooldown:  time.Duration(v.config.ValidationUrlCircuitBreakerCooldownSeconds) * time.Second,
+			gauge:     validationUrlCircuitBreakerState.WithLabelValues(host),
+		}
+		breaker.gauge.Set(float64(circuitBreakerStateClosed))
+		v.circuitBreakers[host] = breaker
+	}
+	breaker.touch(now)
+	return breaker
+}
+
+// validationUrlHost returns the host, and the port if any, of the validation URL, without the credentials, the path
+// and the query parameters that the URL may hold
+func validationUrlHost(validationUrl string) string {
+	parsedUrl, err := url.Parse(validationUrl)
+	if err != nil {
+		return ""
+	}
+	return parsedUrl.Host
+}
+
+// WaitForValidationUrlRateLimit takes a token from the rate limiter of the project's validation URL. If the rate is
+// exceeded, it blocks until a token is available, for up to the configured maximum wait; if the wait would be longer,
+// it returns an error immediately. Callers that run validations concurrently should call this within the goroutine
+// that sends the request, so that the other validations are not held up. A rate limit without a positive rate and
+// burst does not limit the requests.
+func (v *validationService) WaitForValidationUrlRateLimit(projectId int64, rateLimit models.ValidationUrlRateLimit) error {
+	if rateLimit.RequestsPerSecond <= 0 || rateLimit.Burst <= 0 {
+		return nil
+	}
+
+	maxWait := time.Duration(rateLimit.MaxWaitMillis) * time.Millisecond
+	wait, ok := v.getRateLimiter(projectId, rateLimit.Burst).reserve(
+		rateLimit.RequestsPerSecond, rateLimit.Burst, maxWait, time.Now())
+	if !ok {
+		return errors.Newf(errors.BadInput,
+			"Rate limit of the custom validation endpoint of project %d exceeded, please try again later", projectId)
+	}
+	time.Sleep(wait)
+	return nil
+}
+
+func (v *validationService) getRateLimiter(projectId int64, burst int) *tokenBucket {
+	v.rateLimitersLock.Lock()
+	defer v.rateLimitersLock.Unlock()
+
+	limiter, ok := v.rateLimiters[projectId]
+	if !ok {
+		limiter = &tokenBucket{tokens: float64(burst), last: time.Now()}
+		v.rateLimiters[projectId] = limiter
+	}
+	return limiter
+}
+
+// getValidationUrlRateLimit returns the rate limit on the requests to the project's validation URL, if any
+func getValidationUrlRateLimit(settings models.Settings) *models.ValidationUrlRateLimit {
+	if settings.ValidationUrl == nil || settings.Config == nil {
+		return nil
+	}
+	return settings.Config.ValidationUrlRateLimit
+}
+
+// hostRule matches the hosts of the validation URLs by their names, e.g., validation.example.com, or *.example.com for
+// any of its subdomains, or by their IP addresses, within a CIDR range
+type hostRule struct {
+	name    string
+	ipRange *net.IPNet
+}
+
+func parseHostRules(rules []string) ([]hostRule, error) {
+	hostRules := []hostRule{}
+	for _, rule := range rules {
+		if strings.Contains(rule, "/") {
+			_, ipRange, err := net.ParseCIDR(rule)
+			if err != nil {
+				return nil, fmt.Errorf("invalid validation URL host rule %s: %v", rule, err)
+			}
+			hostRules = append(hostRules, hostRule{ipRange: ipRange})
+		} else if ip := net.ParseIP(rule); ip != nil {
+			// A single IP address is matched as the CIDR range of just the address
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			hostRules = append(hostRules, hostRule{ipRange: &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}})
+		} else {
+			hostRules = append(hostRules, hostRule{name: strings.ToLower(rule)})
+		}
+	}
+	return hostRules, nil
+}
+
+func (r hostRule) matchesName(host string) bool {
+	if r.ipRange != nil {
+		return false
+	}
+	if strings.HasPrefix(r.name, "*.") {
+		return strings.HasSuffix(host, r.name[1:])
+	}
+	return host == r.name
+}
+
+func (r hostRule) matchesAnyIP(ips []net.IP) bool {
+	if r.ipRange == nil {
+		return false
+	}
+	for _, ip := range ips {
+		if r.ipRange.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostRulesAllow returns true if the host name matches any of the rules, or all of its IP addresses match any of them
+func hostRulesAllow(rules []hostRule, host string, ips []net.IP) bool {
+	for _, rule := range rules {
+		if rule.matchesName(host) {
+			return true
+		}
+	}
+	if len(ips) == 0 {
+		return false
+	}
+	for _, ip := range ips {
+		allowed := false
+		for _, rule := range rules {
+			allowed = allowed || rule.matchesAnyIP([]net.IP{ip})
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenBucket is a rate limiter that holds up to burst tokens, refilled at a constant rate. Requests beyond the rate
+// reserve future tokens (the number of tokens goes negative) and wait for them, so that queued requests are served in
+// order.
+type tokenBucket struct {
+	sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token, returning the time to wait for it. If the wait exceeds maxWait, no token is taken and false
+// is returned.
+func (b *tokenBucket) reserve(rate float64, burst int, maxWait time.Duration, now time.Time) (time.Duration, bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	if now.After(b.last) {
+		b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+		b.last = now
+	}
+
+	tokens := b.tokens - 1
+	var wait time.Duration
+	if tokens < 0 {
+		wait = time.Duration(-tokens / rate * float64(time.Second))
+	}
+	if wait > maxWait {
+		return wait, false
+	}
+	b.tokens = tokens
+	return wait, true
+}
+
+type circuitBreakerState int
+
+const (
+	circuitBreakerStateClosed circuitBreakerState = iota
+	circuitBreakerStateHalfOpen
+	circuitBreakerStateOpen
+)
+
+// circuitBreaker opens after the configured number of consecutive failures, rejecting all requests until the cooldown
+// has elapsed. Thereafter, a single trial request is let through (half-open), which closes the circuit breaker if it
+// succeeds or opens it again otherwise.
+type circuitBreaker struct {
+	sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	gauge     prometheus.Gauge
+
+	state    circuitBreakerState
+	failures int
+	openedAt time.Time
+	lastUsed time.Time
+}
+
+func (b *circuitBreaker) touch(now time.Time) {
+	b.Lock()
+	defer b.Unlock()
+	b.lastUsed = now
+}
+
+// isIdle returns true if the circuit breaker has not been used for circuitBreakerIdleTimeout, nor for its cooldown,
+// so that an open circuit breaker is not evicted before it would have let a request through
+func (b *circuitBreaker) isIdle(now time.Time) bool {
+	b.Lock()
+	defer b.Unlock()
+	idle := now.Sub(b.lastUsed)
+	return idle > circuitBreakerIdleTimeout && idle > b.cooldown
+}
+
+// allow returns true if a request may be sent at the given time
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	switch b.state {
+	case circuitBreakerStateOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.setState(circuitBreakerStateHalfOpen)
+		return true
+	case circuitBreakerStateHalfOpen:
+		// A trial request is already in flight
+		return false
+	default:
+		return true
+	}
+}
+
+// record records the outcome of a request that completed at the given time
+func (b *circuitBreaker) record(success bool, now time.Time) {
+	b.Lock()
+	defer b.Unlock()
+
+	if success {
+		b.failures = 0
+		b.setState(circuitBreakerStateClosed)
+		return
+	}
+
+	b.failures++
+	if b.threshold > 0 && (b.state == circuitBreakerStateHalfOpen || b.failures >= b.threshold) {
+		b.openedAt = now
+		b.setState(circuitBreakerStateOpen)
+	}
+}
+
+func (b *circuitBreaker) setState(state circuitBreakerState) {
+	b.state = state
+	b.gauge.Set(float64(state))
+}
@@ -1,14 +1,18 @@
 package services_test
 
 import (
+	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/caraml-dev/xp/management-service/config"
 	"github.com/caraml-dev/xp/management-service/models"
@@ -30,6 +34,13 @@ var failureEndpoint = "/validate-with-failure"
 // invalidEndpoint is the endpoint of the test HTTP server address that is invalid
 var invalidEndpoint = "/invalid-endpoint"
 
+// fixedClock is a clock that is frozen at the given time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
 type ValidationServiceTestSuite struct {
 	suite.Suite
 	services.ValidationService
@@ -39,7 +50,11 @@ type ValidationServiceTestSuite struct {
 
 func (s *ValidationServiceTestSuite) SetupSuite() {
 	s.Suite.T().Log("Setting up ValidationServiceTestSuite")
-	svc, err := services.NewValidationService(config.ValidationConfig{ValidationUrlTimeoutSeconds: 5})
+	svc, err := services.NewValidationService(config.ValidationConfig{
+		ValidationUrlTimeoutSeconds: 5,
+		ValidationUrlAllowedSchemes: []string{"http"},
+		ValidationUrlBlockedHosts:   []string{"169.254.0.0/16"},
+	})
 	if err != nil {
 		s.Suite.T().Fatalf("Could not set up test data: %v", err)
 	}
@@ -408,7 +423,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentRequestParameters() {
 				Segment:     experimentSegment,
 				StartTime:   time.Now().Add(time.Minute),
 				Status:      models.ExperimentStatusInactive,
-				Treatments:  []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic100}},
+				Treatments:  []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic50}, {Name: name4567, Traffic: &traffic50}},
 				Tier:        models.ExperimentTierDefault,
 				Type:        models.ExperimentTypeSwitchback,
 				UpdatedBy:   &updatedBy,
@@ -425,6 +440,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentRequestParameters() {
 				"Key: 'CreateExperimentRequestBody.Status' Error:Field validation for 'Status' failed on the 'required' tag",
 				"Key: 'CreateExperimentRequestBody.Tier' Error:Field validation for 'Tier' failed on the 'required' tag",
 				"Key: 'CreateExperimentRequestBody.Type' Error:Field validation for 'Type' failed on the 'required' tag",
+				"Key: 'CreateExperimentRequestBody.UpdatedBy' Error:Field validation for 'UpdatedBy' failed on the 'required' tag",
 				strings.Join([]string{
 					"Key: 'CreateExperimentRequestBody.Name' Error:Field validation for 'Name' failed on the",
 					"'Name must be between 4-64 characters long, and begin with an alphanumeric character",
@@ -446,6 +462,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentRequestParameters() {
 			},
 			errString: strings.Join([]string{
 				"Key: 'CreateExperimentRequestBody.Name' Error:Field validation for 'Name' failed on the 'notBlank' tag",
+				"Key: 'CreateExperimentRequestBody.UpdatedBy' Error:Field validation for 'UpdatedBy' failed on the 'notBlank' tag",
 				strings.Join([]string{
 					"Key: 'CreateExperimentRequestBody.Name' Error:Field validation for 'Name' failed on the",
 					"'Name must be between 4-64 characters long, and begin with an alphanumeric character",
@@ -453,8 +470,22 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentRequestParameters() {
 				}, " "),
 				"Key: 'CreateExperimentRequestBody.Interval' Error:Field validation for 'Interval' failed on the 'interval-set-switchback-experiment' tag",
 				"Key: 'CreateExperimentRequestBody.Treatments' Error:Field validation for 'Treatments' failed on the 'notBlank' tag",
+				"Key: 'CreateExperimentRequestBody.Treatments' Error:Field validation for 'Treatments' failed on the 'switchback-min-2-treatments' tag",
 			}, "\n"),
 		},
+		"failure | nil updated by": {
+			data: services.CreateExperimentRequestBody{
+				Name:       nameValid,
+				EndTime:    time.Now().Add(time.Hour),
+				Segment:    experimentSegment,
+				StartTime:  time.Now().Add(time.Minute),
+				Status:     models.ExperimentStatusInactive,
+				Treatments: []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic100}},
+				Tier:       models.ExperimentTierDefault,
+				Type:       models.ExperimentTypeAB,
+			},
+			errString: "Key: 'CreateExperimentRequestBody.UpdatedBy' Error:Field validation for 'UpdatedBy' failed on the 'required' tag",
+		},
 		"failure | traffic not 100": {
 			data: services.CreateExperimentRequestBody{
 				Name:       nameValid,
@@ -523,7 +554,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentRequestParameters() {
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic100}},
+				Treatments: []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic50}, {Name: name4567, Traffic: &traffic50}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -540,7 +571,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentRequestParameters() {
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic100}},
+				Treatments: []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic50}, {Name: name4567, Traffic: &traffic50}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -559,7 +590,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentRequestParameters() {
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: nameInvalid, Traffic: &traffic100}},
+				Treatments: []models.ExperimentTreatment{{Name: nameInvalid, Traffic: &traffic50}, {Name: name1234, Traffic: &traffic50}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -571,6 +602,39 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentRequestParameters() {
 				"and have no trailing spaces and can contain letters, numbers, blank spaces and the following symbols: -_()#$&:.' tag",
 			}, " "),
 		},
+		"failure | switchback with less than 2 periods": {
+			data: services.CreateExperimentRequestBody{
+				Name:      nameValid,
+				EndTime:   time.Now().Add(20 * time.Minute),
+				Interval:  &interval,
+				Segment:   experimentSegment,
+				StartTime: time.Now().Add(time.Minute),
+				Status:    models.ExperimentStatusInactive,
+				Treatments: []models.ExperimentTreatment{
+					{Name: name1234, Traffic: &traffic50},
+					{Name: name4567, Traffic: &traffic50},
+				},
+				Tier:      models.ExperimentTierDefault,
+				Type:      models.ExperimentTypeSwitchback,
+				UpdatedBy: &updatedBy,
+			},
+			errString: "Key: 'CreateExperimentRequestBody.Interval' Error:Field validation for 'Interval' failed on the 'switchback-min-2-periods' tag",
+		},
+		"failure | switchback with one treatment": {
+			data: services.CreateExperimentRequestBody{
+				Name:       nameValid,
+				EndTime:    time.Now().Add(time.Hour),
+				Interval:   &interval,
+				Segment:    experimentSegment,
+				StartTime:  time.Now().Add(time.Minute),
+				Status:     models.ExperimentStatusInactive,
+				Treatments: []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic100}},
+				Tier:       models.ExperimentTierDefault,
+				Type:       models.ExperimentTypeSwitchback,
+				UpdatedBy:  &updatedBy,
+			},
+			errString: "Key: 'CreateExperimentRequestBody.Treatments' Error:Field validation for 'Treatments' failed on the 'switchback-min-2-treatments' tag",
+		},
 		"failure | non-unique treatment name": {
 			data: services.CreateExperimentRequestBody{
 				Name:      nameValid,
@@ -673,7 +737,7 @@ func (s *ValidationServiceTestSuite) TestUpdateExperimentRequestParameters() {
 				Segment:     experimentSegment,
 				StartTime:   time.Now().Add(time.Minute),
 				Status:      models.ExperimentStatusInactive,
-				Treatments:  []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic100}},
+				Treatments:  []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic50}, {Name: name4567, Traffic: &traffic50}},
 				Tier:        models.ExperimentTierOverride,
 				Type:        models.ExperimentTypeSwitchback,
 				UpdatedBy:   &updatedBy,
@@ -689,6 +753,7 @@ func (s *ValidationServiceTestSuite) TestUpdateExperimentRequestParameters() {
 				"Key: 'UpdateExperimentRequestBody.Status' Error:Field validation for 'Status' failed on the 'required' tag",
 				"Key: 'UpdateExperimentRequestBody.Tier' Error:Field validation for 'Tier' failed on the 'required' tag",
 				"Key: 'UpdateExperimentRequestBody.Type' Error:Field validation for 'Type' failed on the 'required' tag",
+				"Key: 'UpdateExperimentRequestBody.UpdatedBy' Error:Field validation for 'UpdatedBy' failed on the 'required' tag",
 				"Key: 'UpdateExperimentRequestBody.StartTime' Error:Field validation for 'StartTime' failed on the 'start-time-in-future' tag",
 				"Key: 'UpdateExperimentRequestBody.Treatments' Error:Field validation for 'Treatments' failed on the 'notBlank' tag",
 			}, "\n"),
@@ -703,10 +768,24 @@ func (s *ValidationServiceTestSuite) TestUpdateExperimentRequestParameters() {
 				UpdatedBy: &blankUpdatedBy,
 			},
 			errString: strings.Join([]string{
+				"Key: 'UpdateExperimentRequestBody.UpdatedBy' Error:Field validation for 'UpdatedBy' failed on the 'notBlank' tag",
 				"Key: 'UpdateExperimentRequestBody.Interval' Error:Field validation for 'Interval' failed on the 'interval-set-switchback-experiment' tag",
 				"Key: 'UpdateExperimentRequestBody.Treatments' Error:Field validation for 'Treatments' failed on the 'notBlank' tag",
+				"Key: 'UpdateExperimentRequestBody.Treatments' Error:Field validation for 'Treatments' failed on the 'switchback-min-2-treatments' tag",
 			}, "\n"),
 		},
+		"failure | nil updated by": {
+			data: services.UpdateExperimentRequestBody{
+				EndTime:    time.Now().Add(time.Hour),
+				Segment:    experimentSegment,
+				StartTime:  time.Now().Add(time.Minute),
+				Status:     models.ExperimentStatusInactive,
+				Treatments: []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic100}},
+				Tier:       models.ExperimentTierDefault,
+				Type:       models.ExperimentTypeAB,
+			},
+			errString: "Key: 'UpdateExperimentRequestBody.UpdatedBy' Error:Field validation for 'UpdatedBy' failed on the 'required' tag",
+		},
 		"failure | traffic not 100": {
 			data: services.UpdateExperimentRequestBody{
 				EndTime:    time.Now().Add(time.Hour),
@@ -770,7 +849,7 @@ func (s *ValidationServiceTestSuite) TestUpdateExperimentRequestParameters() {
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic100}},
+				Treatments: []models.ExperimentTreatment{{Name: name1234, Traffic: &traffic50}, {Name: name4567, Traffic: &traffic50}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -780,13 +859,27 @@ func (s *ValidationServiceTestSuite) TestUpdateExperimentRequestParameters() {
 				"Key: 'UpdateExperimentRequestBody.Interval' Error:Field validation for 'Interval' ",
 				"failed on the 'interval-set-switchback-experiment' tag"}, ""),
 		},
+		"failure | switchback with one treatment": {
+			data: services.UpdateExperimentRequestBody{
+				EndTime:    time.Now().Add(time.Hour),
+				Interval:   &interval,
+				Segment:    experimentSegment,
+				StartTime:  time.Now().Add(time.Minute),
+				Status:     models.ExperimentStatusInactive,
+				Treatments: []models.ExperimentTreatment{{Name: name1234}},
+				Tier:       models.ExperimentTierDefault,
+				Type:       models.ExperimentTypeSwitchback,
+				UpdatedBy:  &updatedBy,
+			},
+			errString: "Key: 'UpdateExperimentRequestBody.Treatments' Error:Field validation for 'Treatments' failed on the 'switchback-min-2-treatments' tag",
+		},
 		"failure | incorrect regex for treatment name": {
 			data: services.UpdateExperimentRequestBody{
 				EndTime:    time.Now().Add(time.Hour),
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: nameInvalid, Traffic: &traffic100}},
+				Treatments: []models.ExperimentTreatment{{Name: nameInvalid, Traffic: &traffic50}, {Name: name1234, Traffic: &traffic50}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -969,6 +1062,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentNameRegex() {
 	interval := int32(10)
 	updatedBy := "testuser"
 	name1234 := "1234"
+	name4567 := "4567"
 	experimentSegment := models.ExperimentSegmentRaw{}
 	tests := map[string]struct {
 		data      services.CreateExperimentRequestBody
@@ -982,7 +1076,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentNameRegex() {
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: name1234}},
+				Treatments: []models.ExperimentTreatment{{Name: name1234}, {Name: name4567}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -1001,7 +1095,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentNameRegex() {
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: name1234}},
+				Treatments: []models.ExperimentTreatment{{Name: name1234}, {Name: name4567}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -1020,7 +1114,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentNameRegex() {
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: name1234}},
+				Treatments: []models.ExperimentTreatment{{Name: name1234}, {Name: name4567}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -1039,7 +1133,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentNameRegex() {
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: name1234}},
+				Treatments: []models.ExperimentTreatment{{Name: name1234}, {Name: name4567}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -1058,7 +1152,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentNameRegex() {
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: name1234}},
+				Treatments: []models.ExperimentTreatment{{Name: name1234}, {Name: name4567}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -1077,7 +1171,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentNameRegex() {
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: name1234}},
+				Treatments: []models.ExperimentTreatment{{Name: name1234}, {Name: name4567}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -1091,7 +1185,7 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentNameRegex() {
 				Segment:    experimentSegment,
 				StartTime:  time.Now().Add(time.Minute),
 				Status:     models.ExperimentStatusInactive,
-				Treatments: []models.ExperimentTreatment{{Name: name1234}},
+				Treatments: []models.ExperimentTreatment{{Name: name1234}, {Name: name4567}},
 				Tier:       models.ExperimentTierDefault,
 				Type:       models.ExperimentTypeSwitchback,
 				UpdatedBy:  &updatedBy,
@@ -1111,6 +1205,127 @@ func (s *ValidationServiceTestSuite) TestCreateExperimentNameRegex() {
 	}
 }
 
+func (s *ValidationServiceTestSuite) TestCreateExperimentNameAndDescriptionLength() {
+	interval := int32(10)
+	updatedBy := "testuser"
+	name1234 := "1234"
+	name4567 := "4567"
+	descriptionMax := strings.Repeat("a", 4096)
+	descriptionTooLong := strings.Repeat("a", 4097)
+	descriptionCustomMax := strings.Repeat("a", 20)
+	newRequestBody := func(name string, description *string) services.CreateExperimentRequestBody {
+		return services.CreateExperimentRequestBody{
+			Name:        name,
+			Description: description,
+			EndTime:     time.Now().Add(time.Hour),
+			Interval:    &interval,
+			Segment:     models.ExperimentSegmentRaw{},
+			StartTime:   time.Now().Add(time.Minute),
+			Status:      models.ExperimentStatusInactive,
+			Treatments:  []models.ExperimentTreatment{{Name: name1234}, {Name: name4567}},
+			Tier:        models.ExperimentTierDefault,
+			Type:        models.ExperimentTypeSwitchback,
+			UpdatedBy:   &updatedBy,
+		}
+	}
+	nameErrString := func(maxLength int) string {
+		return strings.Join([]string{
+			"Key: 'CreateExperimentRequestBody.Name' Error:Field validation for 'Name' failed on the",
+			fmt.Sprintf("'Name must be between 4-%d characters long, and begin with an alphanumeric character", maxLength),
+			"and have no trailing spaces and can contain letters, numbers, blank spaces and the following symbols: -_()#$&:.' tag",
+		}, " ")
+	}
+
+	customSvc, err := services.NewValidationService(config.ValidationConfig{
+		MaxNameLength:        10,
+		MaxDescriptionLength: 20,
+	})
+	s.Suite.Require().NoError(err)
+
+	tests := map[string]struct {
+		svc       services.ValidationService
+		data      services.CreateExperimentRequestBody
+		errString string
+	}{
+		"success | default max lengths": {
+			svc:  s.ValidationService,
+			data: newRequestBody(strings.Repeat("a", 64), &descriptionMax),
+		},
+		"failure | name exceeds default max length": {
+			svc:       s.ValidationService,
+			data:      newRequestBody(strings.Repeat("a", 65), nil),
+			errString: nameErrString(64),
+		},
+		"failure | description exceeds default max length": {
+			svc:  s.ValidationService,
+			data: newRequestBody("abcd", &descriptionTooLong),
+			errString: "Key: 'CreateExperimentRequestBody.Description' " +
+				"Error:Field validation for 'Description' failed on the 'max-length-4096' tag",
+		},
+		"success | configured max lengths": {
+			svc:  customSvc,
+			data: newRequestBody(strings.Repeat("a", 10), &descriptionCustomMax),
+		},
+		"failure | configured max lengths exceeded": {
+			svc:  customSvc,
+			data: newRequestBody(strings.Repeat("a", 11), &descriptionMax),
+			errString: strings.Join([]string{
+				nameErrString(10),
+				"Key: 'CreateExperimentRequestBody.Description' " +
+					"Error:Field validation for 'Description' failed on the 'max-length-20' tag",
+			}, "\n"),
+		},
+	}
+
+	for name, data := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			err := data.svc.Validate(data.data)
+			if data.errString == "" {
+				s.Suite.Require().NoError(err)
+			} else {
+				s.Suite.Assert().EqualError(err, data.errString)
+			}
+		})
+	}
+
+	// The max name length is not limited by the regular expression of the names
+	longNameSvc, err := services.NewValidationService(config.ValidationConfig{MaxNameLength: 2000})
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().NoError(longNameSvc.Validate(newRequestBody(strings.Repeat("a", 2000), nil)))
+	s.Suite.Assert().EqualError(longNameSvc.Validate(newRequestBody(strings.Repeat("a", 2001), nil)), nameErrString(2000))
+
+	// The max name length must allow for the min name length
+	_, err = services.NewValidationService(config.ValidationConfig{MaxNameLength: 3})
+	s.Suite.Assert().EqualError(err, "max name length must be at least 4")
+}
+
+func (s *ValidationServiceTestSuite) TestValidateStartTimeFrozenClock() {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc, err := services.NewValidationServiceWithClock(config.ValidationConfig{}, fixedClock(now))
+	s.Suite.Require().NoError(err)
+
+	newRequestBody := func(startTime time.Time) services.CreateExperimentRequestBody {
+		updatedBy := "test-user"
+		traffic := int32(100)
+		return services.CreateExperimentRequestBody{
+			Name:       "exp-name",
+			EndTime:    now.Add(time.Hour),
+			StartTime:  startTime,
+			Status:     models.ExperimentStatusInactive,
+			Treatments: []models.ExperimentTreatment{{Name: "control", Traffic: &traffic}},
+			Tier:       models.ExperimentTierDefault,
+			Type:       models.ExperimentTypeAB,
+			UpdatedBy:  &updatedBy,
+		}
+	}
+
+	// The start time is checked against the clock of the service rather than the real time
+	s.Suite.Assert().NoError(svc.Validate(newRequestBody(now)))
+	s.Suite.Assert().NoError(svc.Validate(newRequestBody(now.Add(time.Minute))))
+	s.Suite.Assert().EqualError(svc.Validate(newRequestBody(now.Add(-time.Minute))),
+		"Key: 'CreateExperimentRequestBody.StartTime' Error:Field validation for 'StartTime' failed on the 'start-time-in-future' tag")
+}
+
 func (s *ValidationServiceTestSuite) TestValidateDataWithValidationUrl() {
 	treatment := models.Treatment{
 		Configuration: models.TreatmentConfig{
@@ -1185,6 +1400,234 @@ func (s *ValidationServiceTestSuite) TestValidateDataWithValidationUrl() {
 	}
 }
 
+func (s *ValidationServiceTestSuite) TestValidateWithExternalUrlRetriesAndCircuitBreaker() {
+	clock := fixedClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	svc, err := services.NewValidationServiceWithClock(config.ValidationConfig{
+		ValidationUrlTimeoutSeconds:                5,
+		ValidationUrlMaxRetries:                    2,
+		ValidationUrlRetryBackoffMillis:            1,
+		ValidationUrlCircuitBreakerThreshold:       2,
+		ValidationUrlCircuitBreakerCooldownSeconds: 1,
+		ValidationUrlAllowedSchemes:                []string{"http"},
+		ValidationUrlBlockedHosts:                  []string{"169.254.0.0/16"},
+	}, &clock)
+	s.Suite.Require().NoError(err)
+
+	// The test server fails the number of requests set in failuresLeft with the given status, before succeeding
+	var requestCount, failuresLeft, failureStatus int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		if atomic.AddInt32(&failuresLeft, -1) >= 0 {
+			rw.WriteHeader(int(atomic.LoadInt32(&failureStatus)))
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	// The credentials of the validation URL are left out of the metric, which is labelled by the host
+	validationUrl := strings.Replace(server.URL, "http://", "http://user:secret@", 1)
+	host := server.Listener.Addr().String()
+
+	setUpServer := func(failures int32, status int) {
+		atomic.StoreInt32(&requestCount, 0)
+		atomic.StoreInt32(&failuresLeft, failures)
+		atomic.StoreInt32(&failureStatus, int32(status))
+	}
+
+	// Transient failures are retried
+	setUpServer(2, http.StatusServiceUnavailable)
+	s.Suite.Require().NoError(svc.ValidateWithExternalUrl([]byte(`{}`), &validationUrl))
+	s.Suite.Assert().Equal(int32(3), atomic.LoadInt32(&requestCount))
+	s.Suite.Assert().Equal(float64(0), getCircuitBreakerStateMetric(host))
+
+	// Validation failures are not retried, and do not trip the circuit breaker
+	for i := 0; i < 3; i++ {
+		setUpServer(1, http.StatusBadRequest)
+		err = svc.ValidateWithExternalUrl([]byte(`{}`), &validationUrl)
+		s.Suite.Assert().EqualError(err, "Error validating data with validation URL: 400 Bad Request")
+		s.Suite.Assert().Equal(int32(1), atomic.LoadInt32(&requestCount))
+	}
+	s.Suite.Assert().Equal(float64(0), getCircuitBreakerStateMetric(host))
+
+	// Persistent failures open the circuit breaker, after which requests fail fast
+	setUpServer(100, http.StatusInternalServerError)
+	for i := 0; i < 2; i++ {
+		err = svc.ValidateWithExternalUrl([]byte(`{}`), &validationUrl)
+		s.Suite.Assert().EqualError(err, "Error validating data with validation URL: 500 Internal Server Error")
+	}
+	s.Suite.Assert().Equal(int32(6), atomic.LoadInt32(&requestCount))
+	s.Suite.Assert().Equal(float64(2), getCircuitBreakerStateMetric(host))
+	err = svc.ValidateWithExternalUrl([]byte(`{}`), &validationUrl)
+	s.Suite.Assert().EqualError(err,
+		"Custom validation endpoint is unavailable after 2 consecutive failures, please try again later")
+	s.Suite.Assert().Equal(int32(6), atomic.LoadInt32(&requestCount))
+
+	// The circuit breaker stays open until the cooldown has elapsed on the clock
+	clock = fixedClock(time.Time(clock).Add(999 * time.Millisecond))
+	err = svc.ValidateWithExternalUrl([]byte(`{}`), &validationUrl)
+	s.Suite.Assert().EqualError(err,
+		"Custom validation endpoint is unavailable after 2 consecutive failures, please try again later")
+	s.Suite.Assert().Equal(int32(6), atomic.LoadInt32(&requestCount))
+
+	// The circuit breaker closes once the endpoint recovers, after the cooldown
+	setUpServer(0, http.StatusInternalServerError)
+	clock = fixedClock(time.Time(clock).Add(time.Millisecond))
+	s.Suite.Require().NoError(svc.ValidateWithExternalUrl([]byte(`{}`), &validationUrl))
+	s.Suite.Assert().Equal(int32(1), atomic.LoadInt32(&requestCount))
+	s.Suite.Assert().Equal(float64(0), getCircuitBreakerStateMetric(host))
+}
+
+func (s *ValidationServiceTestSuite) TestValidateWithExternalUrlAllowedSchemesAndHosts() {
+	validationSuccessUrl := "http://" + testHTTPServerAddr + successEndpoint
+	// The loopback addresses of the test server are blocked by default
+	blockedHosts := []string{"169.254.0.0/16"}
+
+	tests := map[string]struct {
+		config        config.ValidationConfig
+		validationUrl string
+		errString     string
+	}{
+		"failure | http is not allowed by default": {
+			validationUrl: validationSuccessUrl,
+			errString: fmt.Sprintf("Validation URL %s is not allowed: the scheme must be one of https",
+				validationSuccessUrl),
+		},
+		"failure | metadata endpoint is blocked by default": {
+			config:        config.ValidationConfig{ValidationUrlAllowedSchemes: []string{"http", "https"}},
+			validationUrl: "http://169.254.169.254/computeMetadata/v1/",
+			errString: "Validation URL http://169.254.169.254/computeMetadata/v1/ is not allowed: " +
+				"the host 169.254.169.254 is blocked",
+		},
+		"failure | loopback address is blocked by default": {
+			config:        config.ValidationConfig{ValidationUrlAllowedSchemes: []string{"http"}},
+			validationUrl: validationSuccessUrl,
+			errString: fmt.Sprintf("Validation URL %s is not allowed: the host 127.0.0.1 is blocked",
+				validationSuccessUrl),
+		},
+		"failure | host that cannot be resolved": {
+			validationUrl: "https://validation.invalid/validate",
+			errString: "Validation URL https://validation.invalid/validate is not allowed: " +
+				"the host validation.invalid could not be resolved",
+		},
+		"failure | blocked host name": {
+			config: config.ValidationConfig{
+				ValidationUrlAllowedSchemes: []string{"http"},
+				ValidationUrlBlockedHosts:   []string{"localhost"},
+			},
+			validationUrl: "http://localhost:9000" + successEndpoint,
+			errString: fmt.Sprintf("Validation URL http://localhost:9000%s is not allowed: the host localhost is blocked",
+				successEndpoint),
+		},
+		"failure | host resolving to a blocked range": {
+			config: config.ValidationConfig{
+				ValidationUrlAllowedSchemes: []string{"http"},
+				ValidationUrlBlockedHosts:   []string{"127.0.0.0/8"},
+			},
+			validationUrl: "http://localhost:9000" + successEndpoint,
+			errString: fmt.Sprintf("Validation URL http://localhost:9000%s is not allowed: the host localhost is blocked",
+				successEndpoint),
+		},
+		"failure | host not in the allowed hosts": {
+			config: config.ValidationConfig{
+				ValidationUrlAllowedSchemes: []string{"http"},
+				ValidationUrlAllowedHosts:   []string{"*.example.com", "10.0.0.0/8"},
+				ValidationUrlBlockedHosts:   blockedHosts,
+			},
+			validationUrl: validationSuccessUrl,
+			errString: fmt.Sprintf("Validation URL %s is not allowed: the host 127.0.0.1 is not one of the allowed hosts",
+				validationSuccessUrl),
+		},
+		"success | allowed scheme": {
+			config: config.ValidationConfig{
+				ValidationUrlAllowedSchemes: []string{"http"},
+				ValidationUrlBlockedHosts:   blockedHosts,
+			},
+			validationUrl: validationSuccessUrl,
+		},
+		"success | host in an allowed range": {
+			config: config.ValidationConfig{
+				ValidationUrlAllowedSchemes: []string{"http"},
+				ValidationUrlAllowedHosts:   []string{"*.example.com", "127.0.0.0/8"},
+				ValidationUrlBlockedHosts:   blockedHosts,
+			},
+			validationUrl: validationSuccessUrl,
+		},
+		"success | allowed host name": {
+			config: config.ValidationConfig{
+				ValidationUrlAllowedSchemes: []string{"http"},
+				ValidationUrlAllowedHosts:   []string{"localhost"},
+				ValidationUrlBlockedHosts:   blockedHosts,
+			},
+			validationUrl: "http://localhost:9000" + successEndpoint,
+		},
+	}
+
+	for name, test := range tests {
+		s.Suite.T().Run(name, func(t *testing.T) {
+			svc, err := services.NewValidationService(test.config)
+			s.Suite.Require().NoError(err)
+			err = svc.ValidateWithExternalUrl([]byte(`{}`), &test.validationUrl)
+			if test.errString == "" {
+				s.Suite.Require().NoError(err)
+			} else {
+				s.Suite.Assert().EqualError(err, test.errString)
+			}
+
+			// The validation URL is checked in the same way when the project settings are saved
+			err = svc.CheckValidationUrl(test.validationUrl)
+			if test.errString == "" {
+				s.Suite.Require().NoError(err)
+			} else {
+				s.Suite.Assert().EqualError(err, test.errString)
+			}
+		})
+	}
+
+	// The host rules must be valid
+	_, err := services.NewValidationService(config.ValidationConfig{ValidationUrlBlockedHosts: []string{"10.0.0.0/33"}})
+	s.Suite.Assert().EqualError(err, "invalid validation URL host rule 10.0.0.0/33: invalid CIDR address: 10.0.0.0/33")
+}
+
+func (s *ValidationServiceTestSuite) TestWaitForValidationUrlRateLimit() {
+	svc, err := services.NewValidationService(config.ValidationConfig{})
+	s.Suite.Require().NoError(err)
+
+	// Requests beyond the burst are rejected immediately
+	rejectingLimit := models.ValidationUrlRateLimit{RequestsPerSecond: 1, Burst: 2}
+	s.Suite.Require().NoError(svc.WaitForValidationUrlRateLimit(1, rejectingLimit))
+	s.Suite.Require().NoError(svc.WaitForValidationUrlRateLimit(1, rejectingLimit))
+	err = svc.WaitForValidationUrlRateLimit(1, rejectingLimit)
+	s.Suite.Assert().EqualError(err,
+		"Rate limit of the custom validation endpoint of project 1 exceeded, please try again later")
+	// The rate limit is tracked per project
+	s.Suite.Require().NoError(svc.WaitForValidationUrlRateLimit(2, rejectingLimit))
+
+	// Requests beyond the burst are queued, up to the maximum wait
+	queueingLimit := models.ValidationUrlRateLimit{RequestsPerSecond: 20, Burst: 1, MaxWaitMillis: 1000}
+	startTime := time.Now()
+	g := new(errgroup.Group)
+	for i := 0; i < 3; i++ {
+		g.Go(func() error {
+			return svc.WaitForValidationUrlRateLimit(3, queueingLimit)
+		})
+	}
+	s.Suite.Require().NoError(g.Wait())
+	// The first request is sent at once, and the other two wait for a token each, 50ms apart
+	s.Suite.Assert().GreaterOrEqual(time.Since(startTime), 90*time.Millisecond)
+
+	// Requests that would wait for longer than the maximum are rejected
+	shortWaitLimit := models.ValidationUrlRateLimit{RequestsPerSecond: 1, Burst: 1, MaxWaitMillis: 100}
+	s.Suite.Require().NoError(svc.WaitForValidationUrlRateLimit(4, shortWaitLimit))
+	err = svc.WaitForValidationUrlRateLimit(4, shortWaitLimit)
+	s.Suite.Assert().EqualError(err,
+		"Rate limit of the custom validation endpoint of project 4 exceeded, please try again later")
+
+	// A rate limit without a rate does not limit the requests
+	for i := 0; i < 3; i++ {
+		s.Suite.Require().NoError(svc.WaitForValidationUrlRateLimit(5, models.ValidationUrlRateLimit{}))
+	}
+}
+
 func (s *ValidationServiceTestSuite) TestValidateRulePredicate() {
 	tests := map[string]struct {
 		predicate string
@@ -1209,3 +1652,21 @@ func (s *ValidationServiceTestSuite) TestValidateRulePredicate() {
 		})
 	}
 }
+
+// getCircuitBreakerStateMetric returns the value of the circuit breaker state metric of the given validation URL
+func getCircuitBreakerStateMetric(host string) float64 {
+	metricFamilies, _ := prometheus.DefaultGatherer.Gather()
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() != "mlp_xp_management_service_validation_url_circuit_breaker_state" {
+			continue
+		}
+		for _, metric := range metricFamily.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "validation_host" && label.GetValue() == host {
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return -1
+}
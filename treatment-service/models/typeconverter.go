@@ -33,6 +33,11 @@ func OpenAPIProjectSettingsSpecToProtobuf(projectSettings schema.ProjectSettings
 		Variables: variables,
 	}
 
+	var priorityOverlapResolution bool
+	if projectSettings.PriorityOverlapResolution != nil {
+		priorityOverlapResolution = *projectSettings.PriorityOverlapResolution
+	}
+
 	return &_pubsub.ProjectSettings{
 		ProjectId:            projectSettings.ProjectId,
 		CreatedAt:            &timestamppb.Timestamp{Seconds: projectSettings.CreatedAt.Unix()},
@@ -42,6 +47,8 @@ func OpenAPIProjectSettingsSpecToProtobuf(projectSettings schema.ProjectSettings
 		EnableS2IdClustering: projectSettings.EnableS2idClustering,
 		Segmenters:           segmenters,
 		RandomizationKey:     projectSettings.RandomizationKey,
+
+		PriorityOverlapResolution: priorityOverlapResolution,
 	}
 }
 
@@ -159,6 +166,16 @@ func OpenAPIExperimentSpecToProtobuf(
 		updatedAt = *xpExperiment.UpdatedAt
 	}
 
+	var shadow bool
+	if xpExperiment.Shadow != nil {
+		shadow = *xpExperiment.Shadow
+	}
+
+	var priority int32
+	if xpExperiment.Priority != nil {
+		priority = *xpExperiment.Priority
+	}
+
 	return &_pubsub.Experiment{
 		Id:         *xpExperiment.Id,
 		ProjectId:  *xpExperiment.ProjectId,
@@ -173,6 +190,8 @@ func OpenAPIExperimentSpecToProtobuf(
 		EndTime:    &timestamppb.Timestamp{Seconds: endTime.Unix()},
 		UpdatedAt:  &timestamppb.Timestamp{Seconds: updatedAt.Unix()},
 		Version:    version,
+		Shadow:     shadow,
+		Priority:   priority,
 	}, nil
 }
 
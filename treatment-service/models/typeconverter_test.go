@@ -23,6 +23,8 @@ func TestOpenAPIProjectSettingsSpecToProtobuf(t *testing.T) {
 		},
 	}
 
+	priorityOverlapResolution := true
+
 	tests := []struct {
 		Name     string
 		Settings schema.ProjectSettings
@@ -59,6 +61,28 @@ func TestOpenAPIProjectSettingsSpecToProtobuf(t *testing.T) {
 				EnableS2IdClustering: true,
 			},
 		},
+		{
+			Name: "priority overlap resolution",
+			Settings: schema.ProjectSettings{
+				CreatedAt:                 time.Date(2021, 1, 1, 2, 3, 4, 0, time.UTC),
+				UpdatedAt:                 time.Date(2021, 1, 2, 3, 3, 3, 0, time.UTC),
+				ProjectId:                 2,
+				Username:                  "client-2",
+				Passkey:                   "passkey-2",
+				RandomizationKey:          "rand-2",
+				PriorityOverlapResolution: &priorityOverlapResolution,
+			},
+			Expected: &pubsub.ProjectSettings{
+				ProjectId:                 2,
+				CreatedAt:                 timestamppb.New(time.Date(2021, 1, 1, 2, 3, 4, 0, time.UTC)),
+				RandomizationKey:          "rand-2",
+				Segmenters:                &pubsub.Segmenters{Variables: map[string]*pubsub.ExperimentVariables{}},
+				UpdatedAt:                 timestamppb.New(time.Date(2021, 1, 2, 3, 3, 3, 0, time.UTC)),
+				Username:                  "client-2",
+				Passkey:                   "passkey-2",
+				PriorityOverlapResolution: true,
+			},
+		},
 	}
 
 	// Run tests
@@ -80,6 +104,8 @@ func TestOpenAPIExperimentSpecToProtobuf(t *testing.T) {
 	typeAB := schema.ExperimentTypeAB
 	typeSwitchback := schema.ExperimentTypeSwitchback
 	version := int64(2)
+	shadow := true
+	priority := int32(3)
 	startTime := time.Date(2021, 1, 1, 2, 3, 4, 0, time.UTC)
 	endTime := time.Date(2022, 1, 1, 2, 3, 4, 0, time.UTC)
 	createdAt := time.Date(2020, 1, 1, 2, 3, 4, 0, time.UTC)
@@ -155,7 +181,7 @@ func TestOpenAPIExperimentSpecToProtobuf(t *testing.T) {
 			},
 		},
 		{
-			Name: "inactive override switchback experiment",
+			Name: "inactive override shadow switchback experiment",
 			Experiment: schema.Experiment{
 				ProjectId: &projectId,
 				Id:        &id,
@@ -169,6 +195,8 @@ func TestOpenAPIExperimentSpecToProtobuf(t *testing.T) {
 				CreatedAt: &createdAt,
 				UpdatedAt: &updatedAt,
 				Version:   &version,
+				Shadow:    &shadow,
+				Priority:  &priority,
 			},
 			Expected: &pubsub.Experiment{
 				ProjectId:  1,
@@ -184,6 +212,8 @@ func TestOpenAPIExperimentSpecToProtobuf(t *testing.T) {
 				EndTime:    timestamppb.New(time.Date(2022, 1, 1, 2, 3, 4, 0, time.UTC)),
 				UpdatedAt:  timestamppb.New(time.Date(2020, 2, 1, 2, 3, 4, 0, time.UTC)),
 				Version:    2,
+				Shadow:     true,
+				Priority:   3,
 			},
 		},
 	}
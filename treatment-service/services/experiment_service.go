@@ -44,8 +44,9 @@ func (es *experimentService) GetExperiment(
 ) ([]models.SegmentFilter, *_pubsub.Experiment, error) {
 	// Convert filterParams to Segmenter values
 	lookupRequestFilters := es.generateLookupRequest(requestFilter)
-	// Retrieve all matching experiments from storage
-	matches := es.localStorage.FindExperiments(projectId, lookupRequestFilters)
+	// Retrieve all matching experiments from storage. Shadow experiments only observe the traffic, so they are never
+	// assigned and are not resolved against the other experiments.
+	matches := es.filterShadowExperiments(es.localStorage.FindExperiments(projectId, lookupRequestFilters))
 
 	projectSettings := es.localStorage.FindProjectSettingsWithId(projectId)
 	// Retrieve segmentersTypeMapping that are active with respect to the given project
@@ -71,6 +72,10 @@ func (es *experimentService) GetExperiment(
 		// (in different tiers), based on the orthogonality rules enforced by the management service.
 		es.filterByTierPriority,
 	}
+	// Resolve the remaining overlaps by priority, for the projects that allow the management service to save them
+	if projectSettings.GetPriorityOverlapResolution() {
+		filters = append(filters, es.filterByExperimentPriority)
+	}
 
 	// While we have more than 1 experiment, progressively apply the filters
 	for _, filter := range filters {
@@ -99,6 +104,16 @@ func (es *experimentService) generateLookupRequest(requestFilter map[string][]*_
 	return filters
 }
 
+func (es *experimentService) filterShadowExperiments(matches []*models.ExperimentMatch) []*models.ExperimentMatch {
+	filtered := []*models.ExperimentMatch{}
+	for _, match := range matches {
+		if !match.Experiment.GetShadow() {
+			filtered = append(filtered, match)
+		}
+	}
+	return filtered
+}
+
 func (es *experimentService) filterByMatchStrength(
 	matches []*models.ExperimentMatch,
 	segmenters []string,
@@ -166,6 +181,20 @@ func (es *experimentService) filterByLookupOrder(
 	return filtered
 }
 
+// filterByExperimentPriority keeps the match with the highest priority. Of the matches with the same priority, the
+// one created first (i.e., with the lowest id) is kept, as the management service does when it resolves the overlaps.
+func (es *experimentService) filterByExperimentPriority(matches []*models.ExperimentMatch) []*models.ExperimentMatch {
+	var winner *models.ExperimentMatch
+	for _, match := range matches {
+		if winner == nil ||
+			match.Experiment.Priority > winner.Experiment.Priority ||
+			(match.Experiment.Priority == winner.Experiment.Priority && match.Experiment.Id < winner.Experiment.Id) {
+			winner = match
+		}
+	}
+	return []*models.ExperimentMatch{winner}
+}
+
 func (es *experimentService) filterByTierPriority(matches []*models.ExperimentMatch) []*models.ExperimentMatch {
 	overrides := []*models.ExperimentMatch{}
 	for _, match := range matches {
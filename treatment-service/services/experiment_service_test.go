@@ -291,6 +291,78 @@ func (s *ExperimentServiceTestSuite) TestGetExperiment() {
 	}
 }
 
+func (s *ExperimentServiceTestSuite) TestGetExperimentSkipsShadowExperiments() {
+	rawStringSegmenter := interface{}("seg-1")
+	segment := makeSegment(&rawStringSegmenter, nil, nil, nil, nil, nil, nil)
+	experiment := makeExperimentIndex(1, 1, segment, _pubsub.Experiment_Default)
+	shadowExperiment := makeExperimentIndex(1, 2, segment, _pubsub.Experiment_Default)
+	shadowExperiment.Experiment.Shadow = true
+
+	localStorage := models.LocalStorage{
+		ProjectSettings: []*_pubsub.ProjectSettings{{ProjectId: 1, Segmenters: &_pubsub.Segmenters{}}},
+		Experiments: map[models.ProjectId][]*models.ExperimentIndex{
+			1: {experiment, shadowExperiment},
+		},
+		ProjectSegmenters: map[uint32]map[string]schema.SegmenterType{
+			1: {"string_segmenter": "string"},
+		},
+	}
+	svc, err := NewExperimentService(&localStorage)
+	s.Suite.Require().NoError(err)
+
+	// The shadow experiment matches the same segment, but it is not assigned and does not conflict with the other
+	// experiment
+	reqFilter := map[string][]*_segmenters.SegmenterValue{
+		"string_segmenter": {{Value: &_segmenters.SegmenterValue_String_{String_: "seg-1"}}},
+	}
+	_, exp, err := svc.GetExperiment(1, reqFilter)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(experiment.Experiment, exp)
+
+	// Only shadow experiments match the segment
+	localStorage.Experiments[1] = []*models.ExperimentIndex{shadowExperiment}
+	_, exp, err = svc.GetExperiment(1, reqFilter)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Nil(exp)
+}
+
+func (s *ExperimentServiceTestSuite) TestGetExperimentResolvesOverlapsByPriority() {
+	rawStringSegmenter := interface{}("seg-1")
+	segment := makeSegment(&rawStringSegmenter, nil, nil, nil, nil, nil, nil)
+	lowPriorityExperiment := makeExperimentIndex(1, 1, segment, _pubsub.Experiment_Default)
+	highPriorityExperiment := makeExperimentIndex(1, 2, segment, _pubsub.Experiment_Default)
+	highPriorityExperiment.Experiment.Priority = 5
+	tiedExperiment := makeExperimentIndex(1, 3, segment, _pubsub.Experiment_Default)
+	tiedExperiment.Experiment.Priority = 5
+
+	projectSettings := &_pubsub.ProjectSettings{ProjectId: 1, Segmenters: &_pubsub.Segmenters{}}
+	localStorage := models.LocalStorage{
+		ProjectSettings: []*_pubsub.ProjectSettings{projectSettings},
+		Experiments: map[models.ProjectId][]*models.ExperimentIndex{
+			1: {tiedExperiment, lowPriorityExperiment, highPriorityExperiment},
+		},
+		ProjectSegmenters: map[uint32]map[string]schema.SegmenterType{
+			1: {"string_segmenter": "string"},
+		},
+	}
+	svc, err := NewExperimentService(&localStorage)
+	s.Suite.Require().NoError(err)
+	reqFilter := map[string][]*_segmenters.SegmenterValue{
+		"string_segmenter": {{Value: &_segmenters.SegmenterValue_String_{String_: "seg-1"}}},
+	}
+
+	// The overlaps are not resolved by priority unless the project allows it
+	_, exp, err := svc.GetExperiment(1, reqFilter)
+	s.Suite.Assert().EqualError(err, "more than 1 experiment of the same match strength encountered")
+	s.Suite.Assert().Nil(exp)
+
+	// The highest priority is assigned, and of the tied experiments, the one created first
+	projectSettings.PriorityOverlapResolution = true
+	_, exp, err = svc.GetExperiment(1, reqFilter)
+	s.Suite.Require().NoError(err)
+	s.Suite.Assert().Equal(highPriorityExperiment.Experiment, exp)
+}
+
 func (s *ExperimentServiceTestSuite) TestDumpExperiments() {
 	filename, err := s.ExperimentService.DumpExperiments("/tmp")
 	s.Suite.T().Log(filename)
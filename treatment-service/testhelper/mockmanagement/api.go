@@ -126,13 +126,59 @@ type CreateExperimentRequestBody struct {
 
 // CreateProjectSettingsRequestBody defines model for CreateProjectSettingsRequestBody.
 type CreateProjectSettingsRequestBody struct {
-	EnableS2idClustering *bool                          `json:"enable_s2id_clustering,omitempty"`
-	RandomizationKey     string                         `json:"randomization_key"`
-	Segmenters           externalRef0.ProjectSegmenters `json:"segmenters"`
+	// Whether the experiments with two or more treatments of identical configurations are blocked
+	BlockDuplicateTreatmentConfigs *bool `json:"block_duplicate_treatment_configs,omitempty"`
+
+	// Whether the experiments whose segments list as many values of a segmenter as it allows are blocked
+	BlockExhaustiveSegmentValues *bool `json:"block_exhaustive_segment_values,omitempty"`
+	// Whether disabling a default tier experiment that would orphan an override tier experiment is blocked
+	BlockOrphanedOverrides *bool                           `json:"block_orphaned_overrides,omitempty"`
+	DefaultSegment         *externalRef0.ExperimentSegment `json:"default_segment,omitempty"`
+	DefaultTier            *externalRef0.ExperimentTier    `json:"default_tier,omitempty"`
+	DefaultType            *externalRef0.ExperimentType    `json:"default_type,omitempty"`
+	EnableS2idClustering   *bool                           `json:"enable_s2id_clustering,omitempty"`
+
+	// Names of the registered custom validators that are run on the experiments
+	EnabledValidators *[]string `json:"enabled_validators,omitempty"`
+
+	// Number of running experiments beyond which creating or enabling an active experiment is blocked
+	HardMaxActiveExperiments *int `json:"hard_max_active_experiments,omitempty"`
+
+	// Maximum size in bytes of the configuration of each treatment, marshaled as JSON
+	MaxTreatmentConfigBytes *int `json:"max_treatment_config_bytes,omitempty"`
+
+	// Segmenters that are ignored when checking the orthogonality of the experiments
+	OrthogonalityExemptSegmenters *[]string `json:"orthogonality_exempt_segmenters,omitempty"`
+
+	// Number of seconds by which the end of the time window in which the other experiments are checked for orthogonality is extended
+	OrthogonalityLookaheadSeconds *int `json:"orthogonality_lookahead_seconds,omitempty"`
+
+	// Whether overlapping experiments of the same tier are allowed, in which case the one with the higher priority takes effect
+	PriorityOverlapResolution *bool  `json:"priority_overlap_resolution,omitempty"`
+	RandomizationKey          string `json:"randomization_key"`
+
+	// Groups of segmenters that must be set together, i.e., an experiment that sets any segmenter of a group must set all of them
+	RequiredSegmenterCombinations *[][]string                    `json:"required_segmenter_combinations,omitempty"`
+	Segmenters                    externalRef0.ProjectSegmenters `json:"segmenters"`
+
+	// Number of running experiments beyond which creating or enabling an active experiment is allowed with a warning
+	SoftMaxActiveExperiments *int `json:"soft_max_active_experiments,omitempty"`
+
+	// Object that is deep-merged into the configuration of each treatment before it is validated
+	TreatmentConfigDefaults *map[string]interface{} `json:"treatment_config_defaults,omitempty"`
+
+	// Regular expression that the names of the treatments of the experiments must match
+	TreatmentNamePattern *string `json:"treatment_name_pattern,omitempty"`
 
 	// Object containing information to define a valid treatment schema
 	TreatmentSchema *externalRef0.TreatmentSchema `json:"treatment_schema,omitempty"`
-	ValidationUrl   *string                       `json:"validation_url,omitempty"`
+
+	// Whether the segments of the experiments are also stored and matched as typed values
+	TypedSegmentStorage *bool   `json:"typed_segment_storage,omitempty"`
+	ValidationUrl       *string `json:"validation_url,omitempty"`
+
+	// Token bucket rate limit on the requests to the validation URL of a project
+	ValidationUrlRateLimit *externalRef0.ValidationUrlRateLimit `json:"validation_url_rate_limit,omitempty"`
 }
 
 // CreateSegmenterRequestBody defines model for CreateSegmenterRequestBody.
@@ -162,13 +208,59 @@ type UpdateExperimentRequestBody struct {
 
 // UpdateProjectSettingsRequestBody defines model for UpdateProjectSettingsRequestBody.
 type UpdateProjectSettingsRequestBody struct {
-	EnableS2idClustering *bool                          `json:"enable_s2id_clustering,omitempty"`
-	RandomizationKey     string                         `json:"randomization_key"`
-	Segmenters           externalRef0.ProjectSegmenters `json:"segmenters"`
+	// Whether the experiments with two or more treatments of identical configurations are blocked
+	BlockDuplicateTreatmentConfigs *bool `json:"block_duplicate_treatment_configs,omitempty"`
+
+	// Whether the experiments whose segments list as many values of a segmenter as it allows are blocked
+	BlockExhaustiveSegmentValues *bool `json:"block_exhaustive_segment_values,omitempty"`
+	// Whether disabling a default tier experiment that would orphan an override tier experiment is blocked
+	BlockOrphanedOverrides *bool                           `json:"block_orphaned_overrides,omitempty"`
+	DefaultSegment         *externalRef0.ExperimentSegment `json:"default_segment,omitempty"`
+	DefaultTier            *externalRef0.ExperimentTier    `json:"default_tier,omitempty"`
+	DefaultType            *externalRef0.ExperimentType    `json:"default_type,omitempty"`
+	EnableS2idClustering   *bool                           `json:"enable_s2id_clustering,omitempty"`
+
+	// Names of the registered custom validators that are run on the experiments
+	EnabledValidators *[]string `json:"enabled_validators,omitempty"`
+
+	// Number of running experiments beyond which creating or enabling an active experiment is blocked
+	HardMaxActiveExperiments *int `json:"hard_max_active_experiments,omitempty"`
+
+	// Maximum size in bytes of the configuration of each treatment, marshaled as JSON
+	MaxTreatmentConfigBytes *int `json:"max_treatment_config_bytes,omitempty"`
+
+	// Segmenters that are ignored when checking the orthogonality of the experiments
+	OrthogonalityExemptSegmenters *[]string `json:"orthogonality_exempt_segmenters,omitempty"`
+
+	// Number of seconds by which the end of the time window in which the other experiments are checked for orthogonality is extended
+	OrthogonalityLookaheadSeconds *int `json:"orthogonality_lookahead_seconds,omitempty"`
+
+	// Whether overlapping experiments of the same tier are allowed, in which case the one with the higher priority takes effect
+	PriorityOverlapResolution *bool  `json:"priority_overlap_resolution,omitempty"`
+	RandomizationKey          string `json:"randomization_key"`
+
+	// Groups of segmenters that must be set together, i.e., an experiment that sets any segmenter of a group must set all of them
+	RequiredSegmenterCombinations *[][]string                    `json:"required_segmenter_combinations,omitempty"`
+	Segmenters                    externalRef0.ProjectSegmenters `json:"segmenters"`
+
+	// Number of running experiments beyond which creating or enabling an active experiment is allowed with a warning
+	SoftMaxActiveExperiments *int `json:"soft_max_active_experiments,omitempty"`
+
+	// Object that is deep-merged into the configuration of each treatment before it is validated
+	TreatmentConfigDefaults *map[string]interface{} `json:"treatment_config_defaults,omitempty"`
+
+	// Regular expression that the names of the treatments of the experiments must match
+	TreatmentNamePattern *string `json:"treatment_name_pattern,omitempty"`
 
 	// Object containing information to define a valid treatment schema
 	TreatmentSchema *externalRef0.TreatmentSchema `json:"treatment_schema,omitempty"`
-	ValidationUrl   *string                       `json:"validation_url,omitempty"`
+
+	// Whether the segments of the experiments are also stored and matched as typed values
+	TypedSegmentStorage *bool   `json:"typed_segment_storage,omitempty"`
+	ValidationUrl       *string `json:"validation_url,omitempty"`
+
+	// Token bucket rate limit on the requests to the validation URL of a project
+	ValidationUrlRateLimit *externalRef0.ValidationUrlRateLimit `json:"validation_url_rate_limit,omitempty"`
 }
 
 // UpdateSegmenterRequestBody defines model for UpdateSegmenterRequestBody.